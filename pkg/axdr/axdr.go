@@ -12,39 +12,40 @@ import (
 	"time"
 )
 
-type dataTag int
+// DataTag is the type of a DlmsData.
+type DataTag int
 
 const (
-	TagNull               dataTag = 0
-	TagArray              dataTag = 1
-	TagStructure          dataTag = 2
-	TagBoolean            dataTag = 3
-	TagBitString          dataTag = 4
-	TagDoubleLong         dataTag = 5
-	TagDoubleLongUnsigned dataTag = 6
-	TagFloatingPoint      dataTag = 7
-	TagOctetString        dataTag = 9
-	TagVisibleString      dataTag = 10
-	TagUTF8String         dataTag = 12
-	TagBCD                dataTag = 13
-	TagInteger            dataTag = 15
-	TagLong               dataTag = 16
-	TagUnsigned           dataTag = 17
-	TagLongUnsigned       dataTag = 18
-	TagCompactArray       dataTag = 19
-	TagLong64             dataTag = 20
-	TagLong64Unsigned     dataTag = 21
-	TagEnum               dataTag = 22
-	TagFloat32            dataTag = 23
-	TagFloat64            dataTag = 24
-	TagDateTime           dataTag = 25
-	TagDate               dataTag = 26
-	TagTime               dataTag = 27
-	TagDontCare           dataTag = 255
+	TagNull               DataTag = 0
+	TagArray              DataTag = 1
+	TagStructure          DataTag = 2
+	TagBoolean            DataTag = 3
+	TagBitString          DataTag = 4
+	TagDoubleLong         DataTag = 5
+	TagDoubleLongUnsigned DataTag = 6
+	TagFloatingPoint      DataTag = 7
+	TagOctetString        DataTag = 9
+	TagVisibleString      DataTag = 10
+	TagUTF8String         DataTag = 12
+	TagBCD                DataTag = 13
+	TagInteger            DataTag = 15
+	TagLong               DataTag = 16
+	TagUnsigned           DataTag = 17
+	TagLongUnsigned       DataTag = 18
+	TagCompactArray       DataTag = 19
+	TagLong64             DataTag = 20
+	TagLong64Unsigned     DataTag = 21
+	TagEnum               DataTag = 22
+	TagFloat32            DataTag = 23
+	TagFloat64            DataTag = 24
+	TagDateTime           DataTag = 25
+	TagDate               DataTag = 26
+	TagTime               DataTag = 27
+	TagDontCare           DataTag = 255
 )
 
 type DlmsData struct {
-	Tag   dataTag
+	Tag   DataTag
 	Value interface{}
 }
 
@@ -151,7 +152,7 @@ func CreateAxdrTime(data time.Time) *DlmsData {
 // It will panic if Value is nil, data type does not match
 // the Tag or if failed happen in encoding length/value level.
 func (d *DlmsData) Encode() (out []byte, err error) {
-	if d.Value == nil {
+	if d.Value == nil && d.Tag != TagNull {
 		err = fmt.Errorf("value to encode cannot be nil")
 		return
 	}
@@ -162,7 +163,7 @@ func (d *DlmsData) Encode() (out []byte, err error) {
 
 	switch d.Tag {
 	case TagNull:
-		rawValue = []byte{0}
+		rawValue = []byte{}
 
 	case TagArray:
 		data, ok := d.Value.([]*DlmsData)
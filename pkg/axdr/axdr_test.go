@@ -382,6 +382,13 @@ func TestDlmsData_NilValue(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestDlmsData_Null(t *testing.T) {
+	tDD := DlmsData{Tag: TagNull, Value: nil}
+	encoded, err := tDD.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, decodeHexString("00"), encoded)
+}
+
 func TestDlmsData_WrongBoolValue(t *testing.T) {
 	tDD := DlmsData{Tag: TagBoolean, Value: 1234}
 	_, err := tDD.Encode()
@@ -13,7 +13,7 @@ import (
 )
 
 type Decoder struct {
-	tag dataTag
+	tag DataTag
 }
 
 //nolint:gochecknoglobals
@@ -21,9 +21,9 @@ var ReversedTimeZone = false
 
 var ErrLengthLess = errors.New("not enough byte length provided")
 
-// Get dataTag equivalent of supplied uint8
-func getDataTag(in uint8) (t dataTag, err error) {
-	mapToDataTag := map[uint8]dataTag{
+// Get DataTag equivalent of supplied uint8
+func getDataTag(in uint8) (t DataTag, err error) {
+	mapToDataTag := map[uint8]DataTag{
 		0:   TagNull,
 		1:   TagArray,
 		2:   TagStructure,
@@ -75,7 +75,7 @@ func NewDataDecoder(ori *[]byte) *Decoder {
 
 // Decode expect byte second after tag byte.
 func (dec *Decoder) Decode(ori *[]byte) (r DlmsData, err error) {
-	lengthAfterTag := map[dataTag]bool{
+	lengthAfterTag := map[DataTag]bool{
 		TagNull:               false,
 		TagArray:              true,
 		TagStructure:          true,
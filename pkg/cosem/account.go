@@ -0,0 +1,577 @@
+package cosem
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const (
+	AccountClassID = 111
+	// AccountLogicalName is the logical name of the first account object of the meter
+	AccountLogicalName = "0.0.19.0.0.255"
+)
+
+const (
+	accountModeAndStatus                = 2
+	accountCurrentCreditInUse           = 3
+	accountCurrentCreditStatus          = 4
+	accountAvailableCredit              = 5
+	accountAmountToClear                = 6
+	accountClearanceThreshold           = 7
+	accountAggregatedDebt               = 8
+	accountCreditReferenceList          = 9
+	accountChargeReferenceList          = 10
+	accountCreditChargeConfiguration    = 11
+	accountTokenGatewayConfiguration    = 12
+	accountActivationTime               = 13
+	accountClosureTime                  = 14
+	accountCurrency                     = 15
+	accountLowCreditThreshold           = 16
+	accountNextCreditAvailableThreshold = 17
+	accountMaxProvision                 = 18
+	accountMaxProvisionPeriod           = 19
+	accountActivateAccount              = 1
+	accountCloseAccount                 = 2
+	accountResetAccount                 = 3
+)
+
+// AccountMode is the way the account is paid.
+type AccountMode uint8
+
+const (
+	AccountModeCredit     AccountMode = 1
+	AccountModePrepayment AccountMode = 2
+)
+
+func (m AccountMode) String() string {
+	switch m {
+	case AccountModeCredit:
+		return "credit"
+	case AccountModePrepayment:
+		return "prepayment"
+	default:
+		return fmt.Sprintf("unknown (%d)", uint8(m))
+	}
+}
+
+// AccountStatus is the state of the account.
+type AccountStatus uint8
+
+const (
+	AccountStatusNew    AccountStatus = 1
+	AccountStatusActive AccountStatus = 2
+	AccountStatusClosed AccountStatus = 3
+)
+
+func (s AccountStatus) String() string {
+	switch s {
+	case AccountStatusNew:
+		return "new"
+	case AccountStatusActive:
+		return "active"
+	case AccountStatusClosed:
+		return "closed"
+	default:
+		return fmt.Sprintf("unknown (%d)", uint8(s))
+	}
+}
+
+// CurrentCreditStatus is the current_credit_status of an account.
+type CurrentCreditStatus uint8
+
+const (
+	CurrentCreditInCredit              CurrentCreditStatus = 0x01
+	CurrentCreditLowCredit             CurrentCreditStatus = 0x02
+	CurrentCreditNextCreditEnabled     CurrentCreditStatus = 0x04
+	CurrentCreditNextCreditSelectable  CurrentCreditStatus = 0x08
+	CurrentCreditNextCreditSelected    CurrentCreditStatus = 0x10
+	CurrentCreditSelectableCreditInUse CurrentCreditStatus = 0x20
+	CurrentCreditOutOfCredit           CurrentCreditStatus = 0x40
+)
+
+// Has returns true if all the bits of flag are set.
+func (s CurrentCreditStatus) Has(flag CurrentCreditStatus) bool {
+	return s&flag == flag
+}
+
+// CollectionConfiguration defines when a charge collects from a credit.
+type CollectionConfiguration uint8
+
+const (
+	CollectWhenSupplyDisconnected  CollectionConfiguration = 0x01
+	CollectInLoadLimitingPeriods   CollectionConfiguration = 0x02
+	CollectInFriendlyCreditPeriods CollectionConfiguration = 0x04
+)
+
+// Has returns true if all the bits of flag are set.
+func (c CollectionConfiguration) Has(flag CollectionConfiguration) bool {
+	return c&flag == flag
+}
+
+// CreditChargeConfiguration links a credit with a charge collected from it.
+type CreditChargeConfiguration struct {
+	CreditReference dlms.Obis
+	ChargeReference dlms.Obis
+	Collection      CollectionConfiguration
+}
+
+// Data returns the credit charge configuration as A-XDR data.
+func (c CreditChargeConfiguration) Data() *axdr.DlmsData {
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrOctetString(c.CreditReference.String()),
+		axdr.CreateAxdrOctetString(c.ChargeReference.String()),
+		createBitString(uint32(c.Collection), 3),
+	})
+}
+
+func decodeCreditChargeConfiguration(data axdr.DlmsData) (c CreditChargeConfiguration, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 3)
+	if err != nil {
+		return
+	}
+
+	if c.CreditReference, err = dlms.DecodeLogicalName(*fields[0]); err != nil {
+		return
+	}
+	if c.ChargeReference, err = dlms.DecodeLogicalName(*fields[1]); err != nil {
+		return
+	}
+
+	collection, err := decodeBitString(*fields[2])
+	c.Collection = CollectionConfiguration(collection)
+	return
+}
+
+// TokenGatewayConfiguration is the percentage of the tokens added to a credit.
+type TokenGatewayConfiguration struct {
+	CreditReference dlms.Obis
+	TokenProportion uint8
+}
+
+// Data returns the token gateway configuration as A-XDR data.
+func (c TokenGatewayConfiguration) Data() *axdr.DlmsData {
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrOctetString(c.CreditReference.String()),
+		axdr.CreateAxdrUnsigned(c.TokenProportion),
+	})
+}
+
+func decodeTokenGatewayConfiguration(data axdr.DlmsData) (c TokenGatewayConfiguration, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 2)
+	if err != nil {
+		return
+	}
+
+	if c.CreditReference, err = dlms.DecodeLogicalName(*fields[0]); err != nil {
+		return
+	}
+
+	proportion, ok := fields[1].Value.(uint8)
+	if !ok {
+		return c, fmt.Errorf("invalid token proportion %v", fields[1].Value)
+	}
+	c.TokenProportion = proportion
+
+	return
+}
+
+// CurrencyUnit is what the amounts of an account measure.
+type CurrencyUnit uint8
+
+const (
+	CurrencyUnitTime        CurrencyUnit = 0
+	CurrencyUnitConsumption CurrencyUnit = 1
+	CurrencyUnitMonetary    CurrencyUnit = 2
+)
+
+// Currency is the currency of the amounts of an account. Amounts are multiplied by 10^Scale.
+type Currency struct {
+	Name  string
+	Scale int8
+	Unit  CurrencyUnit
+}
+
+// Data returns the currency as A-XDR data.
+func (c Currency) Data() *axdr.DlmsData {
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrVisibleString(c.Name),
+		axdr.CreateAxdrInteger(c.Scale),
+		axdr.CreateAxdrEnum(uint8(c.Unit)),
+	})
+}
+
+// Format returns the scaled amount followed by the name of the currency.
+func (c Currency) Format(amount int32) string {
+	value, _ := ScalerUnit{Scaler: c.Scale}.Decimal(*axdr.CreateAxdrDoubleLong(amount))
+	if c.Name == "" {
+		return value
+	}
+
+	return value + " " + c.Name
+}
+
+func decodeCurrency(data axdr.DlmsData) (c Currency, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 3)
+	if err != nil {
+		return
+	}
+
+	name, ok1 := fields[0].Value.(string)
+	scale, ok2 := fields[1].Value.(int8)
+	unit, ok3 := fields[2].Value.(uint8)
+	if !ok1 || !ok2 || !ok3 {
+		return c, fmt.Errorf("invalid currency")
+	}
+
+	return Currency{Name: name, Scale: scale, Unit: CurrencyUnit(unit)}, nil
+}
+
+// AccountValue holds the attributes of an account. Amounts are in the currency of the account.
+type AccountValue struct {
+	Mode                         AccountMode
+	Status                       AccountStatus
+	CurrentCreditInUse           uint8
+	CurrentCreditStatus          CurrentCreditStatus
+	AvailableCredit              int32
+	AmountToClear                int32
+	ClearanceThreshold           int32
+	AggregatedDebt               int32
+	CreditReferences             []dlms.Obis
+	ChargeReferences             []dlms.Obis
+	CreditChargeConfigurations   []CreditChargeConfiguration
+	TokenGatewayConfigurations   []TokenGatewayConfiguration
+	ActivationTime               DateTime
+	ClosureTime                  DateTime
+	Currency                     Currency
+	LowCreditThreshold           int32
+	NextCreditAvailableThreshold int32
+	MaxProvision                 uint16
+	MaxProvisionPeriod           time.Duration
+}
+
+// Account is an instance of the Account interface class (class_id 111).
+type Account struct {
+	object
+}
+
+func NewAccount(client dlms.Client, logicalName string) *Account {
+	return &Account{object{client: client, classID: AccountClassID, logicalName: logicalName}}
+}
+
+// Read reads all the attributes of the account.
+func (a *Account) Read() (v AccountValue, err error) {
+	if v.Mode, v.Status, err = a.ModeAndStatus(); err != nil {
+		return
+	}
+	if err = a.get(accountCurrentCreditInUse, &v.CurrentCreditInUse); err != nil {
+		return
+	}
+	if v.CurrentCreditStatus, err = a.CurrentCreditStatus(); err != nil {
+		return
+	}
+	if v.AvailableCredit, err = a.AvailableCredit(); err != nil {
+		return
+	}
+	if err = a.get(accountAmountToClear, &v.AmountToClear); err != nil {
+		return
+	}
+	if err = a.get(accountClearanceThreshold, &v.ClearanceThreshold); err != nil {
+		return
+	}
+	if err = a.get(accountAggregatedDebt, &v.AggregatedDebt); err != nil {
+		return
+	}
+	if v.CreditReferences, err = a.references(accountCreditReferenceList); err != nil {
+		return
+	}
+	if v.ChargeReferences, err = a.references(accountChargeReferenceList); err != nil {
+		return
+	}
+	if v.CreditChargeConfigurations, err = a.CreditChargeConfigurations(); err != nil {
+		return
+	}
+	if v.TokenGatewayConfigurations, err = a.TokenGatewayConfigurations(); err != nil {
+		return
+	}
+	if v.ActivationTime, err = a.dateTime(accountActivationTime); err != nil {
+		return
+	}
+	if v.ClosureTime, err = a.dateTime(accountClosureTime); err != nil {
+		return
+	}
+	if v.Currency, err = a.Currency(); err != nil {
+		return
+	}
+	if err = a.get(accountLowCreditThreshold, &v.LowCreditThreshold); err != nil {
+		return
+	}
+	if err = a.get(accountNextCreditAvailableThreshold, &v.NextCreditAvailableThreshold); err != nil {
+		return
+	}
+	if err = a.get(accountMaxProvision, &v.MaxProvision); err != nil {
+		return
+	}
+
+	var period uint32
+	err = a.get(accountMaxProvisionPeriod, &period)
+	v.MaxProvisionPeriod = time.Duration(period) * time.Second
+
+	return
+}
+
+// ModeAndStatus reads the mode and the status of the account.
+func (a *Account) ModeAndStatus() (AccountMode, AccountStatus, error) {
+	var data axdr.DlmsData
+
+	err := a.get(accountModeAndStatus, &data)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 2)
+	if err != nil {
+		return 0, 0, a.invalidData(accountModeAndStatus, err)
+	}
+
+	mode, ok1 := fields[0].Value.(uint8)
+	status, ok2 := fields[1].Value.(uint8)
+	if !ok1 || !ok2 {
+		return 0, 0, a.invalidData(accountModeAndStatus, fmt.Errorf("unexpected value %v", data.Value))
+	}
+
+	return AccountMode(mode), AccountStatus(status), nil
+}
+
+// SetMode sets the mode of the account, keeping its status.
+func (a *Account) SetMode(mode AccountMode) error {
+	if mode != AccountModeCredit && mode != AccountModePrepayment {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("invalid account mode %d", mode))
+	}
+
+	_, status, err := a.ModeAndStatus()
+	if err != nil {
+		return err
+	}
+
+	return a.set(accountModeAndStatus, axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrEnum(uint8(mode)),
+		axdr.CreateAxdrEnum(uint8(status)),
+	}))
+}
+
+// CurrentCreditStatus reads the status of the credit in use.
+func (a *Account) CurrentCreditStatus() (CurrentCreditStatus, error) {
+	var data axdr.DlmsData
+
+	err := a.get(accountCurrentCreditStatus, &data)
+	if err != nil {
+		return 0, err
+	}
+
+	status, err := decodeBitString(data)
+	if err != nil {
+		return 0, a.invalidData(accountCurrentCreditStatus, err)
+	}
+
+	return CurrentCreditStatus(status), nil
+}
+
+// AvailableCredit reads the sum of the credits that can be used.
+func (a *Account) AvailableCredit() (credit int32, err error) {
+	err = a.get(accountAvailableCredit, &credit)
+	return
+}
+
+// Balance reads the available credit and formats it with the currency of the account.
+func (a *Account) Balance() (string, error) {
+	credit, err := a.AvailableCredit()
+	if err != nil {
+		return "", err
+	}
+
+	currency, err := a.Currency()
+	if err != nil {
+		return "", err
+	}
+
+	return currency.Format(credit), nil
+}
+
+// Currency reads the currency of the amounts of the account.
+func (a *Account) Currency() (Currency, error) {
+	var data axdr.DlmsData
+
+	err := a.get(accountCurrency, &data)
+	if err != nil {
+		return Currency{}, err
+	}
+
+	c, err := decodeCurrency(data)
+	if err != nil {
+		return c, a.invalidData(accountCurrency, err)
+	}
+
+	return c, nil
+}
+
+// CreditChargeConfigurations reads which charges are collected from each credit.
+func (a *Account) CreditChargeConfigurations() ([]CreditChargeConfiguration, error) {
+	elements, err := a.array(accountCreditChargeConfiguration)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]CreditChargeConfiguration, len(elements))
+	for i, e := range elements {
+		if out[i], err = decodeCreditChargeConfiguration(*e); err != nil {
+			return nil, a.invalidData(accountCreditChargeConfiguration, err)
+		}
+	}
+
+	return out, nil
+}
+
+// SetCreditChargeConfigurations sets which charges are collected from each credit. The credits and
+// charges must be in the reference lists of the account.
+func (a *Account) SetCreditChargeConfigurations(configurations []CreditChargeConfiguration) error {
+	credits, err := a.references(accountCreditReferenceList)
+	if err != nil {
+		return err
+	}
+
+	charges, err := a.references(accountChargeReferenceList)
+	if err != nil {
+		return err
+	}
+
+	list := make([]*axdr.DlmsData, len(configurations))
+	for i, c := range configurations {
+		if !containsObis(credits, c.CreditReference) {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("credit %s not in the account", c.CreditReference.String()))
+		}
+		if !containsObis(charges, c.ChargeReference) {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("charge %s not in the account", c.ChargeReference.String()))
+		}
+		list[i] = c.Data()
+	}
+
+	return a.set(accountCreditChargeConfiguration, axdr.CreateAxdrArray(list))
+}
+
+// TokenGatewayConfigurations reads how the tokens are split between the credits.
+func (a *Account) TokenGatewayConfigurations() ([]TokenGatewayConfiguration, error) {
+	elements, err := a.array(accountTokenGatewayConfiguration)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]TokenGatewayConfiguration, len(elements))
+	for i, e := range elements {
+		if out[i], err = decodeTokenGatewayConfiguration(*e); err != nil {
+			return nil, a.invalidData(accountTokenGatewayConfiguration, err)
+		}
+	}
+
+	return out, nil
+}
+
+// SetTokenGatewayConfigurations sets how the tokens are split between the credits. The credits must be
+// in the reference list of the account and the proportions must add up to 100.
+func (a *Account) SetTokenGatewayConfigurations(configurations []TokenGatewayConfiguration) error {
+	credits, err := a.references(accountCreditReferenceList)
+	if err != nil {
+		return err
+	}
+
+	total := 0
+	list := make([]*axdr.DlmsData, len(configurations))
+	for i, c := range configurations {
+		if !containsObis(credits, c.CreditReference) {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("credit %s not in the account", c.CreditReference.String()))
+		}
+		total += int(c.TokenProportion)
+		list[i] = c.Data()
+	}
+
+	if len(configurations) > 0 && total != 100 {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("token proportions add up to %d%%", total))
+	}
+
+	return a.set(accountTokenGatewayConfiguration, axdr.CreateAxdrArray(list))
+}
+
+// ActivateAccount changes the status of the account to active.
+func (a *Account) ActivateAccount() error {
+	return a.action(accountActivateAccount, int8(0))
+}
+
+// CloseAccount changes the status of the account to closed.
+func (a *Account) CloseAccount() error {
+	return a.action(accountCloseAccount, int8(0))
+}
+
+// ResetAccount changes the status of the account to new and clears its amounts.
+func (a *Account) ResetAccount() error {
+	return a.action(accountResetAccount, int8(0))
+}
+
+func (a *Account) array(attributeID int8) ([]*axdr.DlmsData, error) {
+	var data axdr.DlmsData
+
+	err := a.get(attributeID, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	elements, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return nil, a.invalidData(attributeID, err)
+	}
+
+	return elements, nil
+}
+
+func (a *Account) references(attributeID int8) ([]dlms.Obis, error) {
+	elements, err := a.array(attributeID)
+	if err != nil {
+		return nil, err
+	}
+
+	references := make([]dlms.Obis, len(elements))
+	for i, e := range elements {
+		if references[i], err = dlms.DecodeLogicalName(*e); err != nil {
+			return nil, a.invalidData(attributeID, err)
+		}
+	}
+
+	return references, nil
+}
+
+func (a *Account) dateTime(attributeID int8) (DateTime, error) {
+	var data axdr.DlmsData
+
+	err := a.get(attributeID, &data)
+	if err != nil {
+		return DateTime{}, err
+	}
+
+	dt, err := DecodeDateTime(data)
+	if err != nil {
+		return dt, a.invalidData(attributeID, err)
+	}
+
+	return dt, nil
+}
+
+func containsObis(list []dlms.Obis, ln dlms.Obis) bool {
+	for _, e := range list {
+		if e == ln {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,123 @@
+package cosem_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	tokenCreditLogicalName     = "0.0.19.10.0.255"
+	emergencyCreditLogicalName = "0.0.19.10.1.255"
+	standingChargeLogicalName  = "0.0.19.20.0.255"
+)
+
+func TestAccount(t *testing.T) {
+	status := cosem.AccountStatusNew
+	references := func(lns ...string) axdr.DlmsData {
+		list := make([]*axdr.DlmsData, len(lns))
+		for i, ln := range lns {
+			list[i] = axdr.CreateAxdrOctetString(ln)
+		}
+		return *axdr.CreateAxdrArray(list)
+	}
+	setStatus := func(s cosem.AccountStatus) dlmsserver.ActionHandler {
+		return func(dlms.MethodDescriptor, *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			status = s
+			return nil, dlms.TagActSuccess
+		}
+	}
+
+	o := dlmsserver.NewObject(cosem.AccountClassID, 0, cosem.AccountLogicalName).
+		SetAttribute(2, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			return *axdr.CreateAxdrStructure([]*axdr.DlmsData{axdr.CreateAxdrEnum(uint8(cosem.AccountModePrepayment)), axdr.CreateAxdrEnum(uint8(status))}), dlms.TagAccSuccess
+		}, nil).
+		SetValue(3, *axdr.CreateAxdrUnsigned(1), false).
+		SetValue(4, *axdr.CreateAxdrBitString("11000000"), false).
+		SetValue(5, *axdr.CreateAxdrDoubleLong(1234), false).
+		SetValue(6, *axdr.CreateAxdrDoubleLong(0), false).
+		SetValue(7, *axdr.CreateAxdrDoubleLong(0), true).
+		SetValue(8, *axdr.CreateAxdrDoubleLong(-50), false).
+		SetValue(9, references(tokenCreditLogicalName, emergencyCreditLogicalName), true).
+		SetValue(10, references(standingChargeLogicalName), true).
+		SetValue(11, *axdr.CreateAxdrArray([]*axdr.DlmsData{}), true).
+		SetValue(12, *axdr.CreateAxdrArray([]*axdr.DlmsData{}), true).
+		SetValue(13, *axdr.CreateAxdrOctetString("07e8010101000000ff800000"), false).
+		SetValue(14, *axdr.CreateAxdrOctetString("ffffffffffffffffff800000"), false).
+		SetValue(15, *cosem.Currency{Name: "EUR", Scale: -2, Unit: cosem.CurrencyUnitMonetary}.Data(), true).
+		SetValue(16, *axdr.CreateAxdrDoubleLong(500), true).
+		SetValue(17, *axdr.CreateAxdrDoubleLong(100), true).
+		SetValue(18, *axdr.CreateAxdrLongUnsigned(10000), true).
+		SetValue(19, *axdr.CreateAxdrDoubleLongUnsigned(86400), true).
+		SetMethod(1, setStatus(cosem.AccountStatusActive)).
+		SetMethod(2, setStatus(cosem.AccountStatusClosed)).
+		SetMethod(3, setStatus(cosem.AccountStatusNew))
+
+	a := cosem.NewAccount(connect(t, o), cosem.AccountLogicalName)
+
+	v, err := a.Read()
+	require.NoError(t, err)
+	assert.Equal(t, cosem.AccountModePrepayment, v.Mode)
+	assert.Equal(t, cosem.AccountStatusNew, v.Status)
+	assert.True(t, v.CurrentCreditStatus.Has(cosem.CurrentCreditInCredit|cosem.CurrentCreditLowCredit))
+	assert.False(t, v.CurrentCreditStatus.Has(cosem.CurrentCreditOutOfCredit))
+	assert.Equal(t, int32(1234), v.AvailableCredit)
+	assert.Equal(t, int32(-50), v.AggregatedDebt)
+	assert.Equal(t, []dlms.Obis{*dlms.CreateObis(tokenCreditLogicalName), *dlms.CreateObis(emergencyCreditLogicalName)}, v.CreditReferences)
+	assert.Equal(t, []dlms.Obis{*dlms.CreateObis(standingChargeLogicalName)}, v.ChargeReferences)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), v.ActivationTime.Time.UTC())
+	assert.Equal(t, cosem.Currency{Name: "EUR", Scale: -2, Unit: cosem.CurrencyUnitMonetary}, v.Currency)
+	assert.Equal(t, uint16(10000), v.MaxProvision)
+	assert.Equal(t, 24*time.Hour, v.MaxProvisionPeriod)
+
+	balance, err := a.Balance()
+	require.NoError(t, err)
+	assert.Equal(t, "12.34 EUR", balance)
+
+	require.NoError(t, a.ActivateAccount())
+	_, s, err := a.ModeAndStatus()
+	require.NoError(t, err)
+	assert.Equal(t, cosem.AccountStatusActive, s)
+
+	// Credit charge and token gateway configurations
+	configuration := cosem.CreditChargeConfiguration{
+		CreditReference: *dlms.CreateObis(tokenCreditLogicalName),
+		ChargeReference: *dlms.CreateObis(standingChargeLogicalName),
+		Collection:      cosem.CollectWhenSupplyDisconnected | cosem.CollectInFriendlyCreditPeriods,
+	}
+	require.NoError(t, a.SetCreditChargeConfigurations([]cosem.CreditChargeConfiguration{configuration}))
+	configurations, err := a.CreditChargeConfigurations()
+	require.NoError(t, err)
+	assert.Equal(t, []cosem.CreditChargeConfiguration{configuration}, configurations)
+
+	tokens := []cosem.TokenGatewayConfiguration{
+		{CreditReference: *dlms.CreateObis(tokenCreditLogicalName), TokenProportion: 80},
+		{CreditReference: *dlms.CreateObis(emergencyCreditLogicalName), TokenProportion: 20},
+	}
+	require.NoError(t, a.SetTokenGatewayConfigurations(tokens))
+	read, err := a.TokenGatewayConfigurations()
+	require.NoError(t, err)
+	assert.Equal(t, tokens, read)
+
+	tokens[1].TokenProportion = 10
+	assertErrorCode(t, a.SetTokenGatewayConfigurations(tokens), dlms.ErrorInvalidParameter)
+
+	configuration.ChargeReference = *dlms.CreateObis("0.0.19.20.9.255")
+	assertErrorCode(t, a.SetCreditChargeConfigurations([]cosem.CreditChargeConfiguration{configuration}), dlms.ErrorInvalidParameter)
+
+	require.NoError(t, a.CloseAccount())
+	_, s, err = a.ModeAndStatus()
+	require.NoError(t, err)
+	assert.Equal(t, cosem.AccountStatusClosed, s)
+}
+
+func TestCurrency_Format(t *testing.T) {
+	assert.Equal(t, "-0.05 EUR", cosem.Currency{Name: "EUR", Scale: -2}.Format(-5))
+	assert.Equal(t, "1500", cosem.Currency{Scale: 0}.Format(1500))
+}
@@ -0,0 +1,438 @@
+package cosem
+
+import (
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const (
+	ActivityCalendarClassID = 20
+	// ActivityCalendarLogicalName is the logical name of the tariff calendar of the meter
+	ActivityCalendarLogicalName = "0.0.13.0.0.255"
+)
+
+const (
+	calendarNameActive              = 2
+	calendarSeasonProfileActive     = 3
+	calendarWeekProfileTableActive  = 4
+	calendarDayProfileTableActive   = 5
+	calendarNamePassive             = 6
+	calendarSeasonProfilePassive    = 7
+	calendarWeekProfileTablePassive = 8
+	calendarDayProfileTablePassive  = 9
+	calendarActivatePassiveTime     = 10
+	calendarActivatePassiveCalendar = 1
+)
+
+// SeasonProfile defines from when a week profile is used.
+type SeasonProfile struct {
+	Name      string
+	StartDate Date
+	StartTime Time
+	WeekName  string
+}
+
+// Data returns the season profile as A-XDR data. Deviation and status of the start are not specified.
+func (s SeasonProfile) Data() *axdr.DlmsData {
+	start := append(s.StartDate.Bytes(), s.StartTime.Bytes()...)
+	start = append(start, 0x80, 0x00, NotSpecified)
+
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		createOctetString([]byte(s.Name)),
+		createOctetString(start),
+		createOctetString([]byte(s.WeekName)),
+	})
+}
+
+func decodeSeasonProfile(data axdr.DlmsData) (s SeasonProfile, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 3)
+	if err != nil {
+		return
+	}
+
+	name, err := octetString(*fields[0], 0)
+	if err != nil {
+		return
+	}
+
+	start, err := octetString(*fields[1], 12)
+	if err != nil {
+		return
+	}
+
+	week, err := octetString(*fields[2], 0)
+	if err != nil {
+		return
+	}
+
+	return SeasonProfile{Name: string(name), StartDate: decodeDate(start), StartTime: decodeTime(start[5:]), WeekName: string(week)}, nil
+}
+
+// WeekProfile defines the day profile used each day of the week, from Monday to Sunday.
+type WeekProfile struct {
+	Name   string
+	DayIDs [7]uint8
+}
+
+// Data returns the week profile as A-XDR data.
+func (w WeekProfile) Data() *axdr.DlmsData {
+	fields := []*axdr.DlmsData{createOctetString([]byte(w.Name))}
+	for _, id := range w.DayIDs {
+		fields = append(fields, axdr.CreateAxdrUnsigned(id))
+	}
+
+	return axdr.CreateAxdrStructure(fields)
+}
+
+func decodeWeekProfile(data axdr.DlmsData) (w WeekProfile, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 8)
+	if err != nil {
+		return
+	}
+
+	name, err := octetString(*fields[0], 0)
+	if err != nil {
+		return
+	}
+
+	w.Name = string(name)
+	for i := range w.DayIDs {
+		id, ok := fields[i+1].Value.(uint8)
+		if !ok {
+			err = fmt.Errorf("invalid day id %v", fields[i+1].Value)
+			return
+		}
+
+		w.DayIDs[i] = id
+	}
+
+	return
+}
+
+// DayAction is a script executed at a time of the day.
+type DayAction struct {
+	StartTime         Time
+	ScriptLogicalName dlms.Obis
+	ScriptSelector    uint16
+}
+
+// Data returns the day action as A-XDR data.
+func (a DayAction) Data() *axdr.DlmsData {
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		createOctetString(a.StartTime.Bytes()),
+		axdr.CreateAxdrOctetString(a.ScriptLogicalName.String()),
+		axdr.CreateAxdrLongUnsigned(a.ScriptSelector),
+	})
+}
+
+func decodeDayAction(data axdr.DlmsData) (a DayAction, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 3)
+	if err != nil {
+		return
+	}
+
+	start, err := octetString(*fields[0], 4)
+	if err != nil {
+		return
+	}
+
+	ln, err := dlms.DecodeLogicalName(*fields[1])
+	if err != nil {
+		return
+	}
+
+	selector, ok := fields[2].Value.(uint16)
+	if !ok {
+		err = fmt.Errorf("invalid script selector %v", fields[2].Value)
+		return
+	}
+
+	return DayAction{StartTime: decodeTime(start), ScriptLogicalName: ln, ScriptSelector: selector}, nil
+}
+
+// DayProfile is the schedule of the actions executed during a day.
+type DayProfile struct {
+	ID       uint8
+	Schedule []DayAction
+}
+
+// Data returns the day profile as A-XDR data.
+func (d DayProfile) Data() *axdr.DlmsData {
+	actions := make([]*axdr.DlmsData, len(d.Schedule))
+	for i, a := range d.Schedule {
+		actions[i] = a.Data()
+	}
+
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrUnsigned(d.ID),
+		axdr.CreateAxdrArray(actions),
+	})
+}
+
+func decodeDayProfile(data axdr.DlmsData) (d DayProfile, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 2)
+	if err != nil {
+		return
+	}
+
+	id, ok := fields[0].Value.(uint8)
+	if !ok {
+		err = fmt.Errorf("invalid day id %v", fields[0].Value)
+		return
+	}
+
+	actions, err := dlms.DataAsSlice(*fields[1], axdr.TagArray, 0)
+	if err != nil {
+		return
+	}
+
+	d.ID = id
+	d.Schedule = make([]DayAction, len(actions))
+	for i, a := range actions {
+		if d.Schedule[i], err = decodeDayAction(*a); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Calendar is a set of season, week and day profiles.
+type Calendar struct {
+	Name    string
+	Seasons []SeasonProfile
+	Weeks   []WeekProfile
+	Days    []DayProfile
+}
+
+// Validate checks the references between the profiles of the calendar: the week of each season and
+// the days of each week must exist, and names and ids must be unique. If scripts is not nil, the
+// script of each day action must be in the script table with its logical name.
+func (c Calendar) Validate(scripts map[dlms.Obis][]Script) error {
+	weeks := make(map[string]bool, len(c.Weeks))
+	for _, w := range c.Weeks {
+		if weeks[w.Name] {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("duplicated week profile %q", w.Name))
+		}
+		weeks[w.Name] = true
+	}
+
+	days := make(map[uint8]bool, len(c.Days))
+	for _, d := range c.Days {
+		if days[d.ID] {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("duplicated day profile %d", d.ID))
+		}
+		days[d.ID] = true
+	}
+
+	seasons := make(map[string]bool, len(c.Seasons))
+	for _, s := range c.Seasons {
+		if seasons[s.Name] {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("duplicated season profile %q", s.Name))
+		}
+		seasons[s.Name] = true
+
+		if !weeks[s.WeekName] {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("season profile %q references unknown week profile %q", s.Name, s.WeekName))
+		}
+	}
+
+	for _, w := range c.Weeks {
+		for i, id := range w.DayIDs {
+			if !days[id] {
+				return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("week profile %q references unknown day profile %d on day %d", w.Name, id, i+1))
+			}
+		}
+	}
+
+	for _, d := range c.Days {
+		for i, a := range d.Schedule {
+			if i > 0 && a.StartTime.before(d.Schedule[i-1].StartTime) {
+				return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("day profile %d actions are not sorted by start time", d.ID))
+			}
+
+			if scripts == nil {
+				continue
+			}
+
+			table, ok := scripts[a.ScriptLogicalName]
+			if !ok {
+				return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("day profile %d references unknown script table %s", d.ID, a.ScriptLogicalName.String()))
+			}
+
+			if findScript(table, a.ScriptSelector) == nil {
+				return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("day profile %d references unknown script %d of %s", d.ID, a.ScriptSelector, a.ScriptLogicalName.String()))
+			}
+		}
+	}
+
+	return nil
+}
+
+// ActivityCalendar is an instance of the Activity Calendar interface class (class_id 20).
+type ActivityCalendar struct {
+	object
+}
+
+func NewActivityCalendar(client dlms.Client, logicalName string) *ActivityCalendar {
+	return &ActivityCalendar{object{client: client, classID: ActivityCalendarClassID, logicalName: logicalName}}
+}
+
+// Active reads the calendar in use.
+func (c *ActivityCalendar) Active() (Calendar, error) {
+	return c.read(calendarNameActive, calendarSeasonProfileActive, calendarWeekProfileTableActive, calendarDayProfileTableActive)
+}
+
+// Passive reads the calendar that will be used once activated.
+func (c *ActivityCalendar) Passive() (Calendar, error) {
+	return c.read(calendarNamePassive, calendarSeasonProfilePassive, calendarWeekProfileTablePassive, calendarDayProfileTablePassive)
+}
+
+// SetPassive validates and writes the passive calendar. See Calendar.Validate for the scripts.
+func (c *ActivityCalendar) SetPassive(cal Calendar, scripts map[dlms.Obis][]Script) error {
+	err := cal.Validate(scripts)
+	if err != nil {
+		return err
+	}
+
+	days := make([]*axdr.DlmsData, len(cal.Days))
+	for i, d := range cal.Days {
+		days[i] = d.Data()
+	}
+
+	weeks := make([]*axdr.DlmsData, len(cal.Weeks))
+	for i, w := range cal.Weeks {
+		weeks[i] = w.Data()
+	}
+
+	seasons := make([]*axdr.DlmsData, len(cal.Seasons))
+	for i, s := range cal.Seasons {
+		seasons[i] = s.Data()
+	}
+
+	if err = c.set(calendarNamePassive, createOctetString([]byte(cal.Name))); err != nil {
+		return err
+	}
+	if err = c.set(calendarDayProfileTablePassive, axdr.CreateAxdrArray(days)); err != nil {
+		return err
+	}
+	if err = c.set(calendarWeekProfileTablePassive, axdr.CreateAxdrArray(weeks)); err != nil {
+		return err
+	}
+
+	return c.set(calendarSeasonProfilePassive, axdr.CreateAxdrArray(seasons))
+}
+
+// ActivatePassiveCalendarTime reads when the passive calendar will be activated.
+func (c *ActivityCalendar) ActivatePassiveCalendarTime() (DateTime, error) {
+	var data axdr.DlmsData
+
+	err := c.get(calendarActivatePassiveTime, &data)
+	if err != nil {
+		return DateTime{}, err
+	}
+
+	dt, err := DecodeDateTime(data)
+	if err != nil {
+		return dt, c.invalidData(calendarActivatePassiveTime, err)
+	}
+
+	return dt, nil
+}
+
+// SetActivatePassiveCalendarTime sets when the passive calendar will be activated. The passive calendar
+// read from the meter is validated first.
+func (c *ActivityCalendar) SetActivatePassiveCalendarTime(dt DateTime) error {
+	err := c.validatePassive()
+	if err != nil {
+		return err
+	}
+
+	return c.set(calendarActivatePassiveTime, dt.Data())
+}
+
+// ActivatePassiveCalendar copies the passive calendar to the active one. The passive calendar read
+// from the meter is validated first.
+func (c *ActivityCalendar) ActivatePassiveCalendar() error {
+	err := c.validatePassive()
+	if err != nil {
+		return err
+	}
+
+	return c.action(calendarActivatePassiveCalendar, int8(0))
+}
+
+func (c *ActivityCalendar) validatePassive() error {
+	cal, err := c.Passive()
+	if err != nil {
+		return err
+	}
+
+	return cal.Validate(nil)
+}
+
+func (c *ActivityCalendar) read(nameID int8, seasonsID int8, weeksID int8, daysID int8) (cal Calendar, err error) {
+	var data axdr.DlmsData
+
+	if err = c.get(nameID, &data); err != nil {
+		return
+	}
+
+	name, err := octetString(data, 0)
+	if err != nil {
+		return cal, c.invalidData(nameID, err)
+	}
+	cal.Name = string(name)
+
+	if err = c.get(seasonsID, &data); err != nil {
+		return
+	}
+
+	seasons, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return cal, c.invalidData(seasonsID, err)
+	}
+
+	cal.Seasons = make([]SeasonProfile, len(seasons))
+	for i, s := range seasons {
+		if cal.Seasons[i], err = decodeSeasonProfile(*s); err != nil {
+			return cal, c.invalidData(seasonsID, err)
+		}
+	}
+
+	if err = c.get(weeksID, &data); err != nil {
+		return
+	}
+
+	weeks, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return cal, c.invalidData(weeksID, err)
+	}
+
+	cal.Weeks = make([]WeekProfile, len(weeks))
+	for i, w := range weeks {
+		if cal.Weeks[i], err = decodeWeekProfile(*w); err != nil {
+			return cal, c.invalidData(weeksID, err)
+		}
+	}
+
+	if err = c.get(daysID, &data); err != nil {
+		return
+	}
+
+	days, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return cal, c.invalidData(daysID, err)
+	}
+
+	cal.Days = make([]DayProfile, len(days))
+	for i, d := range days {
+		if cal.Days[i], err = decodeDayProfile(*d); err != nil {
+			return cal, c.invalidData(daysID, err)
+		}
+	}
+
+	return cal, nil
+}
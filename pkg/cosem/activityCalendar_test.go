@@ -0,0 +1,152 @@
+package cosem_test
+
+import (
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tariffCalendar() cosem.Calendar {
+	script := *dlms.CreateObis(cosem.TariffScriptTableLogicalName)
+
+	return cosem.Calendar{
+		Name: "2024",
+		Seasons: []cosem.SeasonProfile{
+			{Name: "WINTER", StartDate: cosem.Date{Year: cosem.YearNotSpecified, Month: 10, Day: 1, DayOfWeek: cosem.NotSpecified}, WeekName: "W"},
+			{Name: "SUMMER", StartDate: cosem.Date{Year: cosem.YearNotSpecified, Month: 4, Day: 1, DayOfWeek: cosem.NotSpecified}, WeekName: "W"},
+		},
+		Weeks: []cosem.WeekProfile{
+			{Name: "W", DayIDs: [7]uint8{1, 1, 1, 1, 1, 2, 2}},
+		},
+		Days: []cosem.DayProfile{
+			{ID: 1, Schedule: []cosem.DayAction{
+				{StartTime: cosem.Time{Hour: 0, Hundredths: cosem.NotSpecified}, ScriptLogicalName: script, ScriptSelector: 1},
+				{StartTime: cosem.Time{Hour: 8, Hundredths: cosem.NotSpecified}, ScriptLogicalName: script, ScriptSelector: 2},
+			}},
+			{ID: 2, Schedule: []cosem.DayAction{
+				{StartTime: cosem.Time{Hundredths: cosem.NotSpecified}, ScriptLogicalName: script, ScriptSelector: 1},
+			}},
+		},
+	}
+}
+
+func tariffScripts() map[dlms.Obis][]cosem.Script {
+	return map[dlms.Obis][]cosem.Script{
+		*dlms.CreateObis(cosem.TariffScriptTableLogicalName): {
+			{ID: 1, Actions: []cosem.ScriptAction{{Service: cosem.ScriptServiceWrite, ClassID: 1, LogicalName: *dlms.CreateObis("0.0.96.14.0.255"), Index: 2, Parameter: *axdr.CreateAxdrUnsigned(1)}}},
+			{ID: 2, Actions: []cosem.ScriptAction{{Service: cosem.ScriptServiceWrite, ClassID: 1, LogicalName: *dlms.CreateObis("0.0.96.14.0.255"), Index: 2, Parameter: *axdr.CreateAxdrUnsigned(2)}}},
+		},
+	}
+}
+
+// newMeterCalendar returns an activity calendar whose activation copies the passive calendar to the active one.
+func newMeterCalendar() *dlmsserver.Object {
+	empty := *axdr.CreateAxdrArray([]*axdr.DlmsData{})
+
+	o := dlmsserver.NewObject(cosem.ActivityCalendarClassID, 0, cosem.ActivityCalendarLogicalName)
+	for _, passive := range []bool{false, true} {
+		offset := int8(2)
+		if passive {
+			offset = 6
+		}
+
+		o.SetValue(offset, *axdr.CreateAxdrOctetString(""), passive)
+		for i := int8(1); i < 4; i++ {
+			o.SetValue(offset+i, empty, passive)
+		}
+	}
+
+	o.SetValue(10, *axdr.CreateAxdrOctetString("ffffffffffffffffff8000ff"), true)
+
+	return o.SetMethod(1, func(dlms.MethodDescriptor, *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+		for i := int8(0); i < 4; i++ {
+			value, _ := o.Attributes[6+i].Get(dlms.AttributeDescriptor{}, nil)
+			o.SetValue(2+i, value, false)
+		}
+
+		return nil, dlms.TagActSuccess
+	})
+}
+
+func TestActivityCalendar(t *testing.T) {
+	c := cosem.NewActivityCalendar(connect(t, newMeterCalendar()), cosem.ActivityCalendarLogicalName)
+
+	cal := tariffCalendar()
+	require.NoError(t, c.SetPassive(cal, tariffScripts()))
+
+	passive, err := c.Passive()
+	require.NoError(t, err)
+	assert.Equal(t, cal, passive)
+
+	active, err := c.Active()
+	require.NoError(t, err)
+	assert.Empty(t, active.Seasons)
+
+	require.NoError(t, c.SetActivatePassiveCalendarTime(cosem.DateTime{Raw: []byte{0x07, 0xe8, 0x04, 0x01, 0xff, 0x00, 0x00, 0x00, 0x00, 0x80, 0x00, 0xff}}))
+	dt, err := c.ActivatePassiveCalendarTime()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x07, 0xe8, 0x04, 0x01, 0xff, 0x00, 0x00, 0x00, 0x00, 0x80, 0x00, 0xff}, dt.Raw)
+
+	require.NoError(t, c.ActivatePassiveCalendar())
+
+	active, err = c.Active()
+	require.NoError(t, err)
+	assert.Equal(t, cal, active)
+}
+
+func TestActivityCalendar_InvalidPassive(t *testing.T) {
+	o := newMeterCalendar()
+	c := cosem.NewActivityCalendar(connect(t, o), cosem.ActivityCalendarLogicalName)
+
+	// Passive calendar written by hand with a season referencing a missing week
+	season := cosem.SeasonProfile{Name: "S", WeekName: "missing"}
+	o.SetValue(7, *axdr.CreateAxdrArray([]*axdr.DlmsData{season.Data()}), true)
+
+	err := c.ActivatePassiveCalendar()
+	assertErrorCode(t, err, dlms.ErrorInvalidParameter)
+
+	active, err := c.Active()
+	require.NoError(t, err)
+	assert.Empty(t, active.Seasons)
+}
+
+func TestCalendar_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *cosem.Calendar)
+		err    string
+	}{
+		{"valid", func(*cosem.Calendar) {}, ""},
+		{"unknown week", func(c *cosem.Calendar) { c.Seasons[1].WeekName = "X" }, `season profile "SUMMER" references unknown week profile "X"`},
+		{"unknown day", func(c *cosem.Calendar) { c.Weeks[0].DayIDs[6] = 3 }, `week profile "W" references unknown day profile 3 on day 7`},
+		{"duplicated day", func(c *cosem.Calendar) { c.Days[1].ID = 1 }, "duplicated day profile 1"},
+		{"same start time", func(c *cosem.Calendar) { c.Days[0].Schedule[1].StartTime.Hour = 0 }, ""},
+		{"unsorted actions", func(c *cosem.Calendar) {
+			c.Days[0].Schedule[0].StartTime.Hour = 9
+		}, "day profile 1 actions are not sorted by start time"},
+		{"unknown script", func(c *cosem.Calendar) { c.Days[1].Schedule[0].ScriptSelector = 5 }, "day profile 2 references unknown script 5 of 0.0.10.0.100.255"},
+		{"unknown script table", func(c *cosem.Calendar) {
+			c.Days[1].Schedule[0].ScriptLogicalName = *dlms.CreateObis("0.0.10.0.101.255")
+		}, "day profile 2 references unknown script table 0.0.10.0.101.255"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cal := tariffCalendar()
+			tt.modify(&cal)
+
+			err := cal.Validate(tariffScripts())
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assertErrorCode(t, err, dlms.ErrorInvalidParameter)
+				assert.Contains(t, err.Error(), tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package cosem
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const ChargeClassID = 113
+
+const (
+	chargeTotalAmountPaid          = 2
+	chargeType                     = 3
+	chargePriority                 = 4
+	chargeUnitChargeActive         = 5
+	chargeUnitChargePassive        = 6
+	chargeUnitChargeActivationTime = 7
+	chargePeriod                   = 8
+	chargeConfiguration            = 9
+	chargeLastCollectionTime       = 10
+	chargeLastCollectionAmount     = 11
+	chargeTotalAmountRemaining     = 12
+	chargeProportion               = 13
+	chargeUpdateUnitCharge         = 1
+	chargeActivatePassiveUnit      = 2
+	chargeCollect                  = 3
+	chargeUpdateTotalAmount        = 4
+	chargeSetTotalAmount           = 5
+)
+
+// ChargeType is the way a charge is collected.
+type ChargeType uint8
+
+const (
+	ChargeTypeConsumptionBased ChargeType = 0
+	ChargeTypeTimeBased        ChargeType = 1
+	ChargeTypePaymentEvent     ChargeType = 2
+)
+
+func (t ChargeType) String() string {
+	switch t {
+	case ChargeTypeConsumptionBased:
+		return "consumption based"
+	case ChargeTypeTimeBased:
+		return "time based"
+	case ChargeTypePaymentEvent:
+		return "payment event based"
+	default:
+		return fmt.Sprintf("unknown (%d)", uint8(t))
+	}
+}
+
+// ChargeConfiguration is the charge_configuration of a charge.
+type ChargeConfiguration uint8
+
+const (
+	ChargePercentageBasedCollection ChargeConfiguration = 0x01
+	ChargeContinuousCollection      ChargeConfiguration = 0x02
+)
+
+// Has returns true if all the bits of flag are set.
+func (c ChargeConfiguration) Has(flag ChargeConfiguration) bool {
+	return c&flag == flag
+}
+
+// ChargeTableElement is the price of a unit of commodity when the commodity's index is Index.
+type ChargeTableElement struct {
+	Index         []byte
+	ChargePerUnit int16
+}
+
+// UnitCharge is the price of a commodity. Commodity is scaled by 10^CommodityScale and the charges by
+// 10^PriceScale.
+type UnitCharge struct {
+	CommodityScale int8
+	PriceScale     int8
+	Commodity      ValueDefinition
+	ChargeTable    []ChargeTableElement
+}
+
+// Data returns the unit charge as A-XDR data.
+func (u UnitCharge) Data() *axdr.DlmsData {
+	table := make([]*axdr.DlmsData, len(u.ChargeTable))
+	for i, e := range u.ChargeTable {
+		table[i] = axdr.CreateAxdrStructure([]*axdr.DlmsData{
+			createOctetString(e.Index),
+			axdr.CreateAxdrLong(e.ChargePerUnit),
+		})
+	}
+
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrStructure([]*axdr.DlmsData{
+			axdr.CreateAxdrInteger(u.CommodityScale),
+			axdr.CreateAxdrInteger(u.PriceScale),
+		}),
+		u.Commodity.Data(),
+		axdr.CreateAxdrArray(table),
+	})
+}
+
+// Validate checks that the indexes of the charge table are not repeated.
+func (u UnitCharge) Validate() error {
+	for i, e := range u.ChargeTable {
+		for _, previous := range u.ChargeTable[:i] {
+			if bytes.Equal(e.Index, previous.Index) {
+				return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("duplicated charge table index %s", hex.EncodeToString(e.Index)))
+			}
+		}
+	}
+
+	return nil
+}
+
+// Charge returns the charge per unit of the index, or false if the index is not in the table.
+func (u UnitCharge) Charge(index []byte) (int16, bool) {
+	for _, e := range u.ChargeTable {
+		if bytes.Equal(e.Index, index) {
+			return e.ChargePerUnit, true
+		}
+	}
+
+	return 0, false
+}
+
+func decodeUnitCharge(data axdr.DlmsData) (u UnitCharge, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 3)
+	if err != nil {
+		return
+	}
+
+	scaling, err := dlms.DataAsSlice(*fields[0], axdr.TagStructure, 2)
+	if err != nil {
+		return
+	}
+
+	commodityScale, ok1 := scaling[0].Value.(int8)
+	priceScale, ok2 := scaling[1].Value.(int8)
+	if !ok1 || !ok2 {
+		return u, fmt.Errorf("invalid charge per unit scaling")
+	}
+	u.CommodityScale = commodityScale
+	u.PriceScale = priceScale
+
+	if u.Commodity, err = decodeValueDefinition(*fields[1]); err != nil {
+		return
+	}
+
+	table, err := dlms.DataAsSlice(*fields[2], axdr.TagArray, 0)
+	if err != nil {
+		return
+	}
+
+	u.ChargeTable = make([]ChargeTableElement, len(table))
+	for i, e := range table {
+		element, err := dlms.DataAsSlice(*e, axdr.TagStructure, 2)
+		if err != nil {
+			return u, err
+		}
+
+		index, err := octetString(*element[0], 0)
+		if err != nil {
+			return u, err
+		}
+
+		charge, ok := element[1].Value.(int16)
+		if !ok {
+			return u, fmt.Errorf("invalid charge per unit %v", element[1].Value)
+		}
+
+		u.ChargeTable[i] = ChargeTableElement{Index: index, ChargePerUnit: charge}
+	}
+
+	return u, nil
+}
+
+// ChargeValue holds the attributes of a charge. Amounts are in the currency of the account. A lower
+// priority value means that the charge is collected first.
+type ChargeValue struct {
+	TotalAmountPaid          int32
+	Type                     ChargeType
+	Priority                 uint8
+	UnitChargeActive         UnitCharge
+	UnitChargePassive        UnitCharge
+	UnitChargeActivationTime DateTime
+	Period                   time.Duration
+	Configuration            ChargeConfiguration
+	LastCollectionTime       DateTime
+	LastCollectionAmount     int32
+	TotalAmountRemaining     int32
+	Proportion               uint16
+}
+
+// Charge is an instance of the Charge interface class (class_id 113).
+type Charge struct {
+	object
+}
+
+func NewCharge(client dlms.Client, logicalName string) *Charge {
+	return &Charge{object{client: client, classID: ChargeClassID, logicalName: logicalName}}
+}
+
+// Read reads all the attributes of the charge.
+func (c *Charge) Read() (v ChargeValue, err error) {
+	if err = c.get(chargeTotalAmountPaid, &v.TotalAmountPaid); err != nil {
+		return
+	}
+
+	var value uint8
+	if err = c.get(chargeType, &value); err != nil {
+		return
+	}
+	v.Type = ChargeType(value)
+
+	if err = c.get(chargePriority, &v.Priority); err != nil {
+		return
+	}
+	if v.UnitChargeActive, err = c.unitCharge(chargeUnitChargeActive); err != nil {
+		return
+	}
+	if v.UnitChargePassive, err = c.unitCharge(chargeUnitChargePassive); err != nil {
+		return
+	}
+	if v.UnitChargeActivationTime, err = c.dateTime(chargeUnitChargeActivationTime); err != nil {
+		return
+	}
+
+	var period uint32
+	if err = c.get(chargePeriod, &period); err != nil {
+		return
+	}
+	v.Period = time.Duration(period) * time.Second
+
+	var data axdr.DlmsData
+	if err = c.get(chargeConfiguration, &data); err != nil {
+		return
+	}
+	configuration, err := decodeBitString(data)
+	if err != nil {
+		return v, c.invalidData(chargeConfiguration, err)
+	}
+	v.Configuration = ChargeConfiguration(configuration)
+
+	if v.LastCollectionTime, err = c.dateTime(chargeLastCollectionTime); err != nil {
+		return
+	}
+	if err = c.get(chargeLastCollectionAmount, &v.LastCollectionAmount); err != nil {
+		return
+	}
+	if err = c.get(chargeTotalAmountRemaining, &v.TotalAmountRemaining); err != nil {
+		return
+	}
+
+	err = c.get(chargeProportion, &v.Proportion)
+	return
+}
+
+// UnitChargeActive reads the unit charge in use.
+func (c *Charge) UnitChargeActive() (UnitCharge, error) {
+	return c.unitCharge(chargeUnitChargeActive)
+}
+
+// UpdateUnitCharge validates and writes the passive unit charge.
+func (c *Charge) UpdateUnitCharge(u UnitCharge) error {
+	err := u.Validate()
+	if err != nil {
+		return err
+	}
+
+	return c.action(chargeUpdateUnitCharge, u.Data())
+}
+
+// SetUnitChargeActivationTime sets when the passive unit charge will be activated.
+func (c *Charge) SetUnitChargeActivationTime(dt DateTime) error {
+	return c.set(chargeUnitChargeActivationTime, dt.Data())
+}
+
+// ActivatePassiveUnitCharge copies the passive unit charge to the active one.
+func (c *Charge) ActivatePassiveUnitCharge() error {
+	return c.action(chargeActivatePassiveUnit, int8(0))
+}
+
+// Collect collects the charge immediately.
+func (c *Charge) Collect() error {
+	return c.action(chargeCollect, int8(0))
+}
+
+// UpdateTotalAmountRemaining adds amount, that can be negative, to the total amount remaining.
+func (c *Charge) UpdateTotalAmountRemaining(amount int32) error {
+	return c.action(chargeUpdateTotalAmount, amount)
+}
+
+// SetTotalAmountRemaining sets the total amount remaining.
+func (c *Charge) SetTotalAmountRemaining(amount int32) error {
+	return c.action(chargeSetTotalAmount, amount)
+}
+
+func (c *Charge) unitCharge(attributeID int8) (UnitCharge, error) {
+	var data axdr.DlmsData
+
+	err := c.get(attributeID, &data)
+	if err != nil {
+		return UnitCharge{}, err
+	}
+
+	u, err := decodeUnitCharge(data)
+	if err != nil {
+		return u, c.invalidData(attributeID, err)
+	}
+
+	return u, nil
+}
+
+func (c *Charge) dateTime(attributeID int8) (DateTime, error) {
+	var data axdr.DlmsData
+
+	err := c.get(attributeID, &data)
+	if err != nil {
+		return DateTime{}, err
+	}
+
+	dt, err := DecodeDateTime(data)
+	if err != nil {
+		return dt, c.invalidData(attributeID, err)
+	}
+
+	return dt, nil
+}
@@ -0,0 +1,88 @@
+package cosem_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCharge(t *testing.T) {
+	energy := cosem.ValueDefinition{ClassID: cosem.RegisterClassID, LogicalName: *dlms.CreateObis("1.0.1.8.0.255"), AttributeID: 2}
+	active := cosem.UnitCharge{
+		CommodityScale: 0,
+		PriceScale:     -4,
+		Commodity:      energy,
+		ChargeTable:    []cosem.ChargeTableElement{{Index: []byte{0x01}, ChargePerUnit: 1500}, {Index: []byte{0x02}, ChargePerUnit: 900}},
+	}
+	passive := *active.Data()
+	remaining := int32(10000)
+
+	o := dlmsserver.NewObject(cosem.ChargeClassID, 0, standingChargeLogicalName).
+		SetValue(2, *axdr.CreateAxdrDoubleLong(2500), false).
+		SetValue(3, *axdr.CreateAxdrEnum(uint8(cosem.ChargeTypeConsumptionBased)), false).
+		SetValue(4, *axdr.CreateAxdrUnsigned(1), true).
+		SetValue(5, *active.Data(), false).
+		SetAttribute(6, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			return passive, dlms.TagAccSuccess
+		}, nil).
+		SetValue(7, *axdr.CreateAxdrOctetString("ffffffffffffffffff800000"), true).
+		SetValue(8, *axdr.CreateAxdrDoubleLongUnsigned(3600), true).
+		SetValue(9, *axdr.CreateAxdrBitString("01"), true).
+		SetValue(10, *axdr.CreateAxdrOctetString("07e8010101000000ff800000"), false).
+		SetValue(11, *axdr.CreateAxdrDoubleLong(15), false).
+		SetAttribute(12, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			return *axdr.CreateAxdrDoubleLong(remaining), dlms.TagAccSuccess
+		}, nil).
+		SetValue(13, *axdr.CreateAxdrLongUnsigned(0), true).
+		SetMethod(1, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			passive = *data
+			return nil, dlms.TagActSuccess
+		}).
+		SetMethod(4, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			remaining += data.Value.(int32)
+			return nil, dlms.TagActSuccess
+		}).
+		SetMethod(5, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			remaining = data.Value.(int32)
+			return nil, dlms.TagActSuccess
+		})
+
+	c := cosem.NewCharge(connect(t, o), standingChargeLogicalName)
+
+	v, err := c.Read()
+	require.NoError(t, err)
+	assert.Equal(t, int32(2500), v.TotalAmountPaid)
+	assert.Equal(t, cosem.ChargeTypeConsumptionBased, v.Type)
+	assert.Equal(t, active, v.UnitChargeActive)
+	assert.Equal(t, active, v.UnitChargePassive)
+	assert.Equal(t, time.Hour, v.Period)
+	assert.Equal(t, cosem.ChargeContinuousCollection, v.Configuration)
+	assert.Equal(t, int32(15), v.LastCollectionAmount)
+
+	charge, ok := v.UnitChargeActive.Charge([]byte{0x02})
+	assert.True(t, ok)
+	assert.Equal(t, int16(900), charge)
+	_, ok = v.UnitChargeActive.Charge([]byte{0x03})
+	assert.False(t, ok)
+
+	updated := active
+	updated.ChargeTable = []cosem.ChargeTableElement{{Index: []byte{0x01}, ChargePerUnit: 1600}}
+	require.NoError(t, c.UpdateUnitCharge(updated))
+	v, err = c.Read()
+	require.NoError(t, err)
+	assert.Equal(t, updated, v.UnitChargePassive)
+
+	updated.ChargeTable = append(updated.ChargeTable, cosem.ChargeTableElement{Index: []byte{0x01}, ChargePerUnit: 1})
+	assertErrorCode(t, c.UpdateUnitCharge(updated), dlms.ErrorInvalidParameter)
+
+	require.NoError(t, c.UpdateTotalAmountRemaining(-1000))
+	assert.Equal(t, int32(9000), remaining)
+	require.NoError(t, c.SetTotalAmountRemaining(0))
+	assert.Equal(t, int32(0), remaining)
+}
@@ -0,0 +1,408 @@
+package cosem
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const (
+	ClockClassID = 8
+	// ClockLogicalName is the logical name of the clock of the meter
+	ClockLogicalName = "0.0.1.0.0.255"
+)
+
+const (
+	clockTime                     = 2
+	clockTimeZone                 = 3
+	clockStatus                   = 4
+	clockDaylightSavingsBegin     = 5
+	clockDaylightSavingsEnd       = 6
+	clockDaylightSavingsDeviation = 7
+	clockDaylightSavingsEnabled   = 8
+	clockBase                     = 9
+	clockAdjustToQuarter          = 1
+	clockShiftTime                = 6
+)
+
+const (
+	// DeviationNotSpecified is the deviation of a date-time without time zone
+	DeviationNotSpecified = math.MinInt16
+	// MaxShiftTime is the maximum correction of the shift_time method
+	MaxShiftTime = 900 * time.Second
+)
+
+// ClockStatus is the status of a clock, as defined in the Blue Book.
+type ClockStatus uint8
+
+const (
+	ClockStatusInvalidValue         ClockStatus = 0x01
+	ClockStatusDoubtfulValue        ClockStatus = 0x02
+	ClockStatusDifferentClockBase   ClockStatus = 0x04
+	ClockStatusInvalidClockStatus   ClockStatus = 0x08
+	ClockStatusDaylightSavingActive ClockStatus = 0x80
+)
+
+// Has returns true if all the bits of flag are set.
+func (s ClockStatus) Has(flag ClockStatus) bool {
+	return s&flag == flag
+}
+
+type ClockBase uint8
+
+const (
+	ClockBaseNotDefined      ClockBase = 0
+	ClockBaseInternalCrystal ClockBase = 1
+	ClockBaseMains50Hz       ClockBase = 2
+	ClockBaseMains60Hz       ClockBase = 3
+	ClockBaseGPS             ClockBase = 4
+	ClockBaseRadioControlled ClockBase = 5
+)
+
+func (b ClockBase) String() string {
+	switch b {
+	case ClockBaseNotDefined:
+		return "not defined"
+	case ClockBaseInternalCrystal:
+		return "internal crystal"
+	case ClockBaseMains50Hz:
+		return "mains frequency 50 Hz"
+	case ClockBaseMains60Hz:
+		return "mains frequency 60 Hz"
+	case ClockBaseGPS:
+		return "GPS"
+	case ClockBaseRadioControlled:
+		return "radio controlled"
+	default:
+		return ""
+	}
+}
+
+// DateTime is a COSEM date-time. Time is zero if the date-time has wildcards, which are kept in Raw.
+type DateTime struct {
+	Time      time.Time
+	Deviation int16
+	Status    ClockStatus
+	Raw       []byte
+}
+
+// Data returns the date-time as A-XDR data. Raw is used if present, so wildcards are kept.
+func (dt DateTime) Data() *axdr.DlmsData {
+	if dt.Raw != nil {
+		return axdr.CreateAxdrOctetString(hex.EncodeToString(dt.Raw))
+	}
+
+	return axdr.CreateAxdrOctetString(dt.Time)
+}
+
+// DecodeDateTime decodes a date-time received as octet string.
+func DecodeDateTime(data axdr.DlmsData) (dt DateTime, err error) {
+	str, ok := data.Value.(string)
+	if data.Tag != axdr.TagOctetString || !ok {
+		err = fmt.Errorf("invalid date-time %v", data.Value)
+		return
+	}
+
+	src, err := hex.DecodeString(str)
+	if err != nil || len(src) != 12 {
+		err = fmt.Errorf("invalid date-time %s", str)
+		return
+	}
+
+	dt.Raw = src
+	dt.Deviation = int16(binary.BigEndian.Uint16(src[9:11]))
+	dt.Status = ClockStatus(src[11])
+	_, dt.Time, err = axdr.DecodeDateTime(&src)
+
+	return
+}
+
+// ClockValue holds the attributes of a clock.
+type ClockValue struct {
+	Time                     DateTime
+	TimeZone                 int16
+	Status                   ClockStatus
+	DaylightSavingsBegin     DateTime
+	DaylightSavingsEnd       DateTime
+	DaylightSavingsDeviation int8
+	DaylightSavingsEnabled   bool
+	ClockBase                ClockBase
+}
+
+// Clock is an instance of the Clock interface class (class_id 8).
+type Clock struct {
+	object
+}
+
+func NewClock(client dlms.Client, logicalName string) *Clock {
+	return &Clock{object{client: client, classID: ClockClassID, logicalName: logicalName}}
+}
+
+// Read reads all the attributes of the clock.
+func (c *Clock) Read() (v ClockValue, err error) {
+	if v.Time, err = c.Time(); err != nil {
+		return
+	}
+	if v.TimeZone, err = c.TimeZone(); err != nil {
+		return
+	}
+	if v.Status, err = c.Status(); err != nil {
+		return
+	}
+	if v.DaylightSavingsBegin, err = c.dateTime(clockDaylightSavingsBegin); err != nil {
+		return
+	}
+	if v.DaylightSavingsEnd, err = c.dateTime(clockDaylightSavingsEnd); err != nil {
+		return
+	}
+	if err = c.get(clockDaylightSavingsDeviation, &v.DaylightSavingsDeviation); err != nil {
+		return
+	}
+	if err = c.get(clockDaylightSavingsEnabled, &v.DaylightSavingsEnabled); err != nil {
+		return
+	}
+
+	var base uint8
+	err = c.get(clockBase, &base)
+	v.ClockBase = ClockBase(base)
+
+	return
+}
+
+// Time reads the local time of the meter, with its deviation and status.
+func (c *Clock) Time() (DateTime, error) {
+	return c.dateTime(clockTime)
+}
+
+// SetTime sets the time of the meter. It's sent with the deviation of the time location.
+func (c *Clock) SetTime(t time.Time) error {
+	return c.set(clockTime, t)
+}
+
+// TimeZone reads the deviation of the local time from UTC in minutes.
+func (c *Clock) TimeZone() (tz int16, err error) {
+	err = c.get(clockTimeZone, &tz)
+	return
+}
+
+// SetTimeZone sets the deviation of the local time from UTC in minutes.
+func (c *Clock) SetTimeZone(tz int16) error {
+	return c.set(clockTimeZone, tz)
+}
+
+// Status reads the status of the clock.
+func (c *Clock) Status() (ClockStatus, error) {
+	var status uint8
+
+	err := c.get(clockStatus, &status)
+	return ClockStatus(status), err
+}
+
+// SetDaylightSavings sets the daylight savings period and deviation, and enables or disables them.
+// Begin and end are raw date-times, so they can have wildcards.
+func (c *Clock) SetDaylightSavings(begin []byte, end []byte, deviation int8, enabled bool) error {
+	if len(begin) != 12 || len(end) != 12 {
+		return dlms.NewError(dlms.ErrorInvalidParameter, "daylight savings begin and end must be 12 bytes long")
+	}
+
+	err := c.set(clockDaylightSavingsBegin, hex.EncodeToString(begin))
+	if err != nil {
+		return err
+	}
+
+	err = c.set(clockDaylightSavingsEnd, hex.EncodeToString(end))
+	if err != nil {
+		return err
+	}
+
+	err = c.set(clockDaylightSavingsDeviation, deviation)
+	if err != nil {
+		return err
+	}
+
+	return c.set(clockDaylightSavingsEnabled, enabled)
+}
+
+// AdjustToQuarter sets the time to the nearest quarter of an hour.
+func (c *Clock) AdjustToQuarter() error {
+	return c.action(clockAdjustToQuarter, int8(0))
+}
+
+// ShiftTime shifts the time by the given seconds, up to MaxShiftTime.
+func (c *Clock) ShiftTime(seconds int16) error {
+	if abs(time.Duration(seconds)*time.Second) > MaxShiftTime {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("shift of %d seconds out of range", seconds))
+	}
+
+	return c.action(clockShiftTime, seconds)
+}
+
+func (c *Clock) dateTime(attributeID int8) (dt DateTime, err error) {
+	var data axdr.DlmsData
+
+	err = c.get(attributeID, &data)
+	if err != nil {
+		return
+	}
+
+	dt, err = DecodeDateTime(data)
+	if err != nil {
+		err = c.invalidData(attributeID, err)
+	}
+
+	return
+}
+
+// SyncMethod is how the clock was corrected.
+type SyncMethod uint8
+
+const (
+	SyncNone SyncMethod = iota
+	SyncAdjustToQuarter
+	SyncShiftTime
+	SyncSetTime
+)
+
+func (m SyncMethod) String() string {
+	switch m {
+	case SyncNone:
+		return "none"
+	case SyncAdjustToQuarter:
+		return "adjust_to_quarter"
+	case SyncShiftTime:
+		return "shift_time"
+	case SyncSetTime:
+		return "set time"
+	default:
+		return ""
+	}
+}
+
+// SyncOptions define when and how the clock is corrected.
+type SyncOptions struct {
+	// MinDrift is the drift below which the clock isn't corrected. If zero, one second is used.
+	MinDrift time.Duration
+	// MaxShift is the maximum drift corrected with shift_time. Larger drifts set the time. If zero, MaxShiftTime is used.
+	MaxShift time.Duration
+	// AdjustToQuarter enables the use of adjust_to_quarter when the reference time is at a quarter of an hour
+	// (within MinDrift) and the clock rounds to it.
+	AdjustToQuarter bool
+	// Now returns the reference time. If nil, time.Now is used.
+	Now func() time.Time
+}
+
+// SyncResult reports the state of the clock and the applied correction.
+type SyncResult struct {
+	// MeterTime is the time read from the meter
+	MeterTime DateTime
+	// Latency is the round trip time of the read
+	Latency time.Duration
+	// Drift is the difference between the meter and the reference time, compensated with the latency
+	Drift time.Duration
+	// Method is how the clock was corrected
+	Method SyncMethod
+	// Correction is the correction applied to the clock
+	Correction time.Duration
+}
+
+// Sync compares the time of the meter with the reference time and corrects it if the drift is too large.
+// Small drifts are corrected with shift_time (or adjust_to_quarter if enabled and possible), larger ones
+// setting the time.
+func (c *Clock) Sync(opts SyncOptions) (r SyncResult, err error) {
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	minDrift := opts.MinDrift
+	if minDrift <= 0 {
+		minDrift = time.Second
+	}
+
+	maxShift := opts.MaxShift
+	if maxShift <= 0 || maxShift > MaxShiftTime {
+		maxShift = MaxShiftTime
+	}
+
+	start := now()
+	r.MeterTime, err = c.Time()
+	if err != nil {
+		return
+	}
+	r.Latency = now().Sub(start)
+
+	if r.MeterTime.Time.IsZero() || r.MeterTime.Status.Has(ClockStatusInvalidValue) {
+		// Drift can't be known, so the time is set
+		return r, c.syncSetTime(&r, now)
+	}
+
+	// Meter time was read at half the round trip
+	reference := start.Add(r.Latency / 2)
+	r.Drift = r.MeterTime.Time.Sub(reference)
+
+	if abs(r.Drift) < minDrift {
+		return
+	}
+
+	if opts.AdjustToQuarter {
+		quarter := reference.Round(15 * time.Minute)
+		if abs(reference.Sub(quarter)) < minDrift && r.MeterTime.Time.Round(15*time.Minute).Equal(quarter) {
+			err = c.AdjustToQuarter()
+			if err == nil {
+				r.Method = SyncAdjustToQuarter
+				r.Correction = quarter.Sub(r.MeterTime.Time)
+			}
+			return
+		}
+	}
+
+	if abs(r.Drift) <= maxShift {
+		seconds := -r.Drift.Round(time.Second)
+		if seconds == 0 {
+			// Shifts are in whole seconds, so a drift below half a second can't be corrected
+			return
+		}
+
+		err = c.ShiftTime(int16(seconds / time.Second))
+		if err == nil {
+			r.Method = SyncShiftTime
+			r.Correction = seconds
+		}
+		return
+	}
+
+	return r, c.syncSetTime(&r, now)
+}
+
+func (c *Clock) syncSetTime(r *SyncResult, now func() time.Time) error {
+	location := time.Local
+	if !r.MeterTime.Time.IsZero() {
+		location = r.MeterTime.Time.Location()
+	}
+
+	// The time is applied half a round trip after being sent
+	t := now().Add(r.Latency / 2).In(location)
+
+	err := c.SetTime(t)
+	if err != nil {
+		return err
+	}
+
+	r.Method = SyncSetTime
+	r.Correction = -r.Drift
+
+	return nil
+}
+
+func abs(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+
+	return d
+}
@@ -0,0 +1,151 @@
+package cosem_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// meterClock simulates the clock of a meter, which runs at an offset of the reference time.
+type meterClock struct {
+	reference time.Time
+	offset    time.Duration
+	method    int8
+	mutex     sync.Mutex
+}
+
+func (m *meterClock) now() time.Time {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.reference
+}
+
+func (m *meterClock) object() *dlmsserver.Object {
+	location := time.FixedZone("UTC+1", 3600)
+
+	return dlmsserver.NewObject(cosem.ClockClassID, 0, cosem.ClockLogicalName).
+		SetAttribute(2, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			m.mutex.Lock()
+			defer m.mutex.Unlock()
+
+			return *axdr.CreateAxdrOctetString(m.reference.Add(m.offset).In(location)), dlms.TagAccSuccess
+		}, func(_ dlms.AttributeDescriptor, _ *dlms.SelectiveAccessDescriptor, data axdr.DlmsData) dlms.AccessResultTag {
+			m.mutex.Lock()
+			defer m.mutex.Unlock()
+
+			var t time.Time
+			if axdr.UnmarshalData(data, &t) != nil {
+				return dlms.TagAccTypeUnmatched
+			}
+
+			m.method = 0
+			m.offset = t.Sub(m.reference)
+			return dlms.TagAccSuccess
+		}).
+		SetValue(3, *axdr.CreateAxdrLong(-60), true).
+		SetValue(4, *axdr.CreateAxdrUnsigned(0x80), false).
+		SetValue(5, *axdr.CreateAxdrOctetString("ffff03fe07020000ff800080"), true).
+		SetValue(6, *axdr.CreateAxdrOctetString("ffff0afe07030000ff800000"), true).
+		SetValue(7, *axdr.CreateAxdrInteger(60), true).
+		SetValue(8, *axdr.CreateAxdrBoolean(true), true).
+		SetValue(9, *axdr.CreateAxdrEnum(uint8(cosem.ClockBaseInternalCrystal)), false).
+		SetMethod(1, func(dlms.MethodDescriptor, *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			m.mutex.Lock()
+			defer m.mutex.Unlock()
+
+			m.method = 1
+			m.offset = m.reference.Add(m.offset).Round(15 * time.Minute).Sub(m.reference)
+			return nil, dlms.TagActSuccess
+		}).
+		SetMethod(6, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			m.mutex.Lock()
+			defer m.mutex.Unlock()
+
+			m.method = 6
+			m.offset += time.Duration(data.Value.(int16)) * time.Second
+			return nil, dlms.TagActSuccess
+		})
+}
+
+func TestClock_Read(t *testing.T) {
+	m := &meterClock{reference: time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)}
+	c := cosem.NewClock(connect(t, m.object()), cosem.ClockLogicalName)
+
+	v, err := c.Read()
+	require.NoError(t, err)
+	assert.True(t, m.reference.Equal(v.Time.Time))
+	assert.Equal(t, int16(-60), v.Time.Deviation)
+	assert.Equal(t, int16(-60), v.TimeZone)
+	assert.True(t, v.Status.Has(cosem.ClockStatusDaylightSavingActive))
+	assert.True(t, v.DaylightSavingsBegin.Time.IsZero())
+	assert.Equal(t, byte(0x03), v.DaylightSavingsBegin.Raw[2])
+	assert.Equal(t, int16(cosem.DeviationNotSpecified), v.DaylightSavingsEnd.Deviation)
+	assert.Equal(t, int8(60), v.DaylightSavingsDeviation)
+	assert.True(t, v.DaylightSavingsEnabled)
+	assert.Equal(t, cosem.ClockBaseInternalCrystal, v.ClockBase)
+
+	require.NoError(t, c.SetTimeZone(0))
+	tz, err := c.TimeZone()
+	assert.NoError(t, err)
+	assert.Equal(t, int16(0), tz)
+
+	require.NoError(t, c.SetDaylightSavings(v.DaylightSavingsEnd.Raw, v.DaylightSavingsBegin.Raw, 30, false))
+	v, err = c.Read()
+	require.NoError(t, err)
+	assert.Equal(t, byte(0x0a), v.DaylightSavingsBegin.Raw[2])
+	assert.False(t, v.DaylightSavingsEnabled)
+
+	assert.Error(t, c.ShiftTime(901))
+}
+
+func TestClock_Sync(t *testing.T) {
+	tests := []struct {
+		name       string
+		reference  time.Time
+		offset     time.Duration
+		quarter    bool
+		method     cosem.SyncMethod
+		correction time.Duration
+	}{
+		{"no drift", time.Date(2024, 6, 1, 10, 3, 0, 0, time.UTC), 0, false, cosem.SyncNone, 0},
+		{"shift", time.Date(2024, 6, 1, 10, 3, 0, 0, time.UTC), 30 * time.Second, false, cosem.SyncShiftTime, -30 * time.Second},
+		{"shift backwards", time.Date(2024, 6, 1, 10, 3, 0, 0, time.UTC), -15 * time.Minute, false, cosem.SyncShiftTime, 15 * time.Minute},
+		{"set", time.Date(2024, 6, 1, 10, 3, 0, 0, time.UTC), 2 * time.Hour, false, cosem.SyncSetTime, -2 * time.Hour},
+		{"quarter", time.Date(2024, 6, 1, 10, 15, 0, 0, time.UTC), -10 * time.Second, true, cosem.SyncAdjustToQuarter, 10 * time.Second},
+		{"quarter not possible", time.Date(2024, 6, 1, 10, 3, 0, 0, time.UTC), -10 * time.Second, true, cosem.SyncShiftTime, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &meterClock{reference: tt.reference, offset: tt.offset}
+			c := cosem.NewClock(connect(t, m.object()), cosem.ClockLogicalName)
+
+			r, err := c.Sync(cosem.SyncOptions{AdjustToQuarter: tt.quarter, Now: m.now})
+			require.NoError(t, err)
+			assert.Equal(t, tt.method, r.Method)
+			assert.Equal(t, tt.offset, r.Drift)
+			assert.Equal(t, tt.correction, r.Correction)
+			assert.Equal(t, time.Duration(0), m.offset)
+		})
+	}
+}
+
+func TestClock_SyncBelowOneSecond(t *testing.T) {
+	m := &meterClock{reference: time.Date(2024, 6, 1, 10, 3, 0, 0, time.UTC), offset: 300 * time.Millisecond}
+	c := cosem.NewClock(connect(t, m.object()), cosem.ClockLogicalName)
+
+	r, err := c.Sync(cosem.SyncOptions{MinDrift: 100 * time.Millisecond, Now: m.now})
+	require.NoError(t, err)
+	assert.Equal(t, cosem.SyncNone, r.Method)
+	assert.Equal(t, 300*time.Millisecond, r.Drift)
+	assert.Equal(t, time.Duration(0), r.Correction)
+	assert.Equal(t, int8(0), m.method)
+}
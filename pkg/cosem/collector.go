@@ -0,0 +1,298 @@
+package cosem
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+// DefaultCollectorWindow is the time span read per request if the collector has no other limit.
+const DefaultCollectorWindow = 24 * time.Hour
+
+// Checkpoint is the position of the last entry collected from a profile.
+type Checkpoint struct {
+	// Time is the capture time of the last collected entry
+	Time time.Time
+	// EntriesInUse is the entries_in_use of the profile in the last collection
+	EntriesInUse uint32
+}
+
+// CheckpointStore stores the checkpoints of the collectors. Key identifies the device and the profile.
+type CheckpointStore interface {
+	Load(key string) (cp Checkpoint, ok bool, err error)
+	Save(key string, cp Checkpoint) error
+}
+
+type memoryCheckpointStore struct {
+	checkpoints map[string]Checkpoint
+	mutex       sync.Mutex
+}
+
+// NewCheckpointMemoryStore creates a store that keeps the checkpoints in memory.
+func NewCheckpointMemoryStore() CheckpointStore {
+	return &memoryCheckpointStore{
+		checkpoints: make(map[string]Checkpoint),
+		mutex:       sync.Mutex{},
+	}
+}
+
+func (s *memoryCheckpointStore) Load(key string) (Checkpoint, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cp, ok := s.checkpoints[key]
+	return cp, ok, nil
+}
+
+func (s *memoryCheckpointStore) Save(key string, cp Checkpoint) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.checkpoints[key] = cp
+	return nil
+}
+
+// Collector reads the entries of a profile incrementally, resuming from the checkpoint of the previous
+// collection. The profile must capture the clock (class 8, attribute 2), which is used to select the entries.
+//
+// Entries are read in windows bounded by Window and MaxEntries, from the checkpoint to the time of the meter.
+// If the clock of the meter jumps forward, the empty windows are skipped. If it jumps backward, the entries
+// captured since the jump have a time before the checkpoint: they are identified by the growth of
+// entries_in_use, and when it can't be known (the buffer is full and wraps around) the collection resynchronises
+// with the meter time. Later entries with the same time as others already collected can't be told apart.
+type Collector struct {
+	// Start is where the first collection starts. If zero, it starts a window before the meter time.
+	Start time.Time
+	// Window is the maximum time span read per request. If zero, DefaultCollectorWindow is used.
+	Window time.Duration
+	// MaxEntries, if not zero, limits the window to this number of capture periods.
+	MaxEntries uint32
+
+	profile *ProfileGeneric
+	store   CheckpointStore
+	key     string
+}
+
+// NewCollector creates a collector of a profile. Device identifies the meter in the checkpoint store.
+func NewCollector(profile *ProfileGeneric, store CheckpointStore, device string) *Collector {
+	return &Collector{
+		Start:      time.Time{},
+		Window:     0,
+		MaxEntries: 0,
+		profile:    profile,
+		store:      store,
+		key:        device + "/" + profile.LogicalName(),
+	}
+}
+
+// Key returns the key of the collector in the checkpoint store.
+func (c *Collector) Key() string {
+	return c.key
+}
+
+// Collect reads the entries captured since the last collection and passes them to handler in capture order.
+// The checkpoint is updated with the entries handled, so an error stops the collection and the next one
+// resumes from the first entry not handled.
+func (c *Collector) Collect(handler func(row ProfileRow) error) error {
+	captureObjects, err := c.profile.CaptureObjects()
+	if err != nil {
+		return err
+	}
+
+	clockIndex := -1
+	for i, co := range captureObjects {
+		if co.ClassID == ClockClassID && co.AttributeID == clockTime {
+			clockIndex = i
+			break
+		}
+	}
+	if clockIndex < 0 {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("profile %s doesn't capture the clock", c.profile.LogicalName()))
+	}
+
+	period, err := c.profile.CapturePeriod()
+	if err != nil {
+		return err
+	}
+
+	entriesInUse, err := c.profile.EntriesInUse()
+	if err != nil {
+		return err
+	}
+
+	var now time.Time
+	clock := object{client: c.profile.client, classID: ClockClassID, logicalName: ClockLogicalName}
+	err = clock.get(clockTime, &now)
+	if err != nil {
+		return err
+	}
+
+	cp, found, err := c.store.Load(c.key)
+	if err != nil {
+		return dlms.NewError(dlms.ErrorUnspecified, fmt.Sprintf("error loading checkpoint %s: %v", c.key, err))
+	}
+
+	// entries_in_use is only updated once all the entries are handled, as the next collection
+	// compares with it to find the new entries
+	window := c.window(period)
+	last := cp
+
+	from := c.Start
+	switch {
+	case !found:
+		if from.IsZero() {
+			from = now.Add(-window)
+		}
+		last.Time = from.Add(-time.Nanosecond)
+	case now.Before(cp.Time):
+		// Clock moved backward: the new entries are the last ones in the buffer
+		newEntries := 0
+		if entriesInUse > cp.EntriesInUse {
+			newEntries = int(entriesInUse - cp.EntriesInUse)
+		}
+
+		err = c.collectBackward(captureObjects, clockIndex, period, now.Add(-window), now, newEntries, &last, handler)
+		if err == nil {
+			last.EntriesInUse = entriesInUse
+		}
+
+		return c.save(cp, last, err)
+	default:
+		from = cp.Time
+	}
+
+	for start := from; start.Before(now); {
+		end := start.Add(window)
+		if end.After(now) {
+			end = now
+		}
+
+		rows, times, err := c.read(captureObjects, clockIndex, period, start, end)
+		if err != nil {
+			return c.save(cp, last, err)
+		}
+		sort.Stable(rowsByTime{rows: rows, times: times})
+
+		for i, row := range rows {
+			if !times[i].After(last.Time) {
+				continue
+			}
+
+			err = handler(row)
+			if err != nil {
+				return c.save(cp, last, err)
+			}
+
+			last.Time = times[i]
+		}
+
+		start = end
+	}
+
+	last.EntriesInUse = entriesInUse
+	return c.save(cp, last, nil)
+}
+
+// collectBackward handles the newest entries after the clock moved backward, which are the last ones of
+// the buffer. If their number is unknown, nothing is collected and the checkpoint is moved to the meter time.
+// If handler fails, the checkpoint is left at the last entry handled, so the next collection resumes from it.
+func (c *Collector) collectBackward(captureObjects []CaptureObject, clockIndex int, period uint32, start time.Time, end time.Time,
+	newEntries int, last *Checkpoint, handler func(row ProfileRow) error,
+) error {
+	if newEntries == 0 {
+		last.Time = end
+		return nil
+	}
+
+	rows, times, err := c.read(captureObjects, clockIndex, period, start, end)
+	if err != nil {
+		return err
+	}
+
+	if len(rows) > newEntries {
+		rows = rows[len(rows)-newEntries:]
+		times = times[len(times)-newEntries:]
+	}
+
+	for i, row := range rows {
+		err = handler(row)
+		if err != nil {
+			return err
+		}
+
+		last.Time = times[i]
+	}
+
+	last.Time = end
+	return nil
+}
+
+// read reads the entries between start and end, in the order of the buffer.
+func (c *Collector) read(captureObjects []CaptureObject, clockIndex int, period uint32, start time.Time, end time.Time) ([]ProfileRow, []time.Time, error) {
+	profile, err := c.profile.readBuffer(captureObjects, c.profile.bufferByDate(start, end))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	times := make([]time.Time, len(profile.Rows))
+	for i, row := range profile.Rows {
+		value := row[clockIndex].Value
+
+		// A null clock is the time of the previous entry plus the capture period
+		if value.Tag == axdr.TagNull && i > 0 && period != 0 {
+			times[i] = times[i-1].Add(time.Duration(period) * time.Second)
+			continue
+		}
+
+		err = axdr.UnmarshalData(value, &times[i])
+		if err != nil {
+			return nil, nil, c.profile.invalidData(profileBuffer, fmt.Errorf("invalid capture time of entry %d: %w", i, err))
+		}
+	}
+
+	return profile.Rows, times, nil
+}
+
+func (c *Collector) window(period uint32) time.Duration {
+	window := c.Window
+	if window <= 0 {
+		window = DefaultCollectorWindow
+	}
+
+	if c.MaxEntries != 0 && period != 0 {
+		if limit := time.Duration(c.MaxEntries) * time.Duration(period) * time.Second; limit < window {
+			window = limit
+		}
+	}
+
+	return window
+}
+
+// save stores the checkpoint if it changed, and returns the collection error if any.
+func (c *Collector) save(previous Checkpoint, cp Checkpoint, err error) error {
+	if cp.Time.Equal(previous.Time) && cp.EntriesInUse == previous.EntriesInUse {
+		return err
+	}
+
+	if e := c.store.Save(c.key, cp); e != nil && err == nil {
+		err = dlms.NewError(dlms.ErrorUnspecified, fmt.Sprintf("error saving checkpoint %s: %v", c.key, e))
+	}
+
+	return err
+}
+
+type rowsByTime struct {
+	rows  []ProfileRow
+	times []time.Time
+}
+
+func (r rowsByTime) Len() int           { return len(r.rows) }
+func (r rowsByTime) Less(i, j int) bool { return r.times[i].Before(r.times[j]) }
+func (r rowsByTime) Swap(i, j int) {
+	r.rows[i], r.rows[j] = r.rows[j], r.rows[i]
+	r.times[i], r.times[j] = r.times[j], r.times[i]
+}
@@ -0,0 +1,222 @@
+package cosem_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// meterProfile simulates a load profile of a meter whose clock and entries are set by the test.
+type meterProfile struct {
+	now      time.Time
+	times    []time.Time
+	entries  uint32
+	requests int
+	mutex    sync.Mutex
+}
+
+func (m *meterProfile) capture(t time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.times = append(m.times, t)
+	m.entries++
+	m.now = t
+}
+
+func (m *meterProfile) objects() []*dlmsserver.Object {
+	captureObjects := axdr.CreateAxdrArray([]*axdr.DlmsData{
+		cosem.CaptureObject{ClassID: 8, LogicalName: *dlms.CreateObis("0.0.1.0.0.255"), AttributeID: 2}.Data(),
+		cosem.CaptureObject{ClassID: 3, LogicalName: *dlms.CreateObis("1.0.1.8.0.255"), AttributeID: 2}.Data(),
+	})
+
+	profile := dlmsserver.NewObject(cosem.ProfileGenericClassID, 1, "1.0.99.1.0.255").
+		SetAttribute(2, func(_ dlms.AttributeDescriptor, acc *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			m.mutex.Lock()
+			defer m.mutex.Unlock()
+
+			m.requests++
+
+			var from, to time.Time
+			params := acc.AccessParameter.Value.([]*axdr.DlmsData)
+			if axdr.UnmarshalData(*params[1], &from) != nil || axdr.UnmarshalData(*params[2], &to) != nil {
+				return axdr.DlmsData{}, dlms.TagAccOtherReason
+			}
+
+			entries := make([]*axdr.DlmsData, 0)
+			for i, tm := range m.times {
+				if !tm.Before(from) && !tm.After(to) {
+					entries = append(entries, axdr.CreateAxdrStructure([]*axdr.DlmsData{
+						axdr.CreateAxdrOctetString(tm),
+						axdr.CreateAxdrDoubleLongUnsigned(uint32(i)),
+					}))
+				}
+			}
+
+			return *axdr.CreateAxdrArray(entries), dlms.TagAccSuccess
+		}, nil).
+		SetValue(3, *captureObjects, false).
+		SetValue(4, *axdr.CreateAxdrDoubleLongUnsigned(900), false).
+		SetAttribute(7, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			m.mutex.Lock()
+			defer m.mutex.Unlock()
+
+			return *axdr.CreateAxdrDoubleLongUnsigned(m.entries), dlms.TagAccSuccess
+		}, nil)
+
+	clock := dlmsserver.NewObject(8, 0, "0.0.1.0.0.255").
+		SetAttribute(2, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			m.mutex.Lock()
+			defer m.mutex.Unlock()
+
+			return *axdr.CreateAxdrOctetString(m.now), dlms.TagAccSuccess
+		}, nil)
+
+	return []*dlmsserver.Object{profile, clock}
+}
+
+func collect(t *testing.T, c *cosem.Collector) []uint32 {
+	t.Helper()
+
+	values := make([]uint32, 0)
+	err := c.Collect(func(row cosem.ProfileRow) error {
+		values = append(values, row[1].Value.Value.(uint32))
+		return nil
+	})
+	require.NoError(t, err)
+
+	return values
+}
+
+func TestCollector(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	m := &meterProfile{}
+	for i := 0; i < 8; i++ {
+		m.capture(start.Add(time.Duration(i) * 15 * time.Minute))
+	}
+
+	store := cosem.NewCheckpointMemoryStore()
+	c := cosem.NewCollector(cosem.NewProfileGeneric(connect(t, m.objects()...), "1.0.99.1.0.255"), store, "meter")
+	c.Start = start
+	c.MaxEntries = 4
+	assert.Equal(t, "meter/1.0.99.1.0.255", c.Key())
+
+	// Windows of one hour
+	assert.Equal(t, []uint32{0, 1, 2, 3, 4, 5, 6, 7}, collect(t, c))
+	assert.Equal(t, 2, m.requests)
+
+	cp, ok, err := store.Load(c.Key())
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.True(t, m.now.Equal(cp.Time))
+	assert.Equal(t, uint32(8), cp.EntriesInUse)
+
+	// Only new entries
+	m.capture(m.now.Add(15 * time.Minute))
+	m.capture(m.now.Add(15 * time.Minute))
+	assert.Equal(t, []uint32{8, 9}, collect(t, c))
+	assert.Empty(t, collect(t, c))
+
+	// An error stops the collection, which is resumed later
+	m.capture(m.now.Add(15 * time.Minute))
+	m.capture(m.now.Add(15 * time.Minute))
+	errHandler := errors.New("handler error")
+	err = c.Collect(func(row cosem.ProfileRow) error {
+		if row[1].Value.Value.(uint32) == 11 {
+			return errHandler
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errHandler)
+	assert.Equal(t, []uint32{11}, collect(t, c))
+
+	// Clock jumps forward
+	m.capture(m.now.Add(72 * time.Hour))
+	assert.Equal(t, []uint32{12}, collect(t, c))
+
+	// Clock jumps backward
+	m.capture(start.Add(-2 * time.Hour))
+	m.capture(start.Add(-105 * time.Minute))
+	assert.Equal(t, []uint32{13, 14}, collect(t, c))
+
+	m.capture(m.now.Add(15 * time.Minute))
+	assert.Equal(t, []uint32{15}, collect(t, c))
+}
+
+func TestCollector_Wrap(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	m := &meterProfile{}
+	for i := 0; i < 4; i++ {
+		m.capture(start.Add(time.Duration(i) * 15 * time.Minute))
+	}
+
+	c := cosem.NewCollector(cosem.NewProfileGeneric(connect(t, m.objects()...), "1.0.99.1.0.255"), cosem.NewCheckpointMemoryStore(), "meter")
+	assert.Equal(t, []uint32{0, 1, 2, 3}, collect(t, c))
+
+	// Clock jumps backward with the buffer full: entries can't be identified and the collector resynchronises
+	m.capture(start.Add(-time.Hour))
+	m.entries = 4
+	assert.Empty(t, collect(t, c))
+
+	m.capture(start.Add(-45 * time.Minute))
+	m.entries = 4
+	assert.Equal(t, []uint32{5}, collect(t, c))
+}
+
+func TestCollector_HandlerError(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	m := &meterProfile{}
+	for i := 0; i < 4; i++ {
+		m.capture(start.Add(time.Duration(i) * 15 * time.Minute))
+	}
+
+	store := cosem.NewCheckpointMemoryStore()
+	c := cosem.NewCollector(cosem.NewProfileGeneric(connect(t, m.objects()...), "1.0.99.1.0.255"), store, "meter")
+	assert.Equal(t, []uint32{0, 1, 2, 3}, collect(t, c))
+
+	errHandler := errors.New("handler error")
+	fail := func(cosem.ProfileRow) error { return errHandler }
+
+	// Entries not handled after the clock jumps backward are delivered again
+	m.capture(start.Add(-2 * time.Hour))
+	m.capture(start.Add(-105 * time.Minute))
+	err := c.Collect(fail)
+	assert.ErrorIs(t, err, errHandler)
+
+	cp, ok, err := store.Load(c.Key())
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, uint32(4), cp.EntriesInUse)
+
+	assert.Equal(t, []uint32{4, 5}, collect(t, c))
+
+	// And when it goes forward
+	m.capture(m.now.Add(15 * time.Minute))
+	m.capture(m.now.Add(15 * time.Minute))
+	err = c.Collect(fail)
+	assert.ErrorIs(t, err, errHandler)
+
+	cp, _, err = store.Load(c.Key())
+	require.NoError(t, err)
+	assert.Equal(t, uint32(6), cp.EntriesInUse)
+
+	assert.Equal(t, []uint32{6, 7}, collect(t, c))
+}
+
+func TestCollector_WithoutClock(t *testing.T) {
+	c := cosem.NewCollector(cosem.NewProfileGeneric(connect(t, newLoadProfile(t)[1:]...), "1.0.1.8.0.255"), cosem.NewCheckpointMemoryStore(), "meter")
+
+	err := c.Collect(func(cosem.ProfileRow) error { return nil })
+	assert.Error(t, err)
+}
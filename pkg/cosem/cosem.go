@@ -0,0 +1,82 @@
+// Package cosem implements typed models of the COSEM interface classes on top of a dlms.Client.
+package cosem
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+// object holds what is needed to access the attributes and methods of a COSEM object.
+type object struct {
+	client      dlms.Client
+	classID     uint16
+	logicalName string
+}
+
+// LogicalName returns the logical name of the object.
+func (o object) LogicalName() string {
+	return o.logicalName
+}
+
+func (o object) attribute(attributeID int8) *dlms.AttributeDescriptor {
+	return dlms.CreateAttributeDescriptor(o.classID, o.logicalName, attributeID)
+}
+
+func (o object) method(methodID int8) *dlms.MethodDescriptor {
+	return dlms.CreateMethodDescriptor(o.classID, o.logicalName, methodID)
+}
+
+func (o object) get(attributeID int8, data interface{}) error {
+	return o.client.GetRequest(o.attribute(attributeID), data)
+}
+
+func (o object) set(attributeID int8, data interface{}) error {
+	return o.client.SetRequest(o.attribute(attributeID), data)
+}
+
+func (o object) action(methodID int8, data interface{}) error {
+	return o.client.ActionRequest(o.method(methodID), data)
+}
+
+// invalidData returns the error used when the data of an attribute can't be decoded.
+func (o object) invalidData(attributeID int8, err error) error {
+	return dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("invalid %s data: %v", o.attribute(attributeID).String(), err))
+}
+
+// decodeBitString decodes a bit string as flags, bit 0 of the bit string being the least significant bit.
+func decodeBitString(data axdr.DlmsData) (uint32, error) {
+	bits, ok := data.Value.(string)
+	if data.Tag != axdr.TagBitString || !ok || len(bits) > 32 {
+		return 0, fmt.Errorf("invalid bit string %v", data.Value)
+	}
+
+	var flags uint32
+	for i, b := range bits {
+		if b == '1' {
+			flags |= 1 << i
+		}
+	}
+
+	return flags, nil
+}
+
+// createBitString returns the flags as a bit string of the given length. See decodeBitString.
+func createBitString(flags uint32, length int) *axdr.DlmsData {
+	bits := make([]byte, length)
+	for i := range bits {
+		bits[i] = '0'
+		if flags&(1<<i) != 0 {
+			bits[i] = '1'
+		}
+	}
+
+	return axdr.CreateAxdrBitString(string(bits))
+}
+
+func isGetRejected(err error) bool {
+	var dlmsError *dlms.Error
+	return errors.As(err, &dlmsError) && dlmsError.Code() == dlms.ErrorGetRejected
+}
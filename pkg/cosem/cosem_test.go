@@ -0,0 +1,63 @@
+package cosem_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsclient"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connect returns a client associated to a simulated meter serving the given objects.
+func connect(t *testing.T, objects ...*dlmsserver.Object) dlms.Client {
+	t.Helper()
+
+	settings, _ := dlms.NewSettingsWithoutAuthentication()
+	c, _ := connectWith(t, settings, objects...)
+
+	return c
+}
+
+// connectWith is like connect with the given settings, and returns the transport of the client,
+// which counts the requests sent.
+func connectWith(t *testing.T, settings dlms.Settings, objects ...*dlmsserver.Object) (dlms.Client, *countingTransport) {
+	t.Helper()
+
+	registry := dlmsserver.NewRegistry()
+	require.NoError(t, registry.Register(objects...))
+
+	ct, st := dlmsserver.NewPipe()
+	tr := &countingTransport{Transport: ct}
+
+	s := dlmsserver.New(settings, registry, st)
+	t.Cleanup(s.Close)
+
+	c := dlmsclient.New(settings, tr, time.Second, 0)
+	require.NoError(t, c.Connect())
+	require.NoError(t, c.Associate())
+
+	return c, tr
+}
+
+// countingTransport counts the requests sent through a transport.
+type countingTransport struct {
+	dlms.Transport
+	sent int
+}
+
+func (t *countingTransport) Send(src []byte) error {
+	t.sent++
+	return t.Transport.Send(src)
+}
+
+func assertErrorCode(t *testing.T, err error, code dlms.ErrorCode) {
+	t.Helper()
+
+	var dlmsError *dlms.Error
+	if assert.ErrorAs(t, err, &dlmsError) {
+		assert.Equal(t, code, dlmsError.Code())
+	}
+}
@@ -0,0 +1,232 @@
+package cosem
+
+import (
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const CreditClassID = 112
+
+const (
+	creditCurrentCreditAmount = 2
+	creditType                = 3
+	creditPriority            = 4
+	creditWarningThreshold    = 5
+	creditLimit               = 6
+	creditConfiguration       = 7
+	creditStatus              = 8
+	creditPresetCreditAmount  = 9
+	creditAvailableThreshold  = 10
+	creditPeriod              = 11
+	creditUpdateAmount        = 1
+	creditSetAmountToValue    = 2
+	creditInvokeCredit        = 3
+)
+
+// CreditType is the kind of credit.
+type CreditType uint8
+
+const (
+	CreditTypeToken            CreditType = 0
+	CreditTypeReserved         CreditType = 1
+	CreditTypeEmergency        CreditType = 2
+	CreditTypeTimeBased        CreditType = 3
+	CreditTypeConsumptionBased CreditType = 4
+)
+
+func (t CreditType) String() string {
+	switch t {
+	case CreditTypeToken:
+		return "token"
+	case CreditTypeReserved:
+		return "reserved"
+	case CreditTypeEmergency:
+		return "emergency"
+	case CreditTypeTimeBased:
+		return "time based"
+	case CreditTypeConsumptionBased:
+		return "consumption based"
+	default:
+		return fmt.Sprintf("unknown (%d)", uint8(t))
+	}
+}
+
+// CreditConfiguration is the credit_configuration of a credit.
+type CreditConfiguration uint8
+
+const (
+	CreditRequiresVisualIndication CreditConfiguration = 0x01
+	CreditRequiresConfirmation     CreditConfiguration = 0x02
+	CreditRequiresPaidBack         CreditConfiguration = 0x04
+	CreditResettable               CreditConfiguration = 0x08
+	CreditReceivesTokens           CreditConfiguration = 0x10
+)
+
+// Has returns true if all the bits of flag are set.
+func (c CreditConfiguration) Has(flag CreditConfiguration) bool {
+	return c&flag == flag
+}
+
+// CreditStatus is the state of a credit.
+type CreditStatus uint8
+
+const (
+	CreditStatusEnabled    CreditStatus = 0
+	CreditStatusSelectable CreditStatus = 1
+	CreditStatusSelected   CreditStatus = 2
+	CreditStatusInUse      CreditStatus = 3
+	CreditStatusExhausted  CreditStatus = 4
+)
+
+func (s CreditStatus) String() string {
+	switch s {
+	case CreditStatusEnabled:
+		return "enabled"
+	case CreditStatusSelectable:
+		return "selectable"
+	case CreditStatusSelected:
+		return "selected"
+	case CreditStatusInUse:
+		return "in use"
+	case CreditStatusExhausted:
+		return "exhausted"
+	default:
+		return fmt.Sprintf("unknown (%d)", uint8(s))
+	}
+}
+
+// CreditValue holds the attributes of a credit. Amounts are in the currency of the account. A lower
+// priority value means that the credit is used first.
+type CreditValue struct {
+	CurrentAmount      int32
+	Type               CreditType
+	Priority           uint8
+	WarningThreshold   int32
+	Limit              int32
+	Configuration      CreditConfiguration
+	Status             CreditStatus
+	PresetAmount       int32
+	AvailableThreshold int32
+	Period             DateTime
+}
+
+// Credit is an instance of the Credit interface class (class_id 112).
+type Credit struct {
+	object
+}
+
+func NewCredit(client dlms.Client, logicalName string) *Credit {
+	return &Credit{object{client: client, classID: CreditClassID, logicalName: logicalName}}
+}
+
+// Read reads all the attributes of the credit.
+func (c *Credit) Read() (v CreditValue, err error) {
+	if v.CurrentAmount, err = c.CurrentAmount(); err != nil {
+		return
+	}
+
+	var value uint8
+	if err = c.get(creditType, &value); err != nil {
+		return
+	}
+	v.Type = CreditType(value)
+
+	if err = c.get(creditPriority, &v.Priority); err != nil {
+		return
+	}
+	if err = c.get(creditWarningThreshold, &v.WarningThreshold); err != nil {
+		return
+	}
+	if err = c.get(creditLimit, &v.Limit); err != nil {
+		return
+	}
+
+	var data axdr.DlmsData
+	if err = c.get(creditConfiguration, &data); err != nil {
+		return
+	}
+	configuration, err := decodeBitString(data)
+	if err != nil {
+		return v, c.invalidData(creditConfiguration, err)
+	}
+	v.Configuration = CreditConfiguration(configuration)
+
+	if v.Status, err = c.Status(); err != nil {
+		return
+	}
+	if err = c.get(creditPresetCreditAmount, &v.PresetAmount); err != nil {
+		return
+	}
+	if err = c.get(creditAvailableThreshold, &v.AvailableThreshold); err != nil {
+		return
+	}
+
+	if err = c.get(creditPeriod, &data); err != nil {
+		return
+	}
+	if v.Period, err = DecodeDateTime(data); err != nil {
+		return v, c.invalidData(creditPeriod, err)
+	}
+
+	return v, nil
+}
+
+// CurrentAmount reads the amount of the credit.
+func (c *Credit) CurrentAmount() (amount int32, err error) {
+	err = c.get(creditCurrentCreditAmount, &amount)
+	return
+}
+
+// Status reads the state of the credit.
+func (c *Credit) Status() (CreditStatus, error) {
+	var status uint8
+
+	err := c.get(creditStatus, &status)
+	return CreditStatus(status), err
+}
+
+// SetPriority sets the order in which the credit is used. Priority 0 is not allowed.
+func (c *Credit) SetPriority(priority uint8) error {
+	if priority == 0 {
+		return dlms.NewError(dlms.ErrorInvalidParameter, "credit priority must be at least 1")
+	}
+
+	return c.set(creditPriority, priority)
+}
+
+// SetThresholds sets the warning threshold and the limit below which the credit can't be used. The
+// limit can't be higher than the warning threshold.
+func (c *Credit) SetThresholds(warning int32, limit int32) error {
+	if limit > warning {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("credit limit %d is higher than the warning threshold %d", limit, warning))
+	}
+
+	err := c.set(creditWarningThreshold, warning)
+	if err != nil {
+		return err
+	}
+
+	return c.set(creditLimit, limit)
+}
+
+// SetConfiguration sets the credit configuration.
+func (c *Credit) SetConfiguration(configuration CreditConfiguration) error {
+	return c.set(creditConfiguration, createBitString(uint32(configuration), 5))
+}
+
+// UpdateAmount adds amount, that can be negative, to the current credit amount.
+func (c *Credit) UpdateAmount(amount int32) error {
+	return c.action(creditUpdateAmount, amount)
+}
+
+// SetAmountToValue sets the current credit amount.
+func (c *Credit) SetAmountToValue(amount int32) error {
+	return c.action(creditSetAmountToValue, amount)
+}
+
+// InvokeCredit selects a selectable credit, such as an emergency credit.
+func (c *Credit) InvokeCredit() error {
+	return c.action(creditInvokeCredit, uint8(CreditStatusSelected))
+}
@@ -0,0 +1,90 @@
+package cosem_test
+
+import (
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// meterCredit simulates a credit of a meter.
+type meterCredit struct {
+	amount int32
+	status cosem.CreditStatus
+}
+
+func (m *meterCredit) object(logicalName string, creditType cosem.CreditType) *dlmsserver.Object {
+	return dlmsserver.NewObject(cosem.CreditClassID, 0, logicalName).
+		SetAttribute(2, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			return *axdr.CreateAxdrDoubleLong(m.amount), dlms.TagAccSuccess
+		}, nil).
+		SetValue(3, *axdr.CreateAxdrEnum(uint8(creditType)), false).
+		SetValue(4, *axdr.CreateAxdrUnsigned(1), true).
+		SetValue(5, *axdr.CreateAxdrDoubleLong(500), true).
+		SetValue(6, *axdr.CreateAxdrDoubleLong(0), true).
+		SetValue(7, *axdr.CreateAxdrBitString("10011"), true).
+		SetAttribute(8, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			return *axdr.CreateAxdrEnum(uint8(m.status)), dlms.TagAccSuccess
+		}, nil).
+		SetValue(9, *axdr.CreateAxdrDoubleLong(1000), true).
+		SetValue(10, *axdr.CreateAxdrDoubleLong(0), true).
+		SetValue(11, *axdr.CreateAxdrOctetString("ffffffffffffffffff800000"), true).
+		SetMethod(1, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			m.amount += data.Value.(int32)
+			return nil, dlms.TagActSuccess
+		}).
+		SetMethod(2, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			m.amount = data.Value.(int32)
+			return nil, dlms.TagActSuccess
+		}).
+		SetMethod(3, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			if m.status != cosem.CreditStatusSelectable {
+				return nil, dlms.TagActTemporaryFailure
+			}
+			m.status = cosem.CreditStatus(data.Value.(uint8))
+			return nil, dlms.TagActSuccess
+		})
+}
+
+func TestCredit(t *testing.T) {
+	m := &meterCredit{amount: 1000, status: cosem.CreditStatusSelectable}
+	c := cosem.NewCredit(connect(t, m.object(emergencyCreditLogicalName, cosem.CreditTypeEmergency)), emergencyCreditLogicalName)
+
+	v, err := c.Read()
+	require.NoError(t, err)
+	assert.Equal(t, int32(1000), v.CurrentAmount)
+	assert.Equal(t, cosem.CreditTypeEmergency, v.Type)
+	assert.Equal(t, uint8(1), v.Priority)
+	assert.Equal(t, cosem.CreditRequiresVisualIndication|cosem.CreditResettable|cosem.CreditReceivesTokens, v.Configuration)
+	assert.Equal(t, cosem.CreditStatusSelectable, v.Status)
+	assert.Equal(t, int32(1000), v.PresetAmount)
+
+	require.NoError(t, c.UpdateAmount(-250))
+	require.NoError(t, c.UpdateAmount(100))
+	amount, err := c.CurrentAmount()
+	require.NoError(t, err)
+	assert.Equal(t, int32(850), amount)
+
+	require.NoError(t, c.SetAmountToValue(0))
+	assert.Equal(t, int32(0), m.amount)
+
+	require.NoError(t, c.InvokeCredit())
+	status, err := c.Status()
+	require.NoError(t, err)
+	assert.Equal(t, cosem.CreditStatusSelected, status)
+	assertErrorCode(t, c.InvokeCredit(), dlms.ErrorActionRejected)
+
+	require.NoError(t, c.SetThresholds(200, -100))
+	assertErrorCode(t, c.SetThresholds(200, 300), dlms.ErrorInvalidParameter)
+	assertErrorCode(t, c.SetPriority(0), dlms.ErrorInvalidParameter)
+
+	require.NoError(t, c.SetConfiguration(cosem.CreditRequiresPaidBack))
+	v, err = c.Read()
+	require.NoError(t, err)
+	assert.Equal(t, cosem.CreditRequiresPaidBack, v.Configuration)
+	assert.Equal(t, int32(-100), v.Limit)
+}
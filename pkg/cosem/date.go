@@ -0,0 +1,121 @@
+package cosem
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+)
+
+const (
+	// NotSpecified is the wildcard of the fields of dates and times
+	NotSpecified = 0xFF
+	// YearNotSpecified is the wildcard of the year
+	YearNotSpecified = 0xFFFF
+	// DayLast is the last day of the month
+	DayLast = 0xFE
+	// DaySecondLast is the second last day of the month
+	DaySecondLast = 0xFD
+)
+
+// Date is a COSEM date, used in calendars. Fields can be wildcards.
+type Date struct {
+	Year      uint16
+	Month     uint8
+	Day       uint8
+	DayOfWeek uint8
+}
+
+// Bytes returns the date as a 5 bytes octet string.
+func (d Date) Bytes() []byte {
+	out := make([]byte, 5)
+	binary.BigEndian.PutUint16(out, d.Year)
+	out[2] = d.Month
+	out[3] = d.Day
+	out[4] = d.DayOfWeek
+
+	return out
+}
+
+func (d Date) String() string {
+	return fmt.Sprintf("%s-%s-%s", wildcard(int(d.Year), YearNotSpecified, "%04d"), wildcard(int(d.Month), NotSpecified, "%02d"), wildcard(int(d.Day), NotSpecified, "%02d"))
+}
+
+func decodeDate(src []byte) Date {
+	return Date{Year: binary.BigEndian.Uint16(src), Month: src[2], Day: src[3], DayOfWeek: src[4]}
+}
+
+// Time is a COSEM time, used in calendars. Fields can be wildcards.
+type Time struct {
+	Hour       uint8
+	Minute     uint8
+	Second     uint8
+	Hundredths uint8
+}
+
+// Bytes returns the time as a 4 bytes octet string.
+func (t Time) Bytes() []byte {
+	return []byte{t.Hour, t.Minute, t.Second, t.Hundredths}
+}
+
+func (t Time) String() string {
+	return fmt.Sprintf("%s:%s:%s", wildcard(int(t.Hour), NotSpecified, "%02d"), wildcard(int(t.Minute), NotSpecified, "%02d"), wildcard(int(t.Second), NotSpecified, "%02d"))
+}
+
+// before returns true if t is before other. Wildcards are taken as zero.
+func (t Time) before(other Time) bool {
+	a := t.Bytes()
+	b := other.Bytes()
+
+	for i := range a {
+		x, y := a[i], b[i]
+		if x == NotSpecified {
+			x = 0
+		}
+		if y == NotSpecified {
+			y = 0
+		}
+		if x != y {
+			return x < y
+		}
+	}
+
+	return false
+}
+
+func decodeTime(src []byte) Time {
+	return Time{Hour: src[0], Minute: src[1], Second: src[2], Hundredths: src[3]}
+}
+
+// octetString returns the bytes of an octet string.
+func octetString(data axdr.DlmsData, length int) ([]byte, error) {
+	str, ok := data.Value.(string)
+	if data.Tag != axdr.TagOctetString || !ok {
+		return nil, fmt.Errorf("invalid octet string %v", data.Value)
+	}
+
+	out, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, fmt.Errorf("invalid octet string %s", str)
+	}
+
+	if length != 0 && len(out) != length {
+		return nil, fmt.Errorf("unexpected octet string length %d, expecting %d", len(out), length)
+	}
+
+	return out, nil
+}
+
+// createOctetString returns the bytes as A-XDR octet string.
+func createOctetString(src []byte) *axdr.DlmsData {
+	return axdr.CreateAxdrOctetString(hex.EncodeToString(src))
+}
+
+func wildcard(value int, notSpecified int, format string) string {
+	if value == notSpecified {
+		return "*"
+	}
+
+	return fmt.Sprintf(format, value)
+}
@@ -0,0 +1,154 @@
+package cosem
+
+import (
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const (
+	DisconnectControlClassID = 70
+	// DisconnectControlLogicalName is the logical name of the disconnector of the meter
+	DisconnectControlLogicalName = "0.0.96.3.10.255"
+)
+
+const (
+	disconnectOutputState      = 2
+	disconnectControlState     = 3
+	disconnectControlMode      = 4
+	disconnectRemoteDisconnect = 1
+	disconnectRemoteReconnect  = 2
+)
+
+type ControlState uint8
+
+const (
+	ControlStateDisconnected         ControlState = 0
+	ControlStateConnected            ControlState = 1
+	ControlStateReadyForReconnection ControlState = 2
+)
+
+func (s ControlState) String() string {
+	switch s {
+	case ControlStateDisconnected:
+		return "disconnected"
+	case ControlStateConnected:
+		return "connected"
+	case ControlStateReadyForReconnection:
+		return "ready_for_reconnection"
+	default:
+		return ""
+	}
+}
+
+// ControlMode defines which transitions of the disconnector are possible, as numbered in the Blue Book.
+type ControlMode uint8
+
+const (
+	ControlModeNone ControlMode = 0
+	ControlMode1    ControlMode = 1
+	ControlMode2    ControlMode = 2
+	ControlMode3    ControlMode = 3
+	ControlMode4    ControlMode = 4
+	ControlMode5    ControlMode = 5
+	ControlMode6    ControlMode = 6
+)
+
+// DisconnectControlValue holds the attributes of a disconnector.
+type DisconnectControlValue struct {
+	OutputState  bool
+	ControlState ControlState
+	ControlMode  ControlMode
+}
+
+// DisconnectControl is an instance of the Disconnect Control interface class (class_id 70).
+type DisconnectControl struct {
+	object
+}
+
+func NewDisconnectControl(client dlms.Client, logicalName string) *DisconnectControl {
+	return &DisconnectControl{object{client: client, classID: DisconnectControlClassID, logicalName: logicalName}}
+}
+
+// Read reads the state and mode of the disconnector.
+func (d *DisconnectControl) Read() (v DisconnectControlValue, err error) {
+	if v.OutputState, err = d.OutputState(); err != nil {
+		return
+	}
+	if v.ControlState, err = d.ControlState(); err != nil {
+		return
+	}
+
+	v.ControlMode, err = d.ControlMode()
+	return
+}
+
+// OutputState reads the state of the supply: true if connected.
+func (d *DisconnectControl) OutputState() (state bool, err error) {
+	err = d.get(disconnectOutputState, &state)
+	return
+}
+
+// ControlState reads the internal state of the disconnector.
+func (d *DisconnectControl) ControlState() (ControlState, error) {
+	var state uint8
+
+	err := d.get(disconnectControlState, &state)
+	return ControlState(state), err
+}
+
+// ControlMode reads the configuration of the disconnector.
+func (d *DisconnectControl) ControlMode() (ControlMode, error) {
+	var mode uint8
+
+	err := d.get(disconnectControlMode, &mode)
+	return ControlMode(mode), err
+}
+
+// SetControlMode sets the configuration of the disconnector.
+func (d *DisconnectControl) SetControlMode(mode ControlMode) error {
+	return d.set(disconnectControlMode, axdr.CreateAxdrEnum(uint8(mode)))
+}
+
+// RemoteDisconnect disconnects the supply and checks that the disconnector is disconnected.
+func (d *DisconnectControl) RemoteDisconnect() error {
+	err := d.action(disconnectRemoteDisconnect, int8(0))
+	if err != nil {
+		return err
+	}
+
+	v, err := d.Read()
+	if err != nil {
+		return err
+	}
+
+	if v.ControlState != ControlStateDisconnected || v.OutputState {
+		return dlms.NewError(dlms.ErrorCheckDoesNotMatch, fmt.Sprintf("disconnector not disconnected: %s, output state %t", v.ControlState.String(), v.OutputState))
+	}
+
+	return nil
+}
+
+// RemoteReconnect reconnects the supply and checks the state of the disconnector. Depending on the
+// control mode, the result is connected or ready for a manual reconnection, which is returned.
+func (d *DisconnectControl) RemoteReconnect() (ControlState, error) {
+	err := d.action(disconnectRemoteReconnect, int8(0))
+	if err != nil {
+		return 0, err
+	}
+
+	v, err := d.Read()
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case v.ControlState == ControlStateConnected && v.OutputState:
+	case v.ControlState == ControlStateReadyForReconnection && !v.OutputState:
+	default:
+		return v.ControlState, dlms.NewError(dlms.ErrorCheckDoesNotMatch, fmt.Sprintf("disconnector not reconnected: %s, output state %t", v.ControlState.String(), v.OutputState))
+	}
+
+	return v.ControlState, nil
+}
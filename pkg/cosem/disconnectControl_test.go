@@ -0,0 +1,99 @@
+package cosem_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// meterDisconnector simulates a disconnector. If stuck, remote commands are accepted but ignored.
+type meterDisconnector struct {
+	state cosem.ControlState
+	mode  cosem.ControlMode
+	stuck bool
+	mutex sync.Mutex
+}
+
+func (m *meterDisconnector) object() *dlmsserver.Object {
+	action := func(state cosem.ControlState) dlmsserver.ActionHandler {
+		return func(dlms.MethodDescriptor, *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			m.mutex.Lock()
+			defer m.mutex.Unlock()
+
+			if !m.stuck {
+				m.state = state
+			}
+			return nil, dlms.TagActSuccess
+		}
+	}
+
+	reconnected := cosem.ControlStateConnected
+	if m.mode == cosem.ControlMode2 {
+		reconnected = cosem.ControlStateReadyForReconnection
+	}
+
+	return dlmsserver.NewObject(cosem.DisconnectControlClassID, 0, cosem.DisconnectControlLogicalName).
+		SetAttribute(2, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			m.mutex.Lock()
+			defer m.mutex.Unlock()
+
+			return *axdr.CreateAxdrBoolean(m.state == cosem.ControlStateConnected), dlms.TagAccSuccess
+		}, nil).
+		SetAttribute(3, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			m.mutex.Lock()
+			defer m.mutex.Unlock()
+
+			return *axdr.CreateAxdrEnum(uint8(m.state)), dlms.TagAccSuccess
+		}, nil).
+		SetValue(4, *axdr.CreateAxdrEnum(uint8(m.mode)), true).
+		SetMethod(1, action(cosem.ControlStateDisconnected)).
+		SetMethod(2, action(reconnected))
+}
+
+func TestDisconnectControl(t *testing.T) {
+	m := &meterDisconnector{state: cosem.ControlStateConnected, mode: cosem.ControlMode1}
+	d := cosem.NewDisconnectControl(connect(t, m.object()), cosem.DisconnectControlLogicalName)
+
+	v, err := d.Read()
+	require.NoError(t, err)
+	assert.Equal(t, cosem.DisconnectControlValue{OutputState: true, ControlState: cosem.ControlStateConnected, ControlMode: cosem.ControlMode1}, v)
+
+	require.NoError(t, d.RemoteDisconnect())
+	assert.Equal(t, cosem.ControlStateDisconnected, m.state)
+
+	state, err := d.RemoteReconnect()
+	require.NoError(t, err)
+	assert.Equal(t, cosem.ControlStateConnected, state)
+
+	require.NoError(t, d.SetControlMode(cosem.ControlMode3))
+	mode, err := d.ControlMode()
+	assert.NoError(t, err)
+	assert.Equal(t, cosem.ControlMode3, mode)
+}
+
+func TestDisconnectControl_ReadyForReconnection(t *testing.T) {
+	m := &meterDisconnector{state: cosem.ControlStateDisconnected, mode: cosem.ControlMode2}
+	d := cosem.NewDisconnectControl(connect(t, m.object()), cosem.DisconnectControlLogicalName)
+
+	state, err := d.RemoteReconnect()
+	require.NoError(t, err)
+	assert.Equal(t, cosem.ControlStateReadyForReconnection, state)
+}
+
+func TestDisconnectControl_NotApplied(t *testing.T) {
+	m := &meterDisconnector{state: cosem.ControlStateConnected, mode: cosem.ControlMode1, stuck: true}
+	d := cosem.NewDisconnectControl(connect(t, m.object()), cosem.DisconnectControlLogicalName)
+
+	err := d.RemoteDisconnect()
+	assertErrorCode(t, err, dlms.ErrorCheckDoesNotMatch)
+
+	m.state = cosem.ControlStateDisconnected
+	_, err = d.RemoteReconnect()
+	assertErrorCode(t, err, dlms.ErrorCheckDoesNotMatch)
+}
@@ -0,0 +1,142 @@
+package cosem
+
+import (
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const (
+	GPRSModemSetupClassID = 45
+	// GPRSModemSetupLogicalName is the logical name of the GPRS modem setup object of the meter
+	GPRSModemSetupLogicalName = "0.0.25.4.0.255"
+)
+
+const (
+	gprsAPN              = 2
+	gprsPINCode          = 3
+	gprsQualityOfService = 4
+)
+
+// QualityOfService is a GPRS QoS profile as defined in GSM 03.60. Zero values mean subscribed.
+type QualityOfService struct {
+	Precedence     uint8
+	Delay          uint8
+	Reliability    uint8
+	PeakThroughput uint8
+	MeanThroughput uint8
+}
+
+func (q QualityOfService) data() *axdr.DlmsData {
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrUnsigned(q.Precedence),
+		axdr.CreateAxdrUnsigned(q.Delay),
+		axdr.CreateAxdrUnsigned(q.Reliability),
+		axdr.CreateAxdrUnsigned(q.PeakThroughput),
+		axdr.CreateAxdrUnsigned(q.MeanThroughput),
+	})
+}
+
+func decodeQualityOfService(data axdr.DlmsData) (q QualityOfService, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 5)
+	if err != nil {
+		return
+	}
+
+	values := []*uint8{&q.Precedence, &q.Delay, &q.Reliability, &q.PeakThroughput, &q.MeanThroughput}
+	for i, f := range fields {
+		value, ok := f.Value.(uint8)
+		if !ok {
+			return q, fmt.Errorf("invalid QoS element %v", f.Value)
+		}
+		*values[i] = value
+	}
+
+	return
+}
+
+// GPRSModemSetupValue holds the attributes of a GPRS modem setup. A zero PIN code means that the SIM has
+// no PIN.
+type GPRSModemSetupValue struct {
+	APN          string
+	PINCode      uint16
+	DefaultQoS   QualityOfService
+	RequestedQoS QualityOfService
+}
+
+// Validate checks the APN and the PIN code.
+func (v GPRSModemSetupValue) Validate() error {
+	if v.APN == "" {
+		return dlms.NewError(dlms.ErrorInvalidParameter, "APN is empty")
+	}
+
+	if len(v.APN) > 100 {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("APN %s is longer than 100 characters", v.APN))
+	}
+
+	if v.PINCode > 9999 {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("PIN code %d has more than 4 digits", v.PINCode))
+	}
+
+	return nil
+}
+
+// GPRSModemSetup is an instance of the GPRS modem setup interface class (class_id 45).
+type GPRSModemSetup struct {
+	object
+}
+
+func NewGPRSModemSetup(client dlms.Client, logicalName string) *GPRSModemSetup {
+	return &GPRSModemSetup{object{client: client, classID: GPRSModemSetupClassID, logicalName: logicalName}}
+}
+
+// Read reads all the attributes of the GPRS modem setup.
+func (s *GPRSModemSetup) Read() (v GPRSModemSetupValue, err error) {
+	var data axdr.DlmsData
+	if err = s.get(gprsAPN, &data); err != nil {
+		return
+	}
+	apn, err := octetString(data, 0)
+	if err != nil {
+		return v, s.invalidData(gprsAPN, err)
+	}
+	v.APN = string(apn)
+
+	if err = s.get(gprsPINCode, &v.PINCode); err != nil {
+		return
+	}
+
+	if err = s.get(gprsQualityOfService, &data); err != nil {
+		return
+	}
+	qos, err := dlms.DataAsSlice(data, axdr.TagStructure, 2)
+	if err != nil {
+		return v, s.invalidData(gprsQualityOfService, err)
+	}
+	if v.DefaultQoS, err = decodeQualityOfService(*qos[0]); err != nil {
+		return v, s.invalidData(gprsQualityOfService, err)
+	}
+	if v.RequestedQoS, err = decodeQualityOfService(*qos[1]); err != nil {
+		return v, s.invalidData(gprsQualityOfService, err)
+	}
+
+	return
+}
+
+// Write validates and writes all the attributes of the GPRS modem setup.
+func (s *GPRSModemSetup) Write(v GPRSModemSetupValue) error {
+	err := v.Validate()
+	if err != nil {
+		return err
+	}
+
+	if err = s.set(gprsAPN, createOctetString([]byte(v.APN))); err != nil {
+		return err
+	}
+	if err = s.set(gprsPINCode, v.PINCode); err != nil {
+		return err
+	}
+
+	return s.set(gprsQualityOfService, axdr.CreateAxdrStructure([]*axdr.DlmsData{v.DefaultQoS.data(), v.RequestedQoS.data()}))
+}
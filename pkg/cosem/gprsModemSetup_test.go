@@ -0,0 +1,54 @@
+package cosem_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGPRSModemSetup(t *testing.T) {
+	qos := func(values ...uint8) *axdr.DlmsData {
+		elements := make([]*axdr.DlmsData, len(values))
+		for i, v := range values {
+			elements[i] = axdr.CreateAxdrUnsigned(v)
+		}
+		return axdr.CreateAxdrStructure(elements)
+	}
+
+	o := dlmsserver.NewObject(cosem.GPRSModemSetupClassID, 0, cosem.GPRSModemSetupLogicalName).
+		SetValue(2, *axdr.CreateAxdrOctetString(hex.EncodeToString([]byte("internet"))), true).
+		SetValue(3, *axdr.CreateAxdrLongUnsigned(1234), true).
+		SetValue(4, *axdr.CreateAxdrStructure([]*axdr.DlmsData{qos(0, 0, 0, 0, 0), qos(1, 2, 3, 4, 5)}), true)
+
+	s := cosem.NewGPRSModemSetup(connect(t, o), cosem.GPRSModemSetupLogicalName)
+
+	v, err := s.Read()
+	require.NoError(t, err)
+	assert.Equal(t, cosem.GPRSModemSetupValue{
+		APN:          "internet",
+		PINCode:      1234,
+		RequestedQoS: cosem.QualityOfService{Precedence: 1, Delay: 2, Reliability: 3, PeakThroughput: 4, MeanThroughput: 5},
+	}, v)
+
+	v.APN = "m2m.example.com"
+	v.PINCode = 0
+	v.DefaultQoS.Precedence = 2
+	require.NoError(t, s.Write(v))
+
+	read, err := s.Read()
+	require.NoError(t, err)
+	assert.Equal(t, v, read)
+
+	v.PINCode = 12345
+	assertErrorCode(t, s.Write(v), dlms.ErrorInvalidParameter)
+
+	v.PINCode = 0
+	v.APN = ""
+	assertErrorCode(t, s.Write(v), dlms.ErrorInvalidParameter)
+}
@@ -0,0 +1,195 @@
+package cosem
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const (
+	HDLCSetupClassID = 23
+	// HDLCSetupLogicalName is the logical name of the HDLC setup object of the optical port
+	HDLCSetupLogicalName = "0.0.22.0.0.255"
+)
+
+const (
+	hdlcCommSpeed                  = 2
+	hdlcWindowSizeTransmit         = 3
+	hdlcWindowSizeReceive          = 4
+	hdlcMaxInfoFieldLengthTransmit = 5
+	hdlcMaxInfoFieldLengthReceive  = 6
+	hdlcInterOctetTimeout          = 7
+	hdlcInactivityTimeout          = 8
+	hdlcDeviceAddress              = 9
+)
+
+// CommSpeed is the baud rate of the HDLC port.
+type CommSpeed uint8
+
+const (
+	CommSpeed300    CommSpeed = 0
+	CommSpeed600    CommSpeed = 1
+	CommSpeed1200   CommSpeed = 2
+	CommSpeed2400   CommSpeed = 3
+	CommSpeed4800   CommSpeed = 4
+	CommSpeed9600   CommSpeed = 5
+	CommSpeed19200  CommSpeed = 6
+	CommSpeed38400  CommSpeed = 7
+	CommSpeed57600  CommSpeed = 8
+	CommSpeed115200 CommSpeed = 9
+)
+
+// baudRates are the bauds of each comm speed, by index.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var baudRates = []int{300, 600, 1200, 2400, 4800, 9600, 19200, 38400, 57600, 115200}
+
+// BaudRate returns the speed in bauds, or 0 if the speed is unknown.
+func (s CommSpeed) BaudRate() int {
+	if int(s) >= len(baudRates) {
+		return 0
+	}
+
+	return baudRates[s]
+}
+
+func (s CommSpeed) String() string {
+	if s.BaudRate() == 0 {
+		return fmt.Sprintf("unknown (%d)", uint8(s))
+	}
+
+	return fmt.Sprintf("%d baud", s.BaudRate())
+}
+
+// CommSpeedFromBaudRate returns the comm speed of a baud rate.
+func CommSpeedFromBaudRate(baudRate int) (CommSpeed, error) {
+	for i, b := range baudRates {
+		if b == baudRate {
+			return CommSpeed(i), nil
+		}
+	}
+
+	return 0, dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("unsupported baud rate %d", baudRate))
+}
+
+// HDLCSetupValue holds the attributes of an HDLC setup. A zero inactivity timeout means that the
+// connection is never closed.
+type HDLCSetupValue struct {
+	CommSpeed                  CommSpeed
+	WindowSizeTransmit         uint8
+	WindowSizeReceive          uint8
+	MaxInfoFieldLengthTransmit uint16
+	MaxInfoFieldLengthReceive  uint16
+	InterOctetTimeout          time.Duration
+	InactivityTimeout          time.Duration
+	DeviceAddress              uint16
+}
+
+// Validate checks the values against the ranges of the Blue Book.
+func (v HDLCSetupValue) Validate() error {
+	if v.CommSpeed > CommSpeed115200 {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("invalid comm speed %d", v.CommSpeed))
+	}
+
+	for _, w := range []uint8{v.WindowSizeTransmit, v.WindowSizeReceive} {
+		if w < 1 || w > 7 {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("window size %d out of range 1-7", w))
+		}
+	}
+
+	for _, l := range []uint16{v.MaxInfoFieldLengthTransmit, v.MaxInfoFieldLengthReceive} {
+		if l < 32 || l > 2030 {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("max info field length %d out of range 32-2030", l))
+		}
+	}
+
+	if v.InterOctetTimeout < 20*time.Millisecond || v.InterOctetTimeout > 6000*time.Millisecond || v.InterOctetTimeout%time.Millisecond != 0 {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("inter octet timeout %s must be whole milliseconds from 20ms to 6s", v.InterOctetTimeout))
+	}
+
+	if v.DeviceAddress < 0x10 || v.DeviceAddress > 0x3FFD {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("device address 0x%X out of range 0x10-0x3FFD", v.DeviceAddress))
+	}
+
+	return validateSeconds("inactivity timeout", v.InactivityTimeout)
+}
+
+// HDLCSetup is an instance of the IEC HDLC setup interface class (class_id 23).
+type HDLCSetup struct {
+	object
+}
+
+func NewHDLCSetup(client dlms.Client, logicalName string) *HDLCSetup {
+	return &HDLCSetup{object{client: client, classID: HDLCSetupClassID, logicalName: logicalName}}
+}
+
+// Read reads all the attributes of the HDLC setup.
+func (s *HDLCSetup) Read() (v HDLCSetupValue, err error) {
+	var speed uint8
+	if err = s.get(hdlcCommSpeed, &speed); err != nil {
+		return
+	}
+	v.CommSpeed = CommSpeed(speed)
+
+	if err = s.get(hdlcWindowSizeTransmit, &v.WindowSizeTransmit); err != nil {
+		return
+	}
+	if err = s.get(hdlcWindowSizeReceive, &v.WindowSizeReceive); err != nil {
+		return
+	}
+	if err = s.get(hdlcMaxInfoFieldLengthTransmit, &v.MaxInfoFieldLengthTransmit); err != nil {
+		return
+	}
+	if err = s.get(hdlcMaxInfoFieldLengthReceive, &v.MaxInfoFieldLengthReceive); err != nil {
+		return
+	}
+
+	var timeout uint16
+	if err = s.get(hdlcInterOctetTimeout, &timeout); err != nil {
+		return
+	}
+	v.InterOctetTimeout = time.Duration(timeout) * time.Millisecond
+
+	if err = s.get(hdlcInactivityTimeout, &timeout); err != nil {
+		return
+	}
+	v.InactivityTimeout = time.Duration(timeout) * time.Second
+
+	err = s.get(hdlcDeviceAddress, &v.DeviceAddress)
+	return
+}
+
+// Write validates and writes all the attributes of the HDLC setup. The comm speed is written last, as
+// the meter may apply it immediately.
+func (s *HDLCSetup) Write(v HDLCSetupValue) error {
+	err := v.Validate()
+	if err != nil {
+		return err
+	}
+
+	if err = s.set(hdlcWindowSizeTransmit, v.WindowSizeTransmit); err != nil {
+		return err
+	}
+	if err = s.set(hdlcWindowSizeReceive, v.WindowSizeReceive); err != nil {
+		return err
+	}
+	if err = s.set(hdlcMaxInfoFieldLengthTransmit, v.MaxInfoFieldLengthTransmit); err != nil {
+		return err
+	}
+	if err = s.set(hdlcMaxInfoFieldLengthReceive, v.MaxInfoFieldLengthReceive); err != nil {
+		return err
+	}
+	if err = s.set(hdlcInterOctetTimeout, uint16(v.InterOctetTimeout/time.Millisecond)); err != nil {
+		return err
+	}
+	if err = s.set(hdlcInactivityTimeout, uint16(v.InactivityTimeout/time.Second)); err != nil {
+		return err
+	}
+	if err = s.set(hdlcDeviceAddress, v.DeviceAddress); err != nil {
+		return err
+	}
+
+	return s.set(hdlcCommSpeed, axdr.CreateAxdrEnum(uint8(v.CommSpeed)))
+}
@@ -0,0 +1,69 @@
+package cosem_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHDLCSetup(t *testing.T) {
+	o := dlmsserver.NewObject(cosem.HDLCSetupClassID, 1, cosem.HDLCSetupLogicalName).
+		SetValue(2, *axdr.CreateAxdrEnum(uint8(cosem.CommSpeed9600)), true).
+		SetValue(3, *axdr.CreateAxdrUnsigned(1), true).
+		SetValue(4, *axdr.CreateAxdrUnsigned(1), true).
+		SetValue(5, *axdr.CreateAxdrLongUnsigned(128), true).
+		SetValue(6, *axdr.CreateAxdrLongUnsigned(128), true).
+		SetValue(7, *axdr.CreateAxdrLongUnsigned(25), true).
+		SetValue(8, *axdr.CreateAxdrLongUnsigned(120), true).
+		SetValue(9, *axdr.CreateAxdrLongUnsigned(0x10), true)
+
+	s := cosem.NewHDLCSetup(connect(t, o), cosem.HDLCSetupLogicalName)
+
+	v, err := s.Read()
+	require.NoError(t, err)
+	assert.Equal(t, cosem.HDLCSetupValue{
+		CommSpeed:                  cosem.CommSpeed9600,
+		WindowSizeTransmit:         1,
+		WindowSizeReceive:          1,
+		MaxInfoFieldLengthTransmit: 128,
+		MaxInfoFieldLengthReceive:  128,
+		InterOctetTimeout:          25 * time.Millisecond,
+		InactivityTimeout:          2 * time.Minute,
+		DeviceAddress:              0x10,
+	}, v)
+	assert.Equal(t, 9600, v.CommSpeed.BaudRate())
+
+	v.CommSpeed, err = cosem.CommSpeedFromBaudRate(115200)
+	require.NoError(t, err)
+	v.WindowSizeReceive = 7
+	v.MaxInfoFieldLengthReceive = 2030
+	v.InterOctetTimeout = 200 * time.Millisecond
+	require.NoError(t, s.Write(v))
+
+	read, err := s.Read()
+	require.NoError(t, err)
+	assert.Equal(t, v, read)
+	assert.Equal(t, "115200 baud", read.CommSpeed.String())
+
+	_, err = cosem.CommSpeedFromBaudRate(14400)
+	assertErrorCode(t, err, dlms.ErrorInvalidParameter)
+
+	invalid := []func(v *cosem.HDLCSetupValue){
+		func(v *cosem.HDLCSetupValue) { v.WindowSizeTransmit = 8 },
+		func(v *cosem.HDLCSetupValue) { v.MaxInfoFieldLengthTransmit = 16 },
+		func(v *cosem.HDLCSetupValue) { v.InterOctetTimeout = 10 * time.Millisecond },
+		func(v *cosem.HDLCSetupValue) { v.DeviceAddress = 0x3FFE },
+		func(v *cosem.HDLCSetupValue) { v.CommSpeed = 10 },
+	}
+	for _, change := range invalid {
+		value := v
+		change(&value)
+		assertErrorCode(t, s.Write(value), dlms.ErrorInvalidParameter)
+	}
+}
@@ -0,0 +1,493 @@
+package cosem
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const (
+	ImageTransferClassID = 18
+	// ImageTransferLogicalName is the logical name of the image transfer object of the meter
+	ImageTransferLogicalName = "0.0.44.0.0.255"
+)
+
+const (
+	imageBlockSize                = 2
+	imageTransferredBlocksStatus  = 3
+	imageFirstNotTransferredBlock = 4
+	imageTransferEnabled          = 5
+	imageTransferStatus           = 6
+	imageToActivateInfo           = 7
+	imageTransferInitiate         = 1
+	imageBlockTransfer            = 2
+	imageVerify                   = 3
+	imageActivate                 = 4
+)
+
+type ImageTransferStatus uint8
+
+const (
+	ImageTransferNotInitiated   ImageTransferStatus = 0
+	ImageTransferInitiated      ImageTransferStatus = 1
+	ImageVerificationInitiated  ImageTransferStatus = 2
+	ImageVerificationSuccessful ImageTransferStatus = 3
+	ImageVerificationFailed     ImageTransferStatus = 4
+	ImageActivationInitiated    ImageTransferStatus = 5
+	ImageActivationSuccessful   ImageTransferStatus = 6
+	ImageActivationFailed       ImageTransferStatus = 7
+)
+
+const (
+	defaultImageTransferPollInterval = 5 * time.Second
+	defaultImageTransferTimeout      = 5 * time.Minute
+	defaultImageTransferRetries      = 3
+)
+
+func (s ImageTransferStatus) String() string {
+	switch s {
+	case ImageTransferNotInitiated:
+		return "image transfer not initiated"
+	case ImageTransferInitiated:
+		return "image transfer initiated"
+	case ImageVerificationInitiated:
+		return "image verification initiated"
+	case ImageVerificationSuccessful:
+		return "image verification successful"
+	case ImageVerificationFailed:
+		return "image verification failed"
+	case ImageActivationInitiated:
+		return "image activation initiated"
+	case ImageActivationSuccessful:
+		return "image activation successful"
+	case ImageActivationFailed:
+		return "image activation failed"
+	default:
+		return ""
+	}
+}
+
+// ImageToActivateInfo describes an image ready to be activated.
+type ImageToActivateInfo struct {
+	Size           uint32
+	Identification []byte
+	Signature      []byte
+}
+
+// ImageTransfer is an instance of the Image Transfer interface class (class_id 18).
+type ImageTransfer struct {
+	object
+}
+
+func NewImageTransfer(client dlms.Client, logicalName string) *ImageTransfer {
+	return &ImageTransfer{object{client: client, classID: ImageTransferClassID, logicalName: logicalName}}
+}
+
+// BlockSize reads the size of the blocks the meter can receive.
+func (t *ImageTransfer) BlockSize() (size uint32, err error) {
+	err = t.get(imageBlockSize, &size)
+	return
+}
+
+// TransferredBlocksStatus reads which blocks have been received, indexed by block number.
+func (t *ImageTransfer) TransferredBlocksStatus() ([]bool, error) {
+	var data axdr.DlmsData
+
+	err := t.get(imageTransferredBlocksStatus, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	bits, ok := data.Value.(string)
+	if data.Tag != axdr.TagBitString || !ok {
+		return nil, t.invalidData(imageTransferredBlocksStatus, fmt.Errorf("unexpected value %v", data.Value))
+	}
+
+	status := make([]bool, len(bits))
+	for i, b := range bits {
+		status[i] = b == '1'
+	}
+
+	return status, nil
+}
+
+// FirstNotTransferredBlock reads the number of the first block not received.
+func (t *ImageTransfer) FirstNotTransferredBlock() (number uint32, err error) {
+	err = t.get(imageFirstNotTransferredBlock, &number)
+	return
+}
+
+// TransferEnabled reads if image transfer is enabled.
+func (t *ImageTransfer) TransferEnabled() (enabled bool, err error) {
+	err = t.get(imageTransferEnabled, &enabled)
+	return
+}
+
+// Status reads the status of the image transfer process.
+func (t *ImageTransfer) Status() (ImageTransferStatus, error) {
+	var status uint8
+
+	err := t.get(imageTransferStatus, &status)
+	return ImageTransferStatus(status), err
+}
+
+// ImagesToActivate reads the information of the images ready to be activated.
+func (t *ImageTransfer) ImagesToActivate() ([]ImageToActivateInfo, error) {
+	var data axdr.DlmsData
+
+	err := t.get(imageToActivateInfo, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	elements, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return nil, t.invalidData(imageToActivateInfo, err)
+	}
+
+	out := make([]ImageToActivateInfo, 0, len(elements))
+	for _, element := range elements {
+		fields, err := dlms.DataAsSlice(*element, axdr.TagStructure, 3)
+		if err != nil {
+			return nil, t.invalidData(imageToActivateInfo, err)
+		}
+
+		size, ok1 := fields[0].Value.(uint32)
+		identification, ok2 := fields[1].Value.(string)
+		signature, ok3 := fields[2].Value.(string)
+		if !ok1 || !ok2 || !ok3 {
+			return nil, t.invalidData(imageToActivateInfo, fmt.Errorf("invalid image to activate"))
+		}
+
+		info := ImageToActivateInfo{Size: size}
+		info.Identification, _ = hex.DecodeString(identification)
+		info.Signature, _ = hex.DecodeString(signature)
+		out = append(out, info)
+	}
+
+	return out, nil
+}
+
+// Initiate initiates the transfer of an image.
+func (t *ImageTransfer) Initiate(identifier []byte, size uint32) error {
+	return t.action(imageTransferInitiate, axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrOctetString(hex.EncodeToString(identifier)),
+		axdr.CreateAxdrDoubleLongUnsigned(size),
+	}))
+}
+
+// TransferBlock transfers a block of the image.
+func (t *ImageTransfer) TransferBlock(number uint32, block []byte) error {
+	return t.action(imageBlockTransfer, axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrDoubleLongUnsigned(number),
+		axdr.CreateAxdrOctetString(hex.EncodeToString(block)),
+	}))
+}
+
+// Verify verifies the integrity of the transferred image. The meter can reject it with temporary-failure
+// while verifying in background, so Status must be checked.
+func (t *ImageTransfer) Verify() error {
+	return t.action(imageVerify, int8(0))
+}
+
+// Activate activates the transferred image. The meter can reject it with temporary-failure while
+// activating in background, so Status must be checked.
+func (t *ImageTransfer) Activate() error {
+	return t.action(imageActivate, int8(0))
+}
+
+// ImageUpgrade defines the transfer of an image to a meter.
+type ImageUpgrade struct {
+	Identifier []byte
+	Image      []byte
+	// Restart forces a new transfer, instead of resuming the one already initiated in the meter.
+	Restart bool
+	// Resume resumes the transfer initiated in the meter, that the caller knows to be of this image
+	// (e.g. persisted when Initiated was called by an interrupted upgrade).
+	Resume bool
+	// Initiated, if not nil, is called once the transfer of the image is initiated in the meter. If it
+	// returns an error the transfer is interrupted.
+	Initiated func() error
+	// SkipActivation leaves the image verified but not activated.
+	SkipActivation bool
+	// Retries is the number of passes sending the missing blocks. If zero, 3 are done.
+	Retries int
+	// PollInterval is the wait between status checks while verifying or activating. If zero, 5 seconds.
+	PollInterval time.Duration
+	// Timeout is the maximum time waiting for verification or activation. If zero, 5 minutes.
+	Timeout time.Duration
+	// Progress, if not nil, is called after each block with the number of blocks transferred and the total.
+	// If it returns an error the transfer is interrupted, and can be resumed later.
+	Progress func(transferred uint32, total uint32) error
+}
+
+// Upgrade transfers, verifies and activates an image. If the meter already has a transfer initiated,
+// it's resumed from the blocks not received (unless Restart is set), so an upgrade interrupted by a
+// communication error continues with a new association. As the meter only reports the image in
+// image_to_activate_info once verified, the transfer is resumed if Resume is set, or if the meter
+// reports the identifier and size of the image there anyway, as some meters do once initiated; and
+// only if the blocks status fits the size of the image. Otherwise it's initiated again.
+func (t *ImageTransfer) Upgrade(u ImageUpgrade) error {
+	if len(u.Image) == 0 {
+		return dlms.NewError(dlms.ErrorInvalidParameter, "image cannot be empty")
+	}
+
+	enabled, err := t.TransferEnabled()
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return dlms.NewError(dlms.ErrorInvalidState, "image transfer is not enabled")
+	}
+
+	blockSize, err := t.BlockSize()
+	if err != nil {
+		return err
+	}
+	if blockSize == 0 {
+		return t.invalidData(imageBlockSize, fmt.Errorf("block size is zero"))
+	}
+
+	blocks := uint32((len(u.Image) + int(blockSize) - 1) / int(blockSize))
+
+	status, err := t.Status()
+	if err != nil {
+		return err
+	}
+
+	if status == ImageVerificationSuccessful || status == ImageActivationInitiated {
+		// Interrupted after verification, check that it's the same image
+		same, err := t.isImageToActivate(u)
+		if err != nil {
+			return err
+		}
+		if same && !u.Restart {
+			return t.activate(u, status)
+		}
+	}
+
+	var missing []uint32
+	if status == ImageTransferInitiated && !u.Restart {
+		missing, err = t.resumableBlocks(u, blocks)
+		if err != nil {
+			return err
+		}
+	}
+
+	if missing == nil {
+		err = t.Initiate(u.Identifier, uint32(len(u.Image)))
+		if err != nil {
+			return err
+		}
+
+		if u.Initiated != nil {
+			err = u.Initiated()
+			if err != nil {
+				return err
+			}
+		}
+
+		// Sequential transfer, then the missing blocks are sent again
+		missing = make([]uint32, 0, blocks)
+		for n := uint32(0); n < blocks; n++ {
+			missing = append(missing, n)
+		}
+	}
+
+	retries := u.Retries
+	if retries <= 0 {
+		retries = defaultImageTransferRetries
+	}
+
+	for pass := 0; len(missing) > 0; pass++ {
+		if pass > retries {
+			return dlms.NewError(dlms.ErrorInvalidState, fmt.Sprintf("%d blocks not transferred after %d retries", len(missing), retries))
+		}
+
+		for _, n := range missing {
+			end := int(n+1) * int(blockSize)
+			if end > len(u.Image) {
+				end = len(u.Image)
+			}
+
+			err = t.TransferBlock(n, u.Image[int(n)*int(blockSize):end])
+			if err != nil {
+				return err
+			}
+
+			if u.Progress != nil {
+				err = u.Progress(n+1, blocks)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		missing, err = t.missingBlocks(blocks)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = t.wait(u, t.Verify, ImageVerificationInitiated, ImageVerificationSuccessful, ImageVerificationFailed)
+	if err != nil {
+		return err
+	}
+
+	same, err := t.isImageToActivate(u)
+	if err != nil {
+		return err
+	}
+	if !same {
+		return dlms.NewError(dlms.ErrorCheckDoesNotMatch, "verified image doesn't match the transferred one")
+	}
+
+	return t.activate(u, ImageVerificationSuccessful)
+}
+
+func (t *ImageTransfer) activate(u ImageUpgrade, status ImageTransferStatus) error {
+	if u.SkipActivation {
+		return nil
+	}
+
+	activate := t.Activate
+	if status == ImageActivationInitiated {
+		activate = nil
+	}
+
+	return t.wait(u, activate, ImageActivationInitiated, ImageActivationSuccessful, ImageActivationFailed)
+}
+
+// missingBlocks returns the blocks not received by the meter.
+func (t *ImageTransfer) missingBlocks(blocks uint32) ([]uint32, error) {
+	status, err := t.TransferredBlocksStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	missing := make([]uint32, 0)
+	for n := uint32(0); n < blocks; n++ {
+		if int(n) >= len(status) || !status[n] {
+			missing = append(missing, n)
+		}
+	}
+
+	return missing, nil
+}
+
+// resumableBlocks returns the blocks not received of the transfer initiated in the meter, or nil if
+// it's not of the image being upgraded and has to be initiated again.
+func (t *ImageTransfer) resumableBlocks(u ImageUpgrade, blocks uint32) ([]uint32, error) {
+	if !u.Resume {
+		same, err := t.isImageToActivate(u)
+		if err != nil || !same {
+			return nil, err
+		}
+	}
+
+	// The blocks status has a bit for each block of the image initiated, maybe padded to whole bytes
+	status, err := t.TransferredBlocksStatus()
+	if err != nil {
+		return nil, err
+	}
+	if n := uint32(len(status)); n != blocks && n != (blocks+7)/8*8 {
+		return nil, nil
+	}
+
+	first, err := t.FirstNotTransferredBlock()
+	if err != nil {
+		return nil, err
+	}
+	if first > blocks {
+		return nil, nil
+	}
+
+	missing := make([]uint32, 0, blocks-first)
+	for n := first; n < blocks; n++ {
+		if !status[n] {
+			missing = append(missing, n)
+		}
+	}
+
+	return missing, nil
+}
+
+// isImageToActivate returns true if the image ready to be activated is the one being upgraded.
+func (t *ImageTransfer) isImageToActivate(u ImageUpgrade) (bool, error) {
+	infos, err := t.ImagesToActivate()
+	if err != nil {
+		return false, err
+	}
+
+	for _, info := range infos {
+		if bytes.Equal(info.Identification, u.Identifier) && info.Size == uint32(len(u.Image)) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// wait invokes a method and waits until the status is the successful one. A method rejected with
+// temporary-failure, as the process is running in background, is not an error.
+func (t *ImageTransfer) wait(u ImageUpgrade, method func() error, running ImageTransferStatus, success ImageTransferStatus, failure ImageTransferStatus) error {
+	interval := u.PollInterval
+	if interval <= 0 {
+		interval = defaultImageTransferPollInterval
+	}
+
+	timeout := u.Timeout
+	if timeout <= 0 {
+		timeout = defaultImageTransferTimeout
+	}
+
+	var methodErr error
+	if method != nil {
+		methodErr = method()
+		if methodErr != nil && !isTemporaryFailure(methodErr) {
+			return methodErr
+		}
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		status, err := t.Status()
+		if err != nil {
+			return err
+		}
+
+		switch status {
+		case success:
+			return nil
+		case failure:
+			return dlms.NewError(dlms.ErrorActionRejected, status.String())
+		case running:
+		default:
+			if methodErr != nil {
+				return methodErr
+			}
+			return dlms.NewError(dlms.ErrorInvalidState, fmt.Sprintf("unexpected image transfer status %d", status))
+		}
+
+		if time.Now().After(deadline) {
+			return dlms.NewError(dlms.ErrorInvalidState, fmt.Sprintf("timeout waiting for %s", success.String()))
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+func isTemporaryFailure(err error) bool {
+	var dlmsError *dlms.Error
+	if !errors.As(err, &dlmsError) {
+		return false
+	}
+
+	result, ok := dlmsError.ActionResult()
+	return ok && result == dlms.TagActTemporaryFailure
+}
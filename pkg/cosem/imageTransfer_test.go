@@ -0,0 +1,334 @@
+package cosem_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// meterImage simulates the image transfer of a meter. Blocks in drop are lost the first time they're
+// received, and verification and activation run in background for the given number of status reads.
+// With infoOnInitiate, the image is reported in image_to_activate_info as soon as it's initiated. With
+// verifyResult, verification is rejected with it and never ends.
+type meterImage struct {
+	blockSize      uint32
+	infoOnInitiate bool
+	verifyResult   dlms.ActionResultTag
+	identifier     string
+	size           uint32
+	image          []byte
+	received       []bool
+	status         cosem.ImageTransferStatus
+	drop           map[uint32]bool
+	pending        int
+	background     int
+	transfers      int
+	initiations    int
+	activated      []byte
+	mutex          sync.Mutex
+}
+
+func (m *meterImage) object() *dlmsserver.Object {
+	get := func(f func() axdr.DlmsData) dlmsserver.GetHandler {
+		return func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			m.mutex.Lock()
+			defer m.mutex.Unlock()
+
+			return f(), dlms.TagAccSuccess
+		}
+	}
+
+	return dlmsserver.NewObject(cosem.ImageTransferClassID, 0, cosem.ImageTransferLogicalName).
+		SetValue(2, *axdr.CreateAxdrDoubleLongUnsigned(m.blockSize), false).
+		SetAttribute(3, get(func() axdr.DlmsData {
+			bits := ""
+			for _, r := range m.received {
+				if r {
+					bits += "1"
+				} else {
+					bits += "0"
+				}
+			}
+			return *axdr.CreateAxdrBitString(bits)
+		}), nil).
+		SetAttribute(4, get(func() axdr.DlmsData {
+			for i, r := range m.received {
+				if !r {
+					return *axdr.CreateAxdrDoubleLongUnsigned(uint32(i))
+				}
+			}
+			return *axdr.CreateAxdrDoubleLongUnsigned(uint32(len(m.received)))
+		}), nil).
+		SetValue(5, *axdr.CreateAxdrBoolean(true), false).
+		SetAttribute(6, get(func() axdr.DlmsData {
+			if m.pending > 0 {
+				m.pending--
+				if m.pending == 0 {
+					m.status++
+				}
+			}
+			return *axdr.CreateAxdrEnum(uint8(m.status))
+		}), nil).
+		SetAttribute(7, get(func() axdr.DlmsData {
+			infos := make([]*axdr.DlmsData, 0)
+			if m.status >= cosem.ImageVerificationSuccessful || m.infoOnInitiate && m.status >= cosem.ImageTransferInitiated {
+				infos = append(infos, axdr.CreateAxdrStructure([]*axdr.DlmsData{
+					axdr.CreateAxdrDoubleLongUnsigned(m.size),
+					axdr.CreateAxdrOctetString(m.identifier),
+					axdr.CreateAxdrOctetString("00"),
+				}))
+			}
+			return *axdr.CreateAxdrArray(infos)
+		}), nil).
+		SetMethod(1, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			m.mutex.Lock()
+			defer m.mutex.Unlock()
+
+			params := data.Value.([]*axdr.DlmsData)
+			m.identifier = params[0].Value.(string)
+			m.size = params[1].Value.(uint32)
+			m.image = make([]byte, m.size)
+			m.received = make([]bool, (m.size+m.blockSize-1)/m.blockSize)
+			m.status = cosem.ImageTransferInitiated
+			m.initiations++
+			return nil, dlms.TagActSuccess
+		}).
+		SetMethod(2, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			m.mutex.Lock()
+			defer m.mutex.Unlock()
+
+			params := data.Value.([]*axdr.DlmsData)
+			n := params[0].Value.(uint32)
+			block, _ := hex.DecodeString(params[1].Value.(string))
+
+			m.transfers++
+			if m.drop[n] {
+				delete(m.drop, n)
+				return nil, dlms.TagActSuccess
+			}
+
+			copy(m.image[n*m.blockSize:], block)
+			m.received[n] = true
+			return nil, dlms.TagActSuccess
+		}).
+		SetMethod(3, func(dlms.MethodDescriptor, *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			m.mutex.Lock()
+			defer m.mutex.Unlock()
+
+			m.status = cosem.ImageVerificationInitiated
+			if m.verifyResult != dlms.TagActSuccess {
+				return nil, m.verifyResult
+			}
+
+			m.pending = m.background
+			if m.pending == 0 {
+				m.status = cosem.ImageVerificationSuccessful
+				return nil, dlms.TagActSuccess
+			}
+			return nil, dlms.TagActTemporaryFailure
+		}).
+		SetMethod(4, func(dlms.MethodDescriptor, *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			m.mutex.Lock()
+			defer m.mutex.Unlock()
+
+			m.activated = m.image
+			m.status = cosem.ImageActivationInitiated
+			m.pending = m.background
+			if m.pending == 0 {
+				m.status = cosem.ImageActivationSuccessful
+				return nil, dlms.TagActSuccess
+			}
+			return nil, dlms.TagActTemporaryFailure
+		})
+}
+
+func newImage(size int) []byte {
+	image := make([]byte, size)
+	for i := range image {
+		image[i] = byte(i)
+	}
+
+	return image
+}
+
+func TestImageTransfer_Upgrade(t *testing.T) {
+	m := &meterImage{blockSize: 16, drop: map[uint32]bool{2: true, 5: true}, background: 2}
+	it := cosem.NewImageTransfer(connect(t, m.object()), cosem.ImageTransferLogicalName)
+
+	image := newImage(100)
+	progress := 0
+
+	err := it.Upgrade(cosem.ImageUpgrade{
+		Identifier:   []byte("FW-1.2.3"),
+		Image:        image,
+		PollInterval: time.Millisecond,
+		Progress: func(transferred uint32, total uint32) error {
+			progress++
+			assert.Equal(t, uint32(7), total)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, m.initiations)
+	assert.Equal(t, 9, m.transfers)
+	assert.Equal(t, 9, progress)
+	assert.True(t, bytes.Equal(image, m.activated))
+	assert.Equal(t, cosem.ImageActivationSuccessful, m.status)
+}
+
+func TestImageTransfer_Resume(t *testing.T) {
+	m := &meterImage{blockSize: 16, infoOnInitiate: true}
+	image := newImage(100)
+	errInterrupted := errors.New("interrupted")
+
+	it := cosem.NewImageTransfer(connect(t, m.object()), cosem.ImageTransferLogicalName)
+	err := it.Upgrade(cosem.ImageUpgrade{
+		Identifier: []byte("FW-1.2.3"),
+		Image:      image,
+		Progress: func(transferred uint32, _ uint32) error {
+			if transferred == 3 {
+				return errInterrupted
+			}
+			return nil
+		},
+	})
+	assert.ErrorIs(t, err, errInterrupted)
+	assert.Equal(t, 3, m.transfers)
+
+	// A new association resumes the transfer
+	it = cosem.NewImageTransfer(connect(t, m.object()), cosem.ImageTransferLogicalName)
+	err = it.Upgrade(cosem.ImageUpgrade{Identifier: []byte("FW-1.2.3"), Image: image, SkipActivation: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, m.initiations)
+	assert.Equal(t, 7, m.transfers)
+	assert.Equal(t, cosem.ImageVerificationSuccessful, m.status)
+	assert.Nil(t, m.activated)
+
+	// Only activation is pending
+	err = it.Upgrade(cosem.ImageUpgrade{Identifier: []byte("FW-1.2.3"), Image: image})
+	require.NoError(t, err)
+	assert.Equal(t, 7, m.transfers)
+	assert.True(t, bytes.Equal(image, m.activated))
+}
+
+func TestImageTransfer_ResumeWithoutInfo(t *testing.T) {
+	// The meter only reports the image in image_to_activate_info once verified
+	m := &meterImage{blockSize: 16}
+	image := newImage(100)
+	errInterrupted := errors.New("interrupted")
+
+	initiated := false
+	it := cosem.NewImageTransfer(connect(t, m.object()), cosem.ImageTransferLogicalName)
+	err := it.Upgrade(cosem.ImageUpgrade{
+		Identifier: []byte("FW-1.2.3"),
+		Image:      image,
+		Initiated: func() error {
+			initiated = true
+			return nil
+		},
+		Progress: func(transferred uint32, _ uint32) error {
+			if transferred == 3 {
+				return errInterrupted
+			}
+			return nil
+		},
+	})
+	assert.ErrorIs(t, err, errInterrupted)
+	assert.True(t, initiated)
+
+	// A new association resumes the transfer known to be initiated
+	it = cosem.NewImageTransfer(connect(t, m.object()), cosem.ImageTransferLogicalName)
+	err = it.Upgrade(cosem.ImageUpgrade{Identifier: []byte("FW-1.2.3"), Image: image, Resume: initiated})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, m.initiations)
+	assert.Equal(t, 7, m.transfers)
+	assert.True(t, bytes.Equal(image, m.activated))
+}
+
+func TestImageTransfer_ResumeOtherSize(t *testing.T) {
+	m := &meterImage{blockSize: 16}
+	it := cosem.NewImageTransfer(connect(t, m.object()), cosem.ImageTransferLogicalName)
+
+	err := it.Upgrade(cosem.ImageUpgrade{Identifier: []byte("FW-1.2.3"), Image: newImage(100), SkipActivation: true})
+	require.NoError(t, err)
+
+	err = it.Initiate([]byte("FW-1.2.3"), 90)
+	require.NoError(t, err)
+
+	// The blocks status doesn't fit the image, so it's initiated again even if resuming
+	image := newImage(100)
+	err = it.Upgrade(cosem.ImageUpgrade{Identifier: []byte("FW-1.2.3"), Image: image, Resume: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, m.initiations)
+	assert.Equal(t, 7+7, m.transfers)
+	assert.True(t, bytes.Equal(image, m.activated))
+}
+
+func TestImageTransfer_VerifyRejected(t *testing.T) {
+	m := &meterImage{blockSize: 16, verifyResult: dlms.TagActReadWriteDenied}
+	it := cosem.NewImageTransfer(connect(t, m.object()), cosem.ImageTransferLogicalName)
+
+	// Only temporary failures are waited for, while the status is running
+	err := it.Upgrade(cosem.ImageUpgrade{Identifier: []byte("FW"), Image: newImage(10), PollInterval: time.Millisecond, Timeout: time.Minute})
+	var dlmsError *dlms.Error
+	require.ErrorAs(t, err, &dlmsError)
+	result, ok := dlmsError.ActionResult()
+	assert.True(t, ok)
+	assert.Equal(t, dlms.TagActReadWriteDenied, result)
+}
+
+func TestImageTransfer_ResumeOtherImage(t *testing.T) {
+	errInterrupted := errors.New("interrupted")
+	interrupt := func(transferred uint32, _ uint32) error {
+		if transferred == 3 {
+			return errInterrupted
+		}
+		return nil
+	}
+
+	for _, infoOnInitiate := range []bool{true, false} {
+		m := &meterImage{blockSize: 16, infoOnInitiate: infoOnInitiate}
+		it := cosem.NewImageTransfer(connect(t, m.object()), cosem.ImageTransferLogicalName)
+
+		err := it.Upgrade(cosem.ImageUpgrade{Identifier: []byte("FW-1.2.3"), Image: newImage(100), Progress: interrupt})
+		assert.ErrorIs(t, err, errInterrupted)
+
+		// The transfer of another image is initiated again, whether the meter reports it or not
+		image := newImage(90)
+		err = it.Upgrade(cosem.ImageUpgrade{Identifier: []byte("FW-2.0.0"), Image: image})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, m.initiations)
+		assert.Equal(t, 3+6, m.transfers)
+		assert.True(t, bytes.Equal(image, m.activated))
+	}
+}
+
+func TestImageTransfer_MissingBlocks(t *testing.T) {
+	m := &meterImage{blockSize: 16, drop: map[uint32]bool{0: true}}
+	it := cosem.NewImageTransfer(connect(t, m.object()), cosem.ImageTransferLogicalName)
+
+	// Blocks never received
+	err := it.Upgrade(cosem.ImageUpgrade{Identifier: []byte("FW"), Image: newImage(10), Retries: 1, Progress: func(uint32, uint32) error {
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+
+		m.drop[0] = true
+		return nil
+	}})
+	assertErrorCode(t, err, dlms.ErrorInvalidState)
+}
@@ -0,0 +1,315 @@
+package cosem
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const IPv4SetupClassID = 42
+
+const (
+	ipv4DLReference            = 2
+	ipv4Address                = 3
+	ipv4MulticastAddresses     = 4
+	ipv4Options                = 5
+	ipv4SubnetMask             = 6
+	ipv4Gateway                = 7
+	ipv4UseDHCP                = 8
+	ipv4PrimaryDNS             = 9
+	ipv4SecondaryDNS           = 10
+	ipv4AddMulticastAddress    = 1
+	ipv4DeleteMulticastAddress = 2
+)
+
+// IPOption is an option of the IPv4 header.
+type IPOption struct {
+	Type uint8
+	Data []byte
+}
+
+// IPv4SetupValue holds the attributes of an IPv4 setup. DLReference is the logical name of the data link
+// setup used. Address, SubnetMask and Gateway are ignored by the meter if DHCP is used.
+type IPv4SetupValue struct {
+	DLReference        dlms.Obis
+	Address            net.IP
+	MulticastAddresses []net.IP
+	Options            []IPOption
+	SubnetMask         net.IP
+	Gateway            net.IP
+	UseDHCP            bool
+	PrimaryDNS         net.IP
+	SecondaryDNS       net.IP
+}
+
+// Validate checks that the addresses are IPv4 and the subnet mask is valid. Nil addresses are sent as 0.0.0.0.
+func (v IPv4SetupValue) Validate() error {
+	names := []string{"address", "subnet mask", "gateway", "primary DNS", "secondary DNS"}
+	for i, ip := range []net.IP{v.Address, v.SubnetMask, v.Gateway, v.PrimaryDNS, v.SecondaryDNS} {
+		if _, err := ipv4ToUint32(ip); err != nil {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("invalid %s: %v", names[i], err))
+		}
+	}
+
+	for _, ip := range v.MulticastAddresses {
+		if ip.To4() == nil || !ip.IsMulticast() {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("invalid multicast address %s", ip.String()))
+		}
+	}
+
+	if ones, bits := net.IPMask(v.SubnetMask.To4()).Size(); v.SubnetMask != nil && ones == 0 && bits == 0 {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("invalid subnet mask %s", v.SubnetMask.String()))
+	}
+
+	for _, o := range v.Options {
+		if len(o.Data) > 0xFF-2 {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("IP option %d too long", o.Type))
+		}
+	}
+
+	return nil
+}
+
+// IPv4Setup is an instance of the IPv4 setup interface class (class_id 42).
+type IPv4Setup struct {
+	object
+}
+
+func NewIPv4Setup(client dlms.Client, logicalName string) *IPv4Setup {
+	return &IPv4Setup{object{client: client, classID: IPv4SetupClassID, logicalName: logicalName}}
+}
+
+// Read reads all the attributes of the IPv4 setup.
+func (s *IPv4Setup) Read() (v IPv4SetupValue, err error) {
+	var data axdr.DlmsData
+	if err = s.get(ipv4DLReference, &data); err != nil {
+		return
+	}
+	if v.DLReference, err = dlms.DecodeLogicalName(data); err != nil {
+		return v, s.invalidData(ipv4DLReference, err)
+	}
+
+	if v.Address, err = s.address(ipv4Address); err != nil {
+		return
+	}
+	if v.MulticastAddresses, err = s.MulticastAddresses(); err != nil {
+		return
+	}
+	if v.Options, err = s.options(); err != nil {
+		return
+	}
+	if v.SubnetMask, err = s.address(ipv4SubnetMask); err != nil {
+		return
+	}
+	if v.Gateway, err = s.address(ipv4Gateway); err != nil {
+		return
+	}
+	if err = s.get(ipv4UseDHCP, &v.UseDHCP); err != nil {
+		return
+	}
+	if v.PrimaryDNS, err = s.address(ipv4PrimaryDNS); err != nil {
+		return
+	}
+
+	v.SecondaryDNS, err = s.address(ipv4SecondaryDNS)
+	return
+}
+
+// Write validates and writes the attributes of the IPv4 setup. Multicast addresses not in the list are
+// deleted and the missing ones added.
+func (s *IPv4Setup) Write(v IPv4SetupValue) error {
+	err := v.Validate()
+	if err != nil {
+		return err
+	}
+
+	options := make([]*axdr.DlmsData, len(v.Options))
+	for i, o := range v.Options {
+		options[i] = axdr.CreateAxdrStructure([]*axdr.DlmsData{
+			axdr.CreateAxdrUnsigned(o.Type),
+			axdr.CreateAxdrUnsigned(uint8(len(o.Data) + 2)),
+			createOctetString(o.Data),
+		})
+	}
+
+	if err = s.set(ipv4DLReference, axdr.CreateAxdrOctetString(v.DLReference.String())); err != nil {
+		return err
+	}
+	if err = s.set(ipv4UseDHCP, v.UseDHCP); err != nil {
+		return err
+	}
+	if err = s.setAddress(ipv4Address, v.Address); err != nil {
+		return err
+	}
+	if err = s.set(ipv4Options, axdr.CreateAxdrArray(options)); err != nil {
+		return err
+	}
+	if err = s.setAddress(ipv4SubnetMask, v.SubnetMask); err != nil {
+		return err
+	}
+	if err = s.setAddress(ipv4Gateway, v.Gateway); err != nil {
+		return err
+	}
+	if err = s.setAddress(ipv4PrimaryDNS, v.PrimaryDNS); err != nil {
+		return err
+	}
+
+	if err = s.setAddress(ipv4SecondaryDNS, v.SecondaryDNS); err != nil {
+		return err
+	}
+
+	return s.syncMulticastAddresses(v.MulticastAddresses)
+}
+
+func (s *IPv4Setup) syncMulticastAddresses(addresses []net.IP) error {
+	current, err := s.MulticastAddresses()
+	if err != nil {
+		return err
+	}
+
+	for _, ip := range current {
+		if !containsIP(addresses, ip) {
+			if err = s.DeleteMulticastAddress(ip); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, ip := range addresses {
+		if !containsIP(current, ip) {
+			if err = s.AddMulticastAddress(ip); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// MulticastAddresses reads the multicast addresses the meter listens to.
+func (s *IPv4Setup) MulticastAddresses() ([]net.IP, error) {
+	var addresses []uint32
+
+	err := s.get(ipv4MulticastAddresses, &addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]net.IP, len(addresses))
+	for i, a := range addresses {
+		out[i] = uint32ToIPv4(a)
+	}
+
+	return out, nil
+}
+
+// AddMulticastAddress adds a multicast address to the list.
+func (s *IPv4Setup) AddMulticastAddress(ip net.IP) error {
+	if ip.To4() == nil || !ip.IsMulticast() {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("invalid multicast address %s", ip.String()))
+	}
+
+	address, _ := ipv4ToUint32(ip)
+	return s.action(ipv4AddMulticastAddress, address)
+}
+
+// DeleteMulticastAddress deletes a multicast address from the list.
+func (s *IPv4Setup) DeleteMulticastAddress(ip net.IP) error {
+	address, err := ipv4ToUint32(ip)
+	if err != nil {
+		return dlms.NewError(dlms.ErrorInvalidParameter, err.Error())
+	}
+
+	return s.action(ipv4DeleteMulticastAddress, address)
+}
+
+func (s *IPv4Setup) address(attributeID int8) (net.IP, error) {
+	var address uint32
+
+	err := s.get(attributeID, &address)
+	if err != nil {
+		return nil, err
+	}
+
+	return uint32ToIPv4(address), nil
+}
+
+func (s *IPv4Setup) setAddress(attributeID int8, ip net.IP) error {
+	address, err := ipv4ToUint32(ip)
+	if err != nil {
+		return dlms.NewError(dlms.ErrorInvalidParameter, err.Error())
+	}
+
+	return s.set(attributeID, address)
+}
+
+func (s *IPv4Setup) options() ([]IPOption, error) {
+	var data axdr.DlmsData
+
+	err := s.get(ipv4Options, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	elements, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return nil, s.invalidData(ipv4Options, err)
+	}
+
+	options := make([]IPOption, len(elements))
+	for i, e := range elements {
+		fields, err := dlms.DataAsSlice(*e, axdr.TagStructure, 3)
+		if err != nil {
+			return nil, s.invalidData(ipv4Options, err)
+		}
+
+		optionType, ok := fields[0].Value.(uint8)
+		if !ok {
+			return nil, s.invalidData(ipv4Options, fmt.Errorf("invalid option type %v", fields[0].Value))
+		}
+
+		optionData, err := octetString(*fields[2], 0)
+		if err != nil {
+			return nil, s.invalidData(ipv4Options, err)
+		}
+
+		options[i] = IPOption{Type: optionType, Data: optionData}
+	}
+
+	return options, nil
+}
+
+func containsIP(list []net.IP, ip net.IP) bool {
+	for _, e := range list {
+		if e.Equal(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ipv4ToUint32 converts an IPv4 address to the double-long-unsigned used by the meter. A nil address
+// is taken as 0.0.0.0.
+func ipv4ToUint32(ip net.IP) (uint32, error) {
+	if ip == nil {
+		return 0, nil
+	}
+
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return 0, fmt.Errorf("%s is not an IPv4 address", ip.String())
+	}
+
+	return binary.BigEndian.Uint32(ip4), nil
+}
+
+func uint32ToIPv4(address uint32) net.IP {
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, address)
+
+	return ip
+}
@@ -0,0 +1,81 @@
+package cosem_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPv4Setup(t *testing.T) {
+	multicast := []uint32{0xE0000001}
+
+	o := dlmsserver.NewObject(cosem.IPv4SetupClassID, 0, "0.0.25.1.0.255").
+		SetValue(2, *axdr.CreateAxdrOctetString("0.0.25.4.0.255"), true).
+		SetValue(3, *axdr.CreateAxdrDoubleLongUnsigned(0xC0A80164), true).
+		SetAttribute(4, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			list := make([]*axdr.DlmsData, len(multicast))
+			for i, m := range multicast {
+				list[i] = axdr.CreateAxdrDoubleLongUnsigned(m)
+			}
+			return *axdr.CreateAxdrArray(list), dlms.TagAccSuccess
+		}, nil).
+		SetValue(5, *axdr.CreateAxdrArray([]*axdr.DlmsData{}), true).
+		SetValue(6, *axdr.CreateAxdrDoubleLongUnsigned(0xFFFFFF00), true).
+		SetValue(7, *axdr.CreateAxdrDoubleLongUnsigned(0xC0A80101), true).
+		SetValue(8, *axdr.CreateAxdrBoolean(false), true).
+		SetValue(9, *axdr.CreateAxdrDoubleLongUnsigned(0x08080808), true).
+		SetValue(10, *axdr.CreateAxdrDoubleLongUnsigned(0), true).
+		SetMethod(1, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			multicast = append(multicast, data.Value.(uint32))
+			return nil, dlms.TagActSuccess
+		}).
+		SetMethod(2, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			for i, m := range multicast {
+				if m == data.Value.(uint32) {
+					multicast = append(multicast[:i], multicast[i+1:]...)
+					return nil, dlms.TagActSuccess
+				}
+			}
+			return nil, dlms.TagActObjectUndefined
+		})
+
+	s := cosem.NewIPv4Setup(connect(t, o), "0.0.25.1.0.255")
+
+	v, err := s.Read()
+	require.NoError(t, err)
+	assert.Equal(t, cosem.IPv4SetupValue{
+		DLReference:        *dlms.CreateObis("0.0.25.4.0.255"),
+		Address:            net.IPv4(192, 168, 1, 100).To4(),
+		MulticastAddresses: []net.IP{net.IPv4(224, 0, 0, 1).To4()},
+		Options:            []cosem.IPOption{},
+		SubnetMask:         net.IPv4(255, 255, 255, 0).To4(),
+		Gateway:            net.IPv4(192, 168, 1, 1).To4(),
+		PrimaryDNS:         net.IPv4(8, 8, 8, 8).To4(),
+		SecondaryDNS:       net.IPv4zero.To4(),
+	}, v)
+
+	// Write applies the addresses and replaces the multicast list
+	v.Address = net.ParseIP("10.0.0.2")
+	v.SecondaryDNS = nil
+	v.MulticastAddresses = []net.IP{net.ParseIP("239.1.1.1")}
+	require.NoError(t, s.Write(v))
+	assert.Equal(t, []uint32{0xEF010101}, multicast)
+
+	read, err := s.Read()
+	require.NoError(t, err)
+	assert.True(t, v.Address.Equal(read.Address))
+	assert.True(t, net.IPv4zero.Equal(read.SecondaryDNS))
+
+	require.NoError(t, s.DeleteMulticastAddress(net.ParseIP("239.1.1.1")))
+	assert.Empty(t, multicast)
+
+	v.Gateway = net.ParseIP("2001:db8::1")
+	assertErrorCode(t, s.Write(v), dlms.ErrorInvalidParameter)
+	assertErrorCode(t, s.AddMulticastAddress(net.ParseIP("10.0.0.1")), dlms.ErrorInvalidParameter)
+}
@@ -0,0 +1,323 @@
+package cosem
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const IPv6SetupClassID = 48
+
+const (
+	ipv6DLReference       = 2
+	ipv6AddressConfigMode = 3
+	ipv6UnicastAddresses  = 4
+	ipv6MulticastAddress  = 5
+	ipv6GatewayAddresses  = 6
+	ipv6PrimaryDNS        = 7
+	ipv6SecondaryDNS      = 8
+	ipv6TrafficClass      = 9
+	ipv6AddAddress        = 1
+	ipv6RemoveAddress     = 2
+)
+
+// AddressConfigMode is the way the IPv6 addresses are configured.
+type AddressConfigMode uint8
+
+const (
+	AddressConfigAuto              AddressConfigMode = 0
+	AddressConfigDHCPv6            AddressConfigMode = 1
+	AddressConfigManual            AddressConfigMode = 2
+	AddressConfigNeighbourDiscover AddressConfigMode = 3
+)
+
+func (m AddressConfigMode) String() string {
+	switch m {
+	case AddressConfigAuto:
+		return "auto"
+	case AddressConfigDHCPv6:
+		return "DHCPv6"
+	case AddressConfigManual:
+		return "manual"
+	case AddressConfigNeighbourDiscover:
+		return "neighbour discovery"
+	default:
+		return fmt.Sprintf("unknown (%d)", uint8(m))
+	}
+}
+
+// IPv6AddressType selects the address list changed by AddAddress and RemoveAddress.
+type IPv6AddressType uint8
+
+const (
+	IPv6Unicast   IPv6AddressType = 0
+	IPv6Multicast IPv6AddressType = 1
+	IPv6Gateway   IPv6AddressType = 2
+)
+
+func (t IPv6AddressType) String() string {
+	switch t {
+	case IPv6Unicast:
+		return "unicast"
+	case IPv6Multicast:
+		return "multicast"
+	case IPv6Gateway:
+		return "gateway"
+	default:
+		return fmt.Sprintf("unknown (%d)", uint8(t))
+	}
+}
+
+// IPv6SetupValue holds the attributes of an IPv6 setup. DLReference is the logical name of the data link
+// setup used. Nil DNS addresses are sent as empty octet strings.
+type IPv6SetupValue struct {
+	DLReference        dlms.Obis
+	AddressConfigMode  AddressConfigMode
+	UnicastAddresses   []net.IP
+	MulticastAddresses []net.IP
+	GatewayAddresses   []net.IP
+	PrimaryDNS         net.IP
+	SecondaryDNS       net.IP
+	TrafficClass       uint8
+}
+
+// Validate checks that the addresses are IPv6 and belong to the list they are in.
+func (v IPv6SetupValue) Validate() error {
+	if v.AddressConfigMode > AddressConfigNeighbourDiscover {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("invalid address config mode %d", v.AddressConfigMode))
+	}
+
+	lists := []struct {
+		addressType IPv6AddressType
+		addresses   []net.IP
+	}{
+		{IPv6Unicast, v.UnicastAddresses},
+		{IPv6Multicast, v.MulticastAddresses},
+		{IPv6Gateway, v.GatewayAddresses},
+	}
+	for _, l := range lists {
+		for _, ip := range l.addresses {
+			if err := validateIPv6Address(l.addressType, ip); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, ip := range []net.IP{v.PrimaryDNS, v.SecondaryDNS} {
+		if ip != nil && (len(ip) != net.IPv6len || ip.To4() != nil) {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("invalid DNS address %s", ip.String()))
+		}
+	}
+
+	return nil
+}
+
+// IPv6Setup is an instance of the IPv6 setup interface class (class_id 48).
+type IPv6Setup struct {
+	object
+}
+
+func NewIPv6Setup(client dlms.Client, logicalName string) *IPv6Setup {
+	return &IPv6Setup{object{client: client, classID: IPv6SetupClassID, logicalName: logicalName}}
+}
+
+// Read reads all the attributes of the IPv6 setup.
+func (s *IPv6Setup) Read() (v IPv6SetupValue, err error) {
+	var data axdr.DlmsData
+	if err = s.get(ipv6DLReference, &data); err != nil {
+		return
+	}
+	if v.DLReference, err = dlms.DecodeLogicalName(data); err != nil {
+		return v, s.invalidData(ipv6DLReference, err)
+	}
+
+	var mode uint8
+	if err = s.get(ipv6AddressConfigMode, &mode); err != nil {
+		return
+	}
+	v.AddressConfigMode = AddressConfigMode(mode)
+
+	if v.UnicastAddresses, err = s.Addresses(IPv6Unicast); err != nil {
+		return
+	}
+	if v.MulticastAddresses, err = s.Addresses(IPv6Multicast); err != nil {
+		return
+	}
+	if v.GatewayAddresses, err = s.Addresses(IPv6Gateway); err != nil {
+		return
+	}
+	if v.PrimaryDNS, err = s.dns(ipv6PrimaryDNS); err != nil {
+		return
+	}
+	if v.SecondaryDNS, err = s.dns(ipv6SecondaryDNS); err != nil {
+		return
+	}
+
+	err = s.get(ipv6TrafficClass, &v.TrafficClass)
+	return
+}
+
+// Write validates and writes the attributes of the IPv6 setup. Addresses not in the lists are removed
+// and the missing ones added.
+func (s *IPv6Setup) Write(v IPv6SetupValue) error {
+	err := v.Validate()
+	if err != nil {
+		return err
+	}
+
+	if err = s.set(ipv6DLReference, axdr.CreateAxdrOctetString(v.DLReference.String())); err != nil {
+		return err
+	}
+	if err = s.set(ipv6AddressConfigMode, axdr.CreateAxdrEnum(uint8(v.AddressConfigMode))); err != nil {
+		return err
+	}
+	if err = s.set(ipv6PrimaryDNS, createOctetString(v.PrimaryDNS)); err != nil {
+		return err
+	}
+	if err = s.set(ipv6SecondaryDNS, createOctetString(v.SecondaryDNS)); err != nil {
+		return err
+	}
+	if err = s.set(ipv6TrafficClass, v.TrafficClass); err != nil {
+		return err
+	}
+
+	if err = s.syncAddresses(IPv6Unicast, v.UnicastAddresses); err != nil {
+		return err
+	}
+	if err = s.syncAddresses(IPv6Multicast, v.MulticastAddresses); err != nil {
+		return err
+	}
+
+	return s.syncAddresses(IPv6Gateway, v.GatewayAddresses)
+}
+
+// Addresses reads one of the address lists.
+func (s *IPv6Setup) Addresses(addressType IPv6AddressType) ([]net.IP, error) {
+	attributeID, err := ipv6AddressAttribute(addressType)
+	if err != nil {
+		return nil, err
+	}
+
+	var data axdr.DlmsData
+	if err = s.get(attributeID, &data); err != nil {
+		return nil, err
+	}
+
+	elements, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return nil, s.invalidData(attributeID, err)
+	}
+
+	addresses := make([]net.IP, len(elements))
+	for i, e := range elements {
+		address, err := octetString(*e, net.IPv6len)
+		if err != nil {
+			return nil, s.invalidData(attributeID, err)
+		}
+		addresses[i] = address
+	}
+
+	return addresses, nil
+}
+
+// AddAddress adds an address to the list of the given type (add_IPv6_address).
+func (s *IPv6Setup) AddAddress(addressType IPv6AddressType, ip net.IP) error {
+	err := validateIPv6Address(addressType, ip)
+	if err != nil {
+		return err
+	}
+
+	return s.action(ipv6AddAddress, ipv6AddressData(addressType, ip))
+}
+
+// RemoveAddress removes an address from the list of the given type (remove_IPv6_address).
+func (s *IPv6Setup) RemoveAddress(addressType IPv6AddressType, ip net.IP) error {
+	err := validateIPv6Address(addressType, ip)
+	if err != nil {
+		return err
+	}
+
+	return s.action(ipv6RemoveAddress, ipv6AddressData(addressType, ip))
+}
+
+func (s *IPv6Setup) syncAddresses(addressType IPv6AddressType, addresses []net.IP) error {
+	current, err := s.Addresses(addressType)
+	if err != nil {
+		return err
+	}
+
+	for _, ip := range current {
+		if !containsIP(addresses, ip) {
+			if err = s.RemoveAddress(addressType, ip); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, ip := range addresses {
+		if !containsIP(current, ip) {
+			if err = s.AddAddress(addressType, ip); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *IPv6Setup) dns(attributeID int8) (net.IP, error) {
+	var data axdr.DlmsData
+
+	err := s.get(attributeID, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := octetString(data, 0)
+	if err != nil {
+		return nil, s.invalidData(attributeID, err)
+	}
+
+	switch len(address) {
+	case 0:
+		return nil, nil
+	case net.IPv6len:
+		return address, nil
+	default:
+		return nil, s.invalidData(attributeID, fmt.Errorf("invalid address length %d", len(address)))
+	}
+}
+
+func ipv6AddressAttribute(addressType IPv6AddressType) (int8, error) {
+	switch addressType {
+	case IPv6Unicast:
+		return ipv6UnicastAddresses, nil
+	case IPv6Multicast:
+		return ipv6MulticastAddress, nil
+	case IPv6Gateway:
+		return ipv6GatewayAddresses, nil
+	default:
+		return 0, dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("invalid address type %d", addressType))
+	}
+}
+
+func validateIPv6Address(addressType IPv6AddressType, ip net.IP) error {
+	if _, err := ipv6AddressAttribute(addressType); err != nil {
+		return err
+	}
+
+	if len(ip) != net.IPv6len || ip.To4() != nil || ip.IsMulticast() != (addressType == IPv6Multicast) {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("invalid %s address %s", addressType, ip.String()))
+	}
+
+	return nil
+}
+
+func ipv6AddressData(addressType IPv6AddressType, ip net.IP) *axdr.DlmsData {
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrEnum(uint8(addressType)),
+		createOctetString(ip),
+	})
+}
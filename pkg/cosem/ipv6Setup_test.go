@@ -0,0 +1,92 @@
+package cosem_test
+
+import (
+	"encoding/hex"
+	"net"
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPv6Setup(t *testing.T) {
+	addresses := map[uint8][]string{
+		uint8(cosem.IPv6Unicast):   {"20010db8000000000000000000000010"},
+		uint8(cosem.IPv6Multicast): {},
+		uint8(cosem.IPv6Gateway):   {"20010db8000000000000000000000001"},
+	}
+	list := func(addressType uint8) dlmsserver.GetHandler {
+		return func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			list := make([]*axdr.DlmsData, len(addresses[addressType]))
+			for i, a := range addresses[addressType] {
+				list[i] = axdr.CreateAxdrOctetString(a)
+			}
+			return *axdr.CreateAxdrArray(list), dlms.TagAccSuccess
+		}
+	}
+
+	o := dlmsserver.NewObject(cosem.IPv6SetupClassID, 0, "0.0.25.7.0.255").
+		SetValue(2, *axdr.CreateAxdrOctetString("0.0.25.4.0.255"), true).
+		SetValue(3, *axdr.CreateAxdrEnum(uint8(cosem.AddressConfigManual)), true).
+		SetAttribute(4, list(uint8(cosem.IPv6Unicast)), nil).
+		SetAttribute(5, list(uint8(cosem.IPv6Multicast)), nil).
+		SetAttribute(6, list(uint8(cosem.IPv6Gateway)), nil).
+		SetValue(7, *axdr.CreateAxdrOctetString("20014860486000000000000000008888"), true).
+		SetValue(8, *axdr.CreateAxdrOctetString(""), true).
+		SetValue(9, *axdr.CreateAxdrUnsigned(0), true).
+		SetMethod(1, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			fields := data.Value.([]*axdr.DlmsData)
+			addressType := fields[0].Value.(uint8)
+			addresses[addressType] = append(addresses[addressType], fields[1].Value.(string))
+			return nil, dlms.TagActSuccess
+		}).
+		SetMethod(2, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			fields := data.Value.([]*axdr.DlmsData)
+			addressType := fields[0].Value.(uint8)
+			for i, a := range addresses[addressType] {
+				if a == fields[1].Value.(string) {
+					addresses[addressType] = append(addresses[addressType][:i], addresses[addressType][i+1:]...)
+					return nil, dlms.TagActSuccess
+				}
+			}
+			return nil, dlms.TagActObjectUndefined
+		})
+
+	s := cosem.NewIPv6Setup(connect(t, o), "0.0.25.7.0.255")
+
+	v, err := s.Read()
+	require.NoError(t, err)
+	assert.Equal(t, *dlms.CreateObis("0.0.25.4.0.255"), v.DLReference)
+	assert.Equal(t, cosem.AddressConfigManual, v.AddressConfigMode)
+	assert.Equal(t, []net.IP{net.ParseIP("2001:db8::10")}, v.UnicastAddresses)
+	assert.Empty(t, v.MulticastAddresses)
+	assert.Equal(t, []net.IP{net.ParseIP("2001:db8::1")}, v.GatewayAddresses)
+	assert.Equal(t, net.ParseIP("2001:4860:4860::8888"), v.PrimaryDNS)
+	assert.Nil(t, v.SecondaryDNS)
+
+	// Write replaces the address lists
+	v.UnicastAddresses = []net.IP{net.ParseIP("2001:db8::20")}
+	v.MulticastAddresses = []net.IP{net.ParseIP("ff02::1")}
+	v.SecondaryDNS = net.ParseIP("2001:4860:4860::8844")
+	v.TrafficClass = 0xB8
+	require.NoError(t, s.Write(v))
+	assert.Equal(t, []string{hex.EncodeToString(net.ParseIP("2001:db8::20"))}, addresses[uint8(cosem.IPv6Unicast)])
+	assert.Equal(t, []string{hex.EncodeToString(net.ParseIP("ff02::1"))}, addresses[uint8(cosem.IPv6Multicast)])
+
+	read, err := s.Read()
+	require.NoError(t, err)
+	assert.Equal(t, v, read)
+
+	require.NoError(t, s.RemoveAddress(cosem.IPv6Gateway, net.ParseIP("2001:db8::1")))
+	assert.Empty(t, addresses[uint8(cosem.IPv6Gateway)])
+
+	assertErrorCode(t, s.AddAddress(cosem.IPv6Unicast, net.ParseIP("ff02::1")), dlms.ErrorInvalidParameter)
+	assertErrorCode(t, s.AddAddress(cosem.IPv6Gateway, net.ParseIP("10.0.0.1")), dlms.ErrorInvalidParameter)
+
+	v.PrimaryDNS = net.IP{1, 2, 3, 4}
+	assertErrorCode(t, s.Write(v), dlms.ErrorInvalidParameter)
+}
@@ -0,0 +1,77 @@
+package cosem
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"fmt"
+)
+
+// keyWrapIV is the default initial value of RFC 3394.
+const keyWrapIV = "\xA6\xA6\xA6\xA6\xA6\xA6\xA6\xA6"
+
+// WrapKey wraps a key with the key encryption key (the master key) as defined in RFC 3394.
+func WrapKey(kek []byte, key []byte) ([]byte, error) {
+	if len(key) < 16 || len(key)%8 != 0 {
+		return nil, fmt.Errorf("key length must be a multiple of 8 bytes, at least 16")
+	}
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key encryption key: %w", err)
+	}
+
+	n := len(key) / 8
+	out := make([]byte, 8+len(key))
+	copy(out, keyWrapIV)
+	copy(out[8:], key)
+
+	b := make([]byte, 16)
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(b, out[:8])
+			copy(b[8:], out[8*i:8*i+8])
+			block.Encrypt(b, b)
+
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(out[:8], binary.BigEndian.Uint64(b[:8])^t)
+			copy(out[8*i:], b[8:])
+		}
+	}
+
+	return out, nil
+}
+
+// UnwrapKey unwraps a key wrapped with WrapKey, checking its integrity.
+func UnwrapKey(kek []byte, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		return nil, fmt.Errorf("wrapped key length must be a multiple of 8 bytes, at least 24")
+	}
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key encryption key: %w", err)
+	}
+
+	n := len(wrapped)/8 - 1
+	out := make([]byte, len(wrapped))
+	copy(out, wrapped)
+
+	b := make([]byte, 16)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(b[:8], binary.BigEndian.Uint64(out[:8])^t)
+			copy(b[8:], out[8*i:8*i+8])
+			block.Decrypt(b, b)
+
+			copy(out[:8], b[:8])
+			copy(out[8*i:], b[8:])
+		}
+	}
+
+	if string(out[:8]) != keyWrapIV {
+		return nil, fmt.Errorf("integrity check of wrapped key failed")
+	}
+
+	return out[8:], nil
+}
@@ -0,0 +1,341 @@
+package cosem
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const LimiterClassID = 71
+
+const (
+	limiterMonitoredValue            = 2
+	limiterThresholdActive           = 3
+	limiterThresholdNormal           = 4
+	limiterThresholdEmergency        = 5
+	limiterMinOverThresholdDuration  = 6
+	limiterMinUnderThresholdDuration = 7
+	limiterEmergencyProfile          = 8
+	limiterEmergencyProfileGroupIDs  = 9
+	limiterEmergencyProfileActive    = 10
+	limiterActions                   = 11
+)
+
+// ValueDefinition references an attribute of an object.
+type ValueDefinition struct {
+	ClassID     uint16
+	LogicalName dlms.Obis
+	AttributeID int8
+}
+
+// Data returns the value definition as A-XDR data.
+func (v ValueDefinition) Data() *axdr.DlmsData {
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrLongUnsigned(v.ClassID),
+		axdr.CreateAxdrOctetString(v.LogicalName.String()),
+		axdr.CreateAxdrInteger(v.AttributeID),
+	})
+}
+
+func (v ValueDefinition) String() string {
+	return fmt.Sprintf("{ %d, %s, %d }", v.ClassID, v.LogicalName.String(), v.AttributeID)
+}
+
+func decodeValueDefinition(data axdr.DlmsData) (v ValueDefinition, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 3)
+	if err != nil {
+		return
+	}
+
+	classID, ok1 := fields[0].Value.(uint16)
+	attributeID, ok2 := fields[2].Value.(int8)
+	if !ok1 || !ok2 {
+		err = fmt.Errorf("invalid value definition")
+		return
+	}
+
+	ln, err := dlms.DecodeLogicalName(*fields[1])
+	if err != nil {
+		return
+	}
+
+	return ValueDefinition{ClassID: classID, LogicalName: ln, AttributeID: attributeID}, nil
+}
+
+// EmergencyProfile defines a period in which the emergency threshold is used.
+type EmergencyProfile struct {
+	ID             uint16
+	ActivationTime time.Time
+	Duration       time.Duration
+}
+
+// Data returns the emergency profile as A-XDR data.
+func (p EmergencyProfile) Data() *axdr.DlmsData {
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrLongUnsigned(p.ID),
+		axdr.CreateAxdrOctetString(p.ActivationTime),
+		axdr.CreateAxdrDoubleLongUnsigned(uint32(p.Duration / time.Second)),
+	})
+}
+
+func decodeEmergencyProfile(data axdr.DlmsData) (p EmergencyProfile, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 3)
+	if err != nil {
+		return
+	}
+
+	id, ok1 := fields[0].Value.(uint16)
+	duration, ok2 := fields[2].Value.(uint32)
+	if !ok1 || !ok2 {
+		err = fmt.Errorf("invalid emergency profile")
+		return
+	}
+
+	activation, err := DecodeDateTime(*fields[1])
+	if err != nil {
+		return
+	}
+
+	return EmergencyProfile{ID: id, ActivationTime: activation.Time, Duration: time.Duration(duration) * time.Second}, nil
+}
+
+// ActionItem references a script executed by an object.
+type ActionItem struct {
+	ScriptLogicalName dlms.Obis
+	ScriptSelector    uint16
+}
+
+// Data returns the action item as A-XDR data.
+func (a ActionItem) Data() *axdr.DlmsData {
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrOctetString(a.ScriptLogicalName.String()),
+		axdr.CreateAxdrLongUnsigned(a.ScriptSelector),
+	})
+}
+
+func (a ActionItem) String() string {
+	return fmt.Sprintf("script %d of %s", a.ScriptSelector, a.ScriptLogicalName.String())
+}
+
+// validate checks that the script is in the script table with its logical name.
+func (a ActionItem) validate(scripts map[dlms.Obis][]Script) error {
+	table, ok := scripts[a.ScriptLogicalName]
+	if !ok {
+		return fmt.Errorf("unknown script table %s", a.ScriptLogicalName.String())
+	}
+
+	if findScript(table, a.ScriptSelector) == nil {
+		return fmt.Errorf("unknown %s", a.String())
+	}
+
+	return nil
+}
+
+func decodeActionItem(data axdr.DlmsData) (a ActionItem, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 2)
+	if err != nil {
+		return
+	}
+
+	selector, ok := fields[1].Value.(uint16)
+	if !ok {
+		err = fmt.Errorf("invalid script selector")
+		return
+	}
+
+	ln, err := dlms.DecodeLogicalName(*fields[0])
+	if err != nil {
+		return
+	}
+
+	return ActionItem{ScriptLogicalName: ln, ScriptSelector: selector}, nil
+}
+
+// LimiterActions are the scripts executed when the monitored value crosses the threshold.
+type LimiterActions struct {
+	OverThreshold  ActionItem
+	UnderThreshold ActionItem
+}
+
+// LimiterValue holds the attributes of a limiter. Thresholds have the type of the monitored value.
+type LimiterValue struct {
+	MonitoredValue            ValueDefinition
+	ThresholdActive           axdr.DlmsData
+	ThresholdNormal           axdr.DlmsData
+	ThresholdEmergency        axdr.DlmsData
+	MinOverThresholdDuration  time.Duration
+	MinUnderThresholdDuration time.Duration
+	EmergencyProfile          EmergencyProfile
+	EmergencyProfileGroupIDs  []uint16
+	EmergencyProfileActive    bool
+	Actions                   LimiterActions
+}
+
+// Limiter is an instance of the Limiter interface class (class_id 71).
+type Limiter struct {
+	object
+}
+
+func NewLimiter(client dlms.Client, logicalName string) *Limiter {
+	return &Limiter{object{client: client, classID: LimiterClassID, logicalName: logicalName}}
+}
+
+// Read reads all the attributes of the limiter.
+func (l *Limiter) Read() (v LimiterValue, err error) {
+	if v.MonitoredValue, err = l.MonitoredValue(); err != nil {
+		return
+	}
+	if err = l.get(limiterThresholdActive, &v.ThresholdActive); err != nil {
+		return
+	}
+	if err = l.get(limiterThresholdNormal, &v.ThresholdNormal); err != nil {
+		return
+	}
+	if err = l.get(limiterThresholdEmergency, &v.ThresholdEmergency); err != nil {
+		return
+	}
+	if v.MinOverThresholdDuration, err = l.seconds(limiterMinOverThresholdDuration); err != nil {
+		return
+	}
+	if v.MinUnderThresholdDuration, err = l.seconds(limiterMinUnderThresholdDuration); err != nil {
+		return
+	}
+	if v.EmergencyProfile, err = l.EmergencyProfile(); err != nil {
+		return
+	}
+	if err = l.get(limiterEmergencyProfileGroupIDs, &v.EmergencyProfileGroupIDs); err != nil {
+		return
+	}
+	if err = l.get(limiterEmergencyProfileActive, &v.EmergencyProfileActive); err != nil {
+		return
+	}
+
+	v.Actions, err = l.Actions()
+	return
+}
+
+// MonitoredValue reads the attribute whose value is compared with the threshold.
+func (l *Limiter) MonitoredValue() (ValueDefinition, error) {
+	var data axdr.DlmsData
+
+	err := l.get(limiterMonitoredValue, &data)
+	if err != nil {
+		return ValueDefinition{}, err
+	}
+
+	v, err := decodeValueDefinition(data)
+	if err != nil {
+		return v, l.invalidData(limiterMonitoredValue, err)
+	}
+
+	return v, nil
+}
+
+// SetMonitoredValue sets the attribute whose value is compared with the threshold.
+func (l *Limiter) SetMonitoredValue(v ValueDefinition) error {
+	return l.set(limiterMonitoredValue, v.Data())
+}
+
+// ThresholdActive reads the threshold in use.
+func (l *Limiter) ThresholdActive() (threshold axdr.DlmsData, err error) {
+	err = l.get(limiterThresholdActive, &threshold)
+	return
+}
+
+// SetThresholds sets the normal and emergency thresholds. They must have the type of the monitored value.
+func (l *Limiter) SetThresholds(normal axdr.DlmsData, emergency axdr.DlmsData) error {
+	err := l.set(limiterThresholdNormal, &normal)
+	if err != nil {
+		return err
+	}
+
+	return l.set(limiterThresholdEmergency, &emergency)
+}
+
+// SetMinDurations sets how long the value must be over or under the threshold to execute the actions.
+func (l *Limiter) SetMinDurations(over time.Duration, under time.Duration) error {
+	err := l.set(limiterMinOverThresholdDuration, uint32(over/time.Second))
+	if err != nil {
+		return err
+	}
+
+	return l.set(limiterMinUnderThresholdDuration, uint32(under/time.Second))
+}
+
+// EmergencyProfile reads the emergency profile.
+func (l *Limiter) EmergencyProfile() (EmergencyProfile, error) {
+	var data axdr.DlmsData
+
+	err := l.get(limiterEmergencyProfile, &data)
+	if err != nil {
+		return EmergencyProfile{}, err
+	}
+
+	p, err := decodeEmergencyProfile(data)
+	if err != nil {
+		return p, l.invalidData(limiterEmergencyProfile, err)
+	}
+
+	return p, nil
+}
+
+// SetEmergencyProfile sets the emergency profile and the groups it applies to. The profile is active
+// if its id is in the groups.
+func (l *Limiter) SetEmergencyProfile(p EmergencyProfile, groupIDs []uint16) error {
+	err := l.set(limiterEmergencyProfile, p.Data())
+	if err != nil {
+		return err
+	}
+
+	groups := make([]*axdr.DlmsData, len(groupIDs))
+	for i, id := range groupIDs {
+		groups[i] = axdr.CreateAxdrLongUnsigned(id)
+	}
+
+	return l.set(limiterEmergencyProfileGroupIDs, axdr.CreateAxdrArray(groups))
+}
+
+// EmergencyProfileActive reads if the emergency profile is active.
+func (l *Limiter) EmergencyProfileActive() (active bool, err error) {
+	err = l.get(limiterEmergencyProfileActive, &active)
+	return
+}
+
+// Actions reads the scripts executed when the threshold is crossed.
+func (l *Limiter) Actions() (LimiterActions, error) {
+	var data axdr.DlmsData
+
+	err := l.get(limiterActions, &data)
+	if err != nil {
+		return LimiterActions{}, err
+	}
+
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 2)
+	if err != nil {
+		return LimiterActions{}, l.invalidData(limiterActions, err)
+	}
+
+	var a LimiterActions
+	if a.OverThreshold, err = decodeActionItem(*fields[0]); err != nil {
+		return a, l.invalidData(limiterActions, err)
+	}
+	if a.UnderThreshold, err = decodeActionItem(*fields[1]); err != nil {
+		return a, l.invalidData(limiterActions, err)
+	}
+
+	return a, nil
+}
+
+// SetActions sets the scripts executed when the threshold is crossed.
+func (l *Limiter) SetActions(a LimiterActions) error {
+	return l.set(limiterActions, axdr.CreateAxdrStructure([]*axdr.DlmsData{a.OverThreshold.Data(), a.UnderThreshold.Data()}))
+}
+
+func (l *Limiter) seconds(attributeID int8) (time.Duration, error) {
+	var seconds uint32
+
+	err := l.get(attributeID, &seconds)
+	return time.Duration(seconds) * time.Second, err
+}
@@ -0,0 +1,73 @@
+package cosem_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	monitored := cosem.ValueDefinition{ClassID: 3, LogicalName: *dlms.CreateObis("1.0.1.7.0.255"), AttributeID: 2}
+	actions := cosem.LimiterActions{
+		OverThreshold:  cosem.ActionItem{ScriptLogicalName: *dlms.CreateObis("0.0.10.0.106.255"), ScriptSelector: 1},
+		UnderThreshold: cosem.ActionItem{ScriptLogicalName: *dlms.CreateObis("0.0.10.0.106.255"), ScriptSelector: 2},
+	}
+	profile := cosem.EmergencyProfile{ID: 1, ActivationTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Duration: time.Hour}
+
+	o := dlmsserver.NewObject(cosem.LimiterClassID, 0, "0.0.17.0.0.255").
+		SetValue(2, *monitored.Data(), true).
+		SetValue(3, *axdr.CreateAxdrDoubleLongUnsigned(5000), false).
+		SetValue(4, *axdr.CreateAxdrDoubleLongUnsigned(5000), true).
+		SetValue(5, *axdr.CreateAxdrDoubleLongUnsigned(2000), true).
+		SetValue(6, *axdr.CreateAxdrDoubleLongUnsigned(60), true).
+		SetValue(7, *axdr.CreateAxdrDoubleLongUnsigned(30), true).
+		SetValue(8, *profile.Data(), true).
+		SetValue(9, *axdr.CreateAxdrArray([]*axdr.DlmsData{axdr.CreateAxdrLongUnsigned(1)}), true).
+		SetValue(10, *axdr.CreateAxdrBoolean(false), false).
+		SetValue(11, *axdr.CreateAxdrStructure([]*axdr.DlmsData{actions.OverThreshold.Data(), actions.UnderThreshold.Data()}), true)
+
+	l := cosem.NewLimiter(connect(t, o), "0.0.17.0.0.255")
+
+	v, err := l.Read()
+	require.NoError(t, err)
+	assert.Equal(t, monitored, v.MonitoredValue)
+	assert.Equal(t, uint32(5000), v.ThresholdActive.Value)
+	assert.Equal(t, uint32(2000), v.ThresholdEmergency.Value)
+	assert.Equal(t, time.Minute, v.MinOverThresholdDuration)
+	assert.Equal(t, 30*time.Second, v.MinUnderThresholdDuration)
+	assert.Equal(t, profile.ID, v.EmergencyProfile.ID)
+	assert.True(t, profile.ActivationTime.Equal(v.EmergencyProfile.ActivationTime))
+	assert.Equal(t, time.Hour, v.EmergencyProfile.Duration)
+	assert.Equal(t, []uint16{1}, v.EmergencyProfileGroupIDs)
+	assert.False(t, v.EmergencyProfileActive)
+	assert.Equal(t, actions, v.Actions)
+
+	monitored.LogicalName = *dlms.CreateObis("1.0.21.7.0.255")
+	require.NoError(t, l.SetMonitoredValue(monitored))
+	require.NoError(t, l.SetThresholds(*axdr.CreateAxdrDoubleLongUnsigned(4000), *axdr.CreateAxdrDoubleLongUnsigned(1000)))
+	require.NoError(t, l.SetMinDurations(2*time.Minute, time.Minute))
+	require.NoError(t, l.SetEmergencyProfile(cosem.EmergencyProfile{ID: 2, ActivationTime: profile.ActivationTime, Duration: 2 * time.Hour}, []uint16{2, 3}))
+
+	actions.OverThreshold.ScriptSelector = 3
+	require.NoError(t, l.SetActions(actions))
+
+	v, err = l.Read()
+	require.NoError(t, err)
+	assert.Equal(t, monitored, v.MonitoredValue)
+	assert.Equal(t, uint32(4000), v.ThresholdNormal.Value)
+	assert.Equal(t, uint32(1000), v.ThresholdEmergency.Value)
+	assert.Equal(t, 2*time.Minute, v.MinOverThresholdDuration)
+	assert.Equal(t, uint16(2), v.EmergencyProfile.ID)
+	assert.Equal(t, []uint16{2, 3}, v.EmergencyProfileGroupIDs)
+	assert.Equal(t, actions, v.Actions)
+
+	// Thresholds must have the type of the monitored value
+	err = l.SetThresholds(*axdr.CreateAxdrLong(1), *axdr.CreateAxdrLong(1))
+	assertErrorCode(t, err, dlms.ErrorSetRejected)
+}
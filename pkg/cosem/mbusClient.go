@@ -0,0 +1,371 @@
+package cosem
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const MBusClientClassID = 72
+
+// MBusChannels is the number of M-Bus channels of a meter.
+const MBusChannels = 4
+
+const (
+	mbusPortReference       = 2
+	mbusCaptureDefinition   = 3
+	mbusCapturePeriod       = 4
+	mbusPrimaryAddress      = 5
+	mbusIdentification      = 6
+	mbusManufacturerID      = 7
+	mbusVersion             = 8
+	mbusDeviceType          = 9
+	mbusAccessNumber        = 10
+	mbusStatus              = 11
+	mbusAlarm               = 12
+	mbusConfiguration       = 13
+	mbusEncryptionKeyStatus = 14
+	mbusSlaveInstall        = 1
+	mbusSlaveDeinstall      = 2
+	mbusCapture             = 3
+	mbusResetAlarm          = 4
+	mbusSynchronizeClock    = 5
+	mbusSetEncryptionKey    = 7
+	mbusTransferKey         = 8
+)
+
+// MBusClientLogicalName returns the logical name of the M-Bus client of a channel.
+func MBusClientLogicalName(channel uint8) string {
+	return fmt.Sprintf("0.%d.24.1.0.255", channel)
+}
+
+// MBusValueLogicalName returns the logical name of a value register of a channel. Index starts at 1.
+func MBusValueLogicalName(channel uint8, index uint8) string {
+	return fmt.Sprintf("0.%d.24.2.%d.255", channel, index)
+}
+
+// MBusProfileLogicalName returns the logical name of the M-Bus master profile of a channel.
+func MBusProfileLogicalName(channel uint8) string {
+	return fmt.Sprintf("0.%d.24.3.0.255", channel)
+}
+
+// EncryptionKeyStatus is the state of the encryption key of an M-Bus device.
+type EncryptionKeyStatus uint8
+
+const (
+	EncryptionKeyNone              EncryptionKeyStatus = 0
+	EncryptionKeySet               EncryptionKeyStatus = 1
+	EncryptionKeyTransferred       EncryptionKeyStatus = 2
+	EncryptionKeySetAndTransferred EncryptionKeyStatus = 3
+	EncryptionKeyInUse             EncryptionKeyStatus = 4
+)
+
+func (s EncryptionKeyStatus) String() string {
+	switch s {
+	case EncryptionKeyNone:
+		return "no encryption key"
+	case EncryptionKeySet:
+		return "encryption key set"
+	case EncryptionKeyTransferred:
+		return "encryption key transferred"
+	case EncryptionKeySetAndTransferred:
+		return "encryption key set and transferred"
+	case EncryptionKeyInUse:
+		return "encryption key in use"
+	default:
+		return fmt.Sprintf("unknown (%d)", uint8(s))
+	}
+}
+
+// CaptureDefinition selects a value of the M-Bus device by its data and value information blocks.
+type CaptureDefinition struct {
+	DIB []byte
+	VIB []byte
+}
+
+// Data returns the capture definition as A-XDR data.
+func (c CaptureDefinition) Data() *axdr.DlmsData {
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		createOctetString(c.DIB),
+		createOctetString(c.VIB),
+	})
+}
+
+func decodeCaptureDefinition(data axdr.DlmsData) (c CaptureDefinition, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 2)
+	if err != nil {
+		return
+	}
+
+	if c.DIB, err = octetString(*fields[0], 0); err != nil {
+		return
+	}
+
+	c.VIB, err = octetString(*fields[1], 0)
+	return
+}
+
+// MBusClientValue holds the attributes of an M-Bus client. A zero primary address means that no device
+// is installed.
+type MBusClientValue struct {
+	PortReference        dlms.Obis
+	CaptureDefinitions   []CaptureDefinition
+	CapturePeriod        time.Duration
+	PrimaryAddress       uint8
+	IdentificationNumber uint32
+	ManufacturerID       uint16
+	Version              uint8
+	DeviceType           uint8
+	AccessNumber         uint8
+	Status               uint8
+	Alarm                uint8
+	Configuration        uint16
+	EncryptionKeyStatus  EncryptionKeyStatus
+}
+
+// Installed returns true if a device is installed in the channel.
+func (v MBusClientValue) Installed() bool {
+	return v.PrimaryAddress != 0
+}
+
+// SerialNumber returns the identification number, that is BCD coded, as a string of 8 digits.
+func (v MBusClientValue) SerialNumber() string {
+	return fmt.Sprintf("%08x", v.IdentificationNumber)
+}
+
+// Manufacturer returns the 3 letters of the manufacturer id, as defined in EN 13757-3.
+func (v MBusClientValue) Manufacturer() string {
+	return string([]byte{
+		byte(v.ManufacturerID>>10&0x1F) + 64,
+		byte(v.ManufacturerID>>5&0x1F) + 64,
+		byte(v.ManufacturerID&0x1F) + 64,
+	})
+}
+
+// MBusClient is an instance of the M-Bus client interface class (class_id 72).
+type MBusClient struct {
+	object
+}
+
+func NewMBusClient(client dlms.Client, logicalName string) *MBusClient {
+	return &MBusClient{object{client: client, classID: MBusClientClassID, logicalName: logicalName}}
+}
+
+// Read reads all the attributes of the M-Bus client.
+func (m *MBusClient) Read() (v MBusClientValue, err error) {
+	var data axdr.DlmsData
+	if err = m.get(mbusPortReference, &data); err != nil {
+		return
+	}
+	if v.PortReference, err = dlms.DecodeLogicalName(data); err != nil {
+		return v, m.invalidData(mbusPortReference, err)
+	}
+
+	if v.CaptureDefinitions, err = m.CaptureDefinitions(); err != nil {
+		return
+	}
+
+	var period uint32
+	if err = m.get(mbusCapturePeriod, &period); err != nil {
+		return
+	}
+	v.CapturePeriod = time.Duration(period) * time.Second
+
+	if err = m.get(mbusPrimaryAddress, &v.PrimaryAddress); err != nil {
+		return
+	}
+	if err = m.get(mbusIdentification, &v.IdentificationNumber); err != nil {
+		return
+	}
+	if err = m.get(mbusManufacturerID, &v.ManufacturerID); err != nil {
+		return
+	}
+	if err = m.get(mbusVersion, &v.Version); err != nil {
+		return
+	}
+	if err = m.get(mbusDeviceType, &v.DeviceType); err != nil {
+		return
+	}
+	if err = m.get(mbusAccessNumber, &v.AccessNumber); err != nil {
+		return
+	}
+	if err = m.get(mbusStatus, &v.Status); err != nil {
+		return
+	}
+	if err = m.get(mbusAlarm, &v.Alarm); err != nil {
+		return
+	}
+	if err = m.get(mbusConfiguration, &v.Configuration); err != nil {
+		return
+	}
+
+	v.EncryptionKeyStatus, err = m.EncryptionKeyStatus()
+	return
+}
+
+// CaptureDefinitions reads the values of the device captured into the value registers of the channel.
+func (m *MBusClient) CaptureDefinitions() ([]CaptureDefinition, error) {
+	var data axdr.DlmsData
+
+	err := m.get(mbusCaptureDefinition, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	elements, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return nil, m.invalidData(mbusCaptureDefinition, err)
+	}
+
+	definitions := make([]CaptureDefinition, len(elements))
+	for i, e := range elements {
+		if definitions[i], err = decodeCaptureDefinition(*e); err != nil {
+			return nil, m.invalidData(mbusCaptureDefinition, err)
+		}
+	}
+
+	return definitions, nil
+}
+
+// SetCaptureDefinitions sets the values of the device captured into the value registers of the channel.
+func (m *MBusClient) SetCaptureDefinitions(definitions []CaptureDefinition) error {
+	list := make([]*axdr.DlmsData, len(definitions))
+	for i, d := range definitions {
+		if len(d.DIB) == 0 || len(d.VIB) == 0 {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("capture definition %d has no DIB or VIB", i+1))
+		}
+		list[i] = d.Data()
+	}
+
+	return m.set(mbusCaptureDefinition, axdr.CreateAxdrArray(list))
+}
+
+// SetCapturePeriod sets how often the device is read. Zero means that it's read on capture.
+func (m *MBusClient) SetCapturePeriod(period time.Duration) error {
+	if period < 0 || period%time.Second != 0 || period/time.Second > 0xFFFFFFFF {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("capture period %s must be whole seconds", period))
+	}
+
+	return m.set(mbusCapturePeriod, uint32(period/time.Second))
+}
+
+// EncryptionKeyStatus reads the state of the encryption key of the device.
+func (m *MBusClient) EncryptionKeyStatus() (EncryptionKeyStatus, error) {
+	var status uint8
+
+	err := m.get(mbusEncryptionKeyStatus, &status)
+	return EncryptionKeyStatus(status), err
+}
+
+// SlaveInstall installs the device with the given primary address, from 1 to 250.
+func (m *MBusClient) SlaveInstall(primaryAddress uint8) error {
+	if primaryAddress == 0 || primaryAddress > 250 {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("invalid primary address %d", primaryAddress))
+	}
+
+	return m.action(mbusSlaveInstall, primaryAddress)
+}
+
+// SlaveDeinstall deinstalls the device of the channel.
+func (m *MBusClient) SlaveDeinstall() error {
+	return m.action(mbusSlaveDeinstall, int8(0))
+}
+
+// Capture reads the device and stores the captured values in the value registers of the channel.
+func (m *MBusClient) Capture() error {
+	return m.action(mbusCapture, int8(0))
+}
+
+// ResetAlarm clears the alarm of the device.
+func (m *MBusClient) ResetAlarm() error {
+	return m.action(mbusResetAlarm, int8(0))
+}
+
+// SynchronizeClock sends the time of the meter to the device.
+func (m *MBusClient) SynchronizeClock() error {
+	return m.action(mbusSynchronizeClock, int8(0))
+}
+
+// SetEncryptionKey sets the key used by the meter to decrypt the device messages. The key isn't sent to
+// the device: see TransferKey.
+func (m *MBusClient) SetEncryptionKey(key []byte) error {
+	if len(key) != 16 {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("invalid encryption key length %d", len(key)))
+	}
+
+	return m.action(mbusSetEncryptionKey, createOctetString(key))
+}
+
+// TransferKey sends the encryption key to the device. The key must be encrypted with the key the device
+// currently uses, as the meter forwards it unchanged.
+func (m *MBusClient) TransferKey(encryptedKey []byte) error {
+	if len(encryptedKey) == 0 {
+		return dlms.NewError(dlms.ErrorInvalidParameter, "empty encryption key")
+	}
+
+	return m.action(mbusTransferKey, createOctetString(encryptedKey))
+}
+
+// MBusChannel holds the data of an installed M-Bus channel: the client, the value registers, one per
+// capture definition, and the entries of the M-Bus master profile.
+type MBusChannel struct {
+	Channel uint8
+	Client  MBusClientValue
+	Values  []ExtendedRegisterValue
+	Profile Profile
+}
+
+// ReadMBusChannel reads the M-Bus client, the value registers and the profile of a channel. The profile
+// entries captured between start and end are read, or the whole buffer if both are zero.
+func ReadMBusChannel(client dlms.Client, channel uint8, start time.Time, end time.Time) (ch MBusChannel, err error) {
+	ch.Channel = channel
+
+	if ch.Client, err = NewMBusClient(client, MBusClientLogicalName(channel)).Read(); err != nil {
+		return
+	}
+
+	ch.Values = make([]ExtendedRegisterValue, len(ch.Client.CaptureDefinitions))
+	for i := range ch.Values {
+		if ch.Values[i], err = NewExtendedRegister(client, MBusValueLogicalName(channel, uint8(i+1))).Read(); err != nil {
+			return
+		}
+	}
+
+	profile := NewProfileGeneric(client, MBusProfileLogicalName(channel))
+	if start.IsZero() && end.IsZero() {
+		ch.Profile, err = profile.Read()
+	} else {
+		ch.Profile, err = profile.ReadByDate(start, end)
+	}
+
+	return
+}
+
+// ReadMBusChannels reads the data of every channel with an installed device. See ReadMBusChannel.
+// Channels whose M-Bus client doesn't exist are skipped.
+func ReadMBusChannels(client dlms.Client, start time.Time, end time.Time) ([]MBusChannel, error) {
+	var channels []MBusChannel
+
+	for channel := uint8(1); channel <= MBusChannels; channel++ {
+		var address uint8
+
+		err := NewMBusClient(client, MBusClientLogicalName(channel)).get(mbusPrimaryAddress, &address)
+		if isGetRejected(err) || (err == nil && address == 0) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		ch, err := ReadMBusChannel(client, channel, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		channels = append(channels, ch)
+	}
+
+	return channels, nil
+}
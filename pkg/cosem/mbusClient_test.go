@@ -0,0 +1,173 @@
+package cosem_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// meterMBusClient simulates an M-Bus client of a meter.
+type meterMBusClient struct {
+	address   uint8
+	keyStatus cosem.EncryptionKeyStatus
+	captures  int
+}
+
+func (m *meterMBusClient) object(channel uint8) *dlmsserver.Object {
+	definition := cosem.CaptureDefinition{DIB: []byte{0x0C}, VIB: []byte{0x13}}
+
+	return dlmsserver.NewObject(cosem.MBusClientClassID, 1, cosem.MBusClientLogicalName(channel)).
+		SetValue(2, *axdr.CreateAxdrOctetString("0.0.24.6.0.255"), false).
+		SetValue(3, *axdr.CreateAxdrArray([]*axdr.DlmsData{definition.Data()}), true).
+		SetValue(4, *axdr.CreateAxdrDoubleLongUnsigned(3600), true).
+		SetAttribute(5, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			return *axdr.CreateAxdrUnsigned(m.address), dlms.TagAccSuccess
+		}, nil).
+		SetValue(6, *axdr.CreateAxdrDoubleLongUnsigned(0x12345678), false).
+		SetValue(7, *axdr.CreateAxdrLongUnsigned(0x1EE6), false).
+		SetValue(8, *axdr.CreateAxdrUnsigned(1), false).
+		SetValue(9, *axdr.CreateAxdrUnsigned(3), false).
+		SetValue(10, *axdr.CreateAxdrUnsigned(42), false).
+		SetValue(11, *axdr.CreateAxdrUnsigned(0), false).
+		SetValue(12, *axdr.CreateAxdrUnsigned(0), false).
+		SetValue(13, *axdr.CreateAxdrLongUnsigned(0), false).
+		SetAttribute(14, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			return *axdr.CreateAxdrEnum(uint8(m.keyStatus)), dlms.TagAccSuccess
+		}, nil).
+		SetMethod(1, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			if m.address != 0 {
+				return nil, dlms.TagActTemporaryFailure
+			}
+			m.address = data.Value.(uint8)
+			return nil, dlms.TagActSuccess
+		}).
+		SetMethod(2, func(_ dlms.MethodDescriptor, _ *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			m.address = 0
+			return nil, dlms.TagActSuccess
+		}).
+		SetMethod(3, func(_ dlms.MethodDescriptor, _ *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			m.captures++
+			return nil, dlms.TagActSuccess
+		}).
+		SetMethod(7, func(_ dlms.MethodDescriptor, _ *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			m.keyStatus |= cosem.EncryptionKeySet
+			return nil, dlms.TagActSuccess
+		}).
+		SetMethod(8, func(_ dlms.MethodDescriptor, _ *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			if m.keyStatus != cosem.EncryptionKeySet {
+				return nil, dlms.TagActTemporaryFailure
+			}
+			m.keyStatus = cosem.EncryptionKeySetAndTransferred
+			return nil, dlms.TagActSuccess
+		})
+}
+
+func newMBusChannel(t *testing.T, channel uint8) []*dlmsserver.Object {
+	t.Helper()
+
+	captureTime := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	value := dlmsserver.NewObject(cosem.ExtendedRegisterClassID, 0, cosem.MBusValueLogicalName(channel, 1)).
+		SetValue(2, *axdr.CreateAxdrDoubleLongUnsigned(123456), false).
+		SetValue(3, *cosem.ScalerUnit{Scaler: -3, Unit: cosem.UnitVolume}.Data(), false).
+		SetValue(4, *axdr.CreateAxdrUnsigned(0), false).
+		SetValue(5, *axdr.CreateAxdrDateTime(captureTime), false)
+
+	captureObjects := []*axdr.DlmsData{
+		cosem.CaptureObject{ClassID: 8, LogicalName: *dlms.CreateObis("0.0.1.0.0.255"), AttributeID: 2}.Data(),
+		cosem.CaptureObject{ClassID: 4, LogicalName: *dlms.CreateObis(cosem.MBusValueLogicalName(channel, 1)), AttributeID: 2}.Data(),
+	}
+
+	entries := make([]*axdr.DlmsData, 2)
+	for i := range entries {
+		entries[i] = axdr.CreateAxdrStructure([]*axdr.DlmsData{
+			axdr.CreateAxdrOctetString(captureTime.Add(time.Duration(i-1) * time.Hour)),
+			axdr.CreateAxdrDoubleLongUnsigned(uint32(123000 + i*456)),
+		})
+	}
+
+	profile := dlmsserver.NewObject(cosem.ProfileGenericClassID, 1, cosem.MBusProfileLogicalName(channel)).
+		SetAttribute(2, func(_ dlms.AttributeDescriptor, acc *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			if acc != nil {
+				return *axdr.CreateAxdrArray(entries[1:]), dlms.TagAccSuccess
+			}
+			return *axdr.CreateAxdrArray(entries), dlms.TagAccSuccess
+		}, nil).
+		SetValue(3, *axdr.CreateAxdrArray(captureObjects), false).
+		SetValue(4, *axdr.CreateAxdrDoubleLongUnsigned(3600), false)
+
+	return []*dlmsserver.Object{value, profile}
+}
+
+func TestMBusClient(t *testing.T) {
+	m := &meterMBusClient{}
+	c := cosem.NewMBusClient(connect(t, m.object(1)), cosem.MBusClientLogicalName(1))
+
+	v, err := c.Read()
+	require.NoError(t, err)
+	assert.False(t, v.Installed())
+	assert.Equal(t, "0.0.24.6.0.255", v.PortReference.String())
+	assert.Equal(t, []cosem.CaptureDefinition{{DIB: []byte{0x0C}, VIB: []byte{0x13}}}, v.CaptureDefinitions)
+	assert.Equal(t, time.Hour, v.CapturePeriod)
+	assert.Equal(t, "12345678", v.SerialNumber())
+	assert.Equal(t, "GWF", v.Manufacturer())
+	assert.Equal(t, uint8(3), v.DeviceType)
+	assert.Equal(t, cosem.EncryptionKeyNone, v.EncryptionKeyStatus)
+
+	assertErrorCode(t, c.SlaveInstall(0), dlms.ErrorInvalidParameter)
+	require.NoError(t, c.SlaveInstall(5))
+	assert.Equal(t, uint8(5), m.address)
+	assertErrorCode(t, c.SlaveInstall(6), dlms.ErrorActionRejected)
+
+	require.NoError(t, c.Capture())
+	assert.Equal(t, 1, m.captures)
+
+	assertErrorCode(t, c.SetEncryptionKey([]byte{1, 2, 3}), dlms.ErrorInvalidParameter)
+	assertErrorCode(t, c.TransferKey([]byte{0x10}), dlms.ErrorActionRejected)
+	require.NoError(t, c.SetEncryptionKey(make([]byte, 16)))
+	require.NoError(t, c.TransferKey(make([]byte, 24)))
+	status, err := c.EncryptionKeyStatus()
+	require.NoError(t, err)
+	assert.Equal(t, cosem.EncryptionKeySetAndTransferred, status)
+	assert.Equal(t, "encryption key set and transferred", status.String())
+
+	assertErrorCode(t, c.SetCapturePeriod(1500*time.Millisecond), dlms.ErrorInvalidParameter)
+	require.NoError(t, c.SetCapturePeriod(15*time.Minute))
+	assertErrorCode(t, c.SetCaptureDefinitions([]cosem.CaptureDefinition{{DIB: []byte{0x0C}}}), dlms.ErrorInvalidParameter)
+
+	require.NoError(t, c.SlaveDeinstall())
+	assert.Equal(t, uint8(0), m.address)
+}
+
+func TestReadMBusChannels(t *testing.T) {
+	installed := &meterMBusClient{address: 5}
+	empty := &meterMBusClient{}
+
+	objects := append(newMBusChannel(t, 1), installed.object(1), empty.object(2))
+	client := connect(t, objects...)
+
+	channels, err := cosem.ReadMBusChannels(client, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, channels, 1)
+
+	ch := channels[0]
+	assert.Equal(t, uint8(1), ch.Channel)
+	assert.Equal(t, uint8(5), ch.Client.PrimaryAddress)
+	require.Len(t, ch.Values, 1)
+	assert.Equal(t, "123.456 m³", ch.Values[0].String())
+	require.Len(t, ch.Profile.Rows, 2)
+
+	start := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	ch, err = cosem.ReadMBusChannel(client, 1, start, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, ch.Profile.Rows, 1)
+
+	_, err = cosem.ReadMBusChannel(client, 3, time.Time{}, time.Time{})
+	assertErrorCode(t, err, dlms.ErrorGetRejected)
+}
@@ -0,0 +1,219 @@
+package cosem
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const ParameterMonitorClassID = 65
+
+const (
+	parameterMonitorChangedParameter = 2
+	parameterMonitorCaptureTime      = 3
+	parameterMonitorParameterList    = 4
+	parameterMonitorActiveList       = 5
+	parameterMonitorAddParameter     = 1
+	parameterMonitorDeleteParameter  = 2
+)
+
+// ParameterValue is a monitored parameter together with its value.
+type ParameterValue struct {
+	Parameter ValueDefinition
+	Value     axdr.DlmsData
+}
+
+func (p ParameterValue) String() string {
+	return fmt.Sprintf("%s = %v", p.Parameter.String(), p.Value.Value)
+}
+
+func decodeParameterValue(data axdr.DlmsData) (p ParameterValue, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 4)
+	if err != nil {
+		return
+	}
+
+	if p.Parameter, err = decodeValueDefinition(*axdr.CreateAxdrStructure(fields[:3])); err != nil {
+		return
+	}
+
+	p.Value = *fields[3]
+	return
+}
+
+// ParameterMonitorValue holds the attributes of a parameter monitor. ChangedParameter is the last
+// parameter changed, captured at CaptureTime.
+type ParameterMonitorValue struct {
+	ChangedParameter ParameterValue
+	CaptureTime      DateTime
+	Parameters       []ValueDefinition
+	ActiveParameters []ParameterValue
+}
+
+// String returns the configuration of the parameter monitor, one parameter per line.
+func (v ParameterMonitorValue) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "last change %s at %s", v.ChangedParameter.String(), v.CaptureTime.Time.Format(time.RFC3339))
+	for _, p := range v.ActiveParameters {
+		fmt.Fprintf(&b, "\nmonitors %s", p.String())
+	}
+
+	return b.String()
+}
+
+// ValidateParameters checks that the parameters reference an attribute other than the logical name
+// and are not repeated.
+func ValidateParameters(parameters []ValueDefinition) error {
+	seen := make(map[ValueDefinition]bool, len(parameters))
+	for _, p := range parameters {
+		if p.AttributeID < 2 {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("invalid attribute of parameter %s", p.String()))
+		}
+
+		if seen[p] {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("duplicated parameter %s", p.String()))
+		}
+		seen[p] = true
+	}
+
+	return nil
+}
+
+// ParameterMonitor is an instance of the Parameter monitor interface class (class_id 65).
+type ParameterMonitor struct {
+	object
+}
+
+func NewParameterMonitor(client dlms.Client, logicalName string) *ParameterMonitor {
+	return &ParameterMonitor{object{client: client, classID: ParameterMonitorClassID, logicalName: logicalName}}
+}
+
+// Read reads all the attributes of the parameter monitor.
+func (m *ParameterMonitor) Read() (v ParameterMonitorValue, err error) {
+	var data axdr.DlmsData
+	if err = m.get(parameterMonitorChangedParameter, &data); err != nil {
+		return
+	}
+	if v.ChangedParameter, err = decodeParameterValue(data); err != nil {
+		return v, m.invalidData(parameterMonitorChangedParameter, err)
+	}
+
+	if err = m.get(parameterMonitorCaptureTime, &data); err != nil {
+		return
+	}
+	if v.CaptureTime, err = DecodeDateTime(data); err != nil {
+		return v, m.invalidData(parameterMonitorCaptureTime, err)
+	}
+
+	if v.Parameters, err = m.Parameters(); err != nil {
+		return
+	}
+
+	if err = m.get(parameterMonitorActiveList, &data); err != nil {
+		return
+	}
+
+	active, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return v, m.invalidData(parameterMonitorActiveList, err)
+	}
+
+	v.ActiveParameters = make([]ParameterValue, len(active))
+	for i, a := range active {
+		if v.ActiveParameters[i], err = decodeParameterValue(*a); err != nil {
+			return v, m.invalidData(parameterMonitorActiveList, err)
+		}
+	}
+
+	return v, nil
+}
+
+// Parameters reads the list of monitored parameters.
+func (m *ParameterMonitor) Parameters() ([]ValueDefinition, error) {
+	var data axdr.DlmsData
+
+	err := m.get(parameterMonitorParameterList, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	elements, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return nil, m.invalidData(parameterMonitorParameterList, err)
+	}
+
+	parameters := make([]ValueDefinition, len(elements))
+	for i, e := range elements {
+		if parameters[i], err = decodeValueDefinition(*e); err != nil {
+			return nil, m.invalidData(parameterMonitorParameterList, err)
+		}
+	}
+
+	return parameters, nil
+}
+
+// SetParameters validates the parameters and makes them the list of monitored parameters. Parameters
+// not in the list are deleted and the missing ones added.
+func (m *ParameterMonitor) SetParameters(parameters []ValueDefinition) error {
+	err := ValidateParameters(parameters)
+	if err != nil {
+		return err
+	}
+
+	current, err := m.Parameters()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range current {
+		if !containsParameter(parameters, p) {
+			if err = m.DeleteParameter(p); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, p := range parameters {
+		if !containsParameter(current, p) {
+			if err = m.AddParameter(p); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// AddParameter adds a parameter to the list of monitored parameters.
+func (m *ParameterMonitor) AddParameter(p ValueDefinition) error {
+	return m.action(parameterMonitorAddParameter, p.Data())
+}
+
+// DeleteParameter deletes a parameter from the list of monitored parameters.
+func (m *ParameterMonitor) DeleteParameter(p ValueDefinition) error {
+	return m.action(parameterMonitorDeleteParameter, p.Data())
+}
+
+// Summary reads the parameter monitor and returns its configuration.
+func (m *ParameterMonitor) Summary() (string, error) {
+	v, err := m.Read()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("parameter monitor %s\n%s", m.logicalName, v.String()), nil
+}
+
+func containsParameter(list []ValueDefinition, p ValueDefinition) bool {
+	for _, e := range list {
+		if e == p {
+			return true
+		}
+	}
+
+	return false
+}
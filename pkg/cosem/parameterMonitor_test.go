@@ -0,0 +1,81 @@
+package cosem_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParameterMonitor(t *testing.T) {
+	ratio := cosem.ValueDefinition{ClassID: 1, LogicalName: *dlms.CreateObis("1.0.0.4.2.255"), AttributeID: 2}
+	threshold := cosem.ValueDefinition{ClassID: cosem.LimiterClassID, LogicalName: *dlms.CreateObis("0.0.17.0.0.255"), AttributeID: 4}
+	parameters := []cosem.ValueDefinition{ratio}
+
+	parameterValue := func(p cosem.ValueDefinition, value *axdr.DlmsData) *axdr.DlmsData {
+		return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+			axdr.CreateAxdrLongUnsigned(p.ClassID),
+			axdr.CreateAxdrOctetString(p.LogicalName.String()),
+			axdr.CreateAxdrInteger(p.AttributeID),
+			value,
+		})
+	}
+	decode := func(data *axdr.DlmsData) cosem.ValueDefinition {
+		fields := data.Value.([]*axdr.DlmsData)
+		src, _ := hex.DecodeString(fields[1].Value.(string))
+		ln, _ := dlms.DecodeObis(&src)
+		return cosem.ValueDefinition{ClassID: fields[0].Value.(uint16), LogicalName: ln, AttributeID: fields[2].Value.(int8)}
+	}
+
+	o := dlmsserver.NewObject(cosem.ParameterMonitorClassID, 0, "0.0.16.2.0.255").
+		SetValue(2, *parameterValue(ratio, axdr.CreateAxdrLongUnsigned(200)), false).
+		SetValue(3, *axdr.CreateAxdrOctetString("07e8010101000000ff800000"), false).
+		SetAttribute(4, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			list := make([]*axdr.DlmsData, len(parameters))
+			for i, p := range parameters {
+				list[i] = p.Data()
+			}
+			return *axdr.CreateAxdrArray(list), dlms.TagAccSuccess
+		}, nil).
+		SetValue(5, *axdr.CreateAxdrArray([]*axdr.DlmsData{parameterValue(ratio, axdr.CreateAxdrLongUnsigned(200))}), false).
+		SetMethod(1, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			parameters = append(parameters, decode(data))
+			return nil, dlms.TagActSuccess
+		}).
+		SetMethod(2, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			p := decode(data)
+			for i := range parameters {
+				if parameters[i] == p {
+					parameters = append(parameters[:i], parameters[i+1:]...)
+					return nil, dlms.TagActSuccess
+				}
+			}
+			return nil, dlms.TagActObjectUndefined
+		})
+
+	m := cosem.NewParameterMonitor(connect(t, o), "0.0.16.2.0.255")
+
+	v, err := m.Read()
+	require.NoError(t, err)
+	assert.Equal(t, ratio, v.ChangedParameter.Parameter)
+	assert.Equal(t, *axdr.CreateAxdrLongUnsigned(200), v.ChangedParameter.Value)
+	assert.Equal(t, []cosem.ValueDefinition{ratio}, v.Parameters)
+	require.Len(t, v.ActiveParameters, 1)
+
+	summary, err := m.Summary()
+	require.NoError(t, err)
+	assert.Equal(t, "parameter monitor 0.0.16.2.0.255\n"+
+		"last change { 1, 1.0.0.4.2.255, 2 } = 200 at 2024-01-01T00:00:00Z\n"+
+		"monitors { 1, 1.0.0.4.2.255, 2 } = 200", summary)
+
+	require.NoError(t, m.SetParameters([]cosem.ValueDefinition{threshold}))
+	assert.Equal(t, []cosem.ValueDefinition{threshold}, parameters)
+
+	assertErrorCode(t, m.SetParameters([]cosem.ValueDefinition{threshold, threshold}), dlms.ErrorInvalidParameter)
+	assertErrorCode(t, m.SetParameters([]cosem.ValueDefinition{{ClassID: 1, LogicalName: ratio.LogicalName, AttributeID: 1}}), dlms.ErrorInvalidParameter)
+}
@@ -0,0 +1,469 @@
+package cosem
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const ProfileGenericClassID = 7
+
+// demandRegisterClassID is the class of the Demand Register, whose values have scaler and unit in attribute 4.
+const demandRegisterClassID = 5
+
+const (
+	profileBuffer         = 2
+	profileCaptureObjects = 3
+	profileCapturePeriod  = 4
+	profileSortMethod     = 5
+	profileEntriesInUse   = 7
+	profileEntries        = 8
+	profileReset          = 1
+	profileCapture        = 2
+)
+
+type SortMethod uint8
+
+const (
+	SortMethodFIFO             SortMethod = 1
+	SortMethodLIFO             SortMethod = 2
+	SortMethodLargest          SortMethod = 3
+	SortMethodSmallest         SortMethod = 4
+	SortMethodNearestToZero    SortMethod = 5
+	SortMethodFarthestFromZero SortMethod = 6
+)
+
+func (s SortMethod) String() string {
+	switch s {
+	case SortMethodFIFO:
+		return "fifo"
+	case SortMethodLIFO:
+		return "lifo"
+	case SortMethodLargest:
+		return "largest"
+	case SortMethodSmallest:
+		return "smallest"
+	case SortMethodNearestToZero:
+		return "nearest_to_zero"
+	case SortMethodFarthestFromZero:
+		return "farthest_from_zero"
+	default:
+		return ""
+	}
+}
+
+// CaptureObject is an attribute, or an element of it, captured in the buffer of a profile.
+type CaptureObject struct {
+	ClassID     uint16
+	LogicalName dlms.Obis
+	AttributeID int8
+	DataIndex   uint16
+}
+
+func (c CaptureObject) String() string {
+	if c.DataIndex != 0 {
+		return fmt.Sprintf("{ %d, %s, %d, %d }", c.ClassID, c.LogicalName.String(), c.AttributeID, c.DataIndex)
+	}
+
+	return fmt.Sprintf("{ %d, %s, %d }", c.ClassID, c.LogicalName.String(), c.AttributeID)
+}
+
+// Data returns the capture object definition as A-XDR data.
+func (c CaptureObject) Data() *axdr.DlmsData {
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrLongUnsigned(c.ClassID),
+		axdr.CreateAxdrOctetString(c.LogicalName.String()),
+		axdr.CreateAxdrInteger(c.AttributeID),
+		axdr.CreateAxdrLongUnsigned(c.DataIndex),
+	})
+}
+
+// DecodeCaptureObjects decodes the capture_objects attribute of a profile.
+func DecodeCaptureObjects(data axdr.DlmsData) ([]CaptureObject, error) {
+	elements, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]CaptureObject, 0, len(elements))
+	for i, element := range elements {
+		fields, err := dlms.DataAsSlice(*element, axdr.TagStructure, 4)
+		if err != nil {
+			return nil, fmt.Errorf("capture object %d: %w", i, err)
+		}
+
+		classID, ok1 := fields[0].Value.(uint16)
+		attributeID, ok2 := fields[2].Value.(int8)
+		dataIndex, ok3 := fields[3].Value.(uint16)
+		if !ok1 || !ok2 || !ok3 {
+			return nil, fmt.Errorf("capture object %d: invalid definition", i)
+		}
+
+		ln, err := dlms.DecodeLogicalName(*fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("capture object %d: %w", i, err)
+		}
+
+		out = append(out, CaptureObject{ClassID: classID, LogicalName: ln, AttributeID: attributeID, DataIndex: dataIndex})
+	}
+
+	return out, nil
+}
+
+// ProfileValue is a captured value. ScalerUnit is nil if the captured attribute has no scaler and unit.
+type ProfileValue struct {
+	CaptureObject CaptureObject
+	Value         axdr.DlmsData
+	ScalerUnit    *ScalerUnit
+}
+
+// Float returns the scaled value.
+func (v ProfileValue) Float() (float64, error) {
+	return v.scalerUnit().Float(v.Value)
+}
+
+// Decimal returns the scaled value as a decimal string, without loss of precision.
+func (v ProfileValue) Decimal() (string, error) {
+	return v.scalerUnit().Decimal(v.Value)
+}
+
+func (v ProfileValue) scalerUnit() ScalerUnit {
+	if v.ScalerUnit == nil {
+		return ScalerUnit{Scaler: 0, Unit: UnitCount}
+	}
+
+	return *v.ScalerUnit
+}
+
+// ProfileRow is an entry of the buffer of a profile, with a value per capture object.
+type ProfileRow []ProfileValue
+
+// Find returns the value of the given attribute, captured as a whole (data index 0), or nil if it's not
+// captured.
+func (r ProfileRow) Find(classID uint16, logicalName string, attributeID int8) *ProfileValue {
+	return r.find(*dlms.CreateAttributeDescriptor(classID, logicalName, attributeID), 0)
+}
+
+func (r ProfileRow) find(att dlms.AttributeDescriptor, dataIndex uint16) *ProfileValue {
+	for i := range r {
+		c := r[i].CaptureObject
+		if c.ClassID == att.ClassID && c.LogicalName == att.InstanceID && c.AttributeID == att.AttributeID && c.DataIndex == dataIndex {
+			return &r[i]
+		}
+	}
+
+	return nil
+}
+
+// Unmarshal stores the row in the struct pointed by v. Fields are matched with the capture objects by their
+// obis tag, "class,logical name,attribute", and its data index option (see dlms.ObisTag, whose other options
+// are ignored, as are fields tagged with a method). Float fields get the value scaled with the scale option,
+// or with the scaler of the capture object if not set; others get the raw one.
+func (r ProfileRow) Unmarshal(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return dlms.NewError(dlms.ErrorInvalidParameter, "data must be a pointer to a struct")
+	}
+
+	fields, err := dlms.ObisFields(rv.Elem().Type())
+	if err != nil {
+		return err
+	}
+
+	return r.unmarshalFields(fields, rv.Elem())
+}
+
+func (r ProfileRow) unmarshalFields(fields []dlms.ObisField, v reflect.Value) error {
+	for _, f := range fields {
+		// Methods aren't captured
+		if f.Tag.Attribute == nil {
+			continue
+		}
+
+		value := r.find(*f.Tag.Attribute, f.Tag.DataIndex)
+		if value == nil || value.Value.Tag == axdr.TagNull {
+			continue
+		}
+
+		err := value.unmarshal(f.Tag, v.FieldByIndex(f.Index))
+		if err != nil {
+			return dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("error unmarshaling %s data: %v", value.CaptureObject.String(), err))
+		}
+	}
+
+	return nil
+}
+
+func (v ProfileValue) unmarshal(ot dlms.ObisTag, field reflect.Value) error {
+	target := field
+	if field.Kind() == reflect.Ptr {
+		target = reflect.New(field.Type().Elem()).Elem()
+	}
+
+	switch target.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f, err := v.Float()
+		if ot.Scaled {
+			f, err = ScalerUnit{Scaler: 0, Unit: UnitCount}.Float(v.Value)
+			f *= math.Pow10(ot.Scale)
+		}
+		if err != nil {
+			return err
+		}
+		target.SetFloat(f)
+	default:
+		err := axdr.UnmarshalData(v.Value, target.Addr().Interface())
+		if err != nil {
+			return err
+		}
+	}
+
+	if field.Kind() == reflect.Ptr {
+		field.Set(target.Addr())
+	}
+
+	return nil
+}
+
+// Profile is the content of the buffer of a profile.
+type Profile struct {
+	CaptureObjects []CaptureObject
+	Rows           []ProfileRow
+}
+
+// Unmarshal stores the rows in the slice of structs pointed by v. See ProfileRow.Unmarshal.
+func (p Profile) Unmarshal(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return dlms.NewError(dlms.ErrorInvalidParameter, "data must be a pointer to a slice")
+	}
+
+	elementType := rv.Elem().Type().Elem()
+	if elementType.Kind() == reflect.Ptr {
+		elementType = elementType.Elem()
+	}
+
+	if elementType.Kind() != reflect.Struct {
+		return dlms.NewError(dlms.ErrorInvalidParameter, "data must be a pointer to a slice of structs")
+	}
+
+	fields, err := dlms.ObisFields(elementType)
+	if err != nil {
+		return err
+	}
+
+	slice := reflect.MakeSlice(rv.Elem().Type(), len(p.Rows), len(p.Rows))
+	for i, row := range p.Rows {
+		element := slice.Index(i)
+		if element.Kind() == reflect.Ptr {
+			element.Set(reflect.New(elementType))
+			element = element.Elem()
+		}
+
+		if err := row.unmarshalFields(fields, element); err != nil {
+			return err
+		}
+	}
+
+	rv.Elem().Set(slice)
+	return nil
+}
+
+// ProfileGeneric is an instance of the Profile Generic interface class (class_id 7). The scaler and unit
+// of the captured registers are read once and kept for the following reads.
+type ProfileGeneric struct {
+	object
+	scalerUnits map[CaptureObject]*ScalerUnit
+}
+
+func NewProfileGeneric(client dlms.Client, logicalName string) *ProfileGeneric {
+	return &ProfileGeneric{
+		object:      object{client: client, classID: ProfileGenericClassID, logicalName: logicalName},
+		scalerUnits: make(map[CaptureObject]*ScalerUnit),
+	}
+}
+
+// CaptureObjects reads the attributes captured in each entry of the buffer.
+func (p *ProfileGeneric) CaptureObjects() ([]CaptureObject, error) {
+	var data axdr.DlmsData
+
+	err := p.get(profileCaptureObjects, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	captureObjects, err := DecodeCaptureObjects(data)
+	if err != nil {
+		return nil, p.invalidData(profileCaptureObjects, err)
+	}
+
+	return captureObjects, nil
+}
+
+// CapturePeriod reads the capturing period in seconds. Zero means that capturing isn't periodic.
+func (p *ProfileGeneric) CapturePeriod() (period uint32, err error) {
+	err = p.get(profileCapturePeriod, &period)
+	return
+}
+
+// SortMethod reads how the entries of the buffer are sorted.
+func (p *ProfileGeneric) SortMethod() (SortMethod, error) {
+	var method uint8
+
+	err := p.get(profileSortMethod, &method)
+	return SortMethod(method), err
+}
+
+// EntriesInUse reads the number of entries stored in the buffer.
+func (p *ProfileGeneric) EntriesInUse() (entries uint32, err error) {
+	err = p.get(profileEntriesInUse, &entries)
+	return
+}
+
+// ProfileEntries reads the maximum number of entries of the buffer.
+func (p *ProfileGeneric) ProfileEntries() (entries uint32, err error) {
+	err = p.get(profileEntries, &entries)
+	return
+}
+
+// Read reads the capture objects and the whole buffer.
+func (p *ProfileGeneric) Read() (Profile, error) {
+	return p.read(nil)
+}
+
+// ReadByDate reads the capture objects and the entries of the buffer captured between start and end.
+func (p *ProfileGeneric) ReadByDate(start time.Time, end time.Time) (Profile, error) {
+	return p.read(p.bufferByDate(start, end))
+}
+
+func (p *ProfileGeneric) bufferByDate(start time.Time, end time.Time) func(data *axdr.DlmsData) error {
+	return func(data *axdr.DlmsData) error {
+		return p.client.GetRequestWithSelectiveAccessByDate(p.attribute(profileBuffer), start, end, data)
+	}
+}
+
+// Reset clears the buffer.
+func (p *ProfileGeneric) Reset() error {
+	return p.action(profileReset, int8(0))
+}
+
+// Capture captures the values of the capture objects into a new entry of the buffer.
+func (p *ProfileGeneric) Capture() error {
+	return p.action(profileCapture, int8(0))
+}
+
+func (p *ProfileGeneric) read(getBuffer func(data *axdr.DlmsData) error) (profile Profile, err error) {
+	captureObjects, err := p.CaptureObjects()
+	if err != nil {
+		return
+	}
+
+	return p.readBuffer(captureObjects, getBuffer)
+}
+
+// readBuffer reads the buffer, whose entries are expected to hold the given capture objects.
+func (p *ProfileGeneric) readBuffer(captureObjects []CaptureObject, getBuffer func(data *axdr.DlmsData) error) (profile Profile, err error) {
+	profile.CaptureObjects = captureObjects
+
+	scalerUnits, err := p.readScalerUnits(captureObjects)
+	if err != nil {
+		return
+	}
+
+	var data axdr.DlmsData
+	if getBuffer != nil {
+		err = getBuffer(&data)
+	} else {
+		err = p.get(profileBuffer, &data)
+	}
+	if err != nil {
+		return
+	}
+
+	profile.Rows, err = decodeBuffer(data, captureObjects, scalerUnits)
+	if err != nil {
+		err = p.invalidData(profileBuffer, err)
+	}
+
+	return
+}
+
+// readScalerUnits reads the scaler and unit of the captured values that have one.
+func (p *ProfileGeneric) readScalerUnits(captureObjects []CaptureObject) ([]*ScalerUnit, error) {
+	out := make([]*ScalerUnit, len(captureObjects))
+
+	for i, c := range captureObjects {
+		attributeID := scalerUnitAttribute(c)
+		if attributeID == 0 {
+			continue
+		}
+
+		su, ok := p.scalerUnits[c]
+		if !ok {
+			o := object{client: p.client, classID: c.ClassID, logicalName: c.LogicalName.String()}
+
+			var data axdr.DlmsData
+			err := o.get(attributeID, &data)
+			if err == nil {
+				var value ScalerUnit
+				value, err = DecodeScalerUnit(data)
+				if err != nil {
+					return nil, o.invalidData(attributeID, err)
+				}
+				su = &value
+			} else if !isGetRejected(err) {
+				return nil, err
+			}
+
+			p.scalerUnits[c] = su
+		}
+
+		out[i] = su
+	}
+
+	return out, nil
+}
+
+// scalerUnitAttribute returns the attribute with the scaler and unit of a captured value, or 0 if it has none.
+func scalerUnitAttribute(c CaptureObject) int8 {
+	switch {
+	case (c.ClassID == RegisterClassID || c.ClassID == ExtendedRegisterClassID) && c.AttributeID == registerValue:
+		return registerScalerUnit
+	case c.ClassID == demandRegisterClassID && (c.AttributeID == 2 || c.AttributeID == 3):
+		return 4
+	default:
+		return 0
+	}
+}
+
+func decodeBuffer(data axdr.DlmsData, captureObjects []CaptureObject, scalerUnits []*ScalerUnit) ([]ProfileRow, error) {
+	entries, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]ProfileRow, 0, len(entries))
+	for i, entry := range entries {
+		values, err := dlms.DataAsSlice(*entry, axdr.TagStructure, 0)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
+
+		if len(values) != len(captureObjects) {
+			return nil, fmt.Errorf("entry %d has %d values, expecting %d", i, len(values), len(captureObjects))
+		}
+
+		row := make(ProfileRow, len(values))
+		for j, value := range values {
+			row[j] = ProfileValue{CaptureObject: captureObjects[j], Value: *value, ScalerUnit: scalerUnits[j]}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
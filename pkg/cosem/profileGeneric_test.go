@@ -0,0 +1,172 @@
+package cosem_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLoadProfile(t *testing.T) []*dlmsserver.Object {
+	t.Helper()
+
+	captureObjects := []cosem.CaptureObject{
+		{ClassID: 8, LogicalName: *dlms.CreateObis("0.0.1.0.0.255"), AttributeID: 2},
+		{ClassID: 1, LogicalName: *dlms.CreateObis("0.0.96.10.1.255"), AttributeID: 2},
+		{ClassID: 3, LogicalName: *dlms.CreateObis("1.0.1.8.0.255"), AttributeID: 2},
+	}
+
+	definitions := make([]*axdr.DlmsData, len(captureObjects))
+	for i, c := range captureObjects {
+		definitions[i] = c.Data()
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := make([]*axdr.DlmsData, 3)
+	for i := range entries {
+		entries[i] = axdr.CreateAxdrStructure([]*axdr.DlmsData{
+			axdr.CreateAxdrOctetString(start.Add(time.Duration(i) * 15 * time.Minute)),
+			axdr.CreateAxdrUnsigned(uint8(i)),
+			axdr.CreateAxdrDoubleLongUnsigned(uint32(1000 + i*25)),
+		})
+	}
+
+	profile := dlmsserver.NewObject(cosem.ProfileGenericClassID, 1, "1.0.99.1.0.255").
+		SetAttribute(2, func(_ dlms.AttributeDescriptor, acc *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			if acc != nil {
+				return *axdr.CreateAxdrArray(entries[1:]), dlms.TagAccSuccess
+			}
+			return *axdr.CreateAxdrArray(entries), dlms.TagAccSuccess
+		}, nil).
+		SetValue(3, *axdr.CreateAxdrArray(definitions), false).
+		SetValue(4, *axdr.CreateAxdrDoubleLongUnsigned(900), false).
+		SetValue(5, *axdr.CreateAxdrEnum(1), false).
+		SetValue(7, *axdr.CreateAxdrDoubleLongUnsigned(3), false).
+		SetValue(8, *axdr.CreateAxdrDoubleLongUnsigned(2880), false)
+
+	register := dlmsserver.NewObject(cosem.RegisterClassID, 0, "1.0.1.8.0.255").
+		SetValue(3, *cosem.ScalerUnit{Scaler: -1, Unit: cosem.UnitActiveEnergy}.Data(), false)
+
+	return []*dlmsserver.Object{profile, register}
+}
+
+func TestProfileGeneric(t *testing.T) {
+	p := cosem.NewProfileGeneric(connect(t, newLoadProfile(t)...), "1.0.99.1.0.255")
+
+	period, err := p.CapturePeriod()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(900), period)
+
+	method, err := p.SortMethod()
+	assert.NoError(t, err)
+	assert.Equal(t, cosem.SortMethodFIFO, method)
+
+	entries, err := p.EntriesInUse()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(3), entries)
+
+	entries, err = p.ProfileEntries()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2880), entries)
+
+	profile, err := p.Read()
+	require.NoError(t, err)
+	require.Len(t, profile.CaptureObjects, 3)
+	require.Len(t, profile.Rows, 3)
+
+	energy := profile.Rows[1].Find(3, "1-0:1.8.0.255", 2)
+	require.NotNil(t, energy)
+	require.NotNil(t, energy.ScalerUnit)
+	assert.Equal(t, cosem.UnitActiveEnergy, energy.ScalerUnit.Unit)
+
+	d, err := energy.Decimal()
+	assert.NoError(t, err)
+	assert.Equal(t, "102.5", d)
+
+	status := profile.Rows[1].Find(1, "0.0.96.10.1.255", 2)
+	require.NotNil(t, status)
+	assert.Nil(t, status.ScalerUnit)
+
+	assert.Nil(t, profile.Rows[1].Find(3, "1.0.2.8.0.255", 2))
+}
+
+func TestProfileGeneric_Unmarshal(t *testing.T) {
+	type entry struct {
+		Energy    float64   `obis:"3,1-0:1.8.0.255,2"`
+		RawEnergy uint32    `obis:"3,1-0:1.8.0.255,2"`
+		Status    *uint8    `obis:"1,0-0:96.10.1.255,2"`
+		Missing   *uint32   `obis:"3,1-0:2.8.0.255,2"`
+		Time      time.Time `obis:"8,0-0:1.0.0.255,2"`
+	}
+
+	p := cosem.NewProfileGeneric(connect(t, newLoadProfile(t)...), "1.0.99.1.0.255")
+
+	profile, err := p.ReadByDate(time.Date(2024, 1, 1, 0, 15, 0, 0, time.UTC), time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	var entries []entry
+	require.NoError(t, profile.Unmarshal(&entries))
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, 102.5, entries[0].Energy)
+	assert.Equal(t, uint32(1025), entries[0].RawEnergy)
+	require.NotNil(t, entries[0].Status)
+	assert.Equal(t, uint8(1), *entries[0].Status)
+	assert.Nil(t, entries[0].Missing)
+	assert.True(t, time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC).Equal(entries[1].Time))
+
+	var invalid []int
+	assert.Error(t, profile.Unmarshal(&invalid))
+}
+
+func TestProfileRow_Unmarshal(t *testing.T) {
+	energy := cosem.CaptureObject{ClassID: 3, LogicalName: *dlms.CreateObis("1.0.1.8.0.255"), AttributeID: 2}
+	tariff := energy
+	tariff.DataIndex = 1
+
+	row := cosem.ProfileRow{
+		{CaptureObject: energy, Value: *axdr.CreateAxdrDoubleLongUnsigned(1025), ScalerUnit: &cosem.ScalerUnit{Scaler: -1, Unit: cosem.UnitCount}},
+		{CaptureObject: tariff, Value: *axdr.CreateAxdrDoubleLongUnsigned(300), ScalerUnit: &cosem.ScalerUnit{Scaler: -1, Unit: cosem.UnitCount}},
+	}
+
+	// Capture objects with the same attribute are told apart by their data index
+	var entry struct {
+		Energy       float64 `obis:"3,1-0:1.8.0.255,2"`
+		EnergyScaled float64 `obis:"3,1-0:1.8.0.255,2,scale=-3"`
+		Tariff       uint32  `obis:"3,1-0:1.8.0.255,2,index=1"`
+	}
+	require.NoError(t, row.Unmarshal(&entry))
+
+	assert.Equal(t, 102.5, entry.Energy)
+	assert.Equal(t, 1.025, entry.EnergyScaled)
+	assert.Equal(t, uint32(300), entry.Tariff)
+
+	var invalid struct {
+		Energy uint32 `obis:"3,1-0:1.8.0.255,2,scale=-3"`
+	}
+	assert.Error(t, row.Unmarshal(&invalid))
+}
+
+func TestDecodeCaptureObjects(t *testing.T) {
+	c := cosem.CaptureObject{ClassID: 3, LogicalName: *dlms.CreateObis("1.0.1.8.0.255"), AttributeID: 2, DataIndex: 1}
+
+	src, err := axdr.CreateAxdrArray([]*axdr.DlmsData{c.Data()}).Encode()
+	require.NoError(t, err)
+
+	decoder := axdr.NewDataDecoder(&src)
+	data, err := decoder.Decode(&src)
+	require.NoError(t, err)
+
+	out, err := cosem.DecodeCaptureObjects(data)
+	require.NoError(t, err)
+	assert.Equal(t, []cosem.CaptureObject{c}, out)
+	assert.Equal(t, "{ 3, 1.0.1.8.0.255, 2, 1 }", out[0].String())
+
+	_, err = cosem.DecodeCaptureObjects(*axdr.CreateAxdrArray([]*axdr.DlmsData{axdr.CreateAxdrUnsigned(1)}))
+	assert.Error(t, err)
+}
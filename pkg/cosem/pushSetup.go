@@ -0,0 +1,426 @@
+package cosem
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const PushSetupClassID = 40
+
+const (
+	pushObjectList               = 2
+	pushSendDestinationAndMethod = 3
+	pushCommunicationWindow      = 4
+	pushRandomisationStart       = 5
+	pushNumberOfRetries          = 6
+	pushRepetitionDelay          = 7
+	pushPush                     = 1
+)
+
+// TransportService is the service used to send the pushed data.
+type TransportService uint8
+
+const (
+	TransportServiceTCP    TransportService = 0
+	TransportServiceUDP    TransportService = 1
+	TransportServiceFTP    TransportService = 2
+	TransportServiceSMTP   TransportService = 3
+	TransportServiceSMS    TransportService = 4
+	TransportServiceHDLC   TransportService = 5
+	TransportServiceMBus   TransportService = 6
+	TransportServiceZigBee TransportService = 7
+)
+
+func (s TransportService) String() string {
+	switch s {
+	case TransportServiceTCP:
+		return "TCP"
+	case TransportServiceUDP:
+		return "UDP"
+	case TransportServiceFTP:
+		return "FTP"
+	case TransportServiceSMTP:
+		return "SMTP"
+	case TransportServiceSMS:
+		return "SMS"
+	case TransportServiceHDLC:
+		return "HDLC"
+	case TransportServiceMBus:
+		return "M-Bus"
+	case TransportServiceZigBee:
+		return "ZigBee"
+	default:
+		return ""
+	}
+}
+
+// MessageType is the encoding of the pushed data.
+type MessageType uint8
+
+const (
+	MessageTypeAXDR MessageType = 0
+	MessageTypeXML  MessageType = 1
+)
+
+// SendDestinationAndMethod defines where and how the data is pushed.
+type SendDestinationAndMethod struct {
+	TransportService TransportService
+	Destination      string
+	Message          MessageType
+}
+
+// Data returns the destination and method as A-XDR data.
+func (s SendDestinationAndMethod) Data() *axdr.DlmsData {
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrEnum(uint8(s.TransportService)),
+		createOctetString([]byte(s.Destination)),
+		axdr.CreateAxdrEnum(uint8(s.Message)),
+	})
+}
+
+func decodeSendDestinationAndMethod(data axdr.DlmsData) (s SendDestinationAndMethod, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 3)
+	if err != nil {
+		return
+	}
+
+	service, ok1 := fields[0].Value.(uint8)
+	message, ok2 := fields[2].Value.(uint8)
+	if !ok1 || !ok2 {
+		err = fmt.Errorf("invalid send destination and method")
+		return
+	}
+
+	destination, err := octetString(*fields[1], 0)
+	if err != nil {
+		return
+	}
+
+	return SendDestinationAndMethod{TransportService: TransportService(service), Destination: string(destination), Message: MessageType(message)}, nil
+}
+
+// CommunicationWindow is a period in which the data can be pushed. Dates usually have wildcards.
+type CommunicationWindow struct {
+	Start DateTime
+	End   DateTime
+}
+
+// Data returns the communication window as A-XDR data.
+func (w CommunicationWindow) Data() *axdr.DlmsData {
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{w.Start.Data(), w.End.Data()})
+}
+
+func decodeCommunicationWindow(data axdr.DlmsData) (w CommunicationWindow, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 2)
+	if err != nil {
+		return
+	}
+
+	if w.Start, err = DecodeDateTime(*fields[0]); err != nil {
+		return
+	}
+
+	w.End, err = DecodeDateTime(*fields[1])
+	return
+}
+
+// PushSetupValue holds the attributes of a push setup.
+type PushSetupValue struct {
+	PushObjectList             []CaptureObject
+	SendDestinationAndMethod   SendDestinationAndMethod
+	CommunicationWindow        []CommunicationWindow
+	RandomisationStartInterval time.Duration
+	NumberOfRetries            uint8
+	RepetitionDelay            time.Duration
+}
+
+// PushSetup is an instance of the Push Setup interface class (class_id 40), version 0. The push object
+// list is read once and kept to decode the notifications.
+type PushSetup struct {
+	object
+	pushObjects []CaptureObject
+	mutex       sync.Mutex
+}
+
+func NewPushSetup(client dlms.Client, logicalName string) *PushSetup {
+	return &PushSetup{object: object{client: client, classID: PushSetupClassID, logicalName: logicalName}}
+}
+
+// Read reads all the attributes of the push setup.
+func (p *PushSetup) Read() (v PushSetupValue, err error) {
+	if v.PushObjectList, err = p.PushObjectList(); err != nil {
+		return
+	}
+	if v.SendDestinationAndMethod, err = p.SendDestinationAndMethod(); err != nil {
+		return
+	}
+	if v.CommunicationWindow, err = p.CommunicationWindow(); err != nil {
+		return
+	}
+	if v.RandomisationStartInterval, err = p.seconds(pushRandomisationStart); err != nil {
+		return
+	}
+	if err = p.get(pushNumberOfRetries, &v.NumberOfRetries); err != nil {
+		return
+	}
+
+	v.RepetitionDelay, err = p.seconds(pushRepetitionDelay)
+	return
+}
+
+// Write writes all the attributes of the push setup.
+func (p *PushSetup) Write(v PushSetupValue) error {
+	err := p.SetPushObjectList(v.PushObjectList)
+	if err != nil {
+		return err
+	}
+	if err = p.SetSendDestinationAndMethod(v.SendDestinationAndMethod); err != nil {
+		return err
+	}
+	if err = p.SetCommunicationWindow(v.CommunicationWindow); err != nil {
+		return err
+	}
+
+	return p.SetRetries(v.RandomisationStartInterval, v.NumberOfRetries, v.RepetitionDelay)
+}
+
+// PushObjectList reads the attributes sent in each push.
+func (p *PushSetup) PushObjectList() ([]CaptureObject, error) {
+	var data axdr.DlmsData
+
+	err := p.get(pushObjectList, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := DecodeCaptureObjects(data)
+	if err != nil {
+		return nil, p.invalidData(pushObjectList, err)
+	}
+
+	p.mutex.Lock()
+	p.pushObjects = objects
+	p.mutex.Unlock()
+
+	return objects, nil
+}
+
+// SetPushObjectList sets the attributes sent in each push.
+func (p *PushSetup) SetPushObjectList(objects []CaptureObject) error {
+	list := make([]*axdr.DlmsData, len(objects))
+	for i, o := range objects {
+		list[i] = o.Data()
+	}
+
+	err := p.set(pushObjectList, axdr.CreateAxdrArray(list))
+	if err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	p.pushObjects = append([]CaptureObject(nil), objects...)
+	p.mutex.Unlock()
+
+	return nil
+}
+
+// SendDestinationAndMethod reads where and how the data is pushed.
+func (p *PushSetup) SendDestinationAndMethod() (SendDestinationAndMethod, error) {
+	var data axdr.DlmsData
+
+	err := p.get(pushSendDestinationAndMethod, &data)
+	if err != nil {
+		return SendDestinationAndMethod{}, err
+	}
+
+	s, err := decodeSendDestinationAndMethod(data)
+	if err != nil {
+		return s, p.invalidData(pushSendDestinationAndMethod, err)
+	}
+
+	return s, nil
+}
+
+// SetSendDestinationAndMethod sets where and how the data is pushed.
+func (p *PushSetup) SetSendDestinationAndMethod(s SendDestinationAndMethod) error {
+	return p.set(pushSendDestinationAndMethod, s.Data())
+}
+
+// CommunicationWindow reads the periods in which the data can be pushed. Empty means always.
+func (p *PushSetup) CommunicationWindow() ([]CommunicationWindow, error) {
+	var data axdr.DlmsData
+
+	err := p.get(pushCommunicationWindow, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	elements, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return nil, p.invalidData(pushCommunicationWindow, err)
+	}
+
+	windows := make([]CommunicationWindow, len(elements))
+	for i, e := range elements {
+		if windows[i], err = decodeCommunicationWindow(*e); err != nil {
+			return nil, p.invalidData(pushCommunicationWindow, err)
+		}
+	}
+
+	return windows, nil
+}
+
+// SetCommunicationWindow sets the periods in which the data can be pushed.
+func (p *PushSetup) SetCommunicationWindow(windows []CommunicationWindow) error {
+	list := make([]*axdr.DlmsData, len(windows))
+	for i, w := range windows {
+		list[i] = w.Data()
+	}
+
+	return p.set(pushCommunicationWindow, axdr.CreateAxdrArray(list))
+}
+
+// SetRetries sets the maximum random delay before pushing, and how many times and how often the push is
+// retried if it fails.
+func (p *PushSetup) SetRetries(randomisation time.Duration, retries uint8, delay time.Duration) error {
+	err := p.set(pushRandomisationStart, uint16(randomisation/time.Second))
+	if err != nil {
+		return err
+	}
+	if err = p.set(pushNumberOfRetries, retries); err != nil {
+		return err
+	}
+
+	return p.set(pushRepetitionDelay, uint16(delay/time.Second))
+}
+
+// Push makes the meter push the data.
+func (p *PushSetup) Push() error {
+	return p.action(pushPush, int8(0))
+}
+
+// Decode decodes a notification pushed by this push setup. The push object list is read if it hasn't
+// been read yet.
+func (p *PushSetup) Decode(dn dlms.DataNotification) (Push, error) {
+	p.mutex.Lock()
+	objects := p.pushObjects
+	p.mutex.Unlock()
+
+	if objects == nil {
+		var err error
+		if objects, err = p.PushObjectList(); err != nil {
+			return Push{}, err
+		}
+	}
+
+	return DecodePush(objects, dn)
+}
+
+func (p *PushSetup) seconds(attributeID int8) (time.Duration, error) {
+	var seconds uint16
+
+	err := p.get(attributeID, &seconds)
+	return time.Duration(seconds) * time.Second, err
+}
+
+// Push is a notification decoded with the push object list of the push setup that sent it.
+type Push struct {
+	Time   *time.Time
+	Values ProfileRow
+}
+
+// Unmarshal stores the pushed values in the struct pointed by v. See ProfileRow.Unmarshal.
+func (p Push) Unmarshal(v interface{}) error {
+	return p.Values.Unmarshal(v)
+}
+
+// DecodePush decodes a notification with the push object list that produced it. The notification must
+// hold a structure with a value per push object.
+func DecodePush(objects []CaptureObject, dn dlms.DataNotification) (Push, error) {
+	values, err := dlms.DataAsSlice(dn.DataValue, axdr.TagStructure, len(objects))
+	if err != nil {
+		return Push{}, dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("push doesn't match the push object list: %v", err))
+	}
+
+	push := Push{Time: dn.DateTime, Values: make(ProfileRow, len(objects))}
+	for i, o := range objects {
+		push.Values[i] = ProfileValue{CaptureObject: o, Value: *values[i]}
+	}
+
+	return push, nil
+}
+
+// PushDecoder decodes the notifications of several push setups.
+type PushDecoder struct {
+	pushObjects map[dlms.Obis][]CaptureObject
+	mutex       sync.RWMutex
+}
+
+func NewPushDecoder() *PushDecoder {
+	return &PushDecoder{pushObjects: make(map[dlms.Obis][]CaptureObject)}
+}
+
+// Add adds the push object list of a push setup.
+func (d *PushDecoder) Add(logicalName string, objects []CaptureObject) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.pushObjects[*dlms.CreateObis(logicalName)] = objects
+}
+
+// Decode decodes a notification with the push object list of the push setup that sent it. If the list
+// includes the logical name of the push setup, as usual, it's used to identify it. Otherwise, the list
+// must be the only one with the length of the notification.
+func (d *PushDecoder) Decode(dn dlms.DataNotification) (Push, error) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	values, err := dlms.DataAsSlice(dn.DataValue, axdr.TagStructure, 0)
+	if err != nil {
+		return Push{}, dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("invalid push: %v", err))
+	}
+
+	var candidates [][]CaptureObject
+	for ln, objects := range d.pushObjects {
+		if len(objects) != len(values) {
+			continue
+		}
+
+		identified, matches := identifyPush(ln, objects, values)
+		if identified && matches {
+			return DecodePush(objects, dn)
+		}
+		if !identified {
+			candidates = append(candidates, objects)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return Push{}, dlms.NewError(dlms.ErrorInvalidResponse, "push doesn't match any push object list")
+	case 1:
+		return DecodePush(candidates[0], dn)
+	default:
+		return Push{}, dlms.NewError(dlms.ErrorInvalidResponse, "push matches several push object lists")
+	}
+}
+
+// identifyPush returns if the push object list includes the logical name of its push setup and, in that
+// case, if it matches the pushed value.
+func identifyPush(ln dlms.Obis, objects []CaptureObject, values []*axdr.DlmsData) (identified bool, matches bool) {
+	for i, o := range objects {
+		if o.ClassID != PushSetupClassID || o.LogicalName != ln || o.AttributeID != 1 {
+			continue
+		}
+
+		str, ok := values[i].Value.(string)
+		return true, ok && str == hex.EncodeToString(ln.Bytes())
+	}
+
+	return false, false
+}
@@ -0,0 +1,137 @@
+package cosem_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const alarmPushSetup = "0.4.25.9.0.255"
+
+func alarmPushObjects() []cosem.CaptureObject {
+	return []cosem.CaptureObject{
+		{ClassID: cosem.PushSetupClassID, LogicalName: *dlms.CreateObis(alarmPushSetup), AttributeID: 1},
+		{ClassID: 1, LogicalName: *dlms.CreateObis("0.0.96.1.0.255"), AttributeID: 2},
+		{ClassID: 3, LogicalName: *dlms.CreateObis("1.0.1.8.0.255"), AttributeID: 2},
+	}
+}
+
+// alarmPush returns a notification as received from the meter.
+func alarmPush(t *testing.T, ln string, serial string, energy uint32) dlms.DataNotification {
+	tm := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	return received(t, *dlms.CreateDataNotification(1, &tm, *axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrOctetString(ln),
+		axdr.CreateAxdrVisibleString(serial),
+		axdr.CreateAxdrDoubleLongUnsigned(energy),
+	})))
+}
+
+func received(t *testing.T, dn dlms.DataNotification) dlms.DataNotification {
+	t.Helper()
+
+	src, err := dn.Encode()
+	require.NoError(t, err)
+
+	dn, err = dlms.DecodeDataNotification(&src)
+	require.NoError(t, err)
+
+	return dn
+}
+
+func TestPushSetup(t *testing.T) {
+	window := cosem.CommunicationWindow{
+		Start: cosem.DateTime{Deviation: cosem.DeviationNotSpecified, Status: 0xff, Raw: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0x08, 0x00, 0x00, 0x00, 0x80, 0x00, 0xff}},
+		End:   cosem.DateTime{Deviation: cosem.DeviationNotSpecified, Status: 0xff, Raw: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0x12, 0x00, 0x00, 0x00, 0x80, 0x00, 0xff}},
+	}
+
+	pushed := 0
+	o := dlmsserver.NewObject(cosem.PushSetupClassID, 0, alarmPushSetup).
+		SetValue(2, *axdr.CreateAxdrArray([]*axdr.DlmsData{}), true).
+		SetValue(3, *cosem.SendDestinationAndMethod{}.Data(), true).
+		SetValue(4, *axdr.CreateAxdrArray([]*axdr.DlmsData{}), true).
+		SetValue(5, *axdr.CreateAxdrLongUnsigned(0), true).
+		SetValue(6, *axdr.CreateAxdrUnsigned(0), true).
+		SetValue(7, *axdr.CreateAxdrLongUnsigned(0), true).
+		SetMethod(1, func(dlms.MethodDescriptor, *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			pushed++
+			return nil, dlms.TagActSuccess
+		})
+
+	p := cosem.NewPushSetup(connect(t, o), alarmPushSetup)
+
+	v := cosem.PushSetupValue{
+		PushObjectList:             alarmPushObjects(),
+		SendDestinationAndMethod:   cosem.SendDestinationAndMethod{TransportService: cosem.TransportServiceTCP, Destination: "10.0.0.1:4059", Message: cosem.MessageTypeAXDR},
+		CommunicationWindow:        []cosem.CommunicationWindow{window},
+		RandomisationStartInterval: 30 * time.Second,
+		NumberOfRetries:            3,
+		RepetitionDelay:            time.Minute,
+	}
+	require.NoError(t, p.Write(v))
+
+	read, err := p.Read()
+	require.NoError(t, err)
+	assert.Equal(t, v, read)
+
+	require.NoError(t, p.Push())
+	assert.Equal(t, 1, pushed)
+
+	push, err := p.Decode(alarmPush(t, alarmPushSetup, "CIR0001", 1234))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), *push.Time)
+	require.Len(t, push.Values, 3)
+	assert.Equal(t, "CIR0001", push.Values.Find(1, "0.0.96.1.0.255", 2).Value.Value)
+
+	var alarm struct {
+		Serial string `obis:"1,0.0.96.1.0.255,2"`
+		Energy uint32 `obis:"3,1.0.1.8.0.255,2"`
+	}
+	require.NoError(t, push.Unmarshal(&alarm))
+	assert.Equal(t, "CIR0001", alarm.Serial)
+	assert.Equal(t, uint32(1234), alarm.Energy)
+
+	_, err = cosem.DecodePush(alarmPushObjects()[1:], alarmPush(t, alarmPushSetup, "CIR0001", 1234))
+	assertErrorCode(t, err, dlms.ErrorInvalidResponse)
+}
+
+func TestPushDecoder(t *testing.T) {
+	const otherPushSetup = "0.5.25.9.0.255"
+	other := alarmPushObjects()
+	other[0].LogicalName = *dlms.CreateObis(otherPushSetup)
+	other[2].LogicalName = *dlms.CreateObis("1.0.2.8.0.255")
+
+	d := cosem.NewPushDecoder()
+	d.Add(alarmPushSetup, alarmPushObjects())
+	d.Add(otherPushSetup, other)
+
+	push, err := d.Decode(alarmPush(t, otherPushSetup, "CIR0001", 10))
+	require.NoError(t, err)
+	assert.NotNil(t, push.Values.Find(3, "1.0.2.8.0.255", 2))
+
+	push, err = d.Decode(alarmPush(t, alarmPushSetup, "CIR0001", 10))
+	require.NoError(t, err)
+	assert.NotNil(t, push.Values.Find(3, "1.0.1.8.0.255", 2))
+
+	_, err = d.Decode(alarmPush(t, "0.6.25.9.0.255", "CIR0001", 10))
+	assertErrorCode(t, err, dlms.ErrorInvalidResponse)
+
+	// Without the logical name of the push setup, the length identifies the list
+	d = cosem.NewPushDecoder()
+	d.Add(alarmPushSetup, alarmPushObjects()[1:])
+	d.Add(otherPushSetup, other)
+
+	push, err = d.Decode(received(t, *dlms.CreateDataNotification(1, nil, *axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrVisibleString("CIR0001"),
+		axdr.CreateAxdrDoubleLongUnsigned(10),
+	}))))
+	require.NoError(t, err)
+	assert.Nil(t, push.Time)
+	assert.NotNil(t, push.Values.Find(3, "1.0.1.8.0.255", 2))
+}
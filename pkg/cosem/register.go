@@ -0,0 +1,160 @@
+package cosem
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const (
+	RegisterClassID         = 3
+	ExtendedRegisterClassID = 4
+)
+
+const (
+	registerValue       = 2
+	registerScalerUnit  = 3
+	registerStatus      = 4
+	registerCaptureTime = 5
+	registerReset       = 1
+)
+
+// RegisterValue is the value of a register together with its scaler and unit.
+type RegisterValue struct {
+	Value      axdr.DlmsData
+	ScalerUnit ScalerUnit
+}
+
+// Float returns the scaled value.
+func (v RegisterValue) Float() (float64, error) {
+	return v.ScalerUnit.Float(v.Value)
+}
+
+// Decimal returns the scaled value as a decimal string, without loss of precision.
+func (v RegisterValue) Decimal() (string, error) {
+	return v.ScalerUnit.Decimal(v.Value)
+}
+
+// String returns the scaled value followed by its unit.
+func (v RegisterValue) String() string {
+	value, err := v.Decimal()
+	if err != nil {
+		value = fmt.Sprintf("%v", v.Value.Value)
+	}
+
+	if unit := v.ScalerUnit.Unit.String(); unit != "" {
+		return value + " " + unit
+	}
+
+	return value
+}
+
+// Register is an instance of the Register interface class (class_id 3).
+type Register struct {
+	object
+}
+
+func NewRegister(client dlms.Client, logicalName string) *Register {
+	return &Register{object{client: client, classID: RegisterClassID, logicalName: logicalName}}
+}
+
+// Read reads the value and its scaler and unit.
+func (r *Register) Read() (v RegisterValue, err error) {
+	return readRegisterValue(r.object)
+}
+
+// ScalerUnit reads the scaler and unit of the register.
+func (r *Register) ScalerUnit() (ScalerUnit, error) {
+	return readScalerUnit(r.object)
+}
+
+// Reset sets the value to the default one.
+func (r *Register) Reset() error {
+	return r.action(registerReset, int8(0))
+}
+
+// ExtendedRegisterValue is the value of an extended register together with its status and capture time.
+type ExtendedRegisterValue struct {
+	RegisterValue
+	Status      axdr.DlmsData
+	CaptureTime time.Time
+}
+
+// ExtendedRegister is an instance of the Extended Register interface class (class_id 4).
+type ExtendedRegister struct {
+	object
+}
+
+func NewExtendedRegister(client dlms.Client, logicalName string) *ExtendedRegister {
+	return &ExtendedRegister{object{client: client, classID: ExtendedRegisterClassID, logicalName: logicalName}}
+}
+
+// Read reads the value, its scaler and unit, the status and the capture time.
+func (r *ExtendedRegister) Read() (v ExtendedRegisterValue, err error) {
+	v.RegisterValue, err = readRegisterValue(r.object)
+	if err != nil {
+		return
+	}
+
+	err = r.get(registerStatus, &v.Status)
+	if err != nil {
+		return
+	}
+
+	err = r.get(registerCaptureTime, &v.CaptureTime)
+	return
+}
+
+// ScalerUnit reads the scaler and unit of the register.
+func (r *ExtendedRegister) ScalerUnit() (ScalerUnit, error) {
+	return readScalerUnit(r.object)
+}
+
+// Reset sets the value to the default one, and the status and capture time to the reset values.
+func (r *ExtendedRegister) Reset() error {
+	return r.action(registerReset, int8(0))
+}
+
+// readRegisterValue reads the value and its scaler and unit together, with a get request with a
+// list if multiple references were negotiated.
+func readRegisterValue(o object) (v RegisterValue, err error) {
+	var data axdr.DlmsData
+
+	atts := []*dlms.AttributeDescriptor{o.attribute(registerValue), o.attribute(registerScalerUnit)}
+	errs, err := o.client.GetRequestWithList(atts, []interface{}{&v.Value, &data})
+	if err != nil {
+		return
+	}
+
+	for _, e := range errs {
+		if e != nil {
+			err = e
+			return
+		}
+	}
+
+	v.ScalerUnit, err = DecodeScalerUnit(data)
+	if err != nil {
+		err = o.invalidData(registerScalerUnit, err)
+	}
+
+	return
+}
+
+func readScalerUnit(o object) (su ScalerUnit, err error) {
+	var data axdr.DlmsData
+
+	err = o.get(registerScalerUnit, &data)
+	if err != nil {
+		return
+	}
+
+	su, err = DecodeScalerUnit(data)
+	if err != nil {
+		err = o.invalidData(registerScalerUnit, err)
+	}
+
+	return
+}
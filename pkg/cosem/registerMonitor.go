@@ -0,0 +1,204 @@
+package cosem
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const RegisterMonitorClassID = 21
+
+const (
+	registerMonitorThresholds     = 2
+	registerMonitorMonitoredValue = 3
+	registerMonitorActions        = 4
+)
+
+// ActionSet are the scripts executed when the monitored value crosses a threshold upwards or downwards.
+type ActionSet struct {
+	Up   ActionItem
+	Down ActionItem
+}
+
+// Data returns the action set as A-XDR data.
+func (a ActionSet) Data() *axdr.DlmsData {
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{a.Up.Data(), a.Down.Data()})
+}
+
+func decodeActionSet(data axdr.DlmsData) (a ActionSet, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 2)
+	if err != nil {
+		return
+	}
+
+	if a.Up, err = decodeActionItem(*fields[0]); err != nil {
+		return
+	}
+
+	a.Down, err = decodeActionItem(*fields[1])
+	return
+}
+
+// RegisterMonitorValue holds the attributes of a register monitor. Thresholds have the type of the
+// monitored value and there is an action set for each threshold.
+type RegisterMonitorValue struct {
+	Thresholds     []axdr.DlmsData
+	MonitoredValue ValueDefinition
+	Actions        []ActionSet
+}
+
+// Validate checks that there is an action set for each threshold and that the thresholds have the type
+// of monitored, a value of the monitored attribute. The type isn't checked if monitored is null. If
+// scripts is not nil, the scripts of the actions must be in the script table with its logical name.
+func (v RegisterMonitorValue) Validate(monitored axdr.DlmsData, scripts map[dlms.Obis][]Script) error {
+	if len(v.Thresholds) != len(v.Actions) {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("%d thresholds with %d action sets", len(v.Thresholds), len(v.Actions)))
+	}
+
+	for i, t := range v.Thresholds {
+		if monitored.Tag != axdr.TagNull && t.Tag != monitored.Tag {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("threshold %d has type %d, monitored value %s has type %d", i+1, t.Tag, v.MonitoredValue.String(), monitored.Tag))
+		}
+	}
+
+	if scripts == nil {
+		return nil
+	}
+
+	for i, a := range v.Actions {
+		for _, item := range []ActionItem{a.Up, a.Down} {
+			if err := item.validate(scripts); err != nil {
+				return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("action set %d references %v", i+1, err))
+			}
+		}
+	}
+
+	return nil
+}
+
+// String returns the configuration of the register monitor, one threshold per line.
+func (v RegisterMonitorValue) String() string {
+	return v.summary(ScalerUnit{})
+}
+
+func (v RegisterMonitorValue) summary(su ScalerUnit) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "monitored value %s", v.MonitoredValue.String())
+	for i, t := range v.Thresholds {
+		fmt.Fprintf(&b, "\nthreshold %d: %s", i+1, RegisterValue{Value: t, ScalerUnit: su}.String())
+		if i < len(v.Actions) {
+			fmt.Fprintf(&b, ", up: %s, down: %s", v.Actions[i].Up.String(), v.Actions[i].Down.String())
+		}
+	}
+
+	return b.String()
+}
+
+// RegisterMonitor is an instance of the Register monitor interface class (class_id 21).
+type RegisterMonitor struct {
+	object
+}
+
+func NewRegisterMonitor(client dlms.Client, logicalName string) *RegisterMonitor {
+	return &RegisterMonitor{object{client: client, classID: RegisterMonitorClassID, logicalName: logicalName}}
+}
+
+// Read reads all the attributes of the register monitor.
+func (m *RegisterMonitor) Read() (v RegisterMonitorValue, err error) {
+	var data axdr.DlmsData
+	if err = m.get(registerMonitorThresholds, &data); err != nil {
+		return
+	}
+
+	thresholds, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return v, m.invalidData(registerMonitorThresholds, err)
+	}
+
+	v.Thresholds = make([]axdr.DlmsData, len(thresholds))
+	for i, t := range thresholds {
+		v.Thresholds[i] = *t
+	}
+
+	if err = m.get(registerMonitorMonitoredValue, &data); err != nil {
+		return
+	}
+	if v.MonitoredValue, err = decodeValueDefinition(data); err != nil {
+		return v, m.invalidData(registerMonitorMonitoredValue, err)
+	}
+
+	if err = m.get(registerMonitorActions, &data); err != nil {
+		return
+	}
+
+	actions, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return v, m.invalidData(registerMonitorActions, err)
+	}
+
+	v.Actions = make([]ActionSet, len(actions))
+	for i, a := range actions {
+		if v.Actions[i], err = decodeActionSet(*a); err != nil {
+			return v, m.invalidData(registerMonitorActions, err)
+		}
+	}
+
+	return v, nil
+}
+
+// Write validates and writes all the attributes of the register monitor. The monitored attribute is
+// read from the meter to check the type of the thresholds. See RegisterMonitorValue.Validate for the
+// scripts.
+func (m *RegisterMonitor) Write(v RegisterMonitorValue, scripts map[dlms.Obis][]Script) error {
+	var monitored axdr.DlmsData
+
+	err := m.client.GetRequest(dlms.CreateAttributeDescriptor(v.MonitoredValue.ClassID, v.MonitoredValue.LogicalName.String(), v.MonitoredValue.AttributeID), &monitored)
+	if err != nil {
+		return err
+	}
+
+	if err = v.Validate(monitored, scripts); err != nil {
+		return err
+	}
+
+	thresholds := make([]*axdr.DlmsData, len(v.Thresholds))
+	for i := range v.Thresholds {
+		thresholds[i] = &v.Thresholds[i]
+	}
+
+	actions := make([]*axdr.DlmsData, len(v.Actions))
+	for i, a := range v.Actions {
+		actions[i] = a.Data()
+	}
+
+	if err = m.set(registerMonitorMonitoredValue, v.MonitoredValue.Data()); err != nil {
+		return err
+	}
+	if err = m.set(registerMonitorThresholds, axdr.CreateAxdrArray(thresholds)); err != nil {
+		return err
+	}
+
+	return m.set(registerMonitorActions, axdr.CreateAxdrArray(actions))
+}
+
+// Summary reads the register monitor and returns its configuration. If the monitored value is the
+// value of a register, the thresholds are scaled and shown with its unit.
+func (m *RegisterMonitor) Summary() (string, error) {
+	v, err := m.Read()
+	if err != nil {
+		return "", err
+	}
+
+	var su ScalerUnit
+	if (v.MonitoredValue.ClassID == RegisterClassID || v.MonitoredValue.ClassID == ExtendedRegisterClassID) && v.MonitoredValue.AttributeID == registerValue {
+		register := object{client: m.client, classID: v.MonitoredValue.ClassID, logicalName: v.MonitoredValue.LogicalName.String()}
+		if su, err = readScalerUnit(register); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("register monitor %s\n%s", m.logicalName, v.summary(su)), nil
+}
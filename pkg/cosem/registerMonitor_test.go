@@ -0,0 +1,67 @@
+package cosem_test
+
+import (
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterMonitor(t *testing.T) {
+	power := dlmsserver.NewObject(cosem.RegisterClassID, 0, "1.0.1.7.0.255").
+		SetValue(2, *axdr.CreateAxdrDoubleLongUnsigned(1500), false).
+		SetValue(3, *cosem.ScalerUnit{Scaler: 0, Unit: cosem.UnitActivePower}.Data(), false)
+
+	monitored := cosem.ValueDefinition{ClassID: cosem.RegisterClassID, LogicalName: *dlms.CreateObis("1.0.1.7.0.255"), AttributeID: 2}
+	tariff := *dlms.CreateObis(cosem.TariffScriptTableLogicalName)
+	actions := cosem.ActionSet{
+		Up:   cosem.ActionItem{ScriptLogicalName: tariff, ScriptSelector: 1},
+		Down: cosem.ActionItem{ScriptLogicalName: tariff, ScriptSelector: 2},
+	}
+
+	o := dlmsserver.NewObject(cosem.RegisterMonitorClassID, 0, "0.0.16.1.0.255").
+		SetValue(2, *axdr.CreateAxdrArray([]*axdr.DlmsData{axdr.CreateAxdrDoubleLongUnsigned(5000)}), true).
+		SetValue(3, *monitored.Data(), true).
+		SetValue(4, *axdr.CreateAxdrArray([]*axdr.DlmsData{actions.Data()}), true)
+
+	m := cosem.NewRegisterMonitor(connect(t, power, o), "0.0.16.1.0.255")
+
+	v, err := m.Read()
+	require.NoError(t, err)
+	assert.Equal(t, cosem.RegisterMonitorValue{
+		Thresholds:     []axdr.DlmsData{*axdr.CreateAxdrDoubleLongUnsigned(5000)},
+		MonitoredValue: monitored,
+		Actions:        []cosem.ActionSet{actions},
+	}, v)
+
+	v.Thresholds = append(v.Thresholds, *axdr.CreateAxdrDoubleLongUnsigned(8000))
+	v.Actions = append(v.Actions, cosem.ActionSet{Up: actions.Up, Down: actions.Up})
+	require.NoError(t, m.Write(v, tariffScripts()))
+
+	summary, err := m.Summary()
+	require.NoError(t, err)
+	assert.Equal(t, "register monitor 0.0.16.1.0.255\n"+
+		"monitored value { 3, 1.0.1.7.0.255, 2 }\n"+
+		"threshold 1: 5000 W, up: script 1 of 0.0.10.0.100.255, down: script 2 of 0.0.10.0.100.255\n"+
+		"threshold 2: 8000 W, up: script 1 of 0.0.10.0.100.255, down: script 1 of 0.0.10.0.100.255", summary)
+
+	// Thresholds must have the type of the monitored register
+	invalid := v
+	invalid.Thresholds = []axdr.DlmsData{*axdr.CreateAxdrLongUnsigned(5000), *axdr.CreateAxdrDoubleLongUnsigned(8000)}
+	assertErrorCode(t, m.Write(invalid, nil), dlms.ErrorInvalidParameter)
+
+	// An action set is needed for each threshold
+	invalid = v
+	invalid.Actions = invalid.Actions[:1]
+	assertErrorCode(t, m.Write(invalid, nil), dlms.ErrorInvalidParameter)
+
+	// Scripts must be in the script table
+	invalid = v
+	invalid.Actions = []cosem.ActionSet{actions, {Up: actions.Up, Down: cosem.ActionItem{ScriptLogicalName: tariff, ScriptSelector: 9}}}
+	assertErrorCode(t, m.Write(invalid, tariffScripts()), dlms.ErrorInvalidParameter)
+	require.NoError(t, m.Write(invalid, nil))
+}
@@ -0,0 +1,147 @@
+package cosem_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegister(t *testing.T) {
+	resets := 0
+
+	o := dlmsserver.NewObject(cosem.RegisterClassID, 0, "1.0.1.8.0.255").
+		SetValue(2, *axdr.CreateAxdrDoubleLongUnsigned(12345), true).
+		SetValue(3, *cosem.ScalerUnit{Scaler: -2, Unit: cosem.UnitActiveEnergy}.Data(), false).
+		SetMethod(1, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			resets++
+			return nil, dlms.TagActSuccess
+		})
+
+	r := cosem.NewRegister(connect(t, o), "1.0.1.8.0.255")
+	assert.Equal(t, "1.0.1.8.0.255", r.LogicalName())
+
+	v, err := r.Read()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(12345), v.Value.Value)
+	assert.Equal(t, cosem.ScalerUnit{Scaler: -2, Unit: cosem.UnitActiveEnergy}, v.ScalerUnit)
+
+	f, err := v.Float()
+	assert.NoError(t, err)
+	assert.Equal(t, 123.45, f)
+
+	d, err := v.Decimal()
+	assert.NoError(t, err)
+	assert.Equal(t, "123.45", d)
+	assert.Equal(t, "123.45 Wh", v.String())
+
+	require.NoError(t, r.Reset())
+	assert.Equal(t, 1, resets)
+}
+
+func TestRegister_InvalidScalerUnit(t *testing.T) {
+	o := dlmsserver.NewObject(cosem.RegisterClassID, 0, "1.0.1.8.0.255").
+		SetValue(2, *axdr.CreateAxdrDoubleLongUnsigned(12345), false).
+		SetValue(3, *axdr.CreateAxdrUnsigned(0), false)
+
+	_, err := cosem.NewRegister(connect(t, o), "1.0.1.8.0.255").Read()
+
+	var dlmsError *dlms.Error
+	require.ErrorAs(t, err, &dlmsError)
+	assert.Equal(t, dlms.ErrorInvalidResponse, dlmsError.Code())
+}
+
+func TestRegister_ReadWithList(t *testing.T) {
+	o := dlmsserver.NewObject(cosem.RegisterClassID, 0, "1.0.1.8.0.255").
+		SetValue(2, *axdr.CreateAxdrDoubleLongUnsigned(12345), false).
+		SetValue(3, *cosem.ScalerUnit{Scaler: -2, Unit: cosem.UnitActiveEnergy}.Data(), false)
+
+	// The value and its scaler and unit are read in a single request
+	settings, _ := dlms.NewSettingsWithoutAuthentication()
+	settings.ConformanceBlock |= dlms.ConformanceBlockMultipleReferences
+	c, tr := connectWith(t, settings, o)
+
+	tr.sent = 0
+	v, err := cosem.NewRegister(c, "1.0.1.8.0.255").Read()
+	require.NoError(t, err)
+	assert.Equal(t, "123.45 Wh", v.String())
+	assert.Equal(t, 1, tr.sent)
+
+	// Or one after the other, without multiple references
+	settings, _ = dlms.NewSettingsWithoutAuthentication()
+	c, tr = connectWith(t, settings, o)
+
+	tr.sent = 0
+	v, err = cosem.NewRegister(c, "1.0.1.8.0.255").Read()
+	require.NoError(t, err)
+	assert.Equal(t, "123.45 Wh", v.String())
+	assert.Equal(t, 2, tr.sent)
+}
+
+func TestExtendedRegister(t *testing.T) {
+	captureTime := time.Date(2024, 3, 1, 10, 15, 0, 0, time.UTC)
+
+	o := dlmsserver.NewObject(cosem.ExtendedRegisterClassID, 0, "1.0.1.6.0.255").
+		SetValue(2, *axdr.CreateAxdrDoubleLong(-1500), false).
+		SetValue(3, *cosem.ScalerUnit{Scaler: 1, Unit: cosem.UnitActivePower}.Data(), false).
+		SetValue(4, *axdr.CreateAxdrUnsigned(2), false).
+		SetValue(5, *axdr.CreateAxdrDateTime(captureTime), false)
+
+	r := cosem.NewExtendedRegister(connect(t, o), "1.0.1.6.0.255")
+
+	v, err := r.Read()
+	require.NoError(t, err)
+	assert.Equal(t, "-15000 W", v.String())
+	assert.Equal(t, uint8(2), v.Status.Value)
+	assert.True(t, captureTime.Equal(v.CaptureTime))
+
+	su, err := r.ScalerUnit()
+	assert.NoError(t, err)
+	assert.Equal(t, cosem.UnitActivePower, su.Unit)
+
+	err = r.Reset()
+	assert.Error(t, err)
+}
+
+func TestScalerUnit(t *testing.T) {
+	tests := []struct {
+		value   axdr.DlmsData
+		scaler  int8
+		decimal string
+		float   float64
+	}{
+		{*axdr.CreateAxdrDoubleLongUnsigned(12340), -3, "12.340", 12.34},
+		{*axdr.CreateAxdrLong(-5), -3, "-0.005", -0.005},
+		{*axdr.CreateAxdrUnsigned(7), 0, "7", 7},
+		{*axdr.CreateAxdrLong64Unsigned(18446744073709551615), 2, "1844674407370955161500", 1.8446744073709552e+21},
+		{*axdr.CreateAxdrFloat32(1.5), -1, "0.15", 0.15},
+	}
+
+	for _, tt := range tests {
+		su := cosem.ScalerUnit{Scaler: tt.scaler, Unit: cosem.UnitCount}
+
+		d, err := su.Decimal(tt.value)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.decimal, d)
+
+		f, err := su.Float(tt.value)
+		assert.NoError(t, err)
+		assert.InDelta(t, tt.float, f, 1e-9)
+	}
+
+	_, err := cosem.ScalerUnit{}.Float(*axdr.CreateAxdrVisibleString("abc"))
+	assert.Error(t, err)
+}
+
+func TestUnit_String(t *testing.T) {
+	assert.Equal(t, "Wh", cosem.UnitActiveEnergy.String())
+	assert.Equal(t, "varh", cosem.UnitReactiveEnergy.String())
+	assert.Equal(t, "m³", cosem.UnitVolume.String())
+	assert.Equal(t, "°C", cosem.UnitTemperature.String())
+	assert.Equal(t, "", cosem.UnitCount.String())
+}
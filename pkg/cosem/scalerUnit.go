@@ -0,0 +1,131 @@
+package cosem
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+// ScalerUnit is the scaler_unit attribute of the registers: value = raw * 10^Scaler [Unit].
+type ScalerUnit struct {
+	Scaler int8
+	Unit   Unit
+}
+
+// DecodeScalerUnit decodes a scal_unit_type structure.
+func DecodeScalerUnit(data axdr.DlmsData) (su ScalerUnit, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 2)
+	if err != nil {
+		return
+	}
+
+	scaler, ok := fields[0].Value.(int8)
+	if !ok {
+		err = fmt.Errorf("invalid scaler %v", fields[0].Value)
+		return
+	}
+
+	unit, ok := fields[1].Value.(uint8)
+	if !ok {
+		err = fmt.Errorf("invalid unit %v", fields[1].Value)
+		return
+	}
+
+	return ScalerUnit{Scaler: scaler, Unit: Unit(unit)}, nil
+}
+
+// Data returns the scaler and unit as A-XDR data.
+func (su ScalerUnit) Data() *axdr.DlmsData {
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrInteger(su.Scaler),
+		axdr.CreateAxdrEnum(uint8(su.Unit)),
+	})
+}
+
+// Float returns the value scaled as a float.
+func (su ScalerUnit) Float(value axdr.DlmsData) (float64, error) {
+	if i, ok := integerValue(value); ok {
+		f, _ := new(big.Float).SetInt(i).Float64()
+		return scale(f, su.Scaler), nil
+	}
+
+	switch v := value.Value.(type) {
+	case float32:
+		return scale(float64(v), su.Scaler), nil
+	case float64:
+		return scale(v, su.Scaler), nil
+	}
+
+	return 0, fmt.Errorf("value %v (%T) is not numeric", value.Value, value.Value)
+}
+
+// Decimal returns the value scaled as a decimal string. Integer values are converted without
+// loss of precision, keeping as many decimals as the scaler defines (e.g. 12340 with scaler -3 is "12.340").
+func (su ScalerUnit) Decimal(value axdr.DlmsData) (string, error) {
+	i, ok := integerValue(value)
+	if !ok {
+		f, err := su.Float(value)
+		if err != nil {
+			return "", err
+		}
+
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+
+	if su.Scaler >= 0 {
+		return i.Mul(i, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(su.Scaler)), nil)).String(), nil
+	}
+
+	sign := ""
+	if i.Sign() < 0 {
+		sign = "-"
+		i.Neg(i)
+	}
+
+	decimals := int(-su.Scaler)
+	digits := i.String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	point := len(digits) - decimals
+	return sign + digits[:point] + "." + digits[point:], nil
+}
+
+// scale divides by the power of ten when the scaler is negative, so the result is correctly rounded.
+func scale(f float64, scaler int8) float64 {
+	if scaler < 0 {
+		return f / math.Pow10(-int(scaler))
+	}
+
+	return f * math.Pow10(int(scaler))
+}
+
+// integerValue returns the value as a big integer if its type is an integer.
+func integerValue(value axdr.DlmsData) (*big.Int, bool) {
+	switch v := value.Value.(type) {
+	case int8:
+		return big.NewInt(int64(v)), true
+	case int16:
+		return big.NewInt(int64(v)), true
+	case int32:
+		return big.NewInt(int64(v)), true
+	case int64:
+		return big.NewInt(v), true
+	case uint8:
+		return big.NewInt(int64(v)), true
+	case uint16:
+		return big.NewInt(int64(v)), true
+	case uint32:
+		return big.NewInt(int64(v)), true
+	case uint64:
+		return new(big.Int).SetUint64(v), true
+	}
+
+	return nil, false
+}
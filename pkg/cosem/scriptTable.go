@@ -0,0 +1,177 @@
+package cosem
+
+import (
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const (
+	ScriptTableClassID = 9
+	// TariffScriptTableLogicalName is the logical name of the script table used by the activity calendar
+	TariffScriptTableLogicalName = "0.0.10.0.100.255"
+)
+
+const (
+	scriptTableScripts = 2
+	scriptTableExecute = 1
+)
+
+// ScriptService is the service of a script action.
+type ScriptService uint8
+
+const (
+	ScriptServiceWrite   ScriptService = 1
+	ScriptServiceExecute ScriptService = 2
+)
+
+func (s ScriptService) String() string {
+	switch s {
+	case ScriptServiceWrite:
+		return "write attribute"
+	case ScriptServiceExecute:
+		return "execute method"
+	default:
+		return ""
+	}
+}
+
+// ScriptAction writes an attribute or executes a method of an object. A zero Parameter is sent as null data.
+type ScriptAction struct {
+	Service     ScriptService
+	ClassID     uint16
+	LogicalName dlms.Obis
+	Index       int8
+	Parameter   axdr.DlmsData
+}
+
+// Data returns the script action as A-XDR data.
+func (a ScriptAction) Data() *axdr.DlmsData {
+	parameter := a.Parameter
+
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrEnum(uint8(a.Service)),
+		axdr.CreateAxdrLongUnsigned(a.ClassID),
+		axdr.CreateAxdrOctetString(a.LogicalName.String()),
+		axdr.CreateAxdrInteger(a.Index),
+		&parameter,
+	})
+}
+
+func decodeScriptAction(data axdr.DlmsData) (a ScriptAction, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 5)
+	if err != nil {
+		return
+	}
+
+	service, ok1 := fields[0].Value.(uint8)
+	classID, ok2 := fields[1].Value.(uint16)
+	index, ok3 := fields[3].Value.(int8)
+	if !ok1 || !ok2 || !ok3 {
+		err = fmt.Errorf("invalid script action")
+		return
+	}
+
+	ln, err := dlms.DecodeLogicalName(*fields[2])
+	if err != nil {
+		return
+	}
+
+	return ScriptAction{Service: ScriptService(service), ClassID: classID, LogicalName: ln, Index: index, Parameter: *fields[4]}, nil
+}
+
+// Script is a sequence of actions identified by the script selector.
+type Script struct {
+	ID      uint16
+	Actions []ScriptAction
+}
+
+// Data returns the script as A-XDR data.
+func (s Script) Data() *axdr.DlmsData {
+	actions := make([]*axdr.DlmsData, len(s.Actions))
+	for i, a := range s.Actions {
+		actions[i] = a.Data()
+	}
+
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrLongUnsigned(s.ID),
+		axdr.CreateAxdrArray(actions),
+	})
+}
+
+func decodeScript(data axdr.DlmsData) (s Script, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 2)
+	if err != nil {
+		return
+	}
+
+	id, ok := fields[0].Value.(uint16)
+	if !ok {
+		err = fmt.Errorf("invalid script identifier %v", fields[0].Value)
+		return
+	}
+
+	actions, err := dlms.DataAsSlice(*fields[1], axdr.TagArray, 0)
+	if err != nil {
+		return
+	}
+
+	s.ID = id
+	s.Actions = make([]ScriptAction, len(actions))
+	for i, a := range actions {
+		if s.Actions[i], err = decodeScriptAction(*a); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func findScript(scripts []Script, id uint16) *Script {
+	for i := range scripts {
+		if scripts[i].ID == id {
+			return &scripts[i]
+		}
+	}
+
+	return nil
+}
+
+// ScriptTable is an instance of the Script Table interface class (class_id 9).
+type ScriptTable struct {
+	object
+}
+
+func NewScriptTable(client dlms.Client, logicalName string) *ScriptTable {
+	return &ScriptTable{object{client: client, classID: ScriptTableClassID, logicalName: logicalName}}
+}
+
+// Scripts reads the scripts of the table.
+func (s *ScriptTable) Scripts() ([]Script, error) {
+	var data axdr.DlmsData
+
+	err := s.get(scriptTableScripts, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	elements, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return nil, s.invalidData(scriptTableScripts, err)
+	}
+
+	scripts := make([]Script, len(elements))
+	for i, e := range elements {
+		if scripts[i], err = decodeScript(*e); err != nil {
+			return nil, s.invalidData(scriptTableScripts, err)
+		}
+	}
+
+	return scripts, nil
+}
+
+// Execute executes the script with the given identifier.
+func (s *ScriptTable) Execute(id uint16) error {
+	return s.action(scriptTableExecute, id)
+}
@@ -0,0 +1,45 @@
+package cosem_test
+
+import (
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptTable(t *testing.T) {
+	ln := *dlms.CreateObis(cosem.TariffScriptTableLogicalName)
+	scripts := tariffScripts()[ln]
+	scripts = append(scripts, cosem.Script{ID: 3, Actions: []cosem.ScriptAction{{Service: cosem.ScriptServiceExecute, ClassID: 70, LogicalName: *dlms.CreateObis(cosem.DisconnectControlLogicalName), Index: 1, Parameter: *axdr.CreateAxdrInteger(0)}}})
+
+	data := make([]*axdr.DlmsData, len(scripts))
+	for i, s := range scripts {
+		data[i] = s.Data()
+	}
+
+	var executed []uint16
+	o := dlmsserver.NewObject(cosem.ScriptTableClassID, 0, cosem.TariffScriptTableLogicalName).
+		SetValue(2, *axdr.CreateAxdrArray(data), false).
+		SetMethod(1, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			id, ok := data.Value.(uint16)
+			if !ok {
+				return nil, dlms.TagActTypeUnmatched
+			}
+
+			executed = append(executed, id)
+			return nil, dlms.TagActSuccess
+		})
+
+	s := cosem.NewScriptTable(connect(t, o), cosem.TariffScriptTableLogicalName)
+
+	read, err := s.Scripts()
+	require.NoError(t, err)
+	assert.Equal(t, scripts, read)
+
+	require.NoError(t, s.Execute(2))
+	assert.Equal(t, []uint16{2}, executed)
+}
@@ -0,0 +1,577 @@
+package cosem
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const (
+	SecuritySetupClassID = 64
+	// SecuritySetupLogicalName is the logical name of the security setup of the management association
+	SecuritySetupLogicalName = "0.0.43.0.0.255"
+)
+
+const (
+	securitySetupPolicy                     = 2
+	securitySetupSuite                      = 3
+	securitySetupClientSystemTitle          = 4
+	securitySetupServerSystemTitle          = 5
+	securitySetupCertificates               = 6
+	securitySetupActivate                   = 1
+	securitySetupKeyTransfer                = 2
+	securitySetupKeyAgreement               = 3
+	securitySetupGenerateKeyPair            = 4
+	securitySetupGenerateCertificateRequest = 5
+	securitySetupImportCertificate          = 6
+	securitySetupExportCertificate          = 7
+	securitySetupRemoveCertificate          = 8
+)
+
+// SecurityPolicy is the security_policy of version 1 of the class: which messages must be protected.
+type SecurityPolicy uint8
+
+const (
+	SecurityPolicyAuthenticatedRequest  SecurityPolicy = 0x04
+	SecurityPolicyEncryptedRequest      SecurityPolicy = 0x08
+	SecurityPolicySignedRequest         SecurityPolicy = 0x10
+	SecurityPolicyAuthenticatedResponse SecurityPolicy = 0x20
+	SecurityPolicyEncryptedResponse     SecurityPolicy = 0x40
+	SecurityPolicySignedResponse        SecurityPolicy = 0x80
+)
+
+func (p SecurityPolicy) Has(flag SecurityPolicy) bool {
+	return p&flag == flag
+}
+
+// SecuritySuite defines the cryptographic algorithms in use.
+type SecuritySuite uint8
+
+const (
+	SecuritySuite0 SecuritySuite = 0 // AES-GCM-128
+	SecuritySuite1 SecuritySuite = 1 // ECDH-ECDSA-AES-GCM-128-SHA-256
+	SecuritySuite2 SecuritySuite = 2 // ECDH-ECDSA-AES-GCM-256-SHA-384
+)
+
+// KeyID identifies the key transferred or agreed.
+type KeyID uint8
+
+const (
+	KeyIDGlobalUnicast   KeyID = 0
+	KeyIDGlobalBroadcast KeyID = 1
+	KeyIDAuthentication  KeyID = 2
+	KeyIDMaster          KeyID = 3
+)
+
+func (k KeyID) String() string {
+	switch k {
+	case KeyIDGlobalUnicast:
+		return "global unicast encryption key"
+	case KeyIDGlobalBroadcast:
+		return "global broadcast encryption key"
+	case KeyIDAuthentication:
+		return "authentication key"
+	case KeyIDMaster:
+		return "master key"
+	default:
+		return ""
+	}
+}
+
+// Key is a key to transfer to the server.
+type Key struct {
+	ID  KeyID
+	Key []byte
+}
+
+// KeyPairType is the use of a key pair of the server.
+type KeyPairType uint8
+
+const (
+	KeyPairDigitalSignature KeyPairType = 0
+	KeyPairKeyAgreement     KeyPairType = 1
+	KeyPairTLS              KeyPairType = 2
+)
+
+// CertificateEntity is the owner of a certificate.
+type CertificateEntity uint8
+
+const (
+	CertificateEntityServer                 CertificateEntity = 0
+	CertificateEntityClient                 CertificateEntity = 1
+	CertificateEntityCertificationAuthority CertificateEntity = 2
+	CertificateEntityOther                  CertificateEntity = 3
+)
+
+const (
+	certificateIdentificationByEntity = 0
+	certificateIdentificationBySerial = 1
+)
+
+// CertificateInfo describes a certificate stored in the server.
+type CertificateInfo struct {
+	Entity         CertificateEntity
+	Type           KeyPairType
+	SerialNumber   []byte
+	Issuer         []byte
+	Subject        []byte
+	SubjectAltName []byte
+}
+
+func decodeCertificateInfo(data axdr.DlmsData) (c CertificateInfo, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 6)
+	if err != nil {
+		return
+	}
+
+	entity, ok1 := fields[0].Value.(uint8)
+	certificateType, ok2 := fields[1].Value.(uint8)
+	if !ok1 || !ok2 {
+		err = fmt.Errorf("invalid certificate info")
+		return
+	}
+
+	c.Entity = CertificateEntity(entity)
+	c.Type = KeyPairType(certificateType)
+	for i, dst := range []*[]byte{&c.SerialNumber, &c.Issuer, &c.Subject, &c.SubjectAltName} {
+		if *dst, err = octetString(*fields[i+2], 0); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// CertificateIdentification identifies a certificate, by entity or by serial number. If SerialNumber is
+// nil, the certificate is identified by Entity, Type and SystemTitle.
+type CertificateIdentification struct {
+	Entity       CertificateEntity
+	Type         KeyPairType
+	SystemTitle  []byte
+	SerialNumber []byte
+	Issuer       []byte
+}
+
+// Data returns the certificate identification as A-XDR data.
+func (c CertificateIdentification) Data() *axdr.DlmsData {
+	if c.SerialNumber == nil {
+		return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+			axdr.CreateAxdrEnum(certificateIdentificationByEntity),
+			axdr.CreateAxdrStructure([]*axdr.DlmsData{
+				axdr.CreateAxdrEnum(uint8(c.Entity)),
+				axdr.CreateAxdrEnum(uint8(c.Type)),
+				createOctetString(c.SystemTitle),
+			}),
+		})
+	}
+
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrEnum(certificateIdentificationBySerial),
+		axdr.CreateAxdrStructure([]*axdr.DlmsData{
+			createOctetString(c.SerialNumber),
+			createOctetString(c.Issuer),
+		}),
+	})
+}
+
+// SecuritySetupValue holds the attributes of a security setup.
+type SecuritySetupValue struct {
+	SecurityPolicy    SecurityPolicy
+	SecuritySuite     SecuritySuite
+	ClientSystemTitle []byte
+	ServerSystemTitle []byte
+	Certificates      []CertificateInfo
+}
+
+// SecuritySetup is an instance of the Security Setup interface class (class_id 64), version 1. The server
+// keeps protecting the current association with the keys and the policy it was established with, so
+// transferring or agreeing keys and activating the security policy don't change the client: they return
+// the ciphering to set in the client settings before the next Associate.
+type SecuritySetup struct {
+	object
+}
+
+func NewSecuritySetup(client dlms.Client, logicalName string) *SecuritySetup {
+	return &SecuritySetup{object{client: client, classID: SecuritySetupClassID, logicalName: logicalName}}
+}
+
+// Read reads all the attributes of the security setup.
+func (s *SecuritySetup) Read() (v SecuritySetupValue, err error) {
+	if v.SecurityPolicy, err = s.SecurityPolicy(); err != nil {
+		return
+	}
+	if v.SecuritySuite, err = s.SecuritySuite(); err != nil {
+		return
+	}
+	if v.ClientSystemTitle, err = s.systemTitle(securitySetupClientSystemTitle); err != nil {
+		return
+	}
+	if v.ServerSystemTitle, err = s.ServerSystemTitle(); err != nil {
+		return
+	}
+
+	v.Certificates, err = s.Certificates()
+	return
+}
+
+// SecurityPolicy reads the security policy in use.
+func (s *SecuritySetup) SecurityPolicy() (SecurityPolicy, error) {
+	var data axdr.DlmsData
+
+	err := s.get(securitySetupPolicy, &data)
+	if err != nil {
+		return 0, err
+	}
+
+	policy, ok := data.Value.(uint8)
+	if !ok {
+		return 0, s.invalidData(securitySetupPolicy, fmt.Errorf("unexpected value %v", data.Value))
+	}
+
+	return SecurityPolicy(policy), nil
+}
+
+// SecuritySuite reads the security suite in use.
+func (s *SecuritySetup) SecuritySuite() (SecuritySuite, error) {
+	var data axdr.DlmsData
+
+	err := s.get(securitySetupSuite, &data)
+	if err != nil {
+		return 0, err
+	}
+
+	suite, ok := data.Value.(uint8)
+	if !ok {
+		return 0, s.invalidData(securitySetupSuite, fmt.Errorf("unexpected value %v", data.Value))
+	}
+
+	return SecuritySuite(suite), nil
+}
+
+// ClientSystemTitle reads the system title of the client known by the server.
+func (s *SecuritySetup) ClientSystemTitle() ([]byte, error) {
+	return s.systemTitle(securitySetupClientSystemTitle)
+}
+
+// SetClientSystemTitle sets the system title of the client.
+func (s *SecuritySetup) SetClientSystemTitle(systemTitle []byte) error {
+	return s.set(securitySetupClientSystemTitle, createOctetString(systemTitle))
+}
+
+// ServerSystemTitle reads the system title of the server.
+func (s *SecuritySetup) ServerSystemTitle() ([]byte, error) {
+	return s.systemTitle(securitySetupServerSystemTitle)
+}
+
+// Certificates reads the certificates stored in the server.
+func (s *SecuritySetup) Certificates() ([]CertificateInfo, error) {
+	var data axdr.DlmsData
+
+	err := s.get(securitySetupCertificates, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	elements, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return nil, s.invalidData(securitySetupCertificates, err)
+	}
+
+	certificates := make([]CertificateInfo, len(elements))
+	for i, e := range elements {
+		if certificates[i], err = decodeCertificateInfo(*e); err != nil {
+			return nil, s.invalidData(securitySetupCertificates, err)
+		}
+	}
+
+	return certificates, nil
+}
+
+// SecurityActivate activates a stronger security policy. It returns the client ciphering with the
+// security required to protect the requests, to be used from the next association.
+func (s *SecuritySetup) SecurityActivate(policy SecurityPolicy) (dlms.Ciphering, error) {
+	ciphering := s.client.GetSettings().Ciphering
+
+	err := s.action(securitySetupActivate, axdr.CreateAxdrEnum(uint8(policy)))
+	if err != nil {
+		return dlms.Ciphering{}, err
+	}
+
+	if policy.Has(SecurityPolicyAuthenticatedRequest) {
+		ciphering.Security |= dlms.SecurityAuthentication
+	}
+	if policy.Has(SecurityPolicyEncryptedRequest) {
+		ciphering.Security |= dlms.SecurityEncryption
+	}
+
+	return ciphering, nil
+}
+
+// KeyTransfer transfers new keys to the server, wrapped with the master key. Once accepted, it returns
+// the client ciphering with the new keys, to be used from the next association. The master key isn't
+// kept by the client, so the caller must keep track of it if it's changed. Invocation counters are kept,
+// as they must keep increasing.
+func (s *SecuritySetup) KeyTransfer(masterKey []byte, keys []Key) (dlms.Ciphering, error) {
+	ciphering := s.client.GetSettings().Ciphering
+
+	list := make([]*axdr.DlmsData, len(keys))
+	for i, k := range keys {
+		wrapped, err := WrapKey(masterKey, k.Key)
+		if err != nil {
+			return dlms.Ciphering{}, dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("error wrapping %s: %v", k.ID.String(), err))
+		}
+
+		list[i] = axdr.CreateAxdrStructure([]*axdr.DlmsData{
+			axdr.CreateAxdrEnum(uint8(k.ID)),
+			createOctetString(wrapped),
+		})
+	}
+
+	err := s.action(securitySetupKeyTransfer, axdr.CreateAxdrArray(list))
+	if err != nil {
+		return dlms.Ciphering{}, err
+	}
+
+	return withKeys(ciphering, keys), nil
+}
+
+// KeyAgreement agrees a new key with the server, as defined in security suite 1: ephemeral ECDH on P-256
+// with the ephemeral public keys signed with ECDSA. signingKey is the private key of the client, whose
+// certificate must be in the server, and serverKey is the public key of the signing certificate of the
+// server. It returns the client ciphering with the agreed key, to be used from the next association.
+func (s *SecuritySetup) KeyAgreement(id KeyID, signingKey *ecdsa.PrivateKey, serverKey *ecdsa.PublicKey) (dlms.Ciphering, error) {
+	ciphering := s.client.GetSettings().Ciphering
+
+	serverTitle, err := s.ServerSystemTitle()
+	if err != nil {
+		return dlms.Ciphering{}, err
+	}
+
+	curve := elliptic.P256()
+	ephemeral, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return dlms.Ciphering{}, dlms.NewError(dlms.ErrorUnspecified, fmt.Sprintf("error generating ephemeral key: %v", err))
+	}
+
+	public := MarshalPublicKey(&ephemeral.PublicKey)
+	signature, err := signKeyAgreement(signingKey, id, public)
+	if err != nil {
+		return dlms.Ciphering{}, dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("error signing ephemeral key: %v", err))
+	}
+
+	var response axdr.DlmsData
+	err = s.client.ActionRequestWithResponse(s.method(securitySetupKeyAgreement), axdr.CreateAxdrArray([]*axdr.DlmsData{
+		axdr.CreateAxdrStructure([]*axdr.DlmsData{
+			axdr.CreateAxdrEnum(uint8(id)),
+			createOctetString(append(public, signature...)),
+		}),
+	}), &response)
+	if err != nil {
+		return dlms.Ciphering{}, err
+	}
+
+	serverPublic, err := s.keyAgreementResponse(response, id, serverKey)
+	if err != nil {
+		return dlms.Ciphering{}, s.invalidData(securitySetupKeyAgreement, err)
+	}
+
+	// Shared secret is the x coordinate of the product
+	x, _ := curve.ScalarMult(serverPublic.X, serverPublic.Y, ephemeral.D.Bytes()) //nolint:staticcheck // crypto/ecdh needs Go 1.20, the module supports Go 1.19
+
+	shared := make([]byte, 32)
+	x.FillBytes(shared)
+
+	key := KeyAgreementKDF(shared, ciphering.SystemTitle, serverTitle)
+
+	return withKeys(ciphering, []Key{{ID: id, Key: key}}), nil
+}
+
+// keyAgreementResponse verifies the ephemeral public key sent by the server.
+func (s *SecuritySetup) keyAgreementResponse(data axdr.DlmsData, id KeyID, serverKey *ecdsa.PublicKey) (*ecdsa.PublicKey, error) {
+	elements, err := dlms.DataAsSlice(data, axdr.TagArray, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := dlms.DataAsSlice(*elements[0], axdr.TagStructure, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	if fields[0].Value != uint8(id) {
+		return nil, fmt.Errorf("unexpected key id %v", fields[0].Value)
+	}
+
+	ciphered, err := octetString(*fields[1], 128)
+	if err != nil {
+		return nil, err
+	}
+
+	public, err := UnmarshalPublicKey(ciphered[:64])
+	if err != nil {
+		return nil, err
+	}
+
+	if !verifyKeyAgreement(serverKey, id, ciphered[:64], ciphered[64:]) {
+		return nil, fmt.Errorf("invalid signature of the ephemeral key")
+	}
+
+	return public, nil
+}
+
+// GenerateKeyPair makes the server generate a new key pair of the given type.
+func (s *SecuritySetup) GenerateKeyPair(t KeyPairType) error {
+	return s.action(securitySetupGenerateKeyPair, axdr.CreateAxdrEnum(uint8(t)))
+}
+
+// GenerateCertificateRequest makes the server generate a certificate signing request (PKCS #10, DER
+// encoded) for the key pair of the given type.
+func (s *SecuritySetup) GenerateCertificateRequest(t KeyPairType) ([]byte, error) {
+	var data axdr.DlmsData
+
+	err := s.client.ActionRequestWithResponse(s.method(securitySetupGenerateCertificateRequest), axdr.CreateAxdrEnum(uint8(t)), &data)
+	if err != nil {
+		return nil, err
+	}
+
+	csr, err := octetString(data, 0)
+	if err != nil {
+		return nil, s.invalidData(securitySetupGenerateCertificateRequest, err)
+	}
+
+	return csr, nil
+}
+
+// ImportCertificate imports a DER encoded X.509 certificate.
+func (s *SecuritySetup) ImportCertificate(der []byte) error {
+	return s.action(securitySetupImportCertificate, createOctetString(der))
+}
+
+// ExportCertificate exports a certificate stored in the server, DER encoded.
+func (s *SecuritySetup) ExportCertificate(id CertificateIdentification) ([]byte, error) {
+	var data axdr.DlmsData
+
+	err := s.client.ActionRequestWithResponse(s.method(securitySetupExportCertificate), id.Data(), &data)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := octetString(data, 0)
+	if err != nil {
+		return nil, s.invalidData(securitySetupExportCertificate, err)
+	}
+
+	return der, nil
+}
+
+// RemoveCertificate removes a certificate stored in the server.
+func (s *SecuritySetup) RemoveCertificate(id CertificateIdentification) error {
+	return s.action(securitySetupRemoveCertificate, id.Data())
+}
+
+func (s *SecuritySetup) systemTitle(attributeID int8) ([]byte, error) {
+	var data axdr.DlmsData
+
+	err := s.get(attributeID, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	title, err := octetString(data, 0)
+	if err != nil {
+		return nil, s.invalidData(attributeID, err)
+	}
+
+	return title, nil
+}
+
+// withKeys returns the ciphering with the keys set, copied.
+func withKeys(ciphering dlms.Ciphering, keys []Key) dlms.Ciphering {
+	for _, k := range keys {
+		key := append([]byte(nil), k.Key...)
+
+		switch k.ID {
+		case KeyIDGlobalUnicast:
+			ciphering.UnicastKey = key
+		case KeyIDGlobalBroadcast:
+			ciphering.BroadcastKey = key
+		case KeyIDAuthentication:
+			ciphering.AuthenticationKey = key
+		}
+	}
+
+	return ciphering
+}
+
+// keyAgreementAlgorithmID identifies AES-GCM-128 in the key derivation.
+const keyAgreementAlgorithmID = "\x60\x85\x74\x05\x08\x03\x00"
+
+// KeyAgreementKDF derives the agreed key from the shared secret with the concatenation KDF of
+// NIST SP 800-56A and SHA-256, as used by security suite 1.
+func KeyAgreementKDF(shared []byte, clientSystemTitle []byte, serverSystemTitle []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{0x00, 0x00, 0x00, 0x01})
+	buf.Write(shared)
+	buf.WriteString(keyAgreementAlgorithmID)
+	buf.Write(clientSystemTitle)
+	buf.Write(serverSystemTitle)
+
+	sum := sha256.Sum256(buf.Bytes())
+	return sum[:16]
+}
+
+// MarshalPublicKey returns the coordinates of a P-256 public key, 32 bytes each.
+func MarshalPublicKey(key *ecdsa.PublicKey) []byte {
+	out := make([]byte, 64)
+	key.X.FillBytes(out[:32])
+	key.Y.FillBytes(out[32:])
+
+	return out
+}
+
+// UnmarshalPublicKey decodes the coordinates of a P-256 public key.
+func UnmarshalPublicKey(src []byte) (*ecdsa.PublicKey, error) {
+	if len(src) != 64 {
+		return nil, fmt.Errorf("invalid public key length %d", len(src))
+	}
+
+	key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(src[:32]), Y: new(big.Int).SetBytes(src[32:])}
+	if !key.Curve.IsOnCurve(key.X, key.Y) {
+		return nil, fmt.Errorf("public key %s is not on the curve", hex.EncodeToString(src))
+	}
+
+	return key, nil
+}
+
+// signKeyAgreement signs the key id and the ephemeral public key. The signature is r and s, 32 bytes each.
+func signKeyAgreement(key *ecdsa.PrivateKey, id KeyID, public []byte) ([]byte, error) {
+	digest := sha256.Sum256(append([]byte{byte(id)}, public...))
+
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 64)
+	r.FillBytes(out[:32])
+	s.FillBytes(out[32:])
+
+	return out, nil
+}
+
+func verifyKeyAgreement(key *ecdsa.PublicKey, id KeyID, public []byte, signature []byte) bool {
+	digest := sha256.Sum256(append([]byte{byte(id)}, public...))
+
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+
+	return ecdsa.Verify(key, digest[:], r, s)
+}
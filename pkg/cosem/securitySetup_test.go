@@ -0,0 +1,242 @@
+package cosem_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	clientSystemTitle = []byte{0x43, 0x49, 0x52, 0x00, 0x00, 0x00, 0x00, 0x01}
+	serverSystemTitle = []byte{0x43, 0x49, 0x52, 0x00, 0x00, 0x00, 0x00, 0x02}
+)
+
+// meterSecurity simulates the security setup of a meter.
+type meterSecurity struct {
+	policy       uint8
+	masterKey    []byte
+	keys         map[cosem.KeyID][]byte
+	signingKey   *ecdsa.PrivateKey
+	clientKey    *ecdsa.PublicKey
+	certificates [][]byte
+}
+
+func (m *meterSecurity) object() *dlmsserver.Object {
+	octetString := func(src []byte) *axdr.DlmsData {
+		return axdr.CreateAxdrOctetString(hex.EncodeToString(src))
+	}
+	bytesOf := func(data *axdr.DlmsData) []byte {
+		out, _ := hex.DecodeString(data.Value.(string))
+		return out
+	}
+
+	return dlmsserver.NewObject(cosem.SecuritySetupClassID, 1, cosem.SecuritySetupLogicalName).
+		SetAttribute(2, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			return *axdr.CreateAxdrEnum(m.policy), dlms.TagAccSuccess
+		}, nil).
+		SetValue(3, *axdr.CreateAxdrEnum(uint8(cosem.SecuritySuite1)), false).
+		SetValue(4, *octetString(clientSystemTitle), true).
+		SetValue(5, *octetString(serverSystemTitle), false).
+		SetAttribute(6, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			list := make([]*axdr.DlmsData, len(m.certificates))
+			for i, c := range m.certificates {
+				list[i] = axdr.CreateAxdrStructure([]*axdr.DlmsData{
+					axdr.CreateAxdrEnum(uint8(cosem.CertificateEntityClient)),
+					axdr.CreateAxdrEnum(uint8(cosem.KeyPairDigitalSignature)),
+					octetString(c[:1]),
+					octetString([]byte("CA")),
+					octetString([]byte("client")),
+					octetString(nil),
+				})
+			}
+			return *axdr.CreateAxdrArray(list), dlms.TagAccSuccess
+		}, nil).
+		SetMethod(1, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			m.policy = data.Value.(uint8)
+			return nil, dlms.TagActSuccess
+		}).
+		SetMethod(2, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			keys := make(map[cosem.KeyID][]byte)
+			for _, k := range data.Value.([]*axdr.DlmsData) {
+				fields := k.Value.([]*axdr.DlmsData)
+
+				key, err := cosem.UnwrapKey(m.masterKey, bytesOf(fields[1]))
+				if err != nil {
+					return nil, dlms.TagActOtherReason
+				}
+				keys[cosem.KeyID(fields[0].Value.(uint8))] = key
+			}
+
+			for id, key := range keys {
+				m.keys[id] = key
+			}
+			return nil, dlms.TagActSuccess
+		}).
+		SetMethod(3, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			fields := data.Value.([]*axdr.DlmsData)[0].Value.([]*axdr.DlmsData)
+			id := fields[0].Value.(uint8)
+			ciphered := bytesOf(fields[1])
+
+			digest := sha256.Sum256(append([]byte{id}, ciphered[:64]...))
+			if !ecdsa.Verify(m.clientKey, digest[:], new(big.Int).SetBytes(ciphered[64:96]), new(big.Int).SetBytes(ciphered[96:])) {
+				return nil, dlms.TagActOtherReason
+			}
+
+			ephemeral, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			public := cosem.MarshalPublicKey(&ephemeral.PublicKey)
+			digest = sha256.Sum256(append([]byte{id}, public...))
+			r, s, _ := ecdsa.Sign(rand.Reader, m.signingKey, digest[:])
+			signature := make([]byte, 64)
+			r.FillBytes(signature[:32])
+			s.FillBytes(signature[32:])
+
+			client, _ := cosem.UnmarshalPublicKey(ciphered[:64])
+			x, _ := elliptic.P256().ScalarMult(client.X, client.Y, ephemeral.D.Bytes())
+			m.keys[cosem.KeyID(id)] = cosem.KeyAgreementKDF(x.FillBytes(make([]byte, 32)), clientSystemTitle, serverSystemTitle)
+
+			return axdr.CreateAxdrArray([]*axdr.DlmsData{
+				axdr.CreateAxdrStructure([]*axdr.DlmsData{axdr.CreateAxdrEnum(id), octetString(append(public, signature...))}),
+			}), dlms.TagActSuccess
+		}).
+		SetMethod(5, func(dlms.MethodDescriptor, *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			return octetString([]byte("csr")), dlms.TagActSuccess
+		}).
+		SetMethod(6, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			m.certificates = append(m.certificates, bytesOf(data))
+			return nil, dlms.TagActSuccess
+		}).
+		SetMethod(7, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			serial := bytesOf(data.Value.([]*axdr.DlmsData)[1].Value.([]*axdr.DlmsData)[0])
+			for _, c := range m.certificates {
+				if bytes.Equal(c[:1], serial) {
+					return octetString(c), dlms.TagActSuccess
+				}
+			}
+			return nil, dlms.TagActObjectUndefined
+		}).
+		SetMethod(8, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			serial := bytesOf(data.Value.([]*axdr.DlmsData)[1].Value.([]*axdr.DlmsData)[0])
+			for i, c := range m.certificates {
+				if bytes.Equal(c[:1], serial) {
+					m.certificates = append(m.certificates[:i], m.certificates[i+1:]...)
+					return nil, dlms.TagActSuccess
+				}
+			}
+			return nil, dlms.TagActObjectUndefined
+		})
+}
+
+func TestSecuritySetup(t *testing.T) {
+	m := &meterSecurity{masterKey: bytes.Repeat([]byte{0x01}, 16), keys: make(map[cosem.KeyID][]byte)}
+	c := connect(t, m.object())
+	s := cosem.NewSecuritySetup(c, cosem.SecuritySetupLogicalName)
+
+	v, err := s.Read()
+	require.NoError(t, err)
+	assert.Equal(t, cosem.SecuritySuite1, v.SecuritySuite)
+	assert.Equal(t, clientSystemTitle, v.ClientSystemTitle)
+	assert.Equal(t, serverSystemTitle, v.ServerSystemTitle)
+	assert.Empty(t, v.Certificates)
+
+	// Key transfer
+	unicast := bytes.Repeat([]byte{0x02}, 16)
+	authentication := bytes.Repeat([]byte{0x03}, 16)
+	ciphering, err := s.KeyTransfer(m.masterKey, []cosem.Key{{ID: cosem.KeyIDGlobalUnicast, Key: unicast}, {ID: cosem.KeyIDAuthentication, Key: authentication}})
+	require.NoError(t, err)
+	assert.Equal(t, unicast, m.keys[cosem.KeyIDGlobalUnicast])
+	assert.Equal(t, authentication, m.keys[cosem.KeyIDAuthentication])
+	assert.Equal(t, unicast, ciphering.UnicastKey)
+	assert.Equal(t, authentication, ciphering.AuthenticationKey)
+
+	// The current association keeps the keys it was established with
+	assert.Nil(t, c.GetSettings().Ciphering.UnicastKey)
+	assert.Nil(t, c.GetSettings().Ciphering.AuthenticationKey)
+
+	// Key transfer wrapped with a wrong master key is rejected
+	_, err = s.KeyTransfer(bytes.Repeat([]byte{0x09}, 16), []cosem.Key{{ID: cosem.KeyIDGlobalUnicast, Key: authentication}})
+	assertErrorCode(t, err, dlms.ErrorActionRejected)
+	assert.Equal(t, unicast, m.keys[cosem.KeyIDGlobalUnicast])
+
+	// Security activate
+	policy := cosem.SecurityPolicyAuthenticatedRequest | cosem.SecurityPolicyEncryptedRequest
+	ciphering, err = s.SecurityActivate(policy)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(policy), m.policy)
+	assert.Equal(t, dlms.SecurityAuthentication|dlms.SecurityEncryption, ciphering.Security)
+	assert.Equal(t, dlms.SecurityNone, c.GetSettings().Ciphering.Security)
+
+	// Key agreement
+	clientKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	m.clientKey = &clientKey.PublicKey
+	m.signingKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	settings := c.GetSettings()
+	settings.Ciphering.SystemTitle = clientSystemTitle
+	c.SetSettings(settings)
+
+	ciphering, err = s.KeyAgreement(cosem.KeyIDGlobalBroadcast, clientKey, &m.signingKey.PublicKey)
+	require.NoError(t, err)
+	assert.Len(t, ciphering.BroadcastKey, 16)
+	assert.Equal(t, m.keys[cosem.KeyIDGlobalBroadcast], ciphering.BroadcastKey)
+	assert.Equal(t, clientSystemTitle, ciphering.SystemTitle)
+	assert.Nil(t, c.GetSettings().Ciphering.BroadcastKey)
+
+	// A response not signed by the server is refused
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, err = s.KeyAgreement(cosem.KeyIDGlobalUnicast, clientKey, &other.PublicKey)
+	assertErrorCode(t, err, dlms.ErrorInvalidResponse)
+
+	// Certificates
+	csr, err := s.GenerateCertificateRequest(cosem.KeyPairDigitalSignature)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("csr"), csr)
+
+	require.NoError(t, s.ImportCertificate([]byte{0x01, 0x30, 0x82}))
+	certificates, err := s.Certificates()
+	require.NoError(t, err)
+	require.Len(t, certificates, 1)
+	assert.Equal(t, cosem.CertificateInfo{Entity: cosem.CertificateEntityClient, Type: cosem.KeyPairDigitalSignature, SerialNumber: []byte{0x01}, Issuer: []byte("CA"), Subject: []byte("client"), SubjectAltName: []byte{}}, certificates[0])
+
+	id := cosem.CertificateIdentification{SerialNumber: []byte{0x01}, Issuer: []byte("CA")}
+	der, err := s.ExportCertificate(id)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x30, 0x82}, der)
+
+	require.NoError(t, s.RemoveCertificate(id))
+	_, err = s.ExportCertificate(id)
+	assertErrorCode(t, err, dlms.ErrorActionRejected)
+}
+
+func TestWrapKey(t *testing.T) {
+	// Test vector of RFC 3394, section 4.1
+	kek, _ := hex.DecodeString("000102030405060708090A0B0C0D0E0F")
+	key, _ := hex.DecodeString("00112233445566778899AABBCCDDEEFF")
+	expected, _ := hex.DecodeString("1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE5")
+
+	wrapped, err := cosem.WrapKey(kek, key)
+	require.NoError(t, err)
+	assert.Equal(t, expected, wrapped)
+
+	unwrapped, err := cosem.UnwrapKey(kek, wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, key, unwrapped)
+
+	wrapped[0] ^= 0x01
+	_, err = cosem.UnwrapKey(kek, wrapped)
+	assert.Error(t, err)
+
+	_, err = cosem.WrapKey(kek, key[:12])
+	assert.Error(t, err)
+}
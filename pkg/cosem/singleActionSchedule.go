@@ -0,0 +1,222 @@
+package cosem
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const SingleActionScheduleClassID = 22
+
+const (
+	scheduleExecutedScript = 2
+	scheduleType           = 3
+	scheduleExecutionTime  = 4
+)
+
+// ScheduleType restricts the execution times of a single action schedule.
+type ScheduleType uint8
+
+const (
+	// ScheduleSingle has one execution time, with wildcards in the date allowed.
+	ScheduleSingle ScheduleType = 1
+	// ScheduleSameTime has execution times with the same time and without wildcards in the date.
+	ScheduleSameTime ScheduleType = 2
+	// ScheduleSameTimeWildcards has execution times with the same time and wildcards in the date allowed.
+	ScheduleSameTimeWildcards ScheduleType = 3
+	// ScheduleAnyTime has execution times with different times and without wildcards in the date.
+	ScheduleAnyTime ScheduleType = 4
+	// ScheduleAnyTimeWildcards has execution times with different times and wildcards in the date allowed.
+	ScheduleAnyTimeWildcards ScheduleType = 5
+)
+
+func (t ScheduleType) String() string {
+	switch t {
+	case ScheduleSingle:
+		return "single"
+	case ScheduleSameTime:
+		return "same time"
+	case ScheduleSameTimeWildcards:
+		return "same time with date wildcards"
+	case ScheduleAnyTime:
+		return "any time"
+	case ScheduleAnyTimeWildcards:
+		return "any time with date wildcards"
+	default:
+		return fmt.Sprintf("unknown (%d)", uint8(t))
+	}
+}
+
+// ExecutionTime is a time at which the script of a single action schedule is executed.
+type ExecutionTime struct {
+	Time Time
+	Date Date
+}
+
+// Data returns the execution time as A-XDR data.
+func (e ExecutionTime) Data() *axdr.DlmsData {
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		createOctetString(e.Time.Bytes()),
+		createOctetString(e.Date.Bytes()),
+	})
+}
+
+func (e ExecutionTime) String() string {
+	return e.Date.String() + " " + e.Time.String()
+}
+
+func decodeExecutionTime(data axdr.DlmsData) (e ExecutionTime, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 2)
+	if err != nil {
+		return
+	}
+
+	t, err := octetString(*fields[0], 4)
+	if err != nil {
+		return
+	}
+
+	d, err := octetString(*fields[1], 5)
+	if err != nil {
+		return
+	}
+
+	return ExecutionTime{Time: decodeTime(t), Date: decodeDate(d)}, nil
+}
+
+// SingleActionScheduleValue holds the attributes of a single action schedule.
+type SingleActionScheduleValue struct {
+	ExecutedScript ActionItem
+	Type           ScheduleType
+	ExecutionTimes []ExecutionTime
+}
+
+// Validate checks the execution times against the type of the schedule. The day of week isn't taken as
+// a wildcard, as it's not specified in most dates. If scripts is not nil, the executed script must be
+// in the script table with its logical name.
+func (v SingleActionScheduleValue) Validate(scripts map[dlms.Obis][]Script) error {
+	if v.Type < ScheduleSingle || v.Type > ScheduleAnyTimeWildcards {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("invalid schedule type %d", v.Type))
+	}
+
+	if v.Type == ScheduleSingle && len(v.ExecutionTimes) != 1 {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("%s schedule with %d execution times", v.Type, len(v.ExecutionTimes)))
+	}
+
+	for i, e := range v.ExecutionTimes {
+		if (v.Type == ScheduleSameTime || v.Type == ScheduleSameTimeWildcards) && e.Time != v.ExecutionTimes[0].Time {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("execution time %d is not at %s", i+1, v.ExecutionTimes[0].Time.String()))
+		}
+
+		if (v.Type == ScheduleSameTime || v.Type == ScheduleAnyTime) && dateHasWildcards(e.Date) {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("execution time %d has wildcards in date %s", i+1, e.Date.String()))
+		}
+	}
+
+	if scripts == nil {
+		return nil
+	}
+
+	if err := v.ExecutedScript.validate(scripts); err != nil {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("executed script references %v", err))
+	}
+
+	return nil
+}
+
+// String returns the configuration of the single action schedule, one execution time per line.
+func (v SingleActionScheduleValue) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "executes %s, %s", v.ExecutedScript.String(), v.Type.String())
+	for _, e := range v.ExecutionTimes {
+		fmt.Fprintf(&b, "\nat %s", e.String())
+	}
+
+	return b.String()
+}
+
+// dateHasWildcards returns if the year, month or day is not specified or is a special value, such as
+// the last day of the month.
+func dateHasWildcards(d Date) bool {
+	return d.Year == YearNotSpecified || d.Month > 12 || d.Day > 31
+}
+
+// SingleActionSchedule is an instance of the Single action schedule interface class (class_id 22).
+type SingleActionSchedule struct {
+	object
+}
+
+func NewSingleActionSchedule(client dlms.Client, logicalName string) *SingleActionSchedule {
+	return &SingleActionSchedule{object{client: client, classID: SingleActionScheduleClassID, logicalName: logicalName}}
+}
+
+// Read reads all the attributes of the single action schedule.
+func (s *SingleActionSchedule) Read() (v SingleActionScheduleValue, err error) {
+	var data axdr.DlmsData
+	if err = s.get(scheduleExecutedScript, &data); err != nil {
+		return
+	}
+	if v.ExecutedScript, err = decodeActionItem(data); err != nil {
+		return v, s.invalidData(scheduleExecutedScript, err)
+	}
+
+	var scheduleTypeValue uint8
+	if err = s.get(scheduleType, &scheduleTypeValue); err != nil {
+		return
+	}
+	v.Type = ScheduleType(scheduleTypeValue)
+
+	if err = s.get(scheduleExecutionTime, &data); err != nil {
+		return
+	}
+
+	times, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return v, s.invalidData(scheduleExecutionTime, err)
+	}
+
+	v.ExecutionTimes = make([]ExecutionTime, len(times))
+	for i, t := range times {
+		if v.ExecutionTimes[i], err = decodeExecutionTime(*t); err != nil {
+			return v, s.invalidData(scheduleExecutionTime, err)
+		}
+	}
+
+	return v, nil
+}
+
+// Write validates and writes all the attributes of the single action schedule. See
+// SingleActionScheduleValue.Validate for the scripts.
+func (s *SingleActionSchedule) Write(v SingleActionScheduleValue, scripts map[dlms.Obis][]Script) error {
+	err := v.Validate(scripts)
+	if err != nil {
+		return err
+	}
+
+	times := make([]*axdr.DlmsData, len(v.ExecutionTimes))
+	for i, e := range v.ExecutionTimes {
+		times[i] = e.Data()
+	}
+
+	if err = s.set(scheduleExecutedScript, v.ExecutedScript.Data()); err != nil {
+		return err
+	}
+	if err = s.set(scheduleType, axdr.CreateAxdrEnum(uint8(v.Type))); err != nil {
+		return err
+	}
+
+	return s.set(scheduleExecutionTime, axdr.CreateAxdrArray(times))
+}
+
+// Summary reads the single action schedule and returns its configuration.
+func (s *SingleActionSchedule) Summary() (string, error) {
+	v, err := s.Read()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("single action schedule %s\n%s", s.logicalName, v.String()), nil
+}
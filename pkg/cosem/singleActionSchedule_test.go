@@ -0,0 +1,64 @@
+package cosem_test
+
+import (
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSingleActionSchedule(t *testing.T) {
+	script := cosem.ActionItem{ScriptLogicalName: *dlms.CreateObis(cosem.TariffScriptTableLogicalName), ScriptSelector: 1}
+	at := cosem.ExecutionTime{
+		Time: cosem.Time{Hour: 0, Minute: 0, Second: 0, Hundredths: 0},
+		Date: cosem.Date{Year: cosem.YearNotSpecified, Month: cosem.NotSpecified, Day: 1, DayOfWeek: cosem.NotSpecified},
+	}
+
+	o := dlmsserver.NewObject(cosem.SingleActionScheduleClassID, 0, "0.0.15.0.0.255").
+		SetValue(2, *script.Data(), true).
+		SetValue(3, *axdr.CreateAxdrEnum(uint8(cosem.ScheduleSingle)), true).
+		SetValue(4, *axdr.CreateAxdrArray([]*axdr.DlmsData{at.Data()}), true)
+
+	s := cosem.NewSingleActionSchedule(connect(t, o), "0.0.15.0.0.255")
+
+	v, err := s.Read()
+	require.NoError(t, err)
+	assert.Equal(t, cosem.SingleActionScheduleValue{ExecutedScript: script, Type: cosem.ScheduleSingle, ExecutionTimes: []cosem.ExecutionTime{at}}, v)
+
+	summary, err := s.Summary()
+	require.NoError(t, err)
+	assert.Equal(t, "single action schedule 0.0.15.0.0.255\nexecutes script 1 of 0.0.10.0.100.255, single\nat *-*-01 00:00:00", summary)
+
+	v.Type = cosem.ScheduleSameTime
+	v.ExecutionTimes = []cosem.ExecutionTime{
+		{Time: cosem.Time{Hour: 22}, Date: cosem.Date{Year: 2024, Month: 6, Day: 1, DayOfWeek: cosem.NotSpecified}},
+		{Time: cosem.Time{Hour: 22}, Date: cosem.Date{Year: 2024, Month: 12, Day: 1, DayOfWeek: cosem.NotSpecified}},
+	}
+	require.NoError(t, s.Write(v, tariffScripts()))
+
+	read, err := s.Read()
+	require.NoError(t, err)
+	assert.Equal(t, v, read)
+
+	tests := []struct {
+		name  string
+		value cosem.SingleActionScheduleValue
+	}{
+		{"several times in single schedule", cosem.SingleActionScheduleValue{ExecutedScript: script, Type: cosem.ScheduleSingle, ExecutionTimes: v.ExecutionTimes}},
+		{"different times", cosem.SingleActionScheduleValue{ExecutedScript: script, Type: cosem.ScheduleSameTimeWildcards, ExecutionTimes: []cosem.ExecutionTime{v.ExecutionTimes[0], {Time: cosem.Time{Hour: 23}, Date: v.ExecutionTimes[1].Date}}}},
+		{"wildcards in date", cosem.SingleActionScheduleValue{ExecutedScript: script, Type: cosem.ScheduleAnyTime, ExecutionTimes: []cosem.ExecutionTime{at}}},
+		{"last day of month", cosem.SingleActionScheduleValue{ExecutedScript: script, Type: cosem.ScheduleSameTime, ExecutionTimes: []cosem.ExecutionTime{{Date: cosem.Date{Year: 2024, Month: 1, Day: cosem.DayLast}}}}},
+		{"unknown script", cosem.SingleActionScheduleValue{ExecutedScript: cosem.ActionItem{ScriptLogicalName: script.ScriptLogicalName, ScriptSelector: 9}, Type: cosem.ScheduleSingle, ExecutionTimes: []cosem.ExecutionTime{at}}},
+		{"invalid type", cosem.SingleActionScheduleValue{ExecutedScript: script, Type: 6, ExecutionTimes: []cosem.ExecutionTime{at}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertErrorCode(t, s.Write(tt.value, tariffScripts()), dlms.ErrorInvalidParameter)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package cosem
+
+import (
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const (
+	SpecialDaysTableClassID = 11
+	// SpecialDaysTableLogicalName is the logical name of the special days table of the meter
+	SpecialDaysTableLogicalName = "0.0.11.0.0.255"
+)
+
+const (
+	specialDaysEntries = 2
+	specialDaysInsert  = 1
+	specialDaysDelete  = 2
+)
+
+// SpecialDay is a date in which a day profile is used instead of the one of the week profile.
+type SpecialDay struct {
+	Index uint16
+	Date  Date
+	DayID uint8
+}
+
+// Data returns the special day as A-XDR data.
+func (s SpecialDay) Data() *axdr.DlmsData {
+	return axdr.CreateAxdrStructure([]*axdr.DlmsData{
+		axdr.CreateAxdrLongUnsigned(s.Index),
+		createOctetString(s.Date.Bytes()),
+		axdr.CreateAxdrUnsigned(s.DayID),
+	})
+}
+
+func decodeSpecialDay(data axdr.DlmsData) (s SpecialDay, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 3)
+	if err != nil {
+		return
+	}
+
+	index, ok1 := fields[0].Value.(uint16)
+	dayID, ok2 := fields[2].Value.(uint8)
+	if !ok1 || !ok2 {
+		err = fmt.Errorf("invalid special day")
+		return
+	}
+
+	date, err := octetString(*fields[1], 5)
+	if err != nil {
+		return
+	}
+
+	return SpecialDay{Index: index, Date: decodeDate(date), DayID: dayID}, nil
+}
+
+// ValidateSpecialDays checks that the day profile of each special day exists in the calendar.
+func (c Calendar) ValidateSpecialDays(days []SpecialDay) error {
+	for _, s := range days {
+		found := false
+		for _, d := range c.Days {
+			if d.ID == s.DayID {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("special day %d (%s) references unknown day profile %d", s.Index, s.Date.String(), s.DayID))
+		}
+	}
+
+	return nil
+}
+
+// SpecialDaysTable is an instance of the Special Days Table interface class (class_id 11).
+type SpecialDaysTable struct {
+	object
+}
+
+func NewSpecialDaysTable(client dlms.Client, logicalName string) *SpecialDaysTable {
+	return &SpecialDaysTable{object{client: client, classID: SpecialDaysTableClassID, logicalName: logicalName}}
+}
+
+// Entries reads the special days of the table.
+func (s *SpecialDaysTable) Entries() ([]SpecialDay, error) {
+	var data axdr.DlmsData
+
+	err := s.get(specialDaysEntries, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	elements, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return nil, s.invalidData(specialDaysEntries, err)
+	}
+
+	entries := make([]SpecialDay, len(elements))
+	for i, e := range elements {
+		if entries[i], err = decodeSpecialDay(*e); err != nil {
+			return nil, s.invalidData(specialDaysEntries, err)
+		}
+	}
+
+	return entries, nil
+}
+
+// Insert adds a special day to the table. An entry with the same index is replaced.
+func (s *SpecialDaysTable) Insert(entry SpecialDay) error {
+	return s.action(specialDaysInsert, entry.Data())
+}
+
+// Delete removes the special day with the given index.
+func (s *SpecialDaysTable) Delete(index uint16) error {
+	return s.action(specialDaysDelete, index)
+}
@@ -0,0 +1,83 @@
+package cosem_test
+
+import (
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMeterSpecialDays returns a special days table whose entries are kept sorted by index.
+func newMeterSpecialDays() *dlmsserver.Object {
+	var entries []*axdr.DlmsData
+
+	o := dlmsserver.NewObject(cosem.SpecialDaysTableClassID, 0, cosem.SpecialDaysTableLogicalName)
+
+	return o.
+		SetAttribute(2, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			return *axdr.CreateAxdrArray(entries), dlms.TagAccSuccess
+		}, nil).
+		SetMethod(1, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			fields, ok := data.Value.([]*axdr.DlmsData)
+			if data.Tag != axdr.TagStructure || !ok || len(fields) != 3 {
+				return nil, dlms.TagActTypeUnmatched
+			}
+
+			index := fields[0].Value.(uint16)
+			for i, e := range entries {
+				current := e.Value.([]*axdr.DlmsData)[0].Value.(uint16)
+				if current == index {
+					entries[i] = data
+					return nil, dlms.TagActSuccess
+				}
+				if current > index {
+					entries = append(entries[:i], append([]*axdr.DlmsData{data}, entries[i:]...)...)
+					return nil, dlms.TagActSuccess
+				}
+			}
+
+			entries = append(entries, data)
+			return nil, dlms.TagActSuccess
+		}).
+		SetMethod(2, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			for i, e := range entries {
+				if e.Value.([]*axdr.DlmsData)[0].Value == data.Value {
+					entries = append(entries[:i], entries[i+1:]...)
+					return nil, dlms.TagActSuccess
+				}
+			}
+
+			return nil, dlms.TagActObjectUndefined
+		})
+}
+
+func TestSpecialDaysTable(t *testing.T) {
+	s := cosem.NewSpecialDaysTable(connect(t, newMeterSpecialDays()), cosem.SpecialDaysTableLogicalName)
+
+	christmas := cosem.SpecialDay{Index: 2, Date: cosem.Date{Year: cosem.YearNotSpecified, Month: 12, Day: 25, DayOfWeek: cosem.NotSpecified}, DayID: 2}
+	newYear := cosem.SpecialDay{Index: 1, Date: cosem.Date{Year: cosem.YearNotSpecified, Month: 1, Day: 1, DayOfWeek: cosem.NotSpecified}, DayID: 2}
+
+	require.NoError(t, s.Insert(christmas))
+	require.NoError(t, s.Insert(newYear))
+
+	entries, err := s.Entries()
+	require.NoError(t, err)
+	assert.Equal(t, []cosem.SpecialDay{newYear, christmas}, entries)
+	assert.NoError(t, tariffCalendar().ValidateSpecialDays(entries))
+
+	require.NoError(t, s.Delete(1))
+	assertErrorCode(t, s.Delete(1), dlms.ErrorActionRejected)
+
+	entries, err = s.Entries()
+	require.NoError(t, err)
+	assert.Equal(t, []cosem.SpecialDay{christmas}, entries)
+
+	christmas.DayID = 3
+	err = tariffCalendar().ValidateSpecialDays([]cosem.SpecialDay{christmas})
+	assertErrorCode(t, err, dlms.ErrorInvalidParameter)
+	assert.Contains(t, err.Error(), "special day 2 (*-12-25) references unknown day profile 3")
+}
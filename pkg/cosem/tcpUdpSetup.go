@@ -0,0 +1,111 @@
+package cosem
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const TCPUDPSetupClassID = 41
+
+const (
+	tcpUDPPort              = 2
+	tcpUDPIPReference       = 3
+	tcpUDPMSS               = 4
+	tcpUDPSimultaneousConns = 5
+	tcpUDPInactivityTimeout = 6
+)
+
+// TCPUDPSetupValue holds the attributes of a TCP-UDP setup. IPReference is the logical name of the IP
+// setup used. A zero inactivity timeout means that the connection is never closed.
+type TCPUDPSetupValue struct {
+	Port              uint16
+	IPReference       dlms.Obis
+	MSS               uint16
+	SimultaneousConns uint8
+	InactivityTimeout time.Duration
+}
+
+// Validate checks the values against the ranges of the Blue Book.
+func (v TCPUDPSetupValue) Validate() error {
+	if v.MSS < 40 {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("MSS %d is lower than 40", v.MSS))
+	}
+
+	if v.SimultaneousConns == 0 {
+		return dlms.NewError(dlms.ErrorInvalidParameter, "at least one simultaneous connection is needed")
+	}
+
+	return validateSeconds("inactivity timeout", v.InactivityTimeout)
+}
+
+// TCPUDPSetup is an instance of the TCP-UDP setup interface class (class_id 41).
+type TCPUDPSetup struct {
+	object
+}
+
+func NewTCPUDPSetup(client dlms.Client, logicalName string) *TCPUDPSetup {
+	return &TCPUDPSetup{object{client: client, classID: TCPUDPSetupClassID, logicalName: logicalName}}
+}
+
+// Read reads all the attributes of the TCP-UDP setup.
+func (s *TCPUDPSetup) Read() (v TCPUDPSetupValue, err error) {
+	if err = s.get(tcpUDPPort, &v.Port); err != nil {
+		return
+	}
+
+	var data axdr.DlmsData
+	if err = s.get(tcpUDPIPReference, &data); err != nil {
+		return
+	}
+	if v.IPReference, err = dlms.DecodeLogicalName(data); err != nil {
+		return v, s.invalidData(tcpUDPIPReference, err)
+	}
+
+	if err = s.get(tcpUDPMSS, &v.MSS); err != nil {
+		return
+	}
+	if err = s.get(tcpUDPSimultaneousConns, &v.SimultaneousConns); err != nil {
+		return
+	}
+
+	var timeout uint16
+	err = s.get(tcpUDPInactivityTimeout, &timeout)
+	v.InactivityTimeout = time.Duration(timeout) * time.Second
+
+	return
+}
+
+// Write validates and writes all the attributes of the TCP-UDP setup.
+func (s *TCPUDPSetup) Write(v TCPUDPSetupValue) error {
+	err := v.Validate()
+	if err != nil {
+		return err
+	}
+
+	if err = s.set(tcpUDPPort, v.Port); err != nil {
+		return err
+	}
+	if err = s.set(tcpUDPIPReference, axdr.CreateAxdrOctetString(v.IPReference.String())); err != nil {
+		return err
+	}
+	if err = s.set(tcpUDPMSS, v.MSS); err != nil {
+		return err
+	}
+	if err = s.set(tcpUDPSimultaneousConns, v.SimultaneousConns); err != nil {
+		return err
+	}
+
+	return s.set(tcpUDPInactivityTimeout, uint16(v.InactivityTimeout/time.Second))
+}
+
+// validateSeconds checks that a duration can be sent as long-unsigned seconds.
+func validateSeconds(name string, d time.Duration) error {
+	if d < 0 || d > 0xFFFF*time.Second || d%time.Second != 0 {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("%s %s must be whole seconds up to 65535", name, d))
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package cosem_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTCPUDPSetup(t *testing.T) {
+	o := dlmsserver.NewObject(cosem.TCPUDPSetupClassID, 0, "0.0.25.0.0.255").
+		SetValue(2, *axdr.CreateAxdrLongUnsigned(4059), true).
+		SetValue(3, *axdr.CreateAxdrOctetString("0.0.25.1.0.255"), true).
+		SetValue(4, *axdr.CreateAxdrLongUnsigned(576), true).
+		SetValue(5, *axdr.CreateAxdrUnsigned(1), true).
+		SetValue(6, *axdr.CreateAxdrLongUnsigned(180), true)
+
+	s := cosem.NewTCPUDPSetup(connect(t, o), "0.0.25.0.0.255")
+
+	v, err := s.Read()
+	require.NoError(t, err)
+	assert.Equal(t, cosem.TCPUDPSetupValue{
+		Port:              4059,
+		IPReference:       *dlms.CreateObis("0.0.25.1.0.255"),
+		MSS:               576,
+		SimultaneousConns: 1,
+		InactivityTimeout: 3 * time.Minute,
+	}, v)
+
+	v.Port = 4060
+	v.SimultaneousConns = 2
+	v.InactivityTimeout = 0
+	require.NoError(t, s.Write(v))
+
+	read, err := s.Read()
+	require.NoError(t, err)
+	assert.Equal(t, v, read)
+
+	v.MSS = 20
+	assertErrorCode(t, s.Write(v), dlms.ErrorInvalidParameter)
+
+	v.MSS = 576
+	v.InactivityTimeout = 1500 * time.Millisecond
+	assertErrorCode(t, s.Write(v), dlms.ErrorInvalidParameter)
+}
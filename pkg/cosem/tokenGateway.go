@@ -0,0 +1,217 @@
+package cosem
+
+import (
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const (
+	TokenGatewayClassID = 115
+	// TokenGatewayLogicalName is the logical name of the token gateway object of the meter
+	TokenGatewayLogicalName = "0.0.19.40.0.255"
+)
+
+const (
+	tokenGatewayToken          = 2
+	tokenGatewayTime           = 3
+	tokenGatewayDescription    = 4
+	tokenGatewayDeliveryMethod = 5
+	tokenGatewayStatus         = 6
+	tokenGatewayEnter          = 1
+)
+
+// TokenDeliveryMethod is how the last token was received.
+type TokenDeliveryMethod uint8
+
+const (
+	TokenDeliveryRemote TokenDeliveryMethod = 0
+	TokenDeliveryLocal  TokenDeliveryMethod = 1
+	TokenDeliveryManual TokenDeliveryMethod = 2
+)
+
+func (m TokenDeliveryMethod) String() string {
+	switch m {
+	case TokenDeliveryRemote:
+		return "remote"
+	case TokenDeliveryLocal:
+		return "local"
+	case TokenDeliveryManual:
+		return "manual"
+	default:
+		return fmt.Sprintf("unknown (%d)", uint8(m))
+	}
+}
+
+// TokenStatusCode is the result of the processing of a token.
+type TokenStatusCode uint8
+
+const (
+	TokenFormatOK              TokenStatusCode = 0
+	TokenAuthenticationOK      TokenStatusCode = 1
+	TokenValidationOK          TokenStatusCode = 2
+	TokenExecutionOK           TokenStatusCode = 3
+	TokenFormatFailure         TokenStatusCode = 4
+	TokenAuthenticationFailure TokenStatusCode = 5
+	TokenValidationFailure     TokenStatusCode = 6
+	TokenExecutionFailure      TokenStatusCode = 7
+	TokenReceivedNotExecuted   TokenStatusCode = 8
+)
+
+func (c TokenStatusCode) String() string {
+	switch c {
+	case TokenFormatOK:
+		return "format OK"
+	case TokenAuthenticationOK:
+		return "authentication OK"
+	case TokenValidationOK:
+		return "validation OK"
+	case TokenExecutionOK:
+		return "execution OK"
+	case TokenFormatFailure:
+		return "format failure"
+	case TokenAuthenticationFailure:
+		return "authentication failure"
+	case TokenValidationFailure:
+		return "validation failure"
+	case TokenExecutionFailure:
+		return "execution failure"
+	case TokenReceivedNotExecuted:
+		return "received and not yet processed"
+	default:
+		return fmt.Sprintf("unknown (%d)", uint8(c))
+	}
+}
+
+// Failed returns true if the token was refused.
+func (c TokenStatusCode) Failed() bool {
+	return c >= TokenFormatFailure && c <= TokenExecutionFailure
+}
+
+// TokenStatus is the status of the last token. Data holds manufacturer specific flags, indexed by bit.
+type TokenStatus struct {
+	Code TokenStatusCode
+	Data []bool
+}
+
+func decodeTokenStatus(data axdr.DlmsData) (s TokenStatus, err error) {
+	fields, err := dlms.DataAsSlice(data, axdr.TagStructure, 2)
+	if err != nil {
+		return
+	}
+
+	code, ok1 := fields[0].Value.(uint8)
+	bits, ok2 := fields[1].Value.(string)
+	if !ok1 || !ok2 || fields[1].Tag != axdr.TagBitString {
+		return s, fmt.Errorf("invalid token status")
+	}
+
+	s.Code = TokenStatusCode(code)
+	s.Data = make([]bool, len(bits))
+	for i, b := range bits {
+		s.Data[i] = b == '1'
+	}
+
+	return
+}
+
+// TokenGatewayValue holds the attributes of a token gateway, that describe the last token received.
+type TokenGatewayValue struct {
+	Token          []byte
+	Time           DateTime
+	Descriptions   [][]byte
+	DeliveryMethod TokenDeliveryMethod
+	Status         TokenStatus
+}
+
+// TokenGateway is an instance of the Token gateway interface class (class_id 115).
+type TokenGateway struct {
+	object
+}
+
+func NewTokenGateway(client dlms.Client, logicalName string) *TokenGateway {
+	return &TokenGateway{object{client: client, classID: TokenGatewayClassID, logicalName: logicalName}}
+}
+
+// Read reads all the attributes of the token gateway.
+func (g *TokenGateway) Read() (v TokenGatewayValue, err error) {
+	var data axdr.DlmsData
+	if err = g.get(tokenGatewayToken, &data); err != nil {
+		return
+	}
+	if v.Token, err = octetString(data, 0); err != nil {
+		return v, g.invalidData(tokenGatewayToken, err)
+	}
+
+	if err = g.get(tokenGatewayTime, &data); err != nil {
+		return
+	}
+	if v.Time, err = DecodeDateTime(data); err != nil {
+		return v, g.invalidData(tokenGatewayTime, err)
+	}
+
+	if err = g.get(tokenGatewayDescription, &data); err != nil {
+		return
+	}
+	descriptions, err := dlms.DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return v, g.invalidData(tokenGatewayDescription, err)
+	}
+	v.Descriptions = make([][]byte, len(descriptions))
+	for i, d := range descriptions {
+		if v.Descriptions[i], err = octetString(*d, 0); err != nil {
+			return v, g.invalidData(tokenGatewayDescription, err)
+		}
+	}
+
+	var method uint8
+	if err = g.get(tokenGatewayDeliveryMethod, &method); err != nil {
+		return
+	}
+	v.DeliveryMethod = TokenDeliveryMethod(method)
+
+	v.Status, err = g.Status()
+	return
+}
+
+// Status reads the status of the last token.
+func (g *TokenGateway) Status() (TokenStatus, error) {
+	var data axdr.DlmsData
+
+	err := g.get(tokenGatewayStatus, &data)
+	if err != nil {
+		return TokenStatus{}, err
+	}
+
+	s, err := decodeTokenStatus(data)
+	if err != nil {
+		return s, g.invalidData(tokenGatewayStatus, err)
+	}
+
+	return s, nil
+}
+
+// Enter sends a token to the meter and reads its status. An error is returned if the meter refused
+// the token, together with the status.
+func (g *TokenGateway) Enter(token []byte) (TokenStatus, error) {
+	if len(token) == 0 {
+		return TokenStatus{}, dlms.NewError(dlms.ErrorInvalidParameter, "empty token")
+	}
+
+	err := g.action(tokenGatewayEnter, createOctetString(token))
+	if err != nil {
+		return TokenStatus{}, err
+	}
+
+	status, err := g.Status()
+	if err != nil {
+		return status, err
+	}
+
+	if status.Code.Failed() {
+		return status, dlms.NewError(dlms.ErrorActionRejected, fmt.Sprintf("token refused: %s", status.Code.String()))
+	}
+
+	return status, nil
+}
@@ -0,0 +1,69 @@
+package cosem_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/cosem"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenGateway(t *testing.T) {
+	valid := []byte("12345678901234567890")
+	credit := &meterCredit{amount: 100}
+
+	var token []byte
+	code := cosem.TokenReceivedNotExecuted
+
+	o := dlmsserver.NewObject(cosem.TokenGatewayClassID, 0, cosem.TokenGatewayLogicalName).
+		SetAttribute(2, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			return *axdr.CreateAxdrOctetString(hex.EncodeToString(token)), dlms.TagAccSuccess
+		}, nil).
+		SetValue(3, *axdr.CreateAxdrOctetString("07e8010101000000ff800000"), false).
+		SetValue(4, *axdr.CreateAxdrArray([]*axdr.DlmsData{axdr.CreateAxdrOctetString(hex.EncodeToString([]byte("credit")))}), false).
+		SetValue(5, *axdr.CreateAxdrEnum(uint8(cosem.TokenDeliveryRemote)), false).
+		SetAttribute(6, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			return *axdr.CreateAxdrStructure([]*axdr.DlmsData{axdr.CreateAxdrEnum(uint8(code)), axdr.CreateAxdrBitString("10")}), dlms.TagAccSuccess
+		}, nil).
+		SetMethod(1, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			token, _ = hex.DecodeString(data.Value.(string))
+			if !bytes.Equal(token, valid) {
+				code = cosem.TokenAuthenticationFailure
+				return nil, dlms.TagActSuccess
+			}
+			code = cosem.TokenExecutionOK
+			credit.amount += 500
+			return nil, dlms.TagActSuccess
+		})
+
+	c := connect(t, o, credit.object(tokenCreditLogicalName, cosem.CreditTypeToken))
+	g := cosem.NewTokenGateway(c, cosem.TokenGatewayLogicalName)
+
+	status, err := g.Enter(valid)
+	require.NoError(t, err)
+	assert.Equal(t, cosem.TokenStatus{Code: cosem.TokenExecutionOK, Data: []bool{true, false}}, status)
+
+	amount, err := cosem.NewCredit(c, tokenCreditLogicalName).CurrentAmount()
+	require.NoError(t, err)
+	assert.Equal(t, int32(600), amount)
+
+	v, err := g.Read()
+	require.NoError(t, err)
+	assert.Equal(t, valid, v.Token)
+	assert.Equal(t, [][]byte{[]byte("credit")}, v.Descriptions)
+	assert.Equal(t, cosem.TokenDeliveryRemote, v.DeliveryMethod)
+	assert.Equal(t, cosem.TokenExecutionOK, v.Status.Code)
+
+	status, err = g.Enter([]byte("00000000000000000000"))
+	assertErrorCode(t, err, dlms.ErrorActionRejected)
+	assert.Equal(t, cosem.TokenAuthenticationFailure, status.Code)
+	assert.Equal(t, "authentication failure", status.Code.String())
+
+	_, err = g.Enter(nil)
+	assertErrorCode(t, err, dlms.ErrorInvalidParameter)
+}
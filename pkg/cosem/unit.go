@@ -0,0 +1,215 @@
+package cosem
+
+// Unit is the physical unit of a value, as enumerated in the Blue Book.
+type Unit uint8
+
+const (
+	UnitYear                             Unit = 1
+	UnitMonth                            Unit = 2
+	UnitWeek                             Unit = 3
+	UnitDay                              Unit = 4
+	UnitHour                             Unit = 5
+	UnitMinute                           Unit = 6
+	UnitSecond                           Unit = 7
+	UnitPhaseAngle                       Unit = 8
+	UnitTemperature                      Unit = 9
+	UnitCurrency                         Unit = 10
+	UnitLength                           Unit = 11
+	UnitSpeed                            Unit = 12
+	UnitVolume                           Unit = 13
+	UnitCorrectedVolume                  Unit = 14
+	UnitVolumeFlux                       Unit = 15
+	UnitCorrectedVolumeFlux              Unit = 16
+	UnitVolumeFluxDay                    Unit = 17
+	UnitCorrectedVolumeFluxDay           Unit = 18
+	UnitLitre                            Unit = 19
+	UnitMass                             Unit = 20
+	UnitForce                            Unit = 21
+	UnitEnergyNewtonMeter                Unit = 22
+	UnitPressurePascal                   Unit = 23
+	UnitPressureBar                      Unit = 24
+	UnitEnergyJoule                      Unit = 25
+	UnitThermalPower                     Unit = 26
+	UnitActivePower                      Unit = 27
+	UnitApparentPower                    Unit = 28
+	UnitReactivePower                    Unit = 29
+	UnitActiveEnergy                     Unit = 30
+	UnitApparentEnergy                   Unit = 31
+	UnitReactiveEnergy                   Unit = 32
+	UnitCurrent                          Unit = 33
+	UnitElectricalCharge                 Unit = 34
+	UnitVoltage                          Unit = 35
+	UnitElectricFieldStrength            Unit = 36
+	UnitCapacitance                      Unit = 37
+	UnitResistance                       Unit = 38
+	UnitResistivity                      Unit = 39
+	UnitMagneticFlux                     Unit = 40
+	UnitInduction                        Unit = 41
+	UnitMagneticFieldStrength            Unit = 42
+	UnitInductivity                      Unit = 43
+	UnitFrequency                        Unit = 44
+	UnitActiveEnergyMeterConstant        Unit = 45
+	UnitReactiveEnergyMeterConstant      Unit = 46
+	UnitApparentEnergyMeterConstant      Unit = 47
+	UnitVoltageSquaredHours              Unit = 48
+	UnitCurrentSquaredHours              Unit = 49
+	UnitMassFlux                         Unit = 50
+	UnitConductance                      Unit = 51
+	UnitKelvin                           Unit = 52
+	UnitVoltageSquaredHoursMeterConstant Unit = 53
+	UnitCurrentSquaredHoursMeterConstant Unit = 54
+	UnitVolumeMeterConstant              Unit = 55
+	UnitPercentage                       Unit = 56
+	UnitAmpereHours                      Unit = 57
+	UnitEnergyPerVolume                  Unit = 60
+	UnitCalorificValue                   Unit = 61
+	UnitMolePercent                      Unit = 62
+	UnitMassDensity                      Unit = 63
+	UnitDynamicViscosity                 Unit = 64
+	UnitSpecificEnergy                   Unit = 65
+	UnitPressureGramPerCm2               Unit = 66
+	UnitPressureAtmosphere               Unit = 67
+	UnitSignalStrengthMilliWatt          Unit = 70
+	UnitSignalStrengthMicroVolt          Unit = 71
+	UnitLogarithmic                      Unit = 72
+	UnitOther                            Unit = 254
+	UnitCount                            Unit = 255
+)
+
+// String returns the SI symbol of the unit. Dimensionless units return an empty string.
+func (u Unit) String() string {
+	switch u {
+	case UnitYear:
+		return "a"
+	case UnitMonth:
+		return "mo"
+	case UnitWeek:
+		return "wk"
+	case UnitDay:
+		return "d"
+	case UnitHour:
+		return "h"
+	case UnitMinute:
+		return "min"
+	case UnitSecond:
+		return "s"
+	case UnitPhaseAngle:
+		return "°"
+	case UnitTemperature:
+		return "°C"
+	case UnitCurrency:
+		return "currency"
+	case UnitLength:
+		return "m"
+	case UnitSpeed:
+		return "m/s"
+	case UnitVolume, UnitCorrectedVolume:
+		return "m³"
+	case UnitVolumeFlux, UnitCorrectedVolumeFlux:
+		return "m³/h"
+	case UnitVolumeFluxDay, UnitCorrectedVolumeFluxDay:
+		return "m³/d"
+	case UnitLitre:
+		return "l"
+	case UnitMass:
+		return "kg"
+	case UnitForce:
+		return "N"
+	case UnitEnergyNewtonMeter:
+		return "Nm"
+	case UnitPressurePascal:
+		return "Pa"
+	case UnitPressureBar:
+		return "bar"
+	case UnitEnergyJoule:
+		return "J"
+	case UnitThermalPower:
+		return "J/h"
+	case UnitActivePower:
+		return "W"
+	case UnitApparentPower:
+		return "VA"
+	case UnitReactivePower:
+		return "var"
+	case UnitActiveEnergy:
+		return "Wh"
+	case UnitApparentEnergy:
+		return "VAh"
+	case UnitReactiveEnergy:
+		return "varh"
+	case UnitCurrent:
+		return "A"
+	case UnitElectricalCharge:
+		return "C"
+	case UnitVoltage:
+		return "V"
+	case UnitElectricFieldStrength:
+		return "V/m"
+	case UnitCapacitance:
+		return "F"
+	case UnitResistance:
+		return "Ω"
+	case UnitResistivity:
+		return "Ωm²/m"
+	case UnitMagneticFlux:
+		return "Wb"
+	case UnitInduction:
+		return "T"
+	case UnitMagneticFieldStrength:
+		return "A/m"
+	case UnitInductivity:
+		return "H"
+	case UnitFrequency:
+		return "Hz"
+	case UnitActiveEnergyMeterConstant:
+		return "1/(Wh)"
+	case UnitReactiveEnergyMeterConstant:
+		return "1/(varh)"
+	case UnitApparentEnergyMeterConstant:
+		return "1/(VAh)"
+	case UnitVoltageSquaredHours:
+		return "V²h"
+	case UnitCurrentSquaredHours:
+		return "A²h"
+	case UnitMassFlux:
+		return "kg/s"
+	case UnitConductance:
+		return "S"
+	case UnitKelvin:
+		return "K"
+	case UnitVoltageSquaredHoursMeterConstant:
+		return "1/(V²h)"
+	case UnitCurrentSquaredHoursMeterConstant:
+		return "1/(A²h)"
+	case UnitVolumeMeterConstant:
+		return "1/m³"
+	case UnitPercentage:
+		return "%"
+	case UnitAmpereHours:
+		return "Ah"
+	case UnitEnergyPerVolume:
+		return "Wh/m³"
+	case UnitCalorificValue:
+		return "J/m³"
+	case UnitMolePercent:
+		return "Mol %"
+	case UnitMassDensity:
+		return "g/m³"
+	case UnitDynamicViscosity:
+		return "Pa s"
+	case UnitSpecificEnergy:
+		return "J/kg"
+	case UnitPressureGramPerCm2:
+		return "g/cm²"
+	case UnitPressureAtmosphere:
+		return "atm"
+	case UnitSignalStrengthMilliWatt:
+		return "dBm"
+	case UnitSignalStrengthMicroVolt:
+		return "dBµV"
+	case UnitLogarithmic:
+		return "dB"
+	default:
+		return ""
+	}
+}
@@ -213,3 +213,84 @@ func parseUserInformation(settings *Settings, tagLength int, src []byte) (ir *In
 
 	return
 }
+
+// EncodeAARE encodes the AARE sent by a server. If ciphering is used, the initiate response is
+// sent as a glo-initiate-response using the own system title and the unicast key.
+func EncodeAARE(settings *Settings, aare AARE) (out []byte, err error) {
+	var buf bytes.Buffer
+
+	// Application Association Response
+	buf.WriteByte(TagAARE.Value())
+
+	// APDU length (to be filled in later)
+	buf.WriteByte(0x00)
+
+	// Application context name - 0xA1
+	buf.WriteByte(BERTypeContext | BERTypeConstructed | PduTypeApplicationContextName)
+	buf.Write([]byte{0x09, 0x06, 0x07, 0x60, 0x85, 0x74, 0x05, 0x08, 0x01})
+	buf.WriteByte(byte(aare.ApplicationContext))
+
+	// Association result - 0xA2
+	buf.WriteByte(BERTypeContext | BERTypeConstructed | PduTypeCalledAPTitle)
+	buf.Write([]byte{0x03, 0x02, 0x01})
+	buf.WriteByte(byte(aare.AssociationResult))
+
+	// Associate source diagnostic (acse-service-user) - 0xA3
+	buf.WriteByte(BERTypeContext | BERTypeConstructed | PduTypeCalledAEQualifier)
+	buf.Write([]byte{0x05, 0xA1, 0x03, 0x02, 0x01})
+	buf.WriteByte(byte(aare.SourceDiagnostic))
+
+	if len(aare.SourceSystemTitle) > 0 {
+		// Responding AP title - 0xA4
+		buf.WriteByte(BERTypeContext | BERTypeConstructed | PduTypeCalledAPInvocationID)
+		buf.Write([]byte{0x0A, 0x04, 0x08})
+		buf.Write(aare.SourceSystemTitle)
+	}
+
+	var userInfo []byte
+	switch {
+	case aare.InitiateResponse != nil:
+		userInfo, err = aare.InitiateResponse.Encode()
+		if err != nil {
+			return
+		}
+
+		if settings != nil && settings.Ciphering.Security != SecurityNone {
+			cfg := Cipher{
+				Tag:          TagGloInitiateResponse,
+				Security:     settings.Ciphering.Security,
+				SystemTitle:  settings.Ciphering.SystemTitle,
+				Key:          settings.Ciphering.UnicastKey,
+				AuthKey:      settings.Ciphering.AuthenticationKey,
+				FrameCounter: settings.Ciphering.UnicastKeyIC,
+			}
+			settings.Ciphering.UnicastKeyIC++
+
+			userInfo, err = CipherData(cfg, userInfo)
+			if err != nil {
+				return
+			}
+		}
+	case aare.ConfirmedServiceError != nil:
+		userInfo, err = aare.ConfirmedServiceError.Encode()
+		if err != nil {
+			return
+		}
+	}
+
+	if len(userInfo) > 0 {
+		// User information - 0xBE
+		buf.WriteByte(BERTypeContext | BERTypeConstructed | PduTypeUserInformation)
+		buf.WriteByte(byte(2 + len(userInfo)))
+		buf.WriteByte(0x04)
+		buf.WriteByte(byte(len(userInfo)))
+		buf.Write(userInfo)
+	}
+
+	out = buf.Bytes()
+
+	// Add length
+	out[1] = byte(len(out) - 2)
+
+	return
+}
@@ -65,3 +65,93 @@ func TestDecodeAAREWithSecurity(t *testing.T) {
 	sourceSystemTitle := decodeHexString("4C475A2022604828")
 	assert.Equal(t, sourceSystemTitle, aare.SourceSystemTitle)
 }
+
+func TestEncodeAARE(t *testing.T) {
+	aare := AARE{
+		ApplicationContext: ApplicationContextLNNoCiphering,
+		AssociationResult:  AssociationResultAccepted,
+		SourceDiagnostic:   SourceDiagnosticNone,
+		InitiateResponse:   CreateInitiateResponse(nil, 0x0000101D, 128),
+	}
+
+	out, err := EncodeAARE(nil, aare)
+	assert.NoError(t, err)
+	assert.Equal(t, decodeHexString("6129A109060760857405080101A203020100A305A103020100BE10040E0800065F1F040000101D00800007"), out)
+
+	decoded, err := DecodeAARE(nil, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, aare, decoded)
+}
+
+func TestEncodeRejectedAARE(t *testing.T) {
+	aare := AARE{
+		ApplicationContext: ApplicationContextLNNoCiphering,
+		AssociationResult:  AssociationResultPermanentRejected,
+		SourceDiagnostic:   SourceDiagnosticAuthenticationFailure,
+	}
+
+	out, err := EncodeAARE(nil, aare)
+	assert.NoError(t, err)
+	assert.Equal(t, decodeHexString("6117A109060760857405080101A203020101A305A10302010D"), out)
+}
+
+func TestEncodeAAREWithSecurity(t *testing.T) {
+	serverSettings := &Settings{
+		Ciphering: Ciphering{
+			Security:          SecurityEncryption | SecurityAuthentication,
+			SystemTitle:       decodeHexString("4C475A2022604828"),
+			UnicastKey:        decodeHexString("00112233445566778899AABBCCDDEEFF"),
+			AuthenticationKey: decodeHexString("00112233445566778899AABBCCDDEEFF"),
+			UnicastKeyIC:      0x5A,
+		},
+	}
+
+	aare := AARE{
+		ApplicationContext: ApplicationContextLNCiphering,
+		AssociationResult:  AssociationResultAccepted,
+		SourceDiagnostic:   SourceDiagnosticNone,
+		SourceSystemTitle:  serverSettings.Ciphering.SystemTitle,
+		InitiateResponse:   CreateInitiateResponse(nil, 0x0000101D, 250),
+	}
+
+	out, err := EncodeAARE(serverSettings, aare)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0x5B), serverSettings.Ciphering.UnicastKeyIC)
+
+	clientSettings := &Settings{
+		Ciphering: Ciphering{
+			Security:          SecurityEncryption | SecurityAuthentication,
+			UnicastKey:        decodeHexString("00112233445566778899AABBCCDDEEFF"),
+			AuthenticationKey: decodeHexString("00112233445566778899AABBCCDDEEFF"),
+		},
+	}
+
+	decoded, err := DecodeAARE(clientSettings, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, aare, decoded)
+	assert.Equal(t, decodeHexString("4C475A2022604828"), clientSettings.Ciphering.SourceSystemTitle)
+}
+
+func TestCreateAssociationInfo(t *testing.T) {
+	src := decodeHexString("6136A109060760857405080101A203020100A305A103020100A40A04084C475A2022604828BE11040F080102065F1F04001C1B200080FA00")
+	aare, err := DecodeAARE(nil, &src)
+	assert.NoError(t, err)
+
+	info := CreateAssociationInfo(aare)
+	assert.NotNil(t, info)
+	assert.Equal(t, ApplicationContextLNNoCiphering, info.ApplicationContext)
+	assert.Equal(t, 0x1C1B20, info.Conformance)
+	assert.Equal(t, uint8(DlmsVersion), info.DlmsVersion)
+	assert.Equal(t, uint16(VAANameSN), info.VAAName)
+	assert.Equal(t, uint16(128), info.ServerMaxReceivePduSize)
+	assert.Equal(t, uint8(2), *info.QualityOfService)
+	assert.Equal(t, decodeHexString("4C475A2022604828"), info.RespondingSystemTitle)
+
+	assert.True(t, info.IsAccepted(ConformanceBlockRead|ConformanceBlockBlockTransferWithGetOrRead))
+	assert.False(t, info.IsAccepted(ConformanceBlockRead|ConformanceBlockGet))
+
+	src = decodeHexString("6117A109060760857405080101A203020101A305A10302010D")
+	aare, err = DecodeAARE(nil, &src)
+	assert.NoError(t, err)
+	assert.Nil(t, CreateAssociationInfo(aare))
+}
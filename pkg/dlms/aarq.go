@@ -107,10 +107,16 @@ func generateApplicationContextName(settings *Settings) (out []byte) {
 	// Application context name - 0xA1
 	buf.WriteByte(BERTypeContext | BERTypeConstructed | PduTypeApplicationContextName)
 	buf.Write([]byte{0x09, 0x06, 0x07, 0x60, 0x85, 0x74, 0x05, 0x08, 0x01})
-	if settings.Ciphering.Security == SecurityNone && len(settings.Ciphering.SystemTitle) == 0 {
-		buf.WriteByte(byte(ApplicationContextLNNoCiphering))
-	} else {
+	ciphered := settings.Ciphering.Security != SecurityNone || len(settings.Ciphering.SystemTitle) != 0
+	switch {
+	case settings.Referencing == ReferencingShortName && ciphered:
+		buf.WriteByte(byte(ApplicationContextSNCiphering))
+	case settings.Referencing == ReferencingShortName:
+		buf.WriteByte(byte(ApplicationContextSNNoCiphering))
+	case ciphered:
 		buf.WriteByte(byte(ApplicationContextLNCiphering))
+	default:
+		buf.WriteByte(byte(ApplicationContextLNNoCiphering))
 	}
 
 	if len(settings.Ciphering.SystemTitle) > 0 {
@@ -217,3 +223,148 @@ func getInitiateRequest(settings *Settings) (out []byte) {
 
 	return
 }
+
+type AARQ struct {
+	ApplicationContext ApplicationContext
+	CallingAPTitle     []byte
+	Authentication     Authentication
+	Password           []byte
+	InitiateRequest    *InitiateRequest
+}
+
+// DecodeAARQ decodes an AARQ received by a server. If the user information is ciphered,
+// settings are used to decipher it and the calling AP title is stored as SourceSystemTitle.
+func DecodeAARQ(settings *Settings, ori *[]byte) (out AARQ, err error) {
+	src := *ori
+
+	if len(src) < 2 {
+		err = ErrWrongLength(len(src), 2)
+		return
+	}
+
+	if src[0] != TagAARQ.Value() {
+		err = ErrWrongTag(0, src[0], byte(TagAARQ))
+		return
+	}
+
+	length := int(2 + src[1])
+	if len(src) < length {
+		err = ErrWrongLength(len(src), length)
+		return
+	}
+
+	src = src[2:]
+	length -= 2
+
+	for {
+		if length == 0 {
+			break
+		}
+
+		if len(src) < 2 {
+			err = ErrWrongLength(len(src), 2)
+			return
+		}
+
+		tagLength := int(src[1])
+		if len(src) < (2 + tagLength) {
+			err = ErrWrongLength(len(src), 2+tagLength)
+			return
+		}
+
+		tag := src[0]
+		switch tag {
+		case BERTypeContext | BERTypeConstructed | PduTypeApplicationContextName:
+			// Application context name - 0xA1
+			out.ApplicationContext, err = parseApplicationContextName(tagLength, src)
+		case BERTypeContext | BERTypeConstructed | PduTypeCallingAPTitle:
+			// Calling AP title - 0xA6
+			out.CallingAPTitle, err = parseAPTitle(tagLength, src)
+			if settings != nil {
+				settings.Ciphering.SourceSystemTitle = out.CallingAPTitle
+			}
+		case BERTypeContext | PduTypeMechanismName:
+			// Mechanism name - 0x8B
+			out.Authentication, err = parseMechanismName(tagLength, src)
+		case BERTypeContext | BERTypeConstructed | PduTypeCallingAuthenticationValue:
+			// Calling authentication value - 0xAC
+			out.Password, err = parseAuthenticationValue(tagLength, src)
+		case BERTypeContext | BERTypeConstructed | PduTypeUserInformation:
+			// User information - 0xBE
+			out.InitiateRequest, err = parseInitiateRequest(settings, tagLength, src)
+		}
+
+		if err != nil {
+			return
+		}
+
+		src = src[2+tagLength:]
+		length -= 2 + tagLength
+	}
+
+	(*ori) = (*ori)[len((*ori))-len(src):]
+	return
+}
+
+func parseMechanismName(tagLength int, src []byte) (out Authentication, err error) {
+	if tagLength != 7 {
+		err = ErrWrongLength(tagLength, 7)
+		return
+	}
+	rsp := []byte{0x60, 0x85, 0x74, 0x05, 0x08, 0x02}
+	if !bytes.Equal(src[2:8], rsp) {
+		err = ErrWrongSlice(src[2:8], rsp)
+		return
+	}
+	out = Authentication(src[8])
+	return
+}
+
+func parseAuthenticationValue(tagLength int, src []byte) (out []byte, err error) {
+	if tagLength < 2 || src[2] != BERTypeContext || int(src[3]) != tagLength-2 {
+		err = errors.New("calling authentication value length error")
+		return
+	}
+	out = make([]byte, tagLength-2)
+	copy(out, src[4:2+tagLength])
+	return
+}
+
+func parseInitiateRequest(settings *Settings, tagLength int, src []byte) (out *InitiateRequest, err error) {
+	if tagLength < 4 {
+		err = ErrWrongLength(tagLength, 4)
+		return
+	}
+	if src[2] != 0x04 || src[3] != byte(tagLength-2) {
+		err = errors.New("user information length error")
+		return
+	}
+	src = src[4 : 2+tagLength]
+
+	if src[0] == TagGloInitiateRequest.Value() {
+		if settings == nil {
+			err = errors.New("ciphered initiate request without settings")
+			return
+		}
+
+		cfg := Cipher{
+			Tag:         TagGloInitiateRequest,
+			Security:    settings.Ciphering.Security,
+			SystemTitle: settings.Ciphering.SourceSystemTitle,
+			Key:         settings.Ciphering.UnicastKey,
+			AuthKey:     settings.Ciphering.AuthenticationKey,
+		}
+
+		src, err = DecipherData(cfg, src)
+		if err != nil {
+			return
+		}
+	}
+
+	ir, err := DecodeInitiateRequest(&src)
+	if err != nil {
+		return
+	}
+
+	return &ir, nil
+}
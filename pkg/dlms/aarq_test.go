@@ -54,3 +54,72 @@ func TestEncodeAARQWithLowAuthenticationAndCipher(t *testing.T) {
 	_, err = EncodeAARQ(&settings)
 	assert.Error(t, err)
 }
+
+func TestDecodeAARQWithLowAuthentication(t *testing.T) {
+	src := decodeHexString("6036A1090607608574050801018A0207808B0760857405080201AC0A80083132333435363738BE10040E01000000065F1F040000181F0100")
+	aarq, err := DecodeAARQ(nil, &src)
+	assert.NoError(t, err)
+	assert.Equal(t, ApplicationContextLNNoCiphering, aarq.ApplicationContext)
+	assert.Equal(t, AuthenticationLow, aarq.Authentication)
+	assert.Equal(t, []byte("12345678"), aarq.Password)
+	assert.Nil(t, aarq.CallingAPTitle)
+
+	assert.NotNil(t, aarq.InitiateRequest)
+	assert.Nil(t, aarq.InitiateRequest.DedicatedKey)
+	assert.True(t, aarq.InitiateRequest.ResponseAllowed)
+	assert.Equal(t, uint8(DlmsVersion), aarq.InitiateRequest.ProposedDlmsVersion)
+	assert.Equal(t, uint32(0x0000181F), aarq.InitiateRequest.ProposedConformance)
+	assert.Equal(t, uint16(256), aarq.InitiateRequest.ClientMaxReceivePduSize)
+	assert.Len(t, src, 0)
+}
+
+func TestDecodeAARQWithCipher(t *testing.T) {
+	settings := &Settings{
+		Ciphering: Ciphering{
+			Security:          SecurityEncryption | SecurityAuthentication,
+			UnicastKey:        decodeHexString("00112233445566778899AABBCCDDEEFF"),
+			AuthenticationKey: decodeHexString("00112233445566778899AABBCCDDEEFF"),
+		},
+	}
+
+	src := decodeHexString("6066A109060760857405080103A60A040843495200000000018A0207808B0760857405080201AC0A80084A7553363642435ABE340432213030000001078E6341442275404C816C6BED3E33AE809EC51E1D0E428BE8F5F643E26C3DD89FD2E3F2220097124F58E0F4")
+	aarq, err := DecodeAARQ(settings, &src)
+	assert.NoError(t, err)
+	assert.Equal(t, ApplicationContextLNCiphering, aarq.ApplicationContext)
+	assert.Equal(t, decodeHexString("4349520000000001"), aarq.CallingAPTitle)
+	assert.Equal(t, decodeHexString("4349520000000001"), settings.Ciphering.SourceSystemTitle)
+	assert.Equal(t, []byte("JuS66BCZ"), aarq.Password)
+
+	assert.NotNil(t, aarq.InitiateRequest)
+	assert.Equal(t, decodeHexString("E803739DBE338C3A790D8D1B12C63FE2"), aarq.InitiateRequest.DedicatedKey)
+	assert.Equal(t, uint16(512), aarq.InitiateRequest.ClientMaxReceivePduSize)
+
+	// Wrong key
+	settings.Ciphering.UnicastKey = decodeHexString("FF112233445566778899AABBCCDDEEFF")
+	src = decodeHexString("6066A109060760857405080103A60A040843495200000000018A0207808B0760857405080201AC0A80084A7553363642435ABE340432213030000001078E6341442275404C816C6BED3E33AE809EC51E1D0E428BE8F5F643E26C3DD89FD2E3F2220097124F58E0F4")
+	_, err = DecodeAARQ(settings, &src)
+	assert.Error(t, err)
+}
+
+func TestEncodeDecodeInitiateRequest(t *testing.T) {
+	ir := CreateInitiateRequest(decodeHexString("E803739DBE338C3A790D8D1B12C63FE2"), 0x0000181F, 512)
+
+	out, err := ir.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, decodeHexString("010110E803739DBE338C3A790D8D1B12C63FE20000065F1F040000181F0200"), out)
+
+	decoded, err := DecodeInitiateRequest(&out)
+	assert.NoError(t, err)
+	assert.Equal(t, *ir, decoded)
+	assert.Len(t, out, 0)
+}
+
+func TestEncodeAARQWithShortNames(t *testing.T) {
+	settings, _ := NewSettingsWithoutAuthentication()
+	settings.UseShortNameReferencing()
+	out, err := EncodeAARQ(&settings)
+	assert.NoError(t, err)
+
+	expected := decodeHexString("601DA109060760857405080102BE10040E01000000065F1F04001C1B200100")
+	assert.Equal(t, expected, out)
+}
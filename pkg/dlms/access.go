@@ -0,0 +1,77 @@
+package dlms
+
+import "github.com/Circutor/gosem/pkg/axdr"
+
+// AccessOperation is an operation of an ACCESS request. Data is the value to set or the method
+// parameters, and Response, if not nil, receives the value read or the method return parameters.
+type AccessOperation struct {
+	Specification AccessRequestSpecification
+	Data          interface{}
+	Response      interface{}
+}
+
+// Access composes gets, sets and actions to be sent in a single ACCESS request. The operations are
+// executed by the server in the order they are added.
+type Access struct {
+	Operations   []AccessOperation
+	BreakOnError bool
+}
+
+func NewAccess() *Access {
+	return &Access{}
+}
+
+// Get reads an attribute into data.
+func (a *Access) Get(att *AttributeDescriptor, data interface{}) *Access {
+	return a.add(AccessRequestSpecification{Tag: TagAccessRequestGet, Attribute: att}, nil, data)
+}
+
+// GetWithSelectiveAccess reads an attribute with selective access into data.
+func (a *Access) GetWithSelectiveAccess(att *AttributeDescriptor, acc *SelectiveAccessDescriptor, data interface{}) *Access {
+	return a.add(AccessRequestSpecification{Tag: TagAccessRequestGetWithSelection, Attribute: att, AccessDescriptor: acc}, nil, data)
+}
+
+// Set writes data into an attribute.
+func (a *Access) Set(att *AttributeDescriptor, data interface{}) *Access {
+	return a.add(AccessRequestSpecification{Tag: TagAccessRequestSet, Attribute: att}, data, nil)
+}
+
+// SetWithSelectiveAccess writes data into the part of an attribute selected by acc.
+func (a *Access) SetWithSelectiveAccess(att *AttributeDescriptor, acc *SelectiveAccessDescriptor, data interface{}) *Access {
+	return a.add(AccessRequestSpecification{Tag: TagAccessRequestSetWithSelection, Attribute: att, AccessDescriptor: acc}, data, nil)
+}
+
+// Action invokes a method with data as parameters.
+func (a *Access) Action(mth *MethodDescriptor, data interface{}) *Access {
+	return a.add(AccessRequestSpecification{Tag: TagAccessRequestAction, Method: mth}, data, nil)
+}
+
+// ActionWithResponse invokes a method with data as parameters, and unmarshals its return
+// parameters into response.
+func (a *Access) ActionWithResponse(mth *MethodDescriptor, data interface{}, response interface{}) *Access {
+	return a.add(AccessRequestSpecification{Tag: TagAccessRequestAction, Method: mth}, data, response)
+}
+
+// WithBreakOnError asks the server to stop at the first operation that fails.
+func (a *Access) WithBreakOnError() *Access {
+	a.BreakOnError = true
+	return a
+}
+
+func (a *Access) add(spec AccessRequestSpecification, data interface{}, response interface{}) *Access {
+	a.Operations = append(a.Operations, AccessOperation{
+		Specification: spec,
+		Data:          data,
+		Response:      response,
+	})
+	return a
+}
+
+// AccessResult is the result of an operation of an ACCESS request. Err is nil if the operation
+// succeeded, or a *Error with the same codes as the equivalent GET, SET or ACTION service.
+type AccessResult struct {
+	Operation AccessRequestSpecification
+	Result    AccessResponseSpecification
+	Data      *axdr.DlmsData
+	Err       error
+}
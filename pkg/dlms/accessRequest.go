@@ -0,0 +1,355 @@
+package dlms
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+)
+
+// Bits of the long-invoke-id-and-priority of the ACCESS service. The lower 24 bits hold the
+// invoke ID.
+const (
+	AccessHighPriority    uint32 = 0x80000000
+	AccessConfirmed       uint32 = 0x40000000
+	AccessBreakOnError    uint32 = 0x20000000
+	AccessSelfDescriptive uint32 = 0x10000000
+	AccessInvokeIDMask    uint32 = 0x00FFFFFF
+)
+
+type accessRequestTag uint8
+
+const (
+	TagAccessRequestGet              accessRequestTag = 0x1
+	TagAccessRequestSet              accessRequestTag = 0x2
+	TagAccessRequestAction           accessRequestTag = 0x3
+	TagAccessRequestGetWithSelection accessRequestTag = 0x4
+	TagAccessRequestSetWithSelection accessRequestTag = 0x5
+)
+
+// Value will return primitive value of the target.
+// This is used for comparing with non custom typed object
+func (s accessRequestTag) Value() uint8 {
+	return uint8(s)
+}
+
+// AccessRequestSpecification is an operation of an AccessRequest. Attribute is used by gets and
+// sets, Method by actions and AccessDescriptor by the variants with selection.
+type AccessRequestSpecification struct {
+	Tag              accessRequestTag
+	Attribute        *AttributeDescriptor
+	Method           *MethodDescriptor
+	AccessDescriptor *SelectiveAccessDescriptor
+}
+
+func CreateAccessRequestGet(att AttributeDescriptor) *AccessRequestSpecification {
+	return &AccessRequestSpecification{Tag: TagAccessRequestGet, Attribute: &att}
+}
+
+func CreateAccessRequestSet(att AttributeDescriptor) *AccessRequestSpecification {
+	return &AccessRequestSpecification{Tag: TagAccessRequestSet, Attribute: &att}
+}
+
+func CreateAccessRequestAction(mth MethodDescriptor) *AccessRequestSpecification {
+	return &AccessRequestSpecification{Tag: TagAccessRequestAction, Method: &mth}
+}
+
+func CreateAccessRequestGetWithSelection(att AttributeDescriptor, acc SelectiveAccessDescriptor) *AccessRequestSpecification {
+	return &AccessRequestSpecification{Tag: TagAccessRequestGetWithSelection, Attribute: &att, AccessDescriptor: &acc}
+}
+
+func CreateAccessRequestSetWithSelection(att AttributeDescriptor, acc SelectiveAccessDescriptor) *AccessRequestSpecification {
+	return &AccessRequestSpecification{Tag: TagAccessRequestSetWithSelection, Attribute: &att, AccessDescriptor: &acc}
+}
+
+// String returns the descriptor of the operation.
+func (s AccessRequestSpecification) String() string {
+	if s.Method != nil {
+		return s.Method.String()
+	}
+
+	if s.Attribute != nil {
+		return s.Attribute.String()
+	}
+
+	return ""
+}
+
+func (s AccessRequestSpecification) Encode() (out []byte, err error) {
+	var buf bytes.Buffer
+	buf.WriteByte(s.Tag.Value())
+
+	var val []byte
+	switch s.Tag {
+	case TagAccessRequestGet, TagAccessRequestSet, TagAccessRequestGetWithSelection, TagAccessRequestSetWithSelection:
+		if s.Attribute == nil {
+			err = fmt.Errorf("access request %d without attribute descriptor", s.Tag)
+			return
+		}
+		val, err = s.Attribute.Encode()
+	case TagAccessRequestAction:
+		if s.Method == nil {
+			err = fmt.Errorf("access request %d without method descriptor", s.Tag)
+			return
+		}
+		val, err = s.Method.Encode()
+	default:
+		err = fmt.Errorf("access request tag not recognized (%v)", s.Tag)
+	}
+	if err != nil {
+		return
+	}
+	buf.Write(val)
+
+	if s.Tag == TagAccessRequestGetWithSelection || s.Tag == TagAccessRequestSetWithSelection {
+		if s.AccessDescriptor == nil {
+			err = fmt.Errorf("access request %d without selective access descriptor", s.Tag)
+			return
+		}
+
+		val, err = s.AccessDescriptor.Encode()
+		if err != nil {
+			return
+		}
+		buf.Write(val)
+	}
+
+	out = buf.Bytes()
+	return
+}
+
+func DecodeAccessRequestSpecification(ori *[]byte) (out AccessRequestSpecification, err error) {
+	src := *ori
+
+	if len(src) < 1 {
+		err = ErrWrongLength(len(src), 1)
+		return
+	}
+
+	out.Tag = accessRequestTag(src[0])
+	src = src[1:]
+
+	switch out.Tag {
+	case TagAccessRequestGet, TagAccessRequestSet, TagAccessRequestGetWithSelection, TagAccessRequestSetWithSelection:
+		att, e := DecodeAttributeDescriptor(&src)
+		if e != nil {
+			err = e
+			return
+		}
+		out.Attribute = &att
+	case TagAccessRequestAction:
+		mth, e := DecodeMethodDescriptor(&src)
+		if e != nil {
+			err = e
+			return
+		}
+		out.Method = &mth
+	default:
+		err = fmt.Errorf("access request tag not recognized (%v)", out.Tag)
+		return
+	}
+
+	if out.Tag == TagAccessRequestGetWithSelection || out.Tag == TagAccessRequestSetWithSelection {
+		if len(src) < 2 {
+			err = ErrWrongLength(len(src), 2)
+			return
+		}
+
+		acc, e := DecodeSelectiveAccessDescriptor(&src)
+		if e != nil {
+			err = e
+			return
+		}
+		out.AccessDescriptor = &acc
+	}
+
+	(*ori) = (*ori)[len((*ori))-len(src):]
+	return
+}
+
+// AccessRequest implement CosemPDU. It combines gets, sets and actions in a single request, with a
+// value for each operation: null-data for gets, the value to write for sets and the method
+// parameters for actions. DateTime is empty when not used.
+type AccessRequest struct {
+	LongInvokeIDAndPriority uint32
+	DateTime                []byte
+	Specifications          []AccessRequestSpecification
+	Data                    []axdr.DlmsData
+}
+
+func CreateAccessRequest(invokeID uint32, dateTime []byte, specifications []AccessRequestSpecification, data []axdr.DlmsData) *AccessRequest {
+	if len(specifications) < 1 || len(specifications) != len(data) {
+		panic("Specifications and Data must have the same number of members, and at least one")
+	}
+	return &AccessRequest{
+		LongInvokeIDAndPriority: invokeID,
+		DateTime:                dateTime,
+		Specifications:          specifications,
+		Data:                    data,
+	}
+}
+
+func (ar AccessRequest) Encode() (out []byte, err error) {
+	var buf bytes.Buffer
+	buf.WriteByte(TagAccessRequest.Value())
+
+	header, err := encodeAccessHeader(ar.LongInvokeIDAndPriority, ar.DateTime)
+	if err != nil {
+		return
+	}
+	buf.Write(header)
+
+	specifications, err := encodeAccessRequestSpecifications(ar.Specifications)
+	if err != nil {
+		return
+	}
+	buf.Write(specifications)
+
+	data, err := encodeAccessData(ar.Data)
+	if err != nil {
+		return
+	}
+	buf.Write(data)
+
+	out = buf.Bytes()
+	return
+}
+
+func DecodeAccessRequest(ori *[]byte) (out AccessRequest, err error) {
+	src := *ori
+
+	if len(src) < 8 {
+		err = ErrWrongLength(len(src), 8)
+		return
+	}
+
+	if src[0] != TagAccessRequest.Value() {
+		err = ErrWrongTag(0, src[0], byte(TagAccessRequest))
+		return
+	}
+	src = src[1:]
+
+	out.LongInvokeIDAndPriority, out.DateTime, err = decodeAccessHeader(&src)
+	if err != nil {
+		return
+	}
+
+	out.Specifications, err = decodeAccessRequestSpecifications(&src)
+	if err != nil {
+		return
+	}
+
+	out.Data, err = decodeAccessData(&src)
+	if err != nil {
+		return
+	}
+
+	if len(out.Data) != len(out.Specifications) {
+		err = fmt.Errorf("%d data for %d access request specifications", len(out.Data), len(out.Specifications))
+		return
+	}
+
+	(*ori) = (*ori)[len((*ori))-len(src):]
+	return
+}
+
+func encodeAccessHeader(invokeID uint32, dateTime []byte) ([]byte, error) {
+	out := make([]byte, 4)
+	binary.BigEndian.PutUint32(out, invokeID)
+
+	dt, err := encodeRawData(dateTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(out, dt...), nil
+}
+
+func decodeAccessHeader(src *[]byte) (invokeID uint32, dateTime []byte, err error) {
+	if len(*src) < 5 {
+		err = ErrWrongLength(len(*src), 5)
+		return
+	}
+
+	invokeID = binary.BigEndian.Uint32((*src)[:4])
+	*src = (*src)[4:]
+
+	dateTime, err = decodeRawData(src)
+	return
+}
+
+func encodeAccessRequestSpecifications(list []AccessRequestSpecification) (out []byte, err error) {
+	out, err = axdr.EncodeLength(len(list))
+	if err != nil {
+		return
+	}
+
+	for _, s := range list {
+		val, e := s.Encode()
+		if e != nil {
+			err = e
+			return
+		}
+		out = append(out, val...)
+	}
+
+	return
+}
+
+func decodeAccessRequestSpecifications(src *[]byte) (out []AccessRequestSpecification, err error) {
+	count, err := decodeQuantity(src)
+	if err != nil {
+		return
+	}
+
+	out = make([]AccessRequestSpecification, 0, count)
+	for i := 0; i < count; i++ {
+		s, e := DecodeAccessRequestSpecification(src)
+		if e != nil {
+			err = e
+			return
+		}
+		out = append(out, s)
+	}
+
+	return
+}
+
+func encodeAccessData(list []axdr.DlmsData) (out []byte, err error) {
+	out, err = axdr.EncodeLength(len(list))
+	if err != nil {
+		return
+	}
+
+	for _, d := range list {
+		val, e := d.Encode()
+		if e != nil {
+			err = e
+			return
+		}
+		out = append(out, val...)
+	}
+
+	return
+}
+
+func decodeAccessData(src *[]byte) (out []axdr.DlmsData, err error) {
+	count, err := decodeQuantity(src)
+	if err != nil {
+		return
+	}
+
+	out = make([]axdr.DlmsData, 0, count)
+	for i := 0; i < count; i++ {
+		decoder := axdr.NewDataDecoder(src)
+		d, e := decoder.Decode(src)
+		if e != nil {
+			err = e
+			return
+		}
+		out = append(out, d)
+	}
+
+	return
+}
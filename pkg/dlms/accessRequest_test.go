@@ -0,0 +1,72 @@
+package dlms
+
+import (
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/stretchr/testify/assert"
+)
+
+const accessRequestHex = "D9C000000100" + "04" +
+	"0100030100010800FF02" +
+	"0200010000600100FF02" +
+	"030046000060030AFF01" +
+	"0400070100630100FF0202020406000000010600000002120000120000" +
+	"04" + "00" + "1105" + "0F00" + "00"
+
+func TestNew_AccessRequest(t *testing.T) {
+	ar := *CreateAccessRequest(AccessHighPriority|AccessConfirmed|1, nil,
+		[]AccessRequestSpecification{
+			*CreateAccessRequestGet(*CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2)),
+			*CreateAccessRequestSet(*CreateAttributeDescriptor(1, "0.0.96.1.0.255", 2)),
+			*CreateAccessRequestAction(*CreateMethodDescriptor(70, "0.0.96.3.10.255", 1)),
+			*CreateAccessRequestGetWithSelection(*CreateAttributeDescriptor(7, "1.0.99.1.0.255", 2), *CreateSelectiveAccessByEntryDescriptor(1, 2)),
+		},
+		[]axdr.DlmsData{
+			{Tag: axdr.TagNull},
+			*axdr.CreateAxdrUnsigned(5),
+			*axdr.CreateAxdrInteger(0),
+			{Tag: axdr.TagNull},
+		})
+
+	out, err := ar.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, accessRequestHex, encodeHexString(out))
+
+	ar.Specifications[0].Attribute = nil
+	_, err = ar.Encode()
+	assert.Error(t, err)
+
+	assert.Panics(t, func() { CreateAccessRequest(0, nil, []AccessRequestSpecification{}, []axdr.DlmsData{}) })
+}
+
+func TestDecode_AccessRequest(t *testing.T) {
+	src := decodeHexString(accessRequestHex)
+	ar, err := DecodeAccessRequest(&src)
+	assert.NoError(t, err)
+	assert.Empty(t, src)
+
+	assert.Equal(t, AccessHighPriority|AccessConfirmed|1, ar.LongInvokeIDAndPriority)
+	assert.Empty(t, ar.DateTime)
+	assert.Len(t, ar.Specifications, 4)
+	assert.Len(t, ar.Data, 4)
+
+	assert.Equal(t, TagAccessRequestGet, ar.Specifications[0].Tag)
+	assert.Equal(t, "1.0.1.8.0.255", ar.Specifications[0].Attribute.InstanceID.String())
+	assert.Equal(t, TagAccessRequestSet, ar.Specifications[1].Tag)
+	assert.Equal(t, uint8(5), ar.Data[1].Value)
+	assert.Equal(t, TagAccessRequestAction, ar.Specifications[2].Tag)
+	assert.Equal(t, int8(1), ar.Specifications[2].Method.MethodID)
+	assert.Equal(t, TagAccessRequestGetWithSelection, ar.Specifications[3].Tag)
+	assert.Equal(t, AccessSelectorEntry, ar.Specifications[3].AccessDescriptor.AccessSelector)
+
+	// More data than specifications
+	src = decodeHexString("D9C000000100010100030100010800FF0202000000")
+	_, err = DecodeAccessRequest(&src)
+	assert.Error(t, err)
+
+	src = decodeHexString(accessRequestHex)
+	pdu, err := DecodeCosem(&src)
+	assert.NoError(t, err)
+	assert.IsType(t, AccessRequest{}, pdu)
+}
@@ -0,0 +1,234 @@
+package dlms
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+)
+
+type accessResponseTag uint8
+
+const (
+	TagAccessResponseGet    accessResponseTag = 0x1
+	TagAccessResponseSet    accessResponseTag = 0x2
+	TagAccessResponseAction accessResponseTag = 0x3
+)
+
+// Value will return primitive value of the target.
+// This is used for comparing with non custom typed object
+func (s accessResponseTag) Value() uint8 {
+	return uint8(s)
+}
+
+// AccessResponseSpecification is the result of an operation of an AccessRequest. Access holds the
+// result of gets and sets, and Action the result of actions.
+type AccessResponseSpecification struct {
+	Tag    accessResponseTag
+	Access AccessResultTag
+	Action ActionResultTag
+}
+
+func CreateAccessResponseGet(access AccessResultTag) *AccessResponseSpecification {
+	return &AccessResponseSpecification{Tag: TagAccessResponseGet, Access: access}
+}
+
+func CreateAccessResponseSet(access AccessResultTag) *AccessResponseSpecification {
+	return &AccessResponseSpecification{Tag: TagAccessResponseSet, Access: access}
+}
+
+func CreateAccessResponseAction(action ActionResultTag) *AccessResponseSpecification {
+	return &AccessResponseSpecification{Tag: TagAccessResponseAction, Action: action}
+}
+
+// Success returns true if the operation succeeded.
+func (s AccessResponseSpecification) Success() bool {
+	if s.Tag == TagAccessResponseAction {
+		return s.Action == TagActSuccess
+	}
+
+	return s.Access == TagAccSuccess
+}
+
+// String returns the name of the result of the operation.
+func (s AccessResponseSpecification) String() string {
+	if s.Tag == TagAccessResponseAction {
+		return s.Action.String()
+	}
+
+	return s.Access.String()
+}
+
+func (s AccessResponseSpecification) Encode() (out []byte, err error) {
+	switch s.Tag {
+	case TagAccessResponseGet, TagAccessResponseSet:
+		out = []byte{s.Tag.Value(), s.Access.Value()}
+	case TagAccessResponseAction:
+		out = []byte{s.Tag.Value(), s.Action.Value()}
+	default:
+		err = fmt.Errorf("access response tag not recognized (%v)", s.Tag)
+	}
+
+	return
+}
+
+func DecodeAccessResponseSpecification(ori *[]byte) (out AccessResponseSpecification, err error) {
+	src := *ori
+
+	if len(src) < 2 {
+		err = ErrWrongLength(len(src), 2)
+		return
+	}
+
+	out.Tag = accessResponseTag(src[0])
+
+	switch out.Tag {
+	case TagAccessResponseGet, TagAccessResponseSet:
+		out.Access, err = GetAccessTag(src[1])
+	case TagAccessResponseAction:
+		out.Action, err = GetActionTag(src[1])
+	default:
+		err = fmt.Errorf("access response tag not recognized (%v)", out.Tag)
+	}
+
+	if err != nil {
+		return
+	}
+
+	(*ori) = (*ori)[2:]
+	return
+}
+
+// AccessResponse implement CosemPDU. There is a value and a result for each operation of the
+// request: the value read by gets, null-data for sets and the return parameters of actions. The
+// server may echo the operations of the request in Specifications.
+type AccessResponse struct {
+	LongInvokeIDAndPriority uint32
+	DateTime                []byte
+	Specifications          []AccessRequestSpecification
+	Data                    []axdr.DlmsData
+	Results                 []AccessResponseSpecification
+}
+
+func CreateAccessResponse(invokeID uint32, dateTime []byte, specifications []AccessRequestSpecification, data []axdr.DlmsData, results []AccessResponseSpecification) *AccessResponse {
+	if len(results) < 1 || len(results) != len(data) {
+		panic("Data and Results must have the same number of members, and at least one")
+	}
+	return &AccessResponse{
+		LongInvokeIDAndPriority: invokeID,
+		DateTime:                dateTime,
+		Specifications:          specifications,
+		Data:                    data,
+		Results:                 results,
+	}
+}
+
+func (ar AccessResponse) Encode() (out []byte, err error) {
+	var buf bytes.Buffer
+	buf.WriteByte(TagAccessResponse.Value())
+
+	header, err := encodeAccessHeader(ar.LongInvokeIDAndPriority, ar.DateTime)
+	if err != nil {
+		return
+	}
+	buf.Write(header)
+
+	if ar.Specifications == nil {
+		buf.WriteByte(0x0)
+	} else {
+		buf.WriteByte(0x1)
+		specifications, e := encodeAccessRequestSpecifications(ar.Specifications)
+		if e != nil {
+			err = e
+			return
+		}
+		buf.Write(specifications)
+	}
+
+	data, err := encodeAccessData(ar.Data)
+	if err != nil {
+		return
+	}
+	buf.Write(data)
+
+	length, err := axdr.EncodeLength(len(ar.Results))
+	if err != nil {
+		return
+	}
+	buf.Write(length)
+
+	for _, r := range ar.Results {
+		val, e := r.Encode()
+		if e != nil {
+			err = e
+			return
+		}
+		buf.Write(val)
+	}
+
+	out = buf.Bytes()
+	return
+}
+
+func DecodeAccessResponse(ori *[]byte) (out AccessResponse, err error) {
+	src := *ori
+
+	if len(src) < 9 {
+		err = ErrWrongLength(len(src), 9)
+		return
+	}
+
+	if src[0] != TagAccessResponse.Value() {
+		err = ErrWrongTag(0, src[0], byte(TagAccessResponse))
+		return
+	}
+	src = src[1:]
+
+	out.LongInvokeIDAndPriority, out.DateTime, err = decodeAccessHeader(&src)
+	if err != nil {
+		return
+	}
+
+	if len(src) < 1 {
+		err = ErrWrongLength(len(src), 1)
+		return
+	}
+
+	haveSpecifications := src[0]
+	src = src[1:]
+
+	if haveSpecifications != 0x0 {
+		out.Specifications, err = decodeAccessRequestSpecifications(&src)
+		if err != nil {
+			return
+		}
+	}
+
+	out.Data, err = decodeAccessData(&src)
+	if err != nil {
+		return
+	}
+
+	count, err := decodeQuantity(&src)
+	if err != nil {
+		return
+	}
+
+	out.Results = make([]AccessResponseSpecification, 0, count)
+	for i := 0; i < count; i++ {
+		r, e := DecodeAccessResponseSpecification(&src)
+		if e != nil {
+			err = e
+			return
+		}
+		out.Results = append(out.Results, r)
+	}
+
+	if len(out.Data) != len(out.Results) {
+		err = fmt.Errorf("%d data for %d access response specifications", len(out.Data), len(out.Results))
+		return
+	}
+
+	(*ori) = (*ori)[len((*ori))-len(src):]
+	return
+}
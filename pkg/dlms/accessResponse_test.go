@@ -0,0 +1,77 @@
+package dlms
+
+import (
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/stretchr/testify/assert"
+)
+
+const accessResponseHex = "DAC00000010000" + "04" +
+	"0600003039" + "00" + "00" + "01010600000005" +
+	"04" + "0100" + "0203" + "0300" + "0100"
+
+func TestNew_AccessResponse(t *testing.T) {
+	ar := *CreateAccessResponse(AccessHighPriority|AccessConfirmed|1, nil, nil,
+		[]axdr.DlmsData{
+			*axdr.CreateAxdrDoubleLongUnsigned(12345),
+			{Tag: axdr.TagNull},
+			{Tag: axdr.TagNull},
+			*axdr.CreateAxdrArray([]*axdr.DlmsData{axdr.CreateAxdrDoubleLongUnsigned(5)}),
+		},
+		[]AccessResponseSpecification{
+			*CreateAccessResponseGet(TagAccSuccess),
+			*CreateAccessResponseSet(TagAccReadWriteDenied),
+			*CreateAccessResponseAction(TagActSuccess),
+			*CreateAccessResponseGet(TagAccSuccess),
+		})
+
+	out, err := ar.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, accessResponseHex, encodeHexString(out))
+
+	// Echoing the request specifications
+	ar = *CreateAccessResponse(1, []byte{0x01}, []AccessRequestSpecification{*CreateAccessRequestGet(*CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2))},
+		[]axdr.DlmsData{*axdr.CreateAxdrDoubleLongUnsigned(12345)},
+		[]AccessResponseSpecification{*CreateAccessResponseGet(TagAccSuccess)})
+
+	out, err = ar.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, "DA00000001010101010100030100010800FF02010600003039010100", encodeHexString(out))
+}
+
+func TestDecode_AccessResponse(t *testing.T) {
+	src := decodeHexString(accessResponseHex)
+	ar, err := DecodeAccessResponse(&src)
+	assert.NoError(t, err)
+	assert.Empty(t, src)
+
+	assert.Equal(t, AccessHighPriority|AccessConfirmed|1, ar.LongInvokeIDAndPriority)
+	assert.Nil(t, ar.Specifications)
+	assert.Len(t, ar.Data, 4)
+	assert.Len(t, ar.Results, 4)
+
+	assert.Equal(t, uint32(12345), ar.Data[0].Value)
+	assert.True(t, ar.Results[0].Success())
+	assert.Equal(t, TagAccessResponseSet, ar.Results[1].Tag)
+	assert.False(t, ar.Results[1].Success())
+	assert.Equal(t, "read-write-denied", ar.Results[1].String())
+	assert.Equal(t, TagAccessResponseAction, ar.Results[2].Tag)
+	assert.True(t, ar.Results[2].Success())
+
+	src = decodeHexString("DA00000001010101010100030100010800FF02010600003039010100")
+	ar, err = DecodeAccessResponse(&src)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x01}, ar.DateTime)
+	assert.Len(t, ar.Specifications, 1)
+
+	// Less results than data
+	src = decodeHexString("DAC0000001000002000000")
+	_, err = DecodeAccessResponse(&src)
+	assert.Error(t, err)
+
+	src = decodeHexString(accessResponseHex)
+	pdu, err := DecodeCosem(&src)
+	assert.NoError(t, err)
+	assert.IsType(t, AccessResponse{}, pdu)
+}
@@ -18,7 +18,7 @@ func TestNew_ActionResponseNormal(t *testing.T) {
 		t.Errorf("t1 Encode Failed. err: %v", e)
 	}
 
-	result := []byte{199, 1, 81, 0, 1, 0, 0}
+	result := []byte{199, 1, 81, 0, 1, 1, 0}
 	res := bytes.Compare(t1, result)
 	if res != 0 {
 		t.Errorf("t1 Failed. get: %d, should:%v", t1, result)
@@ -44,7 +44,7 @@ func TestNew_ActionResponseWithList(t *testing.T) {
 		t.Errorf("t1 Encode Failed. err: %v", e)
 	}
 
-	result := []byte{199, 3, 81, 1, 0, 1, 0, 0}
+	result := []byte{199, 3, 81, 1, 0, 1, 1, 0}
 	res := bytes.Compare(t1, result)
 	if res != 0 {
 		t.Errorf("t1 Failed. get: %d, should:%v", t1, result)
@@ -60,7 +60,7 @@ func TestNew_ActionResponseWithList(t *testing.T) {
 		t.Errorf("t2 Encode Failed. err: %v", e)
 	}
 
-	result = []byte{199, 3, 81, 2, 0, 1, 0, 0, 0, 1, 1, 5, 0, 0, 0, 69}
+	result = []byte{199, 3, 81, 2, 0, 1, 1, 0, 0, 1, 0, 5, 0, 0, 0, 69}
 	res = bytes.Compare(t2, result)
 	if res != 0 {
 		t.Errorf("t2 Failed. get: %d, should:%v", t2, result)
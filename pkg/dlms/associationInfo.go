@@ -0,0 +1,48 @@
+package dlms
+
+// AssociationInfo holds the parameters negotiated with the server in an accepted association.
+// Conformance is the conformance block accepted by the server, that may be a subset of the
+// proposed one.
+type AssociationInfo struct {
+	ApplicationContext      ApplicationContext
+	Conformance             int
+	DlmsVersion             uint8
+	VAAName                 uint16
+	ServerMaxReceivePduSize uint16
+	QualityOfService        *uint8
+	RespondingSystemTitle   []byte
+}
+
+// CreateAssociationInfo returns the parameters negotiated in an AARE. It returns nil if the AARE
+// has no InitiateResponse.
+func CreateAssociationInfo(aare AARE) *AssociationInfo {
+	if aare.InitiateResponse == nil {
+		return nil
+	}
+
+	ir := aare.InitiateResponse
+	info := &AssociationInfo{
+		ApplicationContext:      aare.ApplicationContext,
+		Conformance:             int(ir.NegotiatedConformance),
+		DlmsVersion:             ir.NegotiatedDlmsVersion,
+		VAAName:                 ir.VAAName,
+		ServerMaxReceivePduSize: ir.ServerMaxReceivePduSize,
+	}
+
+	if ir.NegotiatedQualityOfService != nil {
+		qualityOfService := *ir.NegotiatedQualityOfService
+		info.QualityOfService = &qualityOfService
+	}
+
+	if aare.SourceSystemTitle != nil {
+		info.RespondingSystemTitle = make([]byte, len(aare.SourceSystemTitle))
+		copy(info.RespondingSystemTitle, aare.SourceSystemTitle)
+	}
+
+	return info
+}
+
+// IsAccepted returns true if the server accepted all the services of conformance.
+func (ai AssociationInfo) IsAccepted(conformance int) bool {
+	return ai.Conformance&conformance == conformance
+}
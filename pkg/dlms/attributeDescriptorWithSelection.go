@@ -48,8 +48,9 @@ func (ad AttributeDescriptorWithSelection) Encode() (out []byte, err error) {
 func DecodeAttributeDescriptorWithSelection(ori *[]byte) (out AttributeDescriptorWithSelection, err error) {
 	src := *ori
 
-	if len(src) < 11 {
-		err = fmt.Errorf("byte slice length must be at least 11 bytes")
+	// Class, logical name, attribute and the flag of the selective access
+	if len(src) < 10 {
+		err = fmt.Errorf("byte slice length must be at least 10 bytes")
 		return
 	}
 
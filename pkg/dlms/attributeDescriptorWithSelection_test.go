@@ -62,3 +62,21 @@ func TestDecode_AttributeDescriptorWithSelection(t *testing.T) {
 		t.Errorf("t1 reminder failed. get: %v, should: [1, 2, 3]", src)
 	}
 }
+
+func TestDecode_AttributeDescriptorWithoutSelection(t *testing.T) {
+	src := []byte{0, 3, 1, 0, 1, 8, 0, 255, 3, 0}
+	a, e := DecodeAttributeDescriptorWithSelection(&src)
+	if e != nil {
+		t.Errorf("t1 failed with err: %v", e)
+	}
+
+	if a.ClassID != 3 || a.InstanceID.String() != "1.0.1.8.0.255" || a.AttributeID != 3 {
+		t.Errorf("t1 failed. get: %v", a)
+	}
+	if a.AccessDescriptor != nil {
+		t.Errorf("AccessDescriptor get: %v, should: nil", a.AccessDescriptor)
+	}
+	if len(src) > 0 {
+		t.Errorf("t1 failed. src should be empty. get: %v", src)
+	}
+}
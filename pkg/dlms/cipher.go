@@ -106,3 +106,50 @@ func DecipherData(cfg Cipher, data []byte) ([]byte, error) {
 	// Decrypt data
 	return gcm.Open(nil, iv, data, ad)
 }
+
+// CipherGeneralData ciphers data as a general-glo-ciphering or general-ded-ciphering APDU, that
+// carries the system title of the sender before the ciphered content.
+func CipherGeneralData(cfg Cipher, data []byte) ([]byte, error) {
+	if len(cfg.SystemTitle) != 8 {
+		return nil, ErrWrongLength(len(cfg.SystemTitle), 8)
+	}
+
+	ciphered, err := CipherData(cfg, data)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(ciphered)+9)
+	out = append(out, ciphered[0], byte(len(cfg.SystemTitle)))
+	out = append(out, cfg.SystemTitle...)
+	return append(out, ciphered[1:]...), nil
+}
+
+// DecipherGeneralData deciphers a general-glo-ciphering or general-ded-ciphering APDU with the
+// system title it carries, that is returned with the deciphered data. cfg.SystemTitle is ignored.
+func DecipherGeneralData(cfg Cipher, data []byte) (systemTitle []byte, out []byte, err error) {
+	if len(data) < 2 {
+		return nil, nil, ErrWrongLength(len(data), 2)
+	}
+
+	if data[0] != byte(cfg.Tag) {
+		return nil, nil, ErrWrongTag(0, data[0], byte(cfg.Tag))
+	}
+
+	length := int(data[1])
+	if length != 8 || len(data) < 2+length {
+		return nil, nil, ErrWrongLength(length, 8)
+	}
+
+	systemTitle = make([]byte, length)
+	copy(systemTitle, data[2:2+length])
+
+	// The ciphered content is deciphered as a service-specific APDU with the same tag
+	ciphered := make([]byte, 0, len(data)-length-1)
+	ciphered = append(ciphered, data[0])
+	ciphered = append(ciphered, data[2+length:]...)
+
+	cfg.SystemTitle = systemTitle
+	out, err = DecipherData(cfg, ciphered)
+	return
+}
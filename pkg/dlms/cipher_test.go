@@ -96,6 +96,78 @@ func TestDecipherData(t *testing.T) {
 	}
 }
 
+func TestCipherGeneralData(t *testing.T) {
+	cfg := Cipher{
+		Tag:          TagGeneralGloCiphering,
+		Security:     SecurityEncryption | SecurityAuthentication,
+		SystemTitle:  decodeHexString("4D4D4D0000BC614E"),
+		Key:          decodeHexString("000102030405060708090A0B0C0D0E0F"),
+		AuthKey:      decodeHexString("D0D1D2D3D4D5D6D7D8D9DADBDCDDDEDF"),
+		FrameCounter: 0x01234567,
+	}
+	data := decodeHexString("01011000112233445566778899AABBCCDDEEFF0000065F1F0400007E1F04B0")
+	result := decodeHexString("DB084D4D4D0000BC614E303001234567801302FF8A7874133D414CED25B42534D28DB0047720606B175BD52211BE6841DB204D39EE6FDB8E356855")
+
+	out, err := CipherGeneralData(cfg, data)
+	if err != nil {
+		t.Errorf("Got an error when ciphering: %v", err)
+	}
+
+	if !bytes.Equal(out, result) {
+		t.Errorf("Failed. Get: %s, should: %s", encodeHexString(out), encodeHexString(result))
+	}
+
+	cfg.SystemTitle = nil
+	_, err = CipherGeneralData(cfg, data)
+	if err == nil {
+		t.Errorf("Should get an error when ciphering without system title")
+	}
+}
+
+func TestDecipherGeneralData(t *testing.T) {
+	cfg := Cipher{
+		Tag:      TagGeneralGloCiphering,
+		Security: SecurityEncryption | SecurityAuthentication,
+		Key:      decodeHexString("000102030405060708090A0B0C0D0E0F"),
+		AuthKey:  decodeHexString("D0D1D2D3D4D5D6D7D8D9DADBDCDDDEDF"),
+	}
+
+	data := decodeHexString("DB084D4D4D0000BC614E303001234567801302FF8A7874133D414CED25B42534D28DB0047720606B175BD52211BE6841DB204D39EE6FDB8E356855")
+	result := decodeHexString("01011000112233445566778899AABBCCDDEEFF0000065F1F0400007E1F04B0")
+
+	systemTitle, out, err := DecipherGeneralData(cfg, data)
+	if err != nil {
+		t.Errorf("Got an error when deciphering: %v", err)
+	}
+
+	if !bytes.Equal(systemTitle, decodeHexString("4D4D4D0000BC614E")) {
+		t.Errorf("Wrong system title. Get: %s", encodeHexString(systemTitle))
+	}
+
+	if !bytes.Equal(out, result) {
+		t.Errorf("Failed. Get: %s, should: %s", encodeHexString(out), encodeHexString(result))
+	}
+
+	// The system title is authenticated
+	data[9] = 0x4F
+	_, _, err = DecipherGeneralData(cfg, data)
+	if err == nil {
+		t.Errorf("Should get an error when deciphering")
+	}
+
+	data[1] = 0x07
+	_, _, err = DecipherGeneralData(cfg, data)
+	if err == nil {
+		t.Errorf("Should get an error when deciphering")
+	}
+
+	data[0] = byte(TagGeneralDedCiphering)
+	_, _, err = DecipherGeneralData(cfg, data)
+	if err == nil {
+		t.Errorf("Should get an error when deciphering")
+	}
+}
+
 func decodeHexString(s string) []byte {
 	b, _ := hex.DecodeString(s)
 	return b
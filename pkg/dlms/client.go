@@ -21,16 +21,35 @@ type Client interface {
 	SetSettings(settings Settings)
 	SetAddress(client int, server int)
 	SetLogger(logger *log.Logger)
+	SetHighPriority(enabled bool)
+	SetPipelining(outstanding int)
 	Associate() error
 	CloseAssociation() error
 	IsAssociated() bool
+	AssociationInfo() (info AssociationInfo, err error)
 	SetNotificationChannel(id string, nc chan Notification)
 	GetRequest(att *AttributeDescriptor, data interface{}) (err error)
 	GetRequestWithSelectiveAccessByDate(att *AttributeDescriptor, start time.Time, end time.Time, data interface{}) (err error)
 	GetRequestWithSelectiveAccessByDateAndValues(att *AttributeDescriptor, start time.Time, end time.Time, values []AttributeDescriptor, data interface{}) (err error)
 	GetRequestWithStructOfElements(data interface{}) (err error)
+	GetRequestWithStructOfElementsReport(data interface{}, mode ElementsMode) (report ElementsReport, err error)
+	GetRequests(atts []*AttributeDescriptor, data []interface{}) (errs []error, err error)
+	GetRequestWithList(atts []*AttributeDescriptor, data []interface{}) (errs []error, err error)
 	SetRequest(att *AttributeDescriptor, data interface{}) (err error)
+	UnconfirmedSetRequest(att *AttributeDescriptor, data interface{}) (err error)
 	SetRequestWithStructOfElements(data interface{}, continueOnSetRejected bool) (err error)
+	SetRequestWithStructOfElementsReport(data interface{}, mode ElementsMode) (report ElementsReport, err error)
 	ActionRequest(mth *MethodDescriptor, data interface{}) (err error)
+	UnconfirmedActionRequest(mth *MethodDescriptor, data interface{}) (err error)
+	ActionRequestWithResponse(mth *MethodDescriptor, data interface{}, response interface{}) (err error)
 	CheckRequestWithStructOfElements(data interface{}) (err error)
+	ReadRequest(att *AttributeDescriptor, data interface{}) (err error)
+	ReadRequestWithSelectiveAccess(att *AttributeDescriptor, acc *SelectiveAccessDescriptor, data interface{}) (err error)
+	WriteRequest(att *AttributeDescriptor, data interface{}) (err error)
+	UnconfirmedWriteRequest(att *AttributeDescriptor, data interface{}) (err error)
+	AccessRequest(acc *Access) (results []AccessResult, err error)
+	GetShortNameList() (sl ShortNameList, err error)
+	GetObjectList() (ol ObjectList, err error)
+	SetObjectListCache(cache ObjectListCache, key string)
+	SetAccessValidation(enabled bool)
 }
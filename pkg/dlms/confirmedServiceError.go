@@ -34,6 +34,17 @@ const (
 	TagErrOtherError           serviceErrorTag = 10
 )
 
+// Values of the application-reference service error.
+const (
+	ApplicationReferenceOther                      uint8 = 0
+	ApplicationReferenceTimeElapsed                uint8 = 1
+	ApplicationReferenceUnreachable                uint8 = 2
+	ApplicationReferenceInvalid                    uint8 = 3
+	ApplicationReferenceContextUnsupported         uint8 = 4
+	ApplicationReferenceProviderCommunicationError uint8 = 5
+	ApplicationReferenceDecipheringError           uint8 = 6
+)
+
 // Value will return primitive value of the target.
 // This is used for comparing with non custom typed object
 func (s serviceErrorTag) Value() uint8 {
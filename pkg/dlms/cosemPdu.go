@@ -6,23 +6,28 @@ type CosemTag uint8
 
 const (
 	// ---- standardized DLMS APDUs
-	TagInitiateRequest          CosemTag = 1
-	TagReadRequest              CosemTag = 5
-	TagWriteRequest             CosemTag = 6
-	TagInitiateResponse         CosemTag = 8
-	TagReadResponse             CosemTag = 12
-	TagWriteResponse            CosemTag = 13
-	TagConfirmedServiceError    CosemTag = 14
-	TagDataNotification         CosemTag = 15
-	TagUnconfirmedWriteRequest  CosemTag = 22
-	TagInformationReportRequest CosemTag = 24
-	TagGloInitiateRequest       CosemTag = 33
-	TagGloInitiateResponse      CosemTag = 40
-	TagGloConfirmedServiceError CosemTag = 46
-	TagAARQ                     CosemTag = 96
-	TagAARE                     CosemTag = 97
-	TagRLRQ                     CosemTag = 98
-	TagRLRE                     CosemTag = 99
+	TagInitiateRequest            CosemTag = 1
+	TagReadRequest                CosemTag = 5
+	TagWriteRequest               CosemTag = 6
+	TagInitiateResponse           CosemTag = 8
+	TagReadResponse               CosemTag = 12
+	TagWriteResponse              CosemTag = 13
+	TagConfirmedServiceError      CosemTag = 14
+	TagDataNotification           CosemTag = 15
+	TagUnconfirmedWriteRequest    CosemTag = 22
+	TagInformationReportRequest   CosemTag = 24
+	TagGloInitiateRequest         CosemTag = 33
+	TagGloReadRequest             CosemTag = 37
+	TagGloWriteRequest            CosemTag = 38
+	TagGloUnconfirmedWriteRequest CosemTag = 39
+	TagGloInitiateResponse        CosemTag = 40
+	TagGloReadResponse            CosemTag = 44
+	TagGloWriteResponse           CosemTag = 45
+	TagGloConfirmedServiceError   CosemTag = 46
+	TagAARQ                       CosemTag = 96
+	TagAARE                       CosemTag = 97
+	TagRLRQ                       CosemTag = 98
+	TagRLRE                       CosemTag = 99
 	// --- APDUs used for data communication services
 	TagGetRequest               CosemTag = 192
 	TagSetRequest               CosemTag = 193
@@ -48,6 +53,11 @@ const (
 	TagDedSetResponse              CosemTag = 213
 	TagDedActionResponse           CosemTag = 215
 	TagExceptionResponse           CosemTag = 216
+	TagAccessRequest               CosemTag = 217
+	TagAccessResponse              CosemTag = 218
+	// --- general ciphered pdus
+	TagGeneralGloCiphering CosemTag = 219
+	TagGeneralDedCiphering CosemTag = 220
 )
 
 func ErrWrongTag(idx int, get byte, correct byte) error {
@@ -88,6 +98,16 @@ func DecodeCosem(src *[]byte) (out CosemPDU, err error) {
 		out, err = DecodeConfirmedServiceError(src)
 	case TagDataNotification.Value():
 		out, err = DecodeDataNotification(src)
+	case TagReadRequest.Value():
+		out, err = DecodeReadRequest(src)
+	case TagWriteRequest.Value():
+		out, err = DecodeWriteRequest(src)
+	case TagReadResponse.Value():
+		out, err = DecodeReadResponse(src)
+	case TagWriteResponse.Value():
+		out, err = DecodeWriteResponse(src)
+	case TagUnconfirmedWriteRequest.Value():
+		out, err = DecodeUnconfirmedWriteRequest(src)
 	case TagGetRequest.Value():
 		var decoder GetRequest
 		out, err = decoder.Decode(src)
@@ -110,6 +130,10 @@ func DecodeCosem(src *[]byte) (out CosemPDU, err error) {
 		out, err = DecodeEventNotificationRequest(src)
 	case TagExceptionResponse.Value():
 		out, err = DecodeExceptionResponse(src)
+	case TagAccessRequest.Value():
+		out, err = DecodeAccessRequest(src)
+	case TagAccessResponse.Value():
+		out, err = DecodeAccessResponse(src)
 	default:
 		err = fmt.Errorf("byte idx 0 (%v) is not recognized, or relevant DLMS/COSEM is not yet implemented", (*src)[0])
 	}
@@ -0,0 +1,86 @@
+package dlms
+
+import (
+	"fmt"
+	"time"
+)
+
+// ElementsMode selects how an operation on a struct of elements handles the fields that fail.
+type ElementsMode uint8
+
+const (
+	// ElementsFailFast stops at the first field that is rejected or fails.
+	ElementsFailFast ElementsMode = iota
+	// ElementsBestEffort continues with the remaining fields, and only stops if the communication
+	// fails.
+	ElementsBestEffort
+)
+
+func (m ElementsMode) String() string {
+	switch m {
+	case ElementsFailFast:
+		return "fail-fast"
+	case ElementsBestEffort:
+		return "best-effort"
+	default:
+		return fmt.Sprintf("mode %d", m)
+	}
+}
+
+// ElementOutcome is the outcome of the operation on a field of a struct of elements.
+type ElementOutcome uint8
+
+const (
+	ElementSucceeded ElementOutcome = iota
+	ElementRejected                 // rejected by the server or by the access validation
+	ElementFailed                   // failed for any other reason, such as an invalid response
+	ElementSkipped                  // not requested
+)
+
+func (o ElementOutcome) String() string {
+	switch o {
+	case ElementSucceeded:
+		return "succeeded"
+	case ElementRejected:
+		return "rejected"
+	case ElementFailed:
+		return "failed"
+	case ElementSkipped:
+		return "skipped"
+	default:
+		return fmt.Sprintf("outcome %d", o)
+	}
+}
+
+// ElementResult is the result of the operation on a field of a struct of elements. Field is the
+// name of the field, with the names of the enclosing fields for nested structs (e.g. "Clock.Time").
+// Attribute is the attribute of the field, or zero if the field is the parameter of Method. Result
+// is the access result of a rejected attribute: it's only meaningful if Outcome is ElementRejected,
+// and left zero otherwise.
+type ElementResult struct {
+	Field     string
+	Attribute AttributeDescriptor
+	Method    *MethodDescriptor
+	Outcome   ElementOutcome
+	Result    AccessResultTag
+	Err       error
+	Duration  time.Duration
+}
+
+// ElementsReport lists the results of an operation on a struct of elements, in the order of the
+// fields.
+type ElementsReport struct {
+	Results []ElementResult
+}
+
+// Count returns the number of fields with the given outcome.
+func (r ElementsReport) Count(outcome ElementOutcome) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Outcome == outcome {
+			count++
+		}
+	}
+
+	return count
+}
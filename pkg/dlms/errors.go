@@ -2,6 +2,11 @@
 
 package dlms
 
+import (
+	"errors"
+	"fmt"
+)
+
 type ErrorCode int
 
 const (
@@ -17,11 +22,26 @@ const (
 	ErrorActionRejected
 	ErrorSetPartial
 	ErrorCheckDoesNotMatch
+	ErrorServiceError
+)
+
+// Conditions that can be checked with errors.Is on the errors returned by the client, whatever
+// their code.
+var (
+	ErrAssociationNotEstablished = errors.New("association not established")
+	ErrDecipherFailed            = errors.New("decipher failed")
 )
 
+// Error is the error returned by the client. When the server responds with an ExceptionResponse or
+// a ConfirmedServiceError, the code is ErrorServiceError and the PDU is kept in the error.
 type Error struct {
-	code ErrorCode
-	msg  string
+	code                  ErrorCode
+	msg                   string
+	cause                 error
+	accessResult          *AccessResultTag
+	actionResult          *ActionResultTag
+	exceptionResponse     *ExceptionResponse
+	confirmedServiceError *ConfirmedServiceError
 }
 
 func NewError(code ErrorCode, msg string) *Error {
@@ -31,6 +51,73 @@ func NewError(code ErrorCode, msg string) *Error {
 	}
 }
 
+// NewErrorWithCause returns an error that matches cause with errors.Is.
+func NewErrorWithCause(code ErrorCode, msg string, cause error) *Error {
+	return &Error{
+		code:  code,
+		msg:   msg,
+		cause: cause,
+	}
+}
+
+// NewRejectedError returns the error of a get or set rejected with an access result, either by
+// the server or by the access validation of the client.
+func NewRejectedError(code ErrorCode, msg string, result AccessResultTag) *Error {
+	return &Error{
+		code:         code,
+		msg:          msg,
+		accessResult: &result,
+	}
+}
+
+// NewActionRejectedError returns the error of an action rejected by the server with an action
+// result.
+func NewActionRejectedError(msg string, result ActionResultTag) *Error {
+	return &Error{
+		code:         ErrorActionRejected,
+		msg:          msg,
+		actionResult: &result,
+	}
+}
+
+// NewExceptionError returns the error of a request answered with an ExceptionResponse.
+func NewExceptionError(er ExceptionResponse) *Error {
+	ce := &Error{
+		code:              ErrorServiceError,
+		msg:               fmt.Sprintf("exception response: %s", er.String()),
+		exceptionResponse: &er,
+	}
+
+	switch {
+	case er.ServiceError == TagExcDecipheringError:
+		ce.cause = ErrDecipherFailed
+	case er.StateError == TagExcServiceNotAllowed:
+		ce.cause = ErrAssociationNotEstablished
+	}
+
+	return ce
+}
+
+// NewServiceError returns the error of a request answered with a ConfirmedServiceError.
+func NewServiceError(cse ConfirmedServiceError) *Error {
+	ce := &Error{
+		code:                  ErrorServiceError,
+		msg:                   fmt.Sprintf("confirmed service error: %s", cse.String()),
+		confirmedServiceError: &cse,
+	}
+
+	if cse.ServiceError == TagErrApplicationReference {
+		switch cse.Value {
+		case ApplicationReferenceDecipheringError:
+			ce.cause = ErrDecipherFailed
+		case ApplicationReferenceTimeElapsed, ApplicationReferenceInvalid:
+			ce.cause = ErrAssociationNotEstablished
+		}
+	}
+
+	return ce
+}
+
 func (ce *Error) Error() string {
 	return ce.msg
 }
@@ -38,3 +125,36 @@ func (ce *Error) Error() string {
 func (ce *Error) Code() ErrorCode {
 	return ce.code
 }
+
+// Unwrap returns the condition of the error, if any.
+func (ce *Error) Unwrap() error {
+	return ce.cause
+}
+
+// AccessResult returns the access result of a rejected get or set, if any.
+func (ce *Error) AccessResult() (result AccessResultTag, ok bool) {
+	if ce.accessResult == nil {
+		return
+	}
+
+	return *ce.accessResult, true
+}
+
+// ActionResult returns the action result of an action rejected by the server, if any.
+func (ce *Error) ActionResult() (result ActionResultTag, ok bool) {
+	if ce.actionResult == nil {
+		return
+	}
+
+	return *ce.actionResult, true
+}
+
+// ExceptionResponse returns the ExceptionResponse received from the server, or nil.
+func (ce *Error) ExceptionResponse() *ExceptionResponse {
+	return ce.exceptionResponse
+}
+
+// ConfirmedServiceError returns the ConfirmedServiceError received from the server, or nil.
+func (ce *Error) ConfirmedServiceError() *ConfirmedServiceError {
+	return ce.confirmedServiceError
+}
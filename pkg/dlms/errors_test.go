@@ -0,0 +1,71 @@
+package dlms
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewExceptionError(t *testing.T) {
+	err := NewExceptionError(*CreateExceptionResponse(TagExcServiceNotAllowed, TagExcDecipheringError))
+	assert.Equal(t, ErrorServiceError, err.Code())
+	assert.Equal(t, "exception response: service-not-allowed, deciphering-error", err.Error())
+	assert.Equal(t, CreateExceptionResponse(TagExcServiceNotAllowed, TagExcDecipheringError), err.ExceptionResponse())
+	assert.Nil(t, err.ConfirmedServiceError())
+	assert.True(t, errors.Is(err, ErrDecipherFailed))
+	assert.False(t, errors.Is(err, ErrAssociationNotEstablished))
+
+	err = NewExceptionError(*CreateExceptionResponse(TagExcServiceNotAllowed, TagExcOperationNotPossible))
+	assert.True(t, errors.Is(err, ErrAssociationNotEstablished))
+	assert.False(t, errors.Is(err, ErrDecipherFailed))
+
+	err = NewExceptionError(*CreateExceptionResponse(TagExcServiceUnknown, TagExcServiceNotSupported))
+	assert.False(t, errors.Is(err, ErrAssociationNotEstablished))
+	assert.False(t, errors.Is(err, ErrDecipherFailed))
+}
+
+func TestNewServiceError(t *testing.T) {
+	err := NewServiceError(*CreateConfirmedServiceError(TagErrRead, TagErrApplicationReference, ApplicationReferenceDecipheringError))
+	assert.Equal(t, ErrorServiceError, err.Code())
+	assert.Equal(t, CreateConfirmedServiceError(TagErrRead, TagErrApplicationReference, ApplicationReferenceDecipheringError), err.ConfirmedServiceError())
+	assert.Nil(t, err.ExceptionResponse())
+	assert.True(t, errors.Is(err, ErrDecipherFailed))
+
+	err = NewServiceError(*CreateConfirmedServiceError(TagErrRead, TagErrApplicationReference, ApplicationReferenceTimeElapsed))
+	assert.True(t, errors.Is(err, ErrAssociationNotEstablished))
+
+	err = NewServiceError(*CreateConfirmedServiceError(TagErrWrite, TagErrAccess, 3))
+	assert.False(t, errors.Is(err, ErrAssociationNotEstablished))
+	assert.False(t, errors.Is(err, ErrDecipherFailed))
+}
+
+func TestNewErrorWithCause(t *testing.T) {
+	err := NewErrorWithCause(ErrorInvalidState, "not associated", ErrAssociationNotEstablished)
+	assert.Equal(t, ErrorInvalidState, err.Code())
+	assert.True(t, errors.Is(err, ErrAssociationNotEstablished))
+
+	assert.False(t, errors.Is(NewError(ErrorInvalidState, "not associated"), ErrAssociationNotEstablished))
+}
+
+func TestNewRejectedError(t *testing.T) {
+	err := NewRejectedError(ErrorGetRejected, "get rejected", TagAccObjectUndefined)
+	assert.Equal(t, ErrorGetRejected, err.Code())
+	result, ok := err.AccessResult()
+	assert.True(t, ok)
+	assert.Equal(t, TagAccObjectUndefined, result)
+
+	_, ok = NewError(ErrorGetRejected, "get rejected").AccessResult()
+	assert.False(t, ok)
+}
+
+func TestNewActionRejectedError(t *testing.T) {
+	err := NewActionRejectedError("action rejected", TagActTemporaryFailure)
+	assert.Equal(t, ErrorActionRejected, err.Code())
+	result, ok := err.ActionResult()
+	assert.True(t, ok)
+	assert.Equal(t, TagActTemporaryFailure, result)
+
+	_, ok = NewError(ErrorActionRejected, "action rejected").ActionResult()
+	assert.False(t, ok)
+}
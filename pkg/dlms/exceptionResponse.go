@@ -2,6 +2,7 @@ package dlms
 
 import (
 	"bytes"
+	"fmt"
 )
 
 type exceptionStateErrorTag uint8
@@ -11,6 +12,17 @@ const (
 	TagExcServiceUnknown    exceptionStateErrorTag = 2
 )
 
+func (s exceptionStateErrorTag) String() string {
+	switch s {
+	case TagExcServiceNotAllowed:
+		return "service-not-allowed"
+	case TagExcServiceUnknown:
+		return "service-unknown"
+	default:
+		return fmt.Sprintf("state-error %d", s)
+	}
+}
+
 // Value will return primitive value of the target.
 // This is used for comparing with non custom typed object
 func (s exceptionStateErrorTag) Value() uint8 {
@@ -20,11 +32,33 @@ func (s exceptionStateErrorTag) Value() uint8 {
 type exceptionServiceErrorTag uint8
 
 const (
-	TagExcOperationNotPossible exceptionServiceErrorTag = 1
-	TagExcServiceNotSupported  exceptionServiceErrorTag = 2
-	TagExcOtherReason          exceptionServiceErrorTag = 3
+	TagExcOperationNotPossible   exceptionServiceErrorTag = 1
+	TagExcServiceNotSupported    exceptionServiceErrorTag = 2
+	TagExcOtherReason            exceptionServiceErrorTag = 3
+	TagExcPduTooLong             exceptionServiceErrorTag = 4
+	TagExcDecipheringError       exceptionServiceErrorTag = 5
+	TagExcInvocationCounterError exceptionServiceErrorTag = 6
 )
 
+func (s exceptionServiceErrorTag) String() string {
+	switch s {
+	case TagExcOperationNotPossible:
+		return "operation-not-possible"
+	case TagExcServiceNotSupported:
+		return "service-not-supported"
+	case TagExcOtherReason:
+		return "other-reason"
+	case TagExcPduTooLong:
+		return "pdu-too-long"
+	case TagExcDecipheringError:
+		return "deciphering-error"
+	case TagExcInvocationCounterError:
+		return "invocation-counter-error"
+	default:
+		return fmt.Sprintf("service-error %d", s)
+	}
+}
+
 // Value will return primitive value of the target.
 // This is used for comparing with non custom typed object
 func (s exceptionServiceErrorTag) Value() uint8 {
@@ -36,6 +70,10 @@ type ExceptionResponse struct {
 	ServiceError exceptionServiceErrorTag
 }
 
+func (er ExceptionResponse) String() string {
+	return fmt.Sprintf("%s, %s", er.StateError.String(), er.ServiceError.String())
+}
+
 func CreateExceptionResponse(stateError exceptionStateErrorTag, serviceError exceptionServiceErrorTag) *ExceptionResponse {
 	return &ExceptionResponse{
 		StateError:   stateError,
@@ -34,17 +34,18 @@ func CreateGetDataResult(value interface{}) *GetDataResult {
 
 func (dt GetDataResult) Encode() (out []byte, err error) {
 	var buf bytes.Buffer
+	// Get-Data-Result ::= CHOICE { data [0], data-access-result [1] }
 	if dt.IsData {
-		buf.WriteByte(0x1)
+		buf.WriteByte(0x0)
 		value := dt.Value.(axdr.DlmsData)
 		enc, e := value.Encode()
-		if err != nil {
+		if e != nil {
 			err = e
 			return
 		}
 		buf.Write(enc)
 	} else {
-		buf.WriteByte(0x0)
+		buf.WriteByte(0x1)
 		value := dt.Value.(AccessResultTag)
 		buf.WriteByte(byte(value))
 	}
@@ -166,8 +167,13 @@ func (dt DataBlockG) Encode() (out []byte, err error) {
 	} else {
 		buf.WriteByte(0x0)
 		value := dt.Result.([]byte)
-		// not sure if length is limited only 1 byte, or does it follow KLV as in axdr
-		buf.WriteByte(byte(len(value)))
+		// raw-data is an octet string, so its length is A-XDR encoded and may take several bytes
+		dl, e := axdr.EncodeLength(len(value))
+		if e != nil {
+			err = e
+			return
+		}
+		buf.Write(dl)
 		buf.Write(value)
 	}
 
@@ -292,10 +298,17 @@ func DecodeDataBlockSA(ori *[]byte) (out DataBlockSA, err error) {
 
 	_, out.BlockNumber, err = axdr.DecodeDoubleLongUnsigned(&src)
 
-	// not sure if length is limited only 1 byte, or does it follow KLV as in axdr
-	val := src[0]
-	out.Raw = src[1 : val+1]
-	src = src[val+1:]
+	// raw-data is an octet string, so its length is A-XDR encoded and may take several bytes
+	_, val, err := axdr.DecodeLength(&src)
+	if err != nil {
+		return
+	}
+	if uint64(len(src)) < val {
+		err = ErrWrongLength(len(src), int(val))
+		return
+	}
+	out.Raw = src[:val]
+	src = src[val:]
 
 	(*ori) = (*ori)[len((*ori))-len(src):]
 	return
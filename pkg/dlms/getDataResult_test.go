@@ -26,7 +26,7 @@ func TestGetDataResultAsResult(t *testing.T) {
 	if e != nil {
 		t.Errorf("t1 Encode Failed. err: %v", e)
 	}
-	result := []byte{0, 0}
+	result := []byte{1, 0}
 
 	res := bytes.Compare(t1, result)
 	if res != 0 {
@@ -42,7 +42,7 @@ func TestGetDataResultAsData(t *testing.T) {
 	if e != nil {
 		t.Errorf("t1 Encode Failed. err: %v", e)
 	}
-	result := []byte{1, 5, 0, 0, 0, 69}
+	result := []byte{0, 5, 0, 0, 0, 69}
 
 	res := bytes.Compare(t1, result)
 	if res != 0 {
@@ -58,7 +58,7 @@ func TestGetDataResult(t *testing.T) {
 	if e != nil {
 		t.Errorf("t1 Encode Failed. err: %v", e)
 	}
-	result := []byte{0, 0}
+	result := []byte{1, 0}
 	res := bytes.Compare(t1, result)
 	if res != 0 {
 		t.Errorf("t1 Failed. get: %d, should:%v", t1, result)
@@ -70,7 +70,7 @@ func TestGetDataResult(t *testing.T) {
 	if e != nil {
 		t.Errorf("t2 Encode Failed. err: %v", e)
 	}
-	result = []byte{1, 5, 0, 0, 0, 69}
+	result = []byte{0, 5, 0, 0, 0, 69}
 
 	res = bytes.Compare(t2, result)
 	if res != 0 {
@@ -211,7 +211,7 @@ func TestActResponse(t *testing.T) {
 	if e != nil {
 		t.Errorf("t1 Encode Failed. err: %v", e)
 	}
-	result := []byte{0, 1, 1, 5, 0, 0, 0, 69}
+	result := []byte{0, 1, 0, 5, 0, 0, 0, 69}
 
 	res := bytes.Compare(t1, result)
 	if res != 0 {
@@ -17,7 +17,7 @@ func TestNewGetResponseNormal(t *testing.T) {
 	if e != nil {
 		t.Errorf("t1 Encode Failed. err: %v", e)
 	}
-	result := []byte{196, 1, 81, 1, 5, 0, 0, 0, 69}
+	result := []byte{196, 1, 81, 0, 5, 0, 0, 0, 69}
 	res := bytes.Compare(t1, result)
 	if res != 0 {
 		t.Errorf("t1 Failed. get: %d, should:%v", t1, result)
@@ -60,7 +60,7 @@ func TestNewGetResponseWithList(t *testing.T) {
 	if e != nil {
 		t.Errorf("t1 Encode Failed. err: %v", e)
 	}
-	result := []byte{196, 3, 69, 1, 0, 0}
+	result := []byte{196, 3, 69, 1, 1, 0}
 	res := bytes.Compare(t1, result)
 	if res != 0 {
 		t.Errorf("t1 Failed. get: %d, should:%v", t1, result)
@@ -73,7 +73,7 @@ func TestNewGetResponseWithList(t *testing.T) {
 	if e != nil {
 		t.Errorf("t2 Encode Failed. err: %v", e)
 	}
-	result = []byte{196, 3, 69, 2, 0, 0, 1, 5, 0, 0, 0, 1}
+	result = []byte{196, 3, 69, 2, 1, 0, 0, 5, 0, 0, 0, 1}
 	res = bytes.Compare(t2, result)
 	if res != 0 {
 		t.Errorf("t2 failed. get: %d, should:%v", t2, result)
@@ -0,0 +1,136 @@
+package dlms
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+type InitiateRequest struct {
+	DedicatedKey             []byte
+	ResponseAllowed          bool
+	ProposedQualityOfService *uint8
+	ProposedDlmsVersion      uint8
+	ProposedConformance      uint32
+	ClientMaxReceivePduSize  uint16
+}
+
+func CreateInitiateRequest(dedicatedKey []byte, conformance uint32, maxReceivePduSize uint16) *InitiateRequest {
+	return &InitiateRequest{
+		DedicatedKey:             dedicatedKey,
+		ResponseAllowed:          true,
+		ProposedQualityOfService: nil,
+		ProposedDlmsVersion:      DlmsVersion,
+		ProposedConformance:      conformance,
+		ClientMaxReceivePduSize:  maxReceivePduSize,
+	}
+}
+
+func (ir InitiateRequest) Encode() (out []byte, err error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte(TagInitiateRequest.Value())
+
+	if len(ir.DedicatedKey) == 0 {
+		buf.WriteByte(0x00)
+	} else {
+		buf.WriteByte(0x01)
+		buf.WriteByte(byte(len(ir.DedicatedKey)))
+		buf.Write(ir.DedicatedKey)
+	}
+
+	// Response allowed is encoded only when it isn't the default value (true)
+	if ir.ResponseAllowed {
+		buf.WriteByte(0x00)
+	} else {
+		buf.Write([]byte{0x01, 0x00})
+	}
+
+	if ir.ProposedQualityOfService != nil {
+		buf.WriteByte(0x01)
+		buf.WriteByte(*ir.ProposedQualityOfService)
+	} else {
+		buf.WriteByte(0x00)
+	}
+
+	buf.WriteByte(ir.ProposedDlmsVersion)
+
+	buf.Write([]byte{0x5F, 0x1F, 0x04})
+
+	proposedConformance := make([]byte, 4)
+	binary.BigEndian.PutUint32(proposedConformance, ir.ProposedConformance)
+	buf.Write(proposedConformance)
+
+	clientMaxReceivePduSize := make([]byte, 2)
+	binary.BigEndian.PutUint16(clientMaxReceivePduSize, ir.ClientMaxReceivePduSize)
+	buf.Write(clientMaxReceivePduSize)
+
+	out = buf.Bytes()
+	return
+}
+
+func DecodeInitiateRequest(ori *[]byte) (out InitiateRequest, err error) {
+	src := *ori
+
+	if len(src) < 14 {
+		err = ErrWrongLength(len(src), 14)
+		return
+	}
+
+	if src[0] != TagInitiateRequest.Value() {
+		err = ErrWrongTag(0, src[0], byte(TagInitiateRequest))
+		return
+	}
+	src = src[1:]
+
+	if src[0] == 0x01 {
+		length := int(src[1])
+		if len(src) < 2+length {
+			err = ErrWrongLength(len(src), 2+length)
+			return
+		}
+		out.DedicatedKey = make([]byte, length)
+		copy(out.DedicatedKey, src[2:2+length])
+		src = src[2+length:]
+	} else {
+		src = src[1:]
+	}
+
+	out.ResponseAllowed = true
+	if src[0] == 0x01 {
+		out.ResponseAllowed = src[1] != 0x00
+		src = src[2:]
+	} else {
+		src = src[1:]
+	}
+
+	if src[0] == 0x01 {
+		proposedQualityOfService := src[1]
+		out.ProposedQualityOfService = &proposedQualityOfService
+		src = src[2:]
+	} else {
+		src = src[1:]
+	}
+
+	if len(src) < 10 {
+		err = ErrWrongLength(len(src), 10)
+		return
+	}
+
+	out.ProposedDlmsVersion = src[0]
+	if out.ProposedDlmsVersion < DlmsVersion {
+		err = ErrWrongVersion
+		return
+	}
+
+	if !bytes.Equal(src[1:4], []byte{0x5F, 0x1F, 0x04}) {
+		err = ErrWrongSlice(src[1:4], []byte{0x5F, 0x1F, 0x04})
+		return
+	}
+
+	out.ProposedConformance = binary.BigEndian.Uint32(src[4:8])
+	out.ClientMaxReceivePduSize = binary.BigEndian.Uint16(src[8:10])
+	src = src[10:]
+
+	(*ori) = (*ori)[len((*ori))-len(src):]
+	return
+}
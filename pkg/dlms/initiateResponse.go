@@ -20,15 +20,19 @@ var (
 
 type InitiateResponse struct {
 	NegotiatedQualityOfService *uint8
+	NegotiatedDlmsVersion      uint8
 	NegotiatedConformance      uint32
 	ServerMaxReceivePduSize    uint16
+	VAAName                    uint16
 }
 
 func CreateInitiateResponse(qualityOfService *uint8, conformance uint32, maxReceivePduSize uint16) *InitiateResponse {
 	return &InitiateResponse{
 		NegotiatedQualityOfService: qualityOfService,
+		NegotiatedDlmsVersion:      DlmsVersion,
 		NegotiatedConformance:      conformance,
 		ServerMaxReceivePduSize:    maxReceivePduSize,
+		VAAName:                    VAANameLN,
 	}
 }
 
@@ -44,7 +48,11 @@ func (ir InitiateResponse) Encode() (out []byte, err error) {
 		buf.WriteByte(0x00)
 	}
 
-	buf.WriteByte(DlmsVersion)
+	if ir.NegotiatedDlmsVersion != 0 {
+		buf.WriteByte(ir.NegotiatedDlmsVersion)
+	} else {
+		buf.WriteByte(DlmsVersion)
+	}
 
 	buf.Write([]byte{0x5F, 0x1F, 0x04})
 
@@ -57,7 +65,11 @@ func (ir InitiateResponse) Encode() (out []byte, err error) {
 	buf.Write(serverMaxReceivePduSize)
 
 	vaaName := make([]byte, 2)
-	binary.BigEndian.PutUint16(vaaName, VAANameLN)
+	if ir.VAAName != 0 {
+		binary.BigEndian.PutUint16(vaaName, ir.VAAName)
+	} else {
+		binary.BigEndian.PutUint16(vaaName, VAANameLN)
+	}
 	buf.Write(vaaName)
 
 	out = buf.Bytes()
@@ -89,6 +101,7 @@ func DecodeInitiateResponse(ori *[]byte) (out InitiateResponse, err error) {
 		err = ErrWrongVersion
 		return
 	}
+	out.NegotiatedDlmsVersion = src[0]
 
 	if !bytes.Equal(src[1:4], []byte{0x5F, 0x1F, 0x04}) {
 		err = ErrWrongSlice(src[1:4], []byte{0x5F, 0x1F, 0x04})
@@ -98,8 +111,8 @@ func DecodeInitiateResponse(ori *[]byte) (out InitiateResponse, err error) {
 	out.NegotiatedConformance = binary.BigEndian.Uint32(src[4:8])
 	out.ServerMaxReceivePduSize = binary.BigEndian.Uint16(src[8:10])
 
-	vaaName := binary.BigEndian.Uint16(src[10:12])
-	if vaaName != VAANameLN && vaaName != VAANameSN {
+	out.VAAName = binary.BigEndian.Uint16(src[10:12])
+	if out.VAAName != VAANameLN && out.VAAName != VAANameSN {
 		err = ErrWrongVAAName
 		return
 	}
@@ -28,6 +28,18 @@ func TestNew_InitiateResponse(t *testing.T) {
 	if !bytes.Equal(out, result) {
 		t.Errorf("Failed. Get: %s, should: %s", encodeHexString(out), encodeHexString(result))
 	}
+
+	ir = *CreateInitiateResponse(nil, 0x001C1B20, 128)
+	ir.VAAName = VAANameSN
+	out, err = ir.Encode()
+	if err != nil {
+		t.Errorf("Encode Failed. Err: %v", err)
+	}
+
+	result = decodeHexString("0800065F1F04001C1B200080FA00")
+	if !bytes.Equal(out, result) {
+		t.Errorf("Failed. Get: %s, should: %s", encodeHexString(out), encodeHexString(result))
+	}
 }
 
 func TestDecode_InitiateResponse(t *testing.T) {
@@ -41,6 +53,10 @@ func TestDecode_InitiateResponse(t *testing.T) {
 		t.Errorf("Invalid NegotiatedQualityOfService. Get: %v", ir.NegotiatedQualityOfService)
 	}
 
+	if ir.NegotiatedDlmsVersion != DlmsVersion {
+		t.Errorf("Invalid NegotiatedDlmsVersion. Get: %v", ir.NegotiatedDlmsVersion)
+	}
+
 	if ir.NegotiatedConformance != 0x0000101D {
 		t.Errorf("Invalid NegotiatedConformance. Get: %v", ir.NegotiatedConformance)
 	}
@@ -49,6 +65,20 @@ func TestDecode_InitiateResponse(t *testing.T) {
 		t.Errorf("Invalid ServerMaxReceivePduSize. Get: %v", ir.ServerMaxReceivePduSize)
 	}
 
+	if ir.VAAName != VAANameLN {
+		t.Errorf("Invalid VAAName. Get: %v", ir.VAAName)
+	}
+
+	src = decodeHexString("0800065F1F04001C1B200080FA00")
+	ir, err = DecodeInitiateResponse(&src)
+	if err != nil {
+		t.Errorf("Failed on DecodeInitiateResponse. Err: %v", err)
+	}
+
+	if ir.VAAName != VAANameSN {
+		t.Errorf("Invalid VAAName. Get: %v", ir.VAAName)
+	}
+
 	src = decodeHexString("080103065F1F040000101D00800007")
 	ir, err = DecodeInitiateResponse(&src)
 	if err != nil {
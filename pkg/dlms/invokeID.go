@@ -0,0 +1,50 @@
+package dlms
+
+import "encoding/binary"
+
+// Bits of the invoke-id-and-priority of the GET, SET and ACTION services.
+const (
+	InvokeHighPriority uint8 = 0x80
+	InvokeConfirmed    uint8 = 0x40
+	InvokeIDMask       uint8 = 0x0F
+)
+
+// invokeIDAndPriorityIdx is the position of the invoke-id-and-priority in the GET, SET and ACTION
+// PDUs, after the tag and the choice.
+const invokeIDAndPriorityIdx = 2
+
+// CreateInvokeIDAndPriority returns the invoke-id-and-priority of a GET, SET or ACTION request.
+func CreateInvokeIDAndPriority(invokeID uint8, highPriority bool, confirmed bool) uint8 {
+	out := invokeID & InvokeIDMask
+	if highPriority {
+		out |= InvokeHighPriority
+	}
+	if confirmed {
+		out |= InvokeConfirmed
+	}
+	return out
+}
+
+// DecodeInvokeID returns the invoke ID of an encoded GET, SET, ACTION or ACCESS request or
+// response, without the priority and service class bits. It returns false for the PDUs without
+// an invoke ID.
+func DecodeInvokeID(src []byte) (uint32, bool) {
+	if len(src) < 1 {
+		return 0, false
+	}
+
+	switch CosemTag(src[0]) {
+	case TagGetRequest, TagSetRequest, TagActionRequest, TagGetResponse, TagSetResponse, TagActionResponse:
+		if len(src) <= invokeIDAndPriorityIdx {
+			return 0, false
+		}
+		return uint32(src[invokeIDAndPriorityIdx] & InvokeIDMask), true
+	case TagAccessRequest, TagAccessResponse:
+		if len(src) < 5 {
+			return 0, false
+		}
+		return binary.BigEndian.Uint32(src[1:5]) & AccessInvokeIDMask, true
+	default:
+		return 0, false
+	}
+}
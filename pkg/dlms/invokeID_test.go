@@ -0,0 +1,52 @@
+package dlms
+
+import (
+	"testing"
+)
+
+func TestCreateInvokeIDAndPriority(t *testing.T) {
+	tables := []struct {
+		invokeID     uint8
+		highPriority bool
+		confirmed    bool
+		out          uint8
+	}{
+		{1, true, true, 0xC1},
+		{1, false, true, 0x41},
+		{2, true, false, 0x82},
+		{0x1F, false, false, 0x0F},
+	}
+
+	for _, table := range tables {
+		out := CreateInvokeIDAndPriority(table.invokeID, table.highPriority, table.confirmed)
+		if out != table.out {
+			t.Errorf("Wrong invoke-id-and-priority for %d. get: %02X, should: %02X", table.invokeID, out, table.out)
+		}
+	}
+}
+
+func TestDecodeInvokeID(t *testing.T) {
+	tables := []struct {
+		src string
+		out uint32
+		ok  bool
+	}{
+		{"C001C500030100010800FF0200", 5, true},
+		{"C401430010003C", 3, true},
+		{"C5014A00", 10, true},
+		{"C701C70000", 7, true},
+		{"D9C00000120000", 0x12, true},
+		{"DA80FFFFFF", 0xFFFFFF, true},
+		{"0E010203", 0, false},
+		{"C001", 0, false},
+		{"D9C000", 0, false},
+		{"", 0, false},
+	}
+
+	for _, table := range tables {
+		out, ok := DecodeInvokeID(decodeHexString(table.src))
+		if out != table.out || ok != table.ok {
+			t.Errorf("Wrong invoke ID for %s. get: %d (%v), should: %d (%v)", table.src, out, ok, table.out, table.ok)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package dlms
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ObisTag is a parsed obis struct tag: "class,logical name,attribute[,option]...". The attribute may
+// be a method instead, as mN (e.g. m1): the field is the parameter of the method, which is invoked
+// when the struct is set, and it's neither got nor checked. Either Attribute or Method is set. The
+// options are:
+//   - range=lastN: gets the entries of the last N, a duration such as 15m, 24h or 7d, with selective
+//     access by range of the clock.
+//   - omitempty: zero fields are neither set nor checked, and a rejected get leaves the field zero.
+//   - readonly: the field is not set.
+//   - writeonly: the field is neither got nor checked.
+//   - scale=N: the value got is multiplied by 10^N. The field must be a float, and is not set.
+//   - list=name: the fields with the same list name are got together, with a get request with a list.
+//   - index=N: the data index of the capture object of a profile the field is stored from. It's only
+//     used by the rows of a profile, where zero (the default) captures the whole attribute.
+//
+// Methods only accept the omitempty option, and list can't be combined with range.
+type ObisTag struct {
+	Attribute *AttributeDescriptor
+	Method    *MethodDescriptor
+	List      string
+	DataIndex uint16
+	Last      time.Duration
+	OmitEmpty bool
+	ReadOnly  bool
+	WriteOnly bool
+	Scaled    bool
+	Scale     int
+}
+
+// ParseObisTag parses an obis struct tag.
+func ParseObisTag(tag string) (ot ObisTag, err error) {
+	values := strings.Split(tag, ",")
+	if len(values) < 3 {
+		err = NewError(ErrorInvalidParameter, fmt.Sprintf("invalid obis tag: %s", tag))
+		return
+	}
+
+	class, e := strconv.ParseUint(values[0], 0, 16)
+	if e != nil {
+		err = NewError(ErrorInvalidParameter, fmt.Sprintf("invalid class: %s", tag))
+		return
+	}
+	obis := values[1]
+
+	if strings.HasPrefix(values[2], "m") {
+		method, e := strconv.ParseUint(strings.TrimPrefix(values[2], "m"), 0, 7)
+		if e != nil {
+			err = NewError(ErrorInvalidParameter, fmt.Sprintf("invalid method: %s", tag))
+			return
+		}
+
+		ot.Method = CreateMethodDescriptor(uint16(class), obis, int8(method))
+	} else {
+		att, e := strconv.ParseUint(values[2], 0, 8)
+		if e != nil {
+			err = NewError(ErrorInvalidParameter, fmt.Sprintf("invalid attribute: %s", tag))
+			return
+		}
+
+		ot.Attribute = CreateAttributeDescriptor(uint16(class), obis, int8(att))
+	}
+
+	for _, option := range values[3:] {
+		name, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+
+		switch name {
+		case "range":
+			ot.Last, e = parseLast(value)
+		case "omitempty":
+			ot.OmitEmpty = true
+		case "readonly":
+			ot.ReadOnly = true
+		case "writeonly":
+			ot.WriteOnly = true
+		case "scale":
+			ot.Scaled = true
+			ot.Scale, e = strconv.Atoi(value)
+		case "index":
+			var index uint64
+			index, e = strconv.ParseUint(value, 0, 16)
+			ot.DataIndex = uint16(index)
+		case "list":
+			ot.List = value
+			if value == "" {
+				e = errors.New("empty list name")
+			}
+		default:
+			e = errors.New("unknown option")
+		}
+
+		if e != nil {
+			err = NewError(ErrorInvalidParameter, fmt.Sprintf("invalid option %s: %s", option, tag))
+			return
+		}
+	}
+
+	switch {
+	case ot.ReadOnly && ot.WriteOnly:
+		err = NewError(ErrorInvalidParameter, fmt.Sprintf("readonly and writeonly options: %s", tag))
+	case ot.Method != nil && (ot.Last != 0 || ot.ReadOnly || ot.WriteOnly || ot.Scaled || ot.List != "" || ot.DataIndex != 0):
+		err = NewError(ErrorInvalidParameter, fmt.Sprintf("method with options other than omitempty: %s", tag))
+	case ot.List != "" && ot.Last != 0:
+		err = NewError(ErrorInvalidParameter, fmt.Sprintf("list and range options: %s", tag))
+	}
+
+	return
+}
+
+// ObisField is a field of a struct with an obis tag. Name includes the names of the enclosing fields
+// for nested structs (e.g. "Clock.Time"), and Index is the index sequence of reflect.Value.FieldByIndex.
+type ObisField struct {
+	Name  string
+	Index []int
+	Tag   ObisTag
+}
+
+// obisFields caches the fields of each struct type, so tags are parsed once per type.
+//
+//nolint:gochecknoglobals // cache of the obis fields, by type
+var obisFields sync.Map
+
+// ObisFields returns the fields with an obis tag of a struct type, including the ones of nested
+// structs without tag. Scaled fields must be floats.
+func ObisFields(t reflect.Type) ([]ObisField, error) {
+	if fields, ok := obisFields.Load(t); ok {
+		return fields.([]ObisField), nil
+	}
+
+	fields, err := buildObisFields(t, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	obisFields.Store(t, fields)
+	return fields, nil
+}
+
+func buildObisFields(t reflect.Type, prefix string, index []int) (fields []ObisField, err error) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("obis")
+		fieldIndex := append(append([]int{}, index...), i)
+
+		if !sf.IsExported() {
+			if tag != "" {
+				return nil, NewError(ErrorInvalidParameter, fmt.Sprintf("obis tag in unexported field %s%s", prefix, sf.Name))
+			}
+
+			continue
+		}
+
+		if tag == "" {
+			if sf.Type.Kind() == reflect.Struct {
+				nested, err := buildObisFields(sf.Type, prefix+sf.Name+".", fieldIndex)
+				if err != nil {
+					return nil, err
+				}
+
+				fields = append(fields, nested...)
+			}
+
+			continue
+		}
+
+		ot, err := ParseObisTag(tag)
+		if err != nil {
+			return nil, err
+		}
+
+		if ot.Scaled && !isFloat(sf.Type) {
+			return nil, NewError(ErrorInvalidParameter, fmt.Sprintf("scaled field %s%s must be a float", prefix, sf.Name))
+		}
+
+		fields = append(fields, ObisField{Name: prefix + sf.Name, Index: fieldIndex, Tag: ot})
+	}
+
+	return
+}
+
+func isFloat(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64
+}
+
+// parseLast parses a range such as last24h, accepting days (d) besides the units of time.Duration.
+func parseLast(value string) (time.Duration, error) {
+	if !strings.HasPrefix(value, "last") {
+		return 0, fmt.Errorf("invalid range %s", value)
+	}
+
+	value = strings.TrimPrefix(value, "last")
+
+	var last time.Duration
+	var err error
+	if strings.HasSuffix(value, "d") {
+		var days uint64
+		days, err = strconv.ParseUint(strings.TrimSuffix(value, "d"), 10, 16)
+		last = time.Duration(days) * 24 * time.Hour
+	} else {
+		last, err = time.ParseDuration(value)
+	}
+
+	if err != nil || last <= 0 {
+		return 0, fmt.Errorf("invalid range %s", value)
+	}
+
+	return last, nil
+}
@@ -0,0 +1,99 @@
+package dlms
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseObisTag(t *testing.T) {
+	ot, err := ParseObisTag("7,1-0:99.1.0.255,2")
+	assert.NoError(t, err)
+	assert.Equal(t, CreateAttributeDescriptor(7, "1-0:99.1.0.255", 2), ot.Attribute)
+	assert.Nil(t, ot.Method)
+	assert.Zero(t, ot.Last)
+	assert.False(t, ot.OmitEmpty || ot.ReadOnly || ot.WriteOnly || ot.Scaled)
+
+	ot, err = ParseObisTag("7,1-0:99.1.0.255,2,range=last7d,omitempty")
+	assert.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, ot.Last)
+	assert.True(t, ot.OmitEmpty)
+
+	ot, err = ParseObisTag("3,1-0:1.8.0.255,2,readonly,scale=-3")
+	assert.NoError(t, err)
+	assert.True(t, ot.ReadOnly)
+	assert.True(t, ot.Scaled)
+	assert.Equal(t, -3, ot.Scale)
+
+	ot, err = ParseObisTag("3,1-0:1.8.0.255,2,list=energy")
+	assert.NoError(t, err)
+	assert.Equal(t, "energy", ot.List)
+
+	ot, err = ParseObisTag("3,1-0:1.8.0.255,2,index=1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(1), ot.DataIndex)
+
+	ot, err = ParseObisTag("70,0-0:96.3.10.255,m1,omitempty")
+	assert.NoError(t, err)
+	assert.Nil(t, ot.Attribute)
+	assert.Equal(t, CreateMethodDescriptor(70, "0-0:96.3.10.255", 1), ot.Method)
+	assert.True(t, ot.OmitEmpty)
+
+	for _, tag := range []string{
+		"7,1-0:99.1.0.255",
+		"x,1-0:99.1.0.255,2",
+		"7,1-0:99.1.0.255,256",
+		"7,1-0:99.1.0.255,2,range=24h",
+		"7,1-0:99.1.0.255,2,range=last0h",
+		"7,1-0:99.1.0.255,2,scale=x",
+		"7,1-0:99.1.0.255,2,nocache",
+		"7,1-0:99.1.0.255,2,readonly,writeonly",
+		"7,1-0:99.1.0.255,2,list",
+		"7,1-0:99.1.0.255,2,list=profiles,range=last1d",
+		"70,0-0:96.3.10.255,m",
+		"70,0-0:96.3.10.255,m128",
+		"70,0-0:96.3.10.255,m1,readonly",
+		"70,0-0:96.3.10.255,m1,list=control",
+		"7,1-0:99.1.0.255,2,index=-1",
+		"70,0-0:96.3.10.255,m1,index=1",
+	} {
+		_, err = ParseObisTag(tag)
+		var dlmsError *Error
+		if assert.ErrorAs(t, err, &dlmsError, tag) {
+			assert.Equal(t, ErrorInvalidParameter, dlmsError.Code(), tag)
+		}
+	}
+}
+
+func TestObisFields(t *testing.T) {
+	type clock struct {
+		Time time.Time `obis:"8,0-0:1.0.0.255,2"`
+	}
+
+	type meter struct {
+		Energy float64 `obis:"3,1-0:1.8.0.255,2,scale=-3"`
+		Clock  clock
+		Other  int
+	}
+
+	fields, err := ObisFields(reflect.TypeOf(meter{}))
+	assert.NoError(t, err)
+	if assert.Len(t, fields, 2) {
+		assert.Equal(t, "Energy", fields[0].Name)
+		assert.True(t, fields[0].Tag.Scaled)
+		assert.Equal(t, "Clock.Time", fields[1].Name)
+		assert.Equal(t, []int{1, 0}, fields[1].Index)
+	}
+
+	_, err = ObisFields(reflect.TypeOf(struct {
+		Energy uint32 `obis:"3,1-0:1.8.0.255,2,scale=-3"`
+	}{}))
+	assert.Error(t, err)
+
+	_, err = ObisFields(reflect.TypeOf(struct {
+		energy uint32 `obis:"3,1-0:1.8.0.255,2"`
+	}{}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,422 @@
+package dlms
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+)
+
+const (
+	// AssociationLNClassID is the class of the Association LN interface class
+	AssociationLNClassID = 15
+	// AssociationLNCurrent is the logical name used to reference the current association
+	AssociationLNCurrent = "0.0.40.0.0.255"
+	// AssociationLNObjectList is the attribute of the Association LN holding the object list
+	AssociationLNObjectList = 2
+)
+
+type AttributeAccessMode uint8
+
+const (
+	AttributeAccessNoAccess                  AttributeAccessMode = 0
+	AttributeAccessReadOnly                  AttributeAccessMode = 1
+	AttributeAccessWriteOnly                 AttributeAccessMode = 2
+	AttributeAccessReadAndWrite              AttributeAccessMode = 3
+	AttributeAccessAuthenticatedReadOnly     AttributeAccessMode = 4
+	AttributeAccessAuthenticatedWriteOnly    AttributeAccessMode = 5
+	AttributeAccessAuthenticatedReadAndWrite AttributeAccessMode = 6
+)
+
+func (m AttributeAccessMode) String() string {
+	switch m {
+	case AttributeAccessNoAccess:
+		return "no-access"
+	case AttributeAccessReadOnly:
+		return "read-only"
+	case AttributeAccessWriteOnly:
+		return "write-only"
+	case AttributeAccessReadAndWrite:
+		return "read-and-write"
+	case AttributeAccessAuthenticatedReadOnly:
+		return "authenticated-read-only"
+	case AttributeAccessAuthenticatedWriteOnly:
+		return "authenticated-write-only"
+	case AttributeAccessAuthenticatedReadAndWrite:
+		return "authenticated-read-and-write"
+	default:
+		return ""
+	}
+}
+
+// CanRead returns true if the attribute can be read.
+func (m AttributeAccessMode) CanRead() bool {
+	return m == AttributeAccessReadOnly || m == AttributeAccessReadAndWrite ||
+		m == AttributeAccessAuthenticatedReadOnly || m == AttributeAccessAuthenticatedReadAndWrite
+}
+
+// CanWrite returns true if the attribute can be written.
+func (m AttributeAccessMode) CanWrite() bool {
+	return m == AttributeAccessWriteOnly || m == AttributeAccessReadAndWrite ||
+		m == AttributeAccessAuthenticatedWriteOnly || m == AttributeAccessAuthenticatedReadAndWrite
+}
+
+type MethodAccessMode uint8
+
+const (
+	MethodAccessNoAccess            MethodAccessMode = 0
+	MethodAccessAccess              MethodAccessMode = 1
+	MethodAccessAuthenticatedAccess MethodAccessMode = 2
+)
+
+func (m MethodAccessMode) String() string {
+	switch m {
+	case MethodAccessNoAccess:
+		return "no-access"
+	case MethodAccessAccess:
+		return "access"
+	case MethodAccessAuthenticatedAccess:
+		return "authenticated-access"
+	default:
+		return ""
+	}
+}
+
+// CanExecute returns true if the method can be invoked.
+func (m MethodAccessMode) CanExecute() bool {
+	return m == MethodAccessAccess || m == MethodAccessAuthenticatedAccess
+}
+
+type AttributeAccessItem struct {
+	AttributeID     int8
+	AccessMode      AttributeAccessMode
+	AccessSelectors []int8
+}
+
+type MethodAccessItem struct {
+	MethodID   int8
+	AccessMode MethodAccessMode
+}
+
+type AccessRights struct {
+	Attributes []AttributeAccessItem
+	Methods    []MethodAccessItem
+}
+
+// ObjectListElement is an entry of the object_list attribute of the Association LN.
+type ObjectListElement struct {
+	ClassID      uint16
+	Version      uint8
+	LogicalName  Obis
+	AccessRights AccessRights
+}
+
+// AttributeAccess returns the access mode of an attribute. Attributes not listed have no access.
+func (e ObjectListElement) AttributeAccess(attributeID int8) AttributeAccessMode {
+	for _, a := range e.AccessRights.Attributes {
+		if a.AttributeID == attributeID {
+			return a.AccessMode
+		}
+	}
+
+	return AttributeAccessNoAccess
+}
+
+// MethodAccess returns the access mode of a method. Methods not listed have no access.
+func (e ObjectListElement) MethodAccess(methodID int8) MethodAccessMode {
+	for _, m := range e.AccessRights.Methods {
+		if m.MethodID == methodID {
+			return m.AccessMode
+		}
+	}
+
+	return MethodAccessNoAccess
+}
+
+type ObjectList []ObjectListElement
+
+// Find returns the element with the given class and logical name, or nil if it's not in the list.
+func (ol ObjectList) Find(classID uint16, logicalName Obis) *ObjectListElement {
+	for i := range ol {
+		if ol[i].ClassID == classID && bytes.Equal(ol[i].LogicalName.Bytes(), logicalName.Bytes()) {
+			return &ol[i]
+		}
+	}
+
+	return nil
+}
+
+// Data returns the object list as A-XDR data, as sent by a server.
+func (ol ObjectList) Data() *axdr.DlmsData {
+	elements := make([]*axdr.DlmsData, 0, len(ol))
+
+	for _, e := range ol {
+		attributes := make([]*axdr.DlmsData, 0, len(e.AccessRights.Attributes))
+		for _, a := range e.AccessRights.Attributes {
+			selectors := axdr.DlmsData{Tag: axdr.TagNull, Value: nil}
+			if len(a.AccessSelectors) > 0 {
+				values := make([]*axdr.DlmsData, 0, len(a.AccessSelectors))
+				for _, s := range a.AccessSelectors {
+					values = append(values, axdr.CreateAxdrInteger(s))
+				}
+				selectors = *axdr.CreateAxdrArray(values)
+			}
+
+			attributes = append(attributes, axdr.CreateAxdrStructure([]*axdr.DlmsData{
+				axdr.CreateAxdrInteger(a.AttributeID),
+				axdr.CreateAxdrEnum(uint8(a.AccessMode)),
+				&selectors,
+			}))
+		}
+
+		methods := make([]*axdr.DlmsData, 0, len(e.AccessRights.Methods))
+		for _, m := range e.AccessRights.Methods {
+			methods = append(methods, axdr.CreateAxdrStructure([]*axdr.DlmsData{
+				axdr.CreateAxdrInteger(m.MethodID),
+				axdr.CreateAxdrEnum(uint8(m.AccessMode)),
+			}))
+		}
+
+		elements = append(elements, axdr.CreateAxdrStructure([]*axdr.DlmsData{
+			axdr.CreateAxdrLongUnsigned(e.ClassID),
+			axdr.CreateAxdrUnsigned(e.Version),
+			axdr.CreateAxdrOctetString(e.LogicalName.String()),
+			axdr.CreateAxdrStructure([]*axdr.DlmsData{
+				axdr.CreateAxdrArray(attributes),
+				axdr.CreateAxdrArray(methods),
+			}),
+		}))
+	}
+
+	return axdr.CreateAxdrArray(elements)
+}
+
+// DecodeObjectList decodes the object_list attribute of the Association LN. Method access modes
+// are accepted both as boolean (version 0) and as enum (version 1 and later).
+func DecodeObjectList(data axdr.DlmsData) (out ObjectList, err error) {
+	elements, err := DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return nil, fmt.Errorf("object list: %w", err)
+	}
+
+	out = make(ObjectList, 0, len(elements))
+	for i, element := range elements {
+		e, err := decodeObjectListElement(*element)
+		if err != nil {
+			return nil, fmt.Errorf("object list element %d: %w", i, err)
+		}
+
+		out = append(out, e)
+	}
+
+	return out, nil
+}
+
+func decodeObjectListElement(data axdr.DlmsData) (out ObjectListElement, err error) {
+	fields, err := DataAsSlice(data, axdr.TagStructure, 4)
+	if err != nil {
+		return
+	}
+
+	classID, ok := fields[0].Value.(uint16)
+	if fields[0].Tag != axdr.TagLongUnsigned || !ok {
+		err = fmt.Errorf("invalid class id")
+		return
+	}
+	out.ClassID = classID
+
+	version, ok := fields[1].Value.(uint8)
+	if fields[1].Tag != axdr.TagUnsigned || !ok {
+		err = fmt.Errorf("invalid version")
+		return
+	}
+	out.Version = version
+
+	out.LogicalName, err = DecodeLogicalName(*fields[2])
+	if err != nil {
+		return
+	}
+
+	rights, err := DataAsSlice(*fields[3], axdr.TagStructure, 2)
+	if err != nil {
+		err = fmt.Errorf("access rights: %w", err)
+		return
+	}
+
+	out.AccessRights.Attributes, err = decodeAttributeAccess(*rights[0])
+	if err != nil {
+		err = fmt.Errorf("attribute access: %w", err)
+		return
+	}
+
+	out.AccessRights.Methods, err = decodeMethodAccess(*rights[1])
+	if err != nil {
+		err = fmt.Errorf("method access: %w", err)
+		return
+	}
+
+	return
+}
+
+func decodeAttributeAccess(data axdr.DlmsData) ([]AttributeAccessItem, error) {
+	items, err := DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]AttributeAccessItem, 0, len(items))
+	for _, item := range items {
+		fields, err := DataAsSlice(*item, axdr.TagStructure, 3)
+		if err != nil {
+			return nil, err
+		}
+
+		id, ok := fields[0].Value.(int8)
+		if !ok {
+			return nil, fmt.Errorf("invalid attribute id")
+		}
+
+		mode, ok := fields[1].Value.(uint8)
+		if !ok {
+			return nil, fmt.Errorf("invalid access mode of attribute %d", id)
+		}
+
+		a := AttributeAccessItem{AttributeID: id, AccessMode: AttributeAccessMode(mode)}
+
+		if fields[2].Tag == axdr.TagArray {
+			selectors, _ := fields[2].Value.([]*axdr.DlmsData)
+			for _, s := range selectors {
+				selector, ok := s.Value.(int8)
+				if !ok {
+					return nil, fmt.Errorf("invalid access selector of attribute %d", id)
+				}
+				a.AccessSelectors = append(a.AccessSelectors, selector)
+			}
+		}
+
+		out = append(out, a)
+	}
+
+	return out, nil
+}
+
+func decodeMethodAccess(data axdr.DlmsData) ([]MethodAccessItem, error) {
+	items, err := DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]MethodAccessItem, 0, len(items))
+	for _, item := range items {
+		fields, err := DataAsSlice(*item, axdr.TagStructure, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		id, ok := fields[0].Value.(int8)
+		if !ok {
+			return nil, fmt.Errorf("invalid method id")
+		}
+
+		m := MethodAccessItem{MethodID: id}
+		switch mode := fields[1].Value.(type) {
+		case bool:
+			if mode {
+				m.AccessMode = MethodAccessAccess
+			}
+		case uint8:
+			m.AccessMode = MethodAccessMode(mode)
+		default:
+			return nil, fmt.Errorf("invalid access mode of method %d", id)
+		}
+
+		out = append(out, m)
+	}
+
+	return out, nil
+}
+
+// DataAsSlice returns the elements of an array or structure. If length is not zero, it's checked.
+func DataAsSlice(data axdr.DlmsData, tag axdr.DataTag, length int) ([]*axdr.DlmsData, error) {
+	if data.Tag != tag {
+		return nil, fmt.Errorf("unexpected tag %v", data.Tag)
+	}
+
+	elements, ok := data.Value.([]*axdr.DlmsData)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value %T", data.Value)
+	}
+
+	if length != 0 && len(elements) != length {
+		return nil, fmt.Errorf("unexpected length %d, expecting %d", len(elements), length)
+	}
+
+	for _, e := range elements {
+		if e == nil {
+			return nil, fmt.Errorf("nil element")
+		}
+	}
+
+	return elements, nil
+}
+
+// DecodeLogicalName decodes an octet string holding a logical name.
+func DecodeLogicalName(data axdr.DlmsData) (ln Obis, err error) {
+	str, ok := data.Value.(string)
+	if data.Tag != axdr.TagOctetString || !ok {
+		err = fmt.Errorf("invalid logical name %v", data.Value)
+		return
+	}
+
+	src, err := hex.DecodeString(str)
+	if err != nil || len(src) != 6 {
+		err = fmt.Errorf("invalid logical name %s", str)
+		return
+	}
+
+	return DecodeObis(&src)
+}
+
+// ObjectListCache stores the object list of the devices. Key identifies the device.
+type ObjectListCache interface {
+	Get(key string) (ObjectList, bool)
+	Set(key string, ol ObjectList)
+	Delete(key string)
+}
+
+type memoryObjectListCache struct {
+	lists map[string]ObjectList
+	mutex sync.RWMutex
+}
+
+// NewObjectListMemoryCache creates a cache that keeps the object lists in memory.
+func NewObjectListMemoryCache() ObjectListCache {
+	return &memoryObjectListCache{
+		lists: make(map[string]ObjectList),
+		mutex: sync.RWMutex{},
+	}
+}
+
+func (c *memoryObjectListCache) Get(key string) (ObjectList, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	ol, ok := c.lists[key]
+	return ol, ok
+}
+
+func (c *memoryObjectListCache) Set(key string, ol ObjectList) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.lists[key] = ol
+}
+
+func (c *memoryObjectListCache) Delete(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.lists, key)
+}
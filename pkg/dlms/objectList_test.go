@@ -0,0 +1,160 @@
+package dlms
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+)
+
+func TestObjectList(t *testing.T) {
+	ol := ObjectList{
+		{
+			ClassID:     3,
+			Version:     0,
+			LogicalName: *CreateObis("1.0.1.8.0.255"),
+			AccessRights: AccessRights{
+				Attributes: []AttributeAccessItem{
+					{AttributeID: 1, AccessMode: AttributeAccessReadOnly},
+					{AttributeID: 2, AccessMode: AttributeAccessReadAndWrite},
+				},
+				Methods: []MethodAccessItem{{MethodID: 1, AccessMode: MethodAccessAccess}},
+			},
+		},
+		{
+			ClassID:     7,
+			Version:     1,
+			LogicalName: *CreateObis("1.0.99.1.0.255"),
+			AccessRights: AccessRights{
+				Attributes: []AttributeAccessItem{
+					{AttributeID: 2, AccessMode: AttributeAccessAuthenticatedReadOnly, AccessSelectors: []int8{1, 2}},
+				},
+				Methods: []MethodAccessItem{},
+			},
+		},
+	}
+
+	src, err := ol.Data().Encode()
+	if err != nil {
+		t.Fatalf("Encode failed. err: %v", err)
+	}
+
+	decoder := axdr.NewDataDecoder(&src)
+	data, err := decoder.Decode(&src)
+	if err != nil {
+		t.Fatalf("Decode failed. err: %v", err)
+	}
+
+	out, err := DecodeObjectList(data)
+	if err != nil {
+		t.Fatalf("DecodeObjectList failed. err: %v", err)
+	}
+
+	if !reflect.DeepEqual(ol, out) {
+		t.Errorf("Failed. get: %v, should: %v", out, ol)
+	}
+
+	e := out.Find(3, *CreateObis("1.0.1.8.0.255"))
+	if e == nil {
+		t.Fatalf("Find failed")
+	}
+	if !e.AttributeAccess(2).CanWrite() || e.AttributeAccess(1).CanWrite() || e.AttributeAccess(3).CanRead() {
+		t.Errorf("AttributeAccess failed")
+	}
+	if !e.MethodAccess(1).CanExecute() || e.MethodAccess(2).CanExecute() {
+		t.Errorf("MethodAccess failed")
+	}
+
+	if out.Find(3, *CreateObis("1.0.99.1.0.255")) != nil {
+		t.Errorf("Find of unknown object failed")
+	}
+}
+
+func TestDecodeObjectListWithBooleanMethodAccess(t *testing.T) {
+	data := *axdr.CreateAxdrArray([]*axdr.DlmsData{
+		axdr.CreateAxdrStructure([]*axdr.DlmsData{
+			axdr.CreateAxdrLongUnsigned(70),
+			axdr.CreateAxdrUnsigned(0),
+			axdr.CreateAxdrOctetString("000060030aff"),
+			axdr.CreateAxdrStructure([]*axdr.DlmsData{
+				axdr.CreateAxdrArray([]*axdr.DlmsData{}),
+				axdr.CreateAxdrArray([]*axdr.DlmsData{
+					axdr.CreateAxdrStructure([]*axdr.DlmsData{axdr.CreateAxdrInteger(1), axdr.CreateAxdrBoolean(true)}),
+					axdr.CreateAxdrStructure([]*axdr.DlmsData{axdr.CreateAxdrInteger(2), axdr.CreateAxdrBoolean(false)}),
+				}),
+			}),
+		}),
+	})
+
+	out, err := DecodeObjectList(data)
+	if err != nil {
+		t.Fatalf("DecodeObjectList failed. err: %v", err)
+	}
+
+	methods := []MethodAccessItem{{MethodID: 1, AccessMode: MethodAccessAccess}, {MethodID: 2, AccessMode: MethodAccessNoAccess}}
+	if len(out) != 1 || !reflect.DeepEqual(out[0].AccessRights.Methods, methods) {
+		t.Errorf("Failed. get: %v", out)
+	}
+
+	_, err = DecodeObjectList(*axdr.CreateAxdrStructure([]*axdr.DlmsData{}))
+	if err == nil {
+		t.Errorf("DecodeObjectList of invalid data should fail")
+	}
+}
+
+func TestObjectListMemoryCache(t *testing.T) {
+	cache := NewObjectListMemoryCache()
+
+	if _, ok := cache.Get("meter"); ok {
+		t.Errorf("Get of empty cache failed")
+	}
+
+	ol := ObjectList{{ClassID: 1, LogicalName: *CreateObis("0.0.96.1.0.255")}}
+	cache.Set("meter", ol)
+
+	got, ok := cache.Get("meter")
+	if !ok || !reflect.DeepEqual(ol, got) {
+		t.Errorf("Get failed. get: %v, should: %v", got, ol)
+	}
+
+	cache.Delete("meter")
+	if _, ok := cache.Get("meter"); ok {
+		t.Errorf("Delete failed")
+	}
+}
+
+func TestDataAsSlice(t *testing.T) {
+	data := *axdr.CreateAxdrStructure([]*axdr.DlmsData{axdr.CreateAxdrUnsigned(1), axdr.CreateAxdrUnsigned(2)})
+
+	elements, err := DataAsSlice(data, axdr.TagStructure, 2)
+	if err != nil || len(elements) != 2 {
+		t.Errorf("Failed. get: %v, err: %v", elements, err)
+	}
+
+	_, err = DataAsSlice(data, axdr.TagArray, 0)
+	if err == nil {
+		t.Errorf("DataAsSlice with other tag should fail")
+	}
+
+	_, err = DataAsSlice(data, axdr.TagStructure, 3)
+	if err == nil {
+		t.Errorf("DataAsSlice with other length should fail")
+	}
+}
+
+func TestDecodeLogicalName(t *testing.T) {
+	ln, err := DecodeLogicalName(*axdr.CreateAxdrOctetString("000060030aff"))
+	if err != nil || ln.String() != "0.0.96.3.10.255" {
+		t.Errorf("Failed. get: %v, err: %v", ln, err)
+	}
+
+	_, err = DecodeLogicalName(*axdr.CreateAxdrOctetString("000060030a"))
+	if err == nil {
+		t.Errorf("DecodeLogicalName of 5 bytes should fail")
+	}
+
+	_, err = DecodeLogicalName(*axdr.CreateAxdrUnsigned(1))
+	if err == nil {
+		t.Errorf("DecodeLogicalName of an unsigned should fail")
+	}
+}
@@ -0,0 +1,58 @@
+package dlms
+
+import (
+	"bytes"
+)
+
+// ReadRequest implement CosemPDU. It's the short name equivalent of the GetRequest: each variable
+// is a variable name or a parameterized access, or a block number access to request the next
+// block of a response.
+type ReadRequest struct {
+	Variables []VariableAccessSpecification
+}
+
+func CreateReadRequest(variables []VariableAccessSpecification) *ReadRequest {
+	if len(variables) < 1 {
+		panic("Variables cannot have zero members")
+	}
+	return &ReadRequest{
+		Variables: variables,
+	}
+}
+
+func (rr ReadRequest) Encode() (out []byte, err error) {
+	var buf bytes.Buffer
+	buf.WriteByte(TagReadRequest.Value())
+
+	list, err := encodeVariableAccessList(rr.Variables)
+	if err != nil {
+		return
+	}
+	buf.Write(list)
+
+	out = buf.Bytes()
+	return
+}
+
+func DecodeReadRequest(ori *[]byte) (out ReadRequest, err error) {
+	src := *ori
+
+	if len(src) < 2 {
+		err = ErrWrongLength(len(src), 2)
+		return
+	}
+
+	if src[0] != TagReadRequest.Value() {
+		err = ErrWrongTag(0, src[0], byte(TagReadRequest))
+		return
+	}
+	src = src[1:]
+
+	out.Variables, err = decodeVariableAccessList(&src)
+	if err != nil {
+		return
+	}
+
+	(*ori) = (*ori)[len((*ori))-len(src):]
+	return
+}
@@ -0,0 +1,50 @@
+package dlms
+
+import (
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_ReadRequest(t *testing.T) {
+	rr := *CreateReadRequest([]VariableAccessSpecification{*CreateVariableName(0xFA08), *CreateBlockNumberAccess(3)})
+	out, err := rr.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, "050202FA08050003", encodeHexString(out))
+
+	rr = *CreateReadRequest([]VariableAccessSpecification{*CreateParameterizedAccess(0x0110, 2, *axdr.CreateAxdrDoubleLongUnsigned(1))})
+	out, err = rr.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, "0501040110020600000001", encodeHexString(out))
+
+	rr = *CreateReadRequest([]VariableAccessSpecification{*CreateReadDataBlockAccess(true, 1, []byte{0x01, 0x02})})
+	out, err = rr.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, "050106FF0001020102", encodeHexString(out))
+}
+
+func TestDecode_ReadRequest(t *testing.T) {
+	src := decodeHexString("0503020110040118010600000005050002")
+	rr, err := DecodeReadRequest(&src)
+	assert.NoError(t, err)
+	assert.Empty(t, src)
+	assert.Len(t, rr.Variables, 3)
+
+	assert.Equal(t, TagVariableName, rr.Variables[0].Tag)
+	assert.Equal(t, uint16(0x0110), rr.Variables[0].VariableName)
+	assert.Equal(t, TagParameterizedAccess, rr.Variables[1].Tag)
+	assert.Equal(t, uint16(0x0118), rr.Variables[1].VariableName)
+	assert.Equal(t, uint8(1), rr.Variables[1].Selector)
+	assert.Equal(t, uint32(5), rr.Variables[1].Parameter.Value)
+	assert.Equal(t, TagBlockNumberAccess, rr.Variables[2].Tag)
+	assert.Equal(t, uint16(2), rr.Variables[2].BlockNumber)
+
+	src = decodeHexString("05010201")
+	_, err = DecodeReadRequest(&src)
+	assert.Error(t, err)
+
+	src = decodeHexString("050109")
+	_, err = DecodeReadRequest(&src)
+	assert.Error(t, err)
+}
@@ -0,0 +1,192 @@
+package dlms
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+)
+
+type readResultTag uint8
+
+const (
+	TagReadResultData            readResultTag = 0
+	TagReadResultDataAccessError readResultTag = 1
+	TagReadResultDataBlockResult readResultTag = 2
+	TagReadResultBlockNumber     readResultTag = 3
+)
+
+// Value will return primitive value of the target.
+// This is used for comparing with non custom typed object
+func (s readResultTag) Value() uint8 {
+	return uint8(s)
+}
+
+// ReadResult is the result of a variable of a ReadRequest. Only the fields used by Tag are
+// meaningful. The raw data of the data blocks, once joined, holds the encoded ReadResponse without
+// its tag.
+type ReadResult struct {
+	Tag         readResultTag
+	Data        axdr.DlmsData
+	Access      AccessResultTag
+	LastBlock   bool
+	BlockNumber uint16
+	RawData     []byte
+}
+
+func CreateReadResultAsData(data axdr.DlmsData) *ReadResult {
+	return &ReadResult{Tag: TagReadResultData, Data: data}
+}
+
+func CreateReadResultAsAccess(access AccessResultTag) *ReadResult {
+	return &ReadResult{Tag: TagReadResultDataAccessError, Access: access}
+}
+
+func CreateReadResultAsDataBlock(lastBlock bool, blockNum uint16, raw []byte) *ReadResult {
+	return &ReadResult{Tag: TagReadResultDataBlockResult, LastBlock: lastBlock, BlockNumber: blockNum, RawData: raw}
+}
+
+func CreateReadResultAsBlockNumber(blockNum uint16) *ReadResult {
+	return &ReadResult{Tag: TagReadResultBlockNumber, BlockNumber: blockNum}
+}
+
+func (rr ReadResult) Encode() (out []byte, err error) {
+	var buf bytes.Buffer
+	buf.WriteByte(rr.Tag.Value())
+
+	switch rr.Tag {
+	case TagReadResultData:
+		val, e := rr.Data.Encode()
+		if e != nil {
+			err = e
+			return
+		}
+		buf.Write(val)
+	case TagReadResultDataAccessError:
+		buf.WriteByte(rr.Access.Value())
+	case TagReadResultDataBlockResult:
+		buf.WriteByte(encodeBool(rr.LastBlock))
+		buf.Write(encodeUnsigned16(rr.BlockNumber))
+		raw, e := encodeRawData(rr.RawData)
+		if e != nil {
+			err = e
+			return
+		}
+		buf.Write(raw)
+	case TagReadResultBlockNumber:
+		buf.Write(encodeUnsigned16(rr.BlockNumber))
+	default:
+		err = fmt.Errorf("read result tag not recognized (%v)", rr.Tag)
+		return
+	}
+
+	out = buf.Bytes()
+	return
+}
+
+func DecodeReadResult(ori *[]byte) (out ReadResult, err error) {
+	src := *ori
+
+	if len(src) < 2 {
+		err = ErrWrongLength(len(src), 2)
+		return
+	}
+
+	out.Tag = readResultTag(src[0])
+	src = src[1:]
+
+	switch out.Tag {
+	case TagReadResultData:
+		decoder := axdr.NewDataDecoder(&src)
+		out.Data, err = decoder.Decode(&src)
+	case TagReadResultDataAccessError:
+		out.Access, err = GetAccessTag(src[0])
+		src = src[1:]
+	case TagReadResultDataBlockResult:
+		if out.LastBlock, out.BlockNumber, err = decodeBlockHeader(&src); err != nil {
+			return
+		}
+		out.RawData, err = decodeRawData(&src)
+	case TagReadResultBlockNumber:
+		out.BlockNumber, err = decodeUnsigned16(&src)
+	default:
+		err = fmt.Errorf("read result tag not recognized (%v)", out.Tag)
+	}
+
+	if err != nil {
+		return
+	}
+
+	(*ori) = (*ori)[len((*ori))-len(src):]
+	return
+}
+
+// ReadResponse implement CosemPDU. There is a result for each variable of the request.
+type ReadResponse struct {
+	Results []ReadResult
+}
+
+func CreateReadResponse(results []ReadResult) *ReadResponse {
+	if len(results) < 1 {
+		panic("Results cannot have zero members")
+	}
+	return &ReadResponse{
+		Results: results,
+	}
+}
+
+func (rr ReadResponse) Encode() (out []byte, err error) {
+	var buf bytes.Buffer
+	buf.WriteByte(TagReadResponse.Value())
+
+	length, err := axdr.EncodeLength(len(rr.Results))
+	if err != nil {
+		return
+	}
+	buf.Write(length)
+
+	for _, r := range rr.Results {
+		val, e := r.Encode()
+		if e != nil {
+			err = e
+			return
+		}
+		buf.Write(val)
+	}
+
+	out = buf.Bytes()
+	return
+}
+
+func DecodeReadResponse(ori *[]byte) (out ReadResponse, err error) {
+	src := *ori
+
+	if len(src) < 2 {
+		err = ErrWrongLength(len(src), 2)
+		return
+	}
+
+	if src[0] != TagReadResponse.Value() {
+		err = ErrWrongTag(0, src[0], byte(TagReadResponse))
+		return
+	}
+	src = src[1:]
+
+	count, err := decodeQuantity(&src)
+	if err != nil {
+		return
+	}
+
+	out.Results = make([]ReadResult, 0, count)
+	for i := 0; i < count; i++ {
+		r, e := DecodeReadResult(&src)
+		if e != nil {
+			err = e
+			return
+		}
+		out.Results = append(out.Results, r)
+	}
+
+	(*ori) = (*ori)[len((*ori))-len(src):]
+	return
+}
@@ -0,0 +1,47 @@
+package dlms
+
+import (
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_ReadResponse(t *testing.T) {
+	rr := *CreateReadResponse([]ReadResult{
+		*CreateReadResultAsData(*axdr.CreateAxdrLongUnsigned(60)),
+		*CreateReadResultAsAccess(TagAccObjectUndefined),
+		*CreateReadResultAsDataBlock(false, 1, []byte{0xAA, 0xBB}),
+		*CreateReadResultAsBlockNumber(2),
+	})
+	out, err := rr.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, "0C040012003C01040200000102AABB030002", encodeHexString(out))
+}
+
+func TestDecode_ReadResponse(t *testing.T) {
+	src := decodeHexString("0c040012003c01040200000102aabb030002")
+	rr, err := DecodeReadResponse(&src)
+	assert.NoError(t, err)
+	assert.Empty(t, src)
+	assert.Len(t, rr.Results, 4)
+
+	assert.Equal(t, TagReadResultData, rr.Results[0].Tag)
+	assert.Equal(t, uint16(60), rr.Results[0].Data.Value)
+	assert.Equal(t, TagReadResultDataAccessError, rr.Results[1].Tag)
+	assert.Equal(t, TagAccObjectUndefined, rr.Results[1].Access)
+	assert.Equal(t, TagReadResultDataBlockResult, rr.Results[2].Tag)
+	assert.False(t, rr.Results[2].LastBlock)
+	assert.Equal(t, uint16(1), rr.Results[2].BlockNumber)
+	assert.Equal(t, []byte{0xAA, 0xBB}, rr.Results[2].RawData)
+	assert.Equal(t, TagReadResultBlockNumber, rr.Results[3].Tag)
+	assert.Equal(t, uint16(2), rr.Results[3].BlockNumber)
+
+	src = decodeHexString("0c01020000010aaa")
+	_, err = DecodeReadResponse(&src)
+	assert.Error(t, err)
+
+	pdu, err := DecodeCosem(&[]byte{0x0C, 0x01, 0x00, 0x11, 0x05})
+	assert.NoError(t, err)
+	assert.IsType(t, ReadResponse{}, pdu)
+}
@@ -68,3 +68,16 @@ func DecodeRLRE(settings *Settings, ori *[]byte) (out RLRE, err error) {
 	(*ori) = (*ori)[len((*ori))-len(src):]
 	return
 }
+
+func EncodeRLRE(reason *ReleaseResponseReason) (out []byte, err error) {
+	out = []byte{TagRLRE.Value(), 0x00}
+
+	if reason != nil {
+		out = append(out, BERTypeContext, 0x01, byte(*reason))
+	}
+
+	// Add length
+	out[1] = byte(len(out) - 2)
+
+	return
+}
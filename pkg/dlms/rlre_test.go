@@ -1,6 +1,7 @@
 package dlms
 
 import (
+	"bytes"
 	"testing"
 )
 
@@ -27,3 +28,22 @@ func TestDecodeRLREWithUserInformation(t *testing.T) {
 		t.Errorf("Invalid AssociationResult. Get %v", *rlre.ReleaseResponseReason)
 	}
 }
+
+func TestEncodeRLRE(t *testing.T) {
+	out, err := EncodeRLRE(nil)
+	if err != nil {
+		t.Errorf("Encode Failed. Err: %v", err)
+	}
+	if !bytes.Equal(out, decodeHexString("6300")) {
+		t.Errorf("Failed. Get: %s, should: 6300", encodeHexString(out))
+	}
+
+	reason := ReleaseResponseReasonNormal
+	out, err = EncodeRLRE(&reason)
+	if err != nil {
+		t.Errorf("Encode Failed. Err: %v", err)
+	}
+	if !bytes.Equal(out, decodeHexString("6303800100")) {
+		t.Errorf("Failed. Get: %s, should: 6303800100", encodeHexString(out))
+	}
+}
@@ -39,3 +39,55 @@ func EncodeRLRQ(settings *Settings) (out []byte, err error) {
 
 	return
 }
+
+type RLRQ struct {
+	ReleaseRequestReason *ReleaseRequestReason
+}
+
+func DecodeRLRQ(ori *[]byte) (out RLRQ, err error) {
+	src := *ori
+
+	if len(src) < 2 {
+		err = ErrWrongLength(len(src), 2)
+		return
+	}
+
+	if src[0] != TagRLRQ.Value() {
+		err = ErrWrongTag(0, src[0], byte(TagRLRQ))
+		return
+	}
+
+	length := int(2 + src[1])
+	if len(src) < length {
+		err = ErrWrongLength(len(src), length)
+		return
+	}
+
+	src = src[2:]
+	length -= 2
+
+	for length > 0 {
+		if len(src) < 2 {
+			err = ErrWrongLength(len(src), 2)
+			return
+		}
+
+		tagLength := int(src[1])
+		if len(src) < (2 + tagLength) {
+			err = ErrWrongLength(len(src), 2+tagLength)
+			return
+		}
+
+		if src[0] == BERTypeContext && tagLength == 1 {
+			// ReleaseRequestReason - 0x80
+			reason := ReleaseRequestReason(src[2])
+			out.ReleaseRequestReason = &reason
+		}
+
+		src = src[2+tagLength:]
+		length -= 2 + tagLength
+	}
+
+	(*ori) = (*ori)[len((*ori))-len(src):]
+	return
+}
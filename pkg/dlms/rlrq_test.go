@@ -41,3 +41,23 @@ func TestEncodeRLRWithUserInformation(t *testing.T) {
 		t.Errorf("Failed. Get: %s, should: %s", encodeHexString(out), encodeHexString(result))
 	}
 }
+
+func TestDecodeRLRQ(t *testing.T) {
+	src := decodeHexString("6200")
+	rlrq, err := DecodeRLRQ(&src)
+	if err != nil {
+		t.Errorf("Failed on DecodeRLRQ. Err: %v", err)
+	}
+	if rlrq.ReleaseRequestReason != nil {
+		t.Errorf("Invalid ReleaseRequestReason. Should be nil but get %v", *rlrq.ReleaseRequestReason)
+	}
+
+	src = decodeHexString("6239800100BE340432213030000001078E6341442275404C816C6BED3E33AE809EC51E1D0E428BE8F5F643E26C3ED8295297AF055F2BC322DA3BD8")
+	rlrq, err = DecodeRLRQ(&src)
+	if err != nil {
+		t.Errorf("Failed on DecodeRLRQ. Err: %v", err)
+	}
+	if rlrq.ReleaseRequestReason == nil || *rlrq.ReleaseRequestReason != ReleaseRequestReasonNormal {
+		t.Errorf("Invalid ReleaseRequestReason")
+	}
+}
@@ -36,12 +36,20 @@ const (
 	SecurityKeySetBroadcast Security = 0x40 // Key set broadcast security is used.
 )
 
+type Referencing byte
+
+const (
+	ReferencingLogicalName Referencing = 0 // Objects are referenced by class and logical name.
+	ReferencingShortName   Referencing = 1 // Objects are referenced by short name.
+)
+
 type Ciphering struct {
 	Level             SecurityLevel
 	Security          Security
 	SystemTitle       []byte
 	SourceSystemTitle []byte
 	UnicastKey        []byte
+	BroadcastKey      []byte
 	AuthenticationKey []byte
 	UnicastKeyIC      uint32
 	DedicatedKey      []byte
@@ -55,6 +63,7 @@ type Settings struct {
 	MaxPduRecvSize   int
 	MaxPduSendSize   int
 	ConformanceBlock int
+	Referencing      Referencing
 }
 
 func NewSettingsWithoutAuthentication() (Settings, error) {
@@ -95,6 +104,15 @@ func NewSettingsWithLowAuthenticationAndCiphering(password []byte, cipher Cipher
 	return s, nil
 }
 
+// UseShortNameReferencing switches the settings to short name referencing, proposing the short
+// name services in the conformance block.
+func (s *Settings) UseShortNameReferencing() {
+	s.Referencing = ReferencingShortName
+	s.ConformanceBlock = ConformanceBlockRead | ConformanceBlockWrite | ConformanceBlockUnconfirmedWrite |
+		ConformanceBlockBlockTransferWithGetOrRead | ConformanceBlockBlockTransferWithSetOrWrite |
+		ConformanceBlockMultipleReferences | ConformanceBlockParametrizedAccess | ConformanceBlockInformationReport
+}
+
 func NewCiphering(level SecurityLevel, security Security, systemTitle []byte, unicastKey []byte, unicastKeyIC uint32, authenticationKey []byte) (Ciphering, error) {
 	if len(systemTitle) != 8 {
 		return Ciphering{}, fmt.Errorf("system title must be 8 bytes long")
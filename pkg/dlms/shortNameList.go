@@ -0,0 +1,129 @@
+package dlms
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+)
+
+const (
+	// AssociationSNClassID is the class of the Association SN interface class
+	AssociationSNClassID = 12
+	// AssociationSNCurrent is the logical name used to reference the current association
+	AssociationSNCurrent = "0.0.40.0.0.255"
+	// AssociationSNBaseName is the short name of the current association
+	AssociationSNBaseName = 0xFA00
+	// AssociationSNObjectList is the attribute of the Association SN holding the object list
+	AssociationSNObjectList = 2
+)
+
+// ShortNameListElement is an entry of the object_list attribute of the Association SN. The short
+// name of the object is the short name of its first attribute, the logical name.
+type ShortNameListElement struct {
+	BaseName    uint16
+	ClassID     uint16
+	Version     uint8
+	LogicalName Obis
+}
+
+// AttributeName returns the short name of an attribute. Attributes are 8 apart.
+func (e ShortNameListElement) AttributeName(attributeID int8) uint16 {
+	return e.BaseName + uint16(attributeID-1)*8
+}
+
+type ShortNameList []ShortNameListElement
+
+// Find returns the element with the given class and logical name, or nil if it's not in the list.
+func (sl ShortNameList) Find(classID uint16, logicalName Obis) *ShortNameListElement {
+	for i := range sl {
+		if sl[i].ClassID == classID && bytes.Equal(sl[i].LogicalName.Bytes(), logicalName.Bytes()) {
+			return &sl[i]
+		}
+	}
+
+	return nil
+}
+
+// AttributeName returns the short name of the attribute referenced by att, or false if its object
+// is not in the list.
+func (sl ShortNameList) AttributeName(att AttributeDescriptor) (uint16, bool) {
+	if att.AttributeID < 1 {
+		return 0, false
+	}
+
+	e := sl.Find(att.ClassID, att.InstanceID)
+	if e == nil {
+		return 0, false
+	}
+
+	return e.AttributeName(att.AttributeID), true
+}
+
+// Data returns the object list as A-XDR data, as sent by a server.
+func (sl ShortNameList) Data() *axdr.DlmsData {
+	elements := make([]*axdr.DlmsData, 0, len(sl))
+
+	for _, e := range sl {
+		elements = append(elements, axdr.CreateAxdrStructure([]*axdr.DlmsData{
+			axdr.CreateAxdrLong(int16(e.BaseName)),
+			axdr.CreateAxdrLongUnsigned(e.ClassID),
+			axdr.CreateAxdrUnsigned(e.Version),
+			axdr.CreateAxdrOctetString(e.LogicalName.String()),
+		}))
+	}
+
+	return axdr.CreateAxdrArray(elements)
+}
+
+// DecodeShortNameList decodes the object_list attribute of the Association SN.
+func DecodeShortNameList(data axdr.DlmsData) (out ShortNameList, err error) {
+	elements, err := DataAsSlice(data, axdr.TagArray, 0)
+	if err != nil {
+		return nil, fmt.Errorf("short name list: %w", err)
+	}
+
+	out = make(ShortNameList, 0, len(elements))
+	for i, element := range elements {
+		e, err := decodeShortNameListElement(*element)
+		if err != nil {
+			return nil, fmt.Errorf("short name list element %d: %w", i, err)
+		}
+
+		out = append(out, e)
+	}
+
+	return out, nil
+}
+
+func decodeShortNameListElement(data axdr.DlmsData) (out ShortNameListElement, err error) {
+	fields, err := DataAsSlice(data, axdr.TagStructure, 4)
+	if err != nil {
+		return
+	}
+
+	baseName, ok := fields[0].Value.(int16)
+	if fields[0].Tag != axdr.TagLong || !ok {
+		err = fmt.Errorf("invalid base name")
+		return
+	}
+	out.BaseName = uint16(baseName)
+
+	classID, ok := fields[1].Value.(uint16)
+	if fields[1].Tag != axdr.TagLongUnsigned || !ok {
+		err = fmt.Errorf("invalid class id")
+		return
+	}
+	out.ClassID = classID
+
+	version, ok := fields[2].Value.(uint8)
+	if fields[2].Tag != axdr.TagUnsigned || !ok {
+		err = fmt.Errorf("invalid version")
+		return
+	}
+	out.Version = version
+
+	out.LogicalName, err = DecodeLogicalName(*fields[3])
+
+	return
+}
@@ -0,0 +1,53 @@
+package dlms
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+)
+
+func TestShortNameList(t *testing.T) {
+	sl := ShortNameList{
+		{BaseName: 0xFA00, ClassID: AssociationSNClassID, Version: 2, LogicalName: *CreateObis(AssociationSNCurrent)},
+		{BaseName: 0x0110, ClassID: 3, Version: 0, LogicalName: *CreateObis("1.0.1.8.0.255")},
+	}
+
+	src, err := sl.Data().Encode()
+	if err != nil {
+		t.Fatalf("Encode failed. err: %v", err)
+	}
+
+	decoder := axdr.NewDataDecoder(&src)
+	data, err := decoder.Decode(&src)
+	if err != nil {
+		t.Fatalf("Decode failed. err: %v", err)
+	}
+
+	out, err := DecodeShortNameList(data)
+	if err != nil {
+		t.Fatalf("DecodeShortNameList failed. err: %v", err)
+	}
+
+	if !reflect.DeepEqual(sl, out) {
+		t.Errorf("Decoded list differs. Get: %v, should: %v", out, sl)
+	}
+
+	name, ok := out.AttributeName(*CreateAttributeDescriptor(3, "1.0.1.8.0.255", 3))
+	if !ok || name != 0x0120 {
+		t.Errorf("Invalid attribute name. Get: %X (%v)", name, ok)
+	}
+
+	name, ok = out.AttributeName(*CreateAttributeDescriptor(AssociationSNClassID, AssociationSNCurrent, AssociationSNObjectList))
+	if !ok || name != 0xFA08 {
+		t.Errorf("Invalid attribute name. Get: %X (%v)", name, ok)
+	}
+
+	if _, ok = out.AttributeName(*CreateAttributeDescriptor(4, "1.0.1.8.0.255", 2)); ok {
+		t.Errorf("Attribute of an object not in the list should not be found")
+	}
+
+	if _, err = DecodeShortNameList(*axdr.CreateAxdrArray([]*axdr.DlmsData{axdr.CreateAxdrUnsigned(1)})); err == nil {
+		t.Errorf("Should fail on invalid element")
+	}
+}
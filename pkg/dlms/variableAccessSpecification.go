@@ -0,0 +1,261 @@
+package dlms
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+)
+
+type variableAccessTag uint8
+
+const (
+	TagVariableName         variableAccessTag = 2
+	TagParameterizedAccess  variableAccessTag = 4
+	TagBlockNumberAccess    variableAccessTag = 5
+	TagReadDataBlockAccess  variableAccessTag = 6
+	TagWriteDataBlockAccess variableAccessTag = 7
+)
+
+// Value will return primitive value of the target.
+// This is used for comparing with non custom typed object
+func (s variableAccessTag) Value() uint8 {
+	return uint8(s)
+}
+
+// VariableAccessSpecification references a variable in the short name services. Only the fields
+// used by Tag are meaningful: VariableName, Selector and Parameter for the variable name and the
+// parameterized access, LastBlock, BlockNumber and RawData for the block transfer.
+type VariableAccessSpecification struct {
+	Tag          variableAccessTag
+	VariableName uint16
+	Selector     uint8
+	Parameter    axdr.DlmsData
+	LastBlock    bool
+	BlockNumber  uint16
+	RawData      []byte
+}
+
+func CreateVariableName(name uint16) *VariableAccessSpecification {
+	return &VariableAccessSpecification{Tag: TagVariableName, VariableName: name}
+}
+
+func CreateParameterizedAccess(name uint16, selector uint8, parameter axdr.DlmsData) *VariableAccessSpecification {
+	return &VariableAccessSpecification{Tag: TagParameterizedAccess, VariableName: name, Selector: selector, Parameter: parameter}
+}
+
+func CreateBlockNumberAccess(blockNum uint16) *VariableAccessSpecification {
+	return &VariableAccessSpecification{Tag: TagBlockNumberAccess, BlockNumber: blockNum}
+}
+
+func CreateReadDataBlockAccess(lastBlock bool, blockNum uint16, raw []byte) *VariableAccessSpecification {
+	return &VariableAccessSpecification{Tag: TagReadDataBlockAccess, LastBlock: lastBlock, BlockNumber: blockNum, RawData: raw}
+}
+
+func CreateWriteDataBlockAccess(lastBlock bool, blockNum uint16) *VariableAccessSpecification {
+	return &VariableAccessSpecification{Tag: TagWriteDataBlockAccess, LastBlock: lastBlock, BlockNumber: blockNum}
+}
+
+func (v VariableAccessSpecification) Encode() (out []byte, err error) {
+	var buf bytes.Buffer
+	buf.WriteByte(v.Tag.Value())
+
+	switch v.Tag {
+	case TagVariableName:
+		buf.Write(encodeUnsigned16(v.VariableName))
+	case TagParameterizedAccess:
+		buf.Write(encodeUnsigned16(v.VariableName))
+		buf.WriteByte(v.Selector)
+		val, e := v.Parameter.Encode()
+		if e != nil {
+			err = e
+			return
+		}
+		buf.Write(val)
+	case TagBlockNumberAccess:
+		buf.Write(encodeUnsigned16(v.BlockNumber))
+	case TagReadDataBlockAccess:
+		buf.WriteByte(encodeBool(v.LastBlock))
+		buf.Write(encodeUnsigned16(v.BlockNumber))
+		raw, e := encodeRawData(v.RawData)
+		if e != nil {
+			err = e
+			return
+		}
+		buf.Write(raw)
+	case TagWriteDataBlockAccess:
+		buf.WriteByte(encodeBool(v.LastBlock))
+		buf.Write(encodeUnsigned16(v.BlockNumber))
+	default:
+		err = fmt.Errorf("variable access tag not recognized (%v)", v.Tag)
+		return
+	}
+
+	out = buf.Bytes()
+	return
+}
+
+func DecodeVariableAccessSpecification(ori *[]byte) (out VariableAccessSpecification, err error) {
+	src := *ori
+
+	if len(src) < 1 {
+		err = ErrWrongLength(len(src), 1)
+		return
+	}
+
+	out.Tag = variableAccessTag(src[0])
+	src = src[1:]
+
+	switch out.Tag {
+	case TagVariableName:
+		out.VariableName, err = decodeUnsigned16(&src)
+	case TagParameterizedAccess:
+		if out.VariableName, err = decodeUnsigned16(&src); err != nil {
+			return
+		}
+		if len(src) < 1 {
+			err = ErrWrongLength(len(src), 1)
+			return
+		}
+		out.Selector = src[0]
+		src = src[1:]
+		decoder := axdr.NewDataDecoder(&src)
+		out.Parameter, err = decoder.Decode(&src)
+	case TagBlockNumberAccess:
+		out.BlockNumber, err = decodeUnsigned16(&src)
+	case TagReadDataBlockAccess:
+		if out.LastBlock, out.BlockNumber, err = decodeBlockHeader(&src); err != nil {
+			return
+		}
+		out.RawData, err = decodeRawData(&src)
+	case TagWriteDataBlockAccess:
+		out.LastBlock, out.BlockNumber, err = decodeBlockHeader(&src)
+	default:
+		err = fmt.Errorf("variable access tag not recognized (%v)", out.Tag)
+	}
+
+	if err != nil {
+		return
+	}
+
+	(*ori) = (*ori)[len((*ori))-len(src):]
+	return
+}
+
+func encodeVariableAccessList(list []VariableAccessSpecification) (out []byte, err error) {
+	var buf bytes.Buffer
+
+	length, err := axdr.EncodeLength(len(list))
+	if err != nil {
+		return
+	}
+	buf.Write(length)
+
+	for _, v := range list {
+		val, e := v.Encode()
+		if e != nil {
+			err = e
+			return
+		}
+		buf.Write(val)
+	}
+
+	out = buf.Bytes()
+	return
+}
+
+func decodeVariableAccessList(src *[]byte) (out []VariableAccessSpecification, err error) {
+	count, err := decodeQuantity(src)
+	if err != nil {
+		return
+	}
+
+	out = make([]VariableAccessSpecification, 0, count)
+	for i := 0; i < count; i++ {
+		v, e := DecodeVariableAccessSpecification(src)
+		if e != nil {
+			err = e
+			return
+		}
+		out = append(out, v)
+	}
+
+	return
+}
+
+func encodeUnsigned16(value uint16) []byte {
+	out := make([]byte, 2)
+	binary.BigEndian.PutUint16(out, value)
+	return out
+}
+
+func decodeUnsigned16(src *[]byte) (uint16, error) {
+	if len(*src) < 2 {
+		return 0, ErrWrongLength(len(*src), 2)
+	}
+
+	value := binary.BigEndian.Uint16(*src)
+	*src = (*src)[2:]
+	return value, nil
+}
+
+func encodeBool(value bool) byte {
+	if value {
+		return 0xFF
+	}
+	return 0x00
+}
+
+// decodeBlockHeader decodes the last-block and block-number fields of the block transfer.
+func decodeBlockHeader(src *[]byte) (lastBlock bool, blockNum uint16, err error) {
+	if len(*src) < 1 {
+		err = ErrWrongLength(len(*src), 1)
+		return
+	}
+
+	lastBlock = (*src)[0] != 0
+	*src = (*src)[1:]
+
+	blockNum, err = decodeUnsigned16(src)
+	return
+}
+
+func encodeRawData(raw []byte) ([]byte, error) {
+	length, err := axdr.EncodeLength(len(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(length, raw...), nil
+}
+
+func decodeRawData(src *[]byte) ([]byte, error) {
+	length, err := decodeQuantity(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(*src) < length {
+		return nil, ErrWrongLength(len(*src), length)
+	}
+
+	raw := make([]byte, length)
+	copy(raw, *src)
+	*src = (*src)[length:]
+	return raw, nil
+}
+
+// decodeQuantity decodes the A-XDR length that precedes a SEQUENCE OF or an OCTET STRING.
+func decodeQuantity(src *[]byte) (int, error) {
+	if len(*src) < 1 {
+		return 0, ErrWrongLength(len(*src), 1)
+	}
+
+	_, length, err := axdr.DecodeLength(src)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(length), nil
+}
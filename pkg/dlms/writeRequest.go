@@ -0,0 +1,134 @@
+package dlms
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+)
+
+// WriteRequest implement CosemPDU. It's the short name equivalent of the SetRequest: there is a
+// value for each variable. When the request is sent in blocks, the only variable is a write data
+// block access and the only value is an octet string with the block. The blocks, once joined, hold
+// the encoded WriteRequest without its tag.
+type WriteRequest struct {
+	Variables []VariableAccessSpecification
+	Values    []axdr.DlmsData
+}
+
+func CreateWriteRequest(variables []VariableAccessSpecification, values []axdr.DlmsData) *WriteRequest {
+	if len(variables) < 1 || len(variables) != len(values) {
+		panic("Variables and Values must have the same number of members, and at least one")
+	}
+	return &WriteRequest{
+		Variables: variables,
+		Values:    values,
+	}
+}
+
+func (wr WriteRequest) Encode() (out []byte, err error) {
+	return encodeWriteRequest(TagWriteRequest, wr.Variables, wr.Values)
+}
+
+func DecodeWriteRequest(ori *[]byte) (out WriteRequest, err error) {
+	out.Variables, out.Values, err = decodeWriteRequest(TagWriteRequest, ori)
+	return
+}
+
+// UnconfirmedWriteRequest implement CosemPDU. It's a WriteRequest the server doesn't respond to.
+type UnconfirmedWriteRequest struct {
+	Variables []VariableAccessSpecification
+	Values    []axdr.DlmsData
+}
+
+func CreateUnconfirmedWriteRequest(variables []VariableAccessSpecification, values []axdr.DlmsData) *UnconfirmedWriteRequest {
+	if len(variables) < 1 || len(variables) != len(values) {
+		panic("Variables and Values must have the same number of members, and at least one")
+	}
+	return &UnconfirmedWriteRequest{
+		Variables: variables,
+		Values:    values,
+	}
+}
+
+func (wr UnconfirmedWriteRequest) Encode() (out []byte, err error) {
+	return encodeWriteRequest(TagUnconfirmedWriteRequest, wr.Variables, wr.Values)
+}
+
+func DecodeUnconfirmedWriteRequest(ori *[]byte) (out UnconfirmedWriteRequest, err error) {
+	out.Variables, out.Values, err = decodeWriteRequest(TagUnconfirmedWriteRequest, ori)
+	return
+}
+
+func encodeWriteRequest(tag CosemTag, variables []VariableAccessSpecification, values []axdr.DlmsData) (out []byte, err error) {
+	var buf bytes.Buffer
+	buf.WriteByte(tag.Value())
+
+	list, err := encodeVariableAccessList(variables)
+	if err != nil {
+		return
+	}
+	buf.Write(list)
+
+	length, err := axdr.EncodeLength(len(values))
+	if err != nil {
+		return
+	}
+	buf.Write(length)
+
+	for _, v := range values {
+		val, e := v.Encode()
+		if e != nil {
+			err = e
+			return
+		}
+		buf.Write(val)
+	}
+
+	out = buf.Bytes()
+	return
+}
+
+func decodeWriteRequest(tag CosemTag, ori *[]byte) (variables []VariableAccessSpecification, values []axdr.DlmsData, err error) {
+	src := *ori
+
+	if len(src) < 3 {
+		err = ErrWrongLength(len(src), 3)
+		return
+	}
+
+	if src[0] != tag.Value() {
+		err = ErrWrongTag(0, src[0], byte(tag))
+		return
+	}
+	src = src[1:]
+
+	variables, err = decodeVariableAccessList(&src)
+	if err != nil {
+		return
+	}
+
+	count, err := decodeQuantity(&src)
+	if err != nil {
+		return
+	}
+
+	if count != len(variables) {
+		err = fmt.Errorf("%d values for %d variables", count, len(variables))
+		return
+	}
+
+	values = make([]axdr.DlmsData, 0, count)
+	for i := 0; i < count; i++ {
+		decoder := axdr.NewDataDecoder(&src)
+		v, e := decoder.Decode(&src)
+		if e != nil {
+			err = e
+			return
+		}
+		values = append(values, v)
+	}
+
+	(*ori) = (*ori)[len((*ori))-len(src):]
+	return
+}
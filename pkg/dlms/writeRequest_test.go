@@ -0,0 +1,47 @@
+package dlms
+
+import (
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_WriteRequest(t *testing.T) {
+	wr := *CreateWriteRequest([]VariableAccessSpecification{*CreateVariableName(0x0110)}, []axdr.DlmsData{*axdr.CreateAxdrUnsigned(5)})
+	out, err := wr.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, "0601020110011105", encodeHexString(out))
+
+	wr = *CreateWriteRequest([]VariableAccessSpecification{*CreateWriteDataBlockAccess(false, 1)}, []axdr.DlmsData{*axdr.CreateAxdrOctetString("0102")})
+	out, err = wr.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, "0601070000010109020102", encodeHexString(out))
+
+	uw := *CreateUnconfirmedWriteRequest([]VariableAccessSpecification{*CreateVariableName(0x0110)}, []axdr.DlmsData{*axdr.CreateAxdrUnsigned(5)})
+	out, err = uw.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, "1601020110011105", encodeHexString(out))
+}
+
+func TestDecode_WriteRequest(t *testing.T) {
+	src := decodeHexString("0601020110011105")
+	wr, err := DecodeWriteRequest(&src)
+	assert.NoError(t, err)
+	assert.Empty(t, src)
+	assert.Equal(t, uint16(0x0110), wr.Variables[0].VariableName)
+	assert.Equal(t, uint8(5), wr.Values[0].Value)
+
+	src = decodeHexString("1601020110011105")
+	uw, err := DecodeUnconfirmedWriteRequest(&src)
+	assert.NoError(t, err)
+	assert.Equal(t, wr.Values, uw.Values)
+
+	src = decodeHexString("06010201100211051106")
+	_, err = DecodeWriteRequest(&src)
+	assert.Error(t, err)
+
+	src = decodeHexString("1601020110011105")
+	_, err = DecodeWriteRequest(&src)
+	assert.Error(t, err)
+}
@@ -0,0 +1,169 @@
+package dlms
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+)
+
+type writeResultTag uint8
+
+const (
+	TagWriteResultSuccess         writeResultTag = 0
+	TagWriteResultDataAccessError writeResultTag = 1
+	TagWriteResultBlockNumber     writeResultTag = 2
+)
+
+// Value will return primitive value of the target.
+// This is used for comparing with non custom typed object
+func (s writeResultTag) Value() uint8 {
+	return uint8(s)
+}
+
+// WriteResult is the result of a variable of a WriteRequest. A block number acknowledges a block
+// that isn't the last one.
+type WriteResult struct {
+	Tag         writeResultTag
+	Access      AccessResultTag
+	BlockNumber uint16
+}
+
+func CreateWriteResultAsSuccess() *WriteResult {
+	return &WriteResult{Tag: TagWriteResultSuccess, Access: TagAccSuccess}
+}
+
+func CreateWriteResultAsAccess(access AccessResultTag) *WriteResult {
+	if access == TagAccSuccess {
+		return CreateWriteResultAsSuccess()
+	}
+	return &WriteResult{Tag: TagWriteResultDataAccessError, Access: access}
+}
+
+func CreateWriteResultAsBlockNumber(blockNum uint16) *WriteResult {
+	return &WriteResult{Tag: TagWriteResultBlockNumber, BlockNumber: blockNum}
+}
+
+func (wr WriteResult) Encode() (out []byte, err error) {
+	var buf bytes.Buffer
+	buf.WriteByte(wr.Tag.Value())
+
+	switch wr.Tag {
+	case TagWriteResultSuccess:
+	case TagWriteResultDataAccessError:
+		buf.WriteByte(wr.Access.Value())
+	case TagWriteResultBlockNumber:
+		buf.Write(encodeUnsigned16(wr.BlockNumber))
+	default:
+		err = fmt.Errorf("write result tag not recognized (%v)", wr.Tag)
+		return
+	}
+
+	out = buf.Bytes()
+	return
+}
+
+func DecodeWriteResult(ori *[]byte) (out WriteResult, err error) {
+	src := *ori
+
+	if len(src) < 1 {
+		err = ErrWrongLength(len(src), 1)
+		return
+	}
+
+	out.Tag = writeResultTag(src[0])
+	src = src[1:]
+
+	switch out.Tag {
+	case TagWriteResultSuccess:
+		out.Access = TagAccSuccess
+	case TagWriteResultDataAccessError:
+		if len(src) < 1 {
+			err = ErrWrongLength(len(src), 1)
+			return
+		}
+		out.Access, err = GetAccessTag(src[0])
+		src = src[1:]
+	case TagWriteResultBlockNumber:
+		out.BlockNumber, err = decodeUnsigned16(&src)
+	default:
+		err = fmt.Errorf("write result tag not recognized (%v)", out.Tag)
+	}
+
+	if err != nil {
+		return
+	}
+
+	(*ori) = (*ori)[len((*ori))-len(src):]
+	return
+}
+
+// WriteResponse implement CosemPDU. There is a result for each variable of the request.
+type WriteResponse struct {
+	Results []WriteResult
+}
+
+func CreateWriteResponse(results []WriteResult) *WriteResponse {
+	if len(results) < 1 {
+		panic("Results cannot have zero members")
+	}
+	return &WriteResponse{
+		Results: results,
+	}
+}
+
+func (wr WriteResponse) Encode() (out []byte, err error) {
+	var buf bytes.Buffer
+	buf.WriteByte(TagWriteResponse.Value())
+
+	length, err := axdr.EncodeLength(len(wr.Results))
+	if err != nil {
+		return
+	}
+	buf.Write(length)
+
+	for _, r := range wr.Results {
+		val, e := r.Encode()
+		if e != nil {
+			err = e
+			return
+		}
+		buf.Write(val)
+	}
+
+	out = buf.Bytes()
+	return
+}
+
+func DecodeWriteResponse(ori *[]byte) (out WriteResponse, err error) {
+	src := *ori
+
+	if len(src) < 2 {
+		err = ErrWrongLength(len(src), 2)
+		return
+	}
+
+	if src[0] != TagWriteResponse.Value() {
+		err = ErrWrongTag(0, src[0], byte(TagWriteResponse))
+		return
+	}
+	src = src[1:]
+
+	count, err := decodeQuantity(&src)
+	if err != nil {
+		return
+	}
+
+	out.Results = make([]WriteResult, 0, count)
+	for i := 0; i < count; i++ {
+		r, e := DecodeWriteResult(&src)
+		if e != nil {
+			err = e
+			return
+		}
+		out.Results = append(out.Results, r)
+	}
+
+	(*ori) = (*ori)[len((*ori))-len(src):]
+	return
+}
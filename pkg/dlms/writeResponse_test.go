@@ -0,0 +1,37 @@
+package dlms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_WriteResponse(t *testing.T) {
+	wr := *CreateWriteResponse([]WriteResult{
+		*CreateWriteResultAsSuccess(),
+		*CreateWriteResultAsAccess(TagAccReadWriteDenied),
+		*CreateWriteResultAsBlockNumber(3),
+	})
+	out, err := wr.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, "0D03000103020003", encodeHexString(out))
+}
+
+func TestDecode_WriteResponse(t *testing.T) {
+	src := decodeHexString("0d03000103020003")
+	wr, err := DecodeWriteResponse(&src)
+	assert.NoError(t, err)
+	assert.Empty(t, src)
+	assert.Len(t, wr.Results, 3)
+
+	assert.Equal(t, TagWriteResultSuccess, wr.Results[0].Tag)
+	assert.Equal(t, TagAccSuccess, wr.Results[0].Access)
+	assert.Equal(t, TagWriteResultDataAccessError, wr.Results[1].Tag)
+	assert.Equal(t, TagAccReadWriteDenied, wr.Results[1].Access)
+	assert.Equal(t, TagWriteResultBlockNumber, wr.Results[2].Tag)
+	assert.Equal(t, uint16(3), wr.Results[2].BlockNumber)
+
+	src = decodeHexString("0d0101")
+	_, err = DecodeWriteResponse(&src)
+	assert.Error(t, err)
+}
@@ -0,0 +1,234 @@
+package dlmsclient
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+// AccessRequest sends the operations of acc in a single ACCESS request and returns a result for
+// each of them. The error is only set when the request as a whole fails; operations rejected by
+// the server or by the access validation have their error in their result, and the latter aren't
+// sent. The ACCESS service, and multiple references for several operations, must have been
+// negotiated in the association.
+func (c *client) AccessRequest(acc *dlms.Access) (results []dlms.AccessResult, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if acc == nil || len(acc.Operations) == 0 {
+		return nil, dlms.NewError(dlms.ErrorInvalidParameter, "access must have at least one operation")
+	}
+
+	if c.settings.Referencing == dlms.ReferencingShortName {
+		return nil, dlms.NewError(dlms.ErrorInvalidState, "access requires logical name referencing")
+	}
+
+	err = c.checkAccepted(dlms.ConformanceBlockAccess, "access service")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(acc.Operations) > 1 {
+		err = c.checkAccepted(dlms.ConformanceBlockMultipleReferences, "multiple references")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The ACCESS service is ciphered with general ciphering only
+	if c.settings.Ciphering.Level != dlms.SecurityLevelNone {
+		return nil, dlms.NewError(dlms.ErrorInvalidParameter, "access service can't be ciphered with service-specific ciphering")
+	}
+
+	results = make([]dlms.AccessResult, len(acc.Operations))
+	specifications := make([]dlms.AccessRequestSpecification, 0, len(acc.Operations))
+	values := make([]axdr.DlmsData, 0, len(acc.Operations))
+	sent := make([]int, 0, len(acc.Operations))
+
+	for i, op := range acc.Operations {
+		results[i].Operation = op.Specification
+
+		dt, rejected, e := c.accessData(op)
+		if e != nil {
+			return nil, e
+		}
+
+		if rejected != nil {
+			results[i].Err = rejected
+			continue
+		}
+
+		specifications = append(specifications, op.Specification)
+		values = append(values, *dt)
+		sent = append(sent, i)
+	}
+
+	if len(sent) == 0 {
+		return
+	}
+
+	invokeID := dlms.AccessConfirmed | uint32(c.nextInvokeID())
+	if c.highPriority {
+		invokeID |= dlms.AccessHighPriority
+	}
+	if acc.BreakOnError {
+		invokeID |= dlms.AccessBreakOnError
+	}
+
+	req := dlms.CreateAccessRequest(invokeID, nil, specifications, values)
+
+	out, err := req.Encode()
+	if err != nil {
+		return nil, dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("error encoding access request: %v", err))
+	}
+
+	if len(out) > c.maxPlainPduSize() {
+		return nil, dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("access request is too long (%d bytes)", len(out)))
+	}
+
+	pdu, err := c.encodeSendReceiveAndDecode(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, ok := pdu.(dlms.AccessResponse)
+	if !ok {
+		return nil, dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("unexpected PDU response type: %T", pdu))
+	}
+
+	// When breaking on error, the server may not respond to the operations after the failed one
+	if len(resp.Results) > len(sent) || (len(resp.Results) < len(sent) && !acc.BreakOnError) {
+		return nil, dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("expected %d access results, got %d", len(sent), len(resp.Results)))
+	}
+
+	for j, i := range sent {
+		if j >= len(resp.Results) {
+			results[i].Err = accessRejected(acc.Operations[i].Specification, "not executed")
+			continue
+		}
+
+		data := resp.Data[j]
+		results[i].Result = resp.Results[j]
+		results[i].Data = &data
+
+		results[i].Err, err = accessResultError(acc.Operations[i], resp.Results[j], data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return
+}
+
+// accessData validates an operation and returns the data to send with it. If the access
+// validation rejects it, the rejection is returned instead.
+func (c *client) accessData(op dlms.AccessOperation) (dt *axdr.DlmsData, rejected error, err error) {
+	spec := op.Specification
+
+	switch spec.Tag {
+	case dlms.TagAccessRequestGet, dlms.TagAccessRequestGetWithSelection:
+		if spec.Attribute == nil {
+			return nil, nil, dlms.NewError(dlms.ErrorInvalidParameter, "attribute descriptor must be non-nil")
+		}
+		err = c.validateGet(spec.Attribute, spec.AccessDescriptor)
+	case dlms.TagAccessRequestSet, dlms.TagAccessRequestSetWithSelection:
+		if spec.Attribute == nil {
+			return nil, nil, dlms.NewError(dlms.ErrorInvalidParameter, "attribute descriptor must be non-nil")
+		}
+		err = c.validateSet(spec.Attribute)
+	case dlms.TagAccessRequestAction:
+		if spec.Method == nil {
+			return nil, nil, dlms.NewError(dlms.ErrorInvalidParameter, "method descriptor must be non-nil")
+		}
+		err = c.validateAction(spec.Method)
+	default:
+		return nil, nil, dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("access request tag not recognized (%d)", spec.Tag))
+	}
+
+	if err != nil {
+		if isRejected(err) {
+			return nil, err, nil
+		}
+		return nil, nil, err
+	}
+
+	if spec.Tag == dlms.TagAccessRequestGet || spec.Tag == dlms.TagAccessRequestGetWithSelection || op.Data == nil {
+		return &axdr.DlmsData{Tag: axdr.TagNull}, nil, nil
+	}
+
+	dt, ok := op.Data.(*axdr.DlmsData)
+	if !ok {
+		dt, err = axdr.MarshalData(op.Data)
+		if err != nil {
+			return nil, nil, dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("error marshaling %s data: %v", spec.String(), err))
+		}
+	}
+
+	return dt, nil, nil
+}
+
+// accessResultError returns the error of an operation given its result, unmarshaling the data
+// received if it succeeded. A result that doesn't match the operation fails the whole request.
+func accessResultError(op dlms.AccessOperation, result dlms.AccessResponseSpecification, data axdr.DlmsData) (opErr error, err error) {
+	spec := op.Specification
+
+	expected := dlms.TagAccessResponseAction
+	switch spec.Tag {
+	case dlms.TagAccessRequestGet, dlms.TagAccessRequestGetWithSelection:
+		expected = dlms.TagAccessResponseGet
+	case dlms.TagAccessRequestSet, dlms.TagAccessRequestSetWithSelection:
+		expected = dlms.TagAccessResponseSet
+	}
+
+	if result.Tag != expected {
+		return nil, dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("in %s expected access result %d, got %d", spec.String(), expected, result.Tag))
+	}
+
+	if !result.Success() {
+		return accessRejected(spec, result.String()), nil
+	}
+
+	if op.Response == nil {
+		return nil, nil
+	}
+
+	if data.Tag == axdr.TagNull && expected == dlms.TagAccessResponseAction {
+		return dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("action %s returned no data", spec.String())), nil
+	}
+
+	e := axdr.UnmarshalData(data, op.Response)
+	if e != nil {
+		return dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("error unmarshaling %s data: %v", spec.String(), e)), nil
+	}
+
+	return nil, nil
+}
+
+// accessRejected returns the error of a rejected operation, with the code of the equivalent
+// GET, SET or ACTION service.
+func accessRejected(spec dlms.AccessRequestSpecification, reason string) error {
+	switch spec.Tag {
+	case dlms.TagAccessRequestGet, dlms.TagAccessRequestGetWithSelection:
+		return dlms.NewError(dlms.ErrorGetRejected, fmt.Sprintf("get %s rejected: %s", spec.String(), reason))
+	case dlms.TagAccessRequestSet, dlms.TagAccessRequestSetWithSelection:
+		return dlms.NewError(dlms.ErrorSetRejected, fmt.Sprintf("set %s rejected: %s", spec.String(), reason))
+	default:
+		return dlms.NewError(dlms.ErrorActionRejected, fmt.Sprintf("action %s rejected: %s", spec.String(), reason))
+	}
+}
+
+func isRejected(err error) bool {
+	var clientError *dlms.Error
+	if !errors.As(err, &clientError) {
+		return false
+	}
+
+	switch clientError.Code() {
+	case dlms.ErrorGetRejected, dlms.ErrorSetRejected, dlms.ErrorActionRejected:
+		return true
+	default:
+		return false
+	}
+}
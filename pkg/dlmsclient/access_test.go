@@ -0,0 +1,160 @@
+package dlmsclient_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlms/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	accessSpecifications = "03" +
+		"0100030100010800FF02" +
+		"0200010000600100FF02" +
+		"030046000060030AFF01" +
+		"03" + "00" + "1105" + "0F00"
+)
+
+func newAccess() *dlms.Access {
+	return dlms.NewAccess().
+		Get(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), new(uint32)).
+		Set(dlms.CreateAttributeDescriptor(1, "0.0.96.1.0.255", 2), uint8(5)).
+		Action(dlms.CreateMethodDescriptor(70, "0.0.96.3.10.255", 1), int8(0))
+}
+
+func TestClient_AccessRequest(t *testing.T) {
+	c, tm, rdc := associateWithAccess(t)
+
+	var value uint32
+	acc := dlms.NewAccess().
+		Get(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), &value).
+		Set(dlms.CreateAttributeDescriptor(1, "0.0.96.1.0.255", 2), uint8(5)).
+		Action(dlms.CreateMethodDescriptor(70, "0.0.96.3.10.255", 1), int8(0))
+
+	sendReceive(tm, rdc, "D9C000000100"+accessSpecifications, "DAC00000010000030600003039000003010002030300")
+	results, err := c.AccessRequest(acc)
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.Equal(t, uint32(12345), value)
+
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, dlms.TagAccessResponseGet, results[0].Result.Tag)
+
+	var clientError *dlms.Error
+	assert.ErrorAs(t, results[1].Err, &clientError)
+	assert.Equal(t, dlms.ErrorSetRejected, clientError.Code())
+	assert.Equal(t, dlms.TagAccReadWriteDenied, results[1].Result.Access)
+
+	assert.NoError(t, results[2].Err)
+	assert.Equal(t, dlms.TagActSuccess, results[2].Result.Action)
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_AccessRequestBreakOnError(t *testing.T) {
+	c, tm, rdc := associateWithAccess(t)
+
+	// The server stops at the first operation
+	sendReceive(tm, rdc, "D9E000000100"+accessSpecifications, "DAE000000100000100010104")
+	results, err := c.AccessRequest(newAccess().WithBreakOnError())
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+
+	var clientError *dlms.Error
+	assert.ErrorAs(t, results[0].Err, &clientError)
+	assert.Equal(t, dlms.ErrorGetRejected, clientError.Code())
+	assert.ErrorAs(t, results[1].Err, &clientError)
+	assert.Equal(t, dlms.ErrorSetRejected, clientError.Code())
+	assert.ErrorAs(t, results[2].Err, &clientError)
+	assert.Equal(t, dlms.ErrorActionRejected, clientError.Code())
+
+	// Missing results are only allowed when breaking on error
+	sendReceive(tm, rdc, "D9C000000200"+accessSpecifications, "DAC000000200000100010104")
+	_, err = c.AccessRequest(newAccess())
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_AccessRequestFail(t *testing.T) {
+	c, tm, rdc := associateWithAccess(t)
+
+	var clientError *dlms.Error
+
+	_, err := c.AccessRequest(dlms.NewAccess())
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidParameter, clientError.Code())
+
+	_, err = c.AccessRequest(dlms.NewAccess().Get(nil, nil))
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidParameter, clientError.Code())
+
+	// Invoke ID mismatch
+	sendReceive(tm, rdc, "D9C000000100"+accessSpecifications, "DAC00000020000030600003039000003010002000300")
+	_, err = c.AccessRequest(newAccess())
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
+
+	// Result not matching the operation
+	sendReceive(tm, rdc, "D9C000000200"+accessSpecifications, "DAC00000020000030600003039000003010001000300")
+	_, err = c.AccessRequest(newAccess())
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
+
+	// Unexpected response
+	sendReceive(tm, rdc, "D9C000000300"+accessSpecifications, "C401C30010003C")
+	_, err = c.AccessRequest(newAccess())
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_AccessRequestNotNegotiated(t *testing.T) {
+	c, tm, _ := associate(t)
+
+	_, err := c.AccessRequest(newAccess())
+	var clientError *dlms.Error
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidState, clientError.Code())
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_AccessRequestWithoutMultipleReferences(t *testing.T) {
+	settings, _ := dlms.NewSettingsWithoutAuthentication()
+	settings.ConformanceBlock |= dlms.ConformanceBlockAccess
+	c, tm, rdc := associateWith(t, settings, 5*time.Second,
+		"601DA109060760857405080101BE10040E01000000065F1F040000185F0100",
+		"6129A109060760857405080101A203020100A305A103020100BE10040E0800065F1F040000105D00800007")
+
+	// Several operations need multiple references
+	_, err := c.AccessRequest(newAccess())
+	var clientError *dlms.Error
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidState, clientError.Code())
+
+	// A single operation is sent
+	var value uint32
+	sendReceive(tm, rdc, "D9C000000100010100030100010800FF020100", "DAC00000010000010600003039010100")
+	results, err := c.AccessRequest(dlms.NewAccess().Get(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), &value))
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, uint32(12345), value)
+
+	tm.AssertExpectations(t)
+}
+
+func associateWithAccess(t *testing.T) (dlms.Client, *mocks.TransportMock, dlms.DataChannel) {
+	t.Helper()
+
+	settings, _ := dlms.NewSettingsWithoutAuthentication()
+	settings.ConformanceBlock |= dlms.ConformanceBlockAccess | dlms.ConformanceBlockMultipleReferences
+	return associateWith(t, settings, 5*time.Second,
+		"601DA109060760857405080101BE10040E01000000065F1F0400001A5F0100",
+		"6129A109060760857405080101A203020100A305A103020100BE10040E0800065F1F040000125D00800007")
+}
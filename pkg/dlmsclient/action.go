@@ -11,33 +11,157 @@ func (c *client) ActionRequest(mth *dlms.MethodDescriptor, data interface{}) (er
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
+	_, err = c.actionRequest(mth, data)
+	return
+}
+
+// UnconfirmedActionRequest invokes a method without waiting for the result, with the unconfirmed
+// service class. The request must fit in a single PDU.
+func (c *client) UnconfirmedActionRequest(mth *dlms.MethodDescriptor, data interface{}) (err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	req, err := c.actionRequestNormal(mth, data, false)
+	if err != nil {
+		return
+	}
+
+	out, err := req.Encode()
+	if err != nil {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("error encoding %s data: %v", mth.String(), err))
+	}
+
+	if len(out) > c.maxPlainPduSize() {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("%s data is too long for an unconfirmed action (%d bytes)", mth.String(), len(out)))
+	}
+
+	return c.encodeAndSend(req)
+}
+
+// ActionRequestWithResponse invokes a method and unmarshals its return parameters into response.
+func (c *client) ActionRequestWithResponse(mth *dlms.MethodDescriptor, data interface{}, response interface{}) (err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ret, err := c.actionRequest(mth, data)
+	if err != nil {
+		return
+	}
+
+	if ret == nil {
+		return dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("action %s returned no data", mth.String()))
+	}
+
+	err = axdr.UnmarshalData(*ret, response)
+	if err != nil {
+		return dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("error unmarshaling %s return parameters: %v", mth.String(), err))
+	}
+
+	return
+}
+
+func (c *client) actionRequest(mth *dlms.MethodDescriptor, data interface{}) (ret *axdr.DlmsData, err error) {
+	req, err := c.actionRequestNormal(mth, data, true)
+	if err != nil {
+		return
+	}
+
+	pdu, err := c.encodeSendReceiveAndDecode(req)
+	if err != nil {
+		return
+	}
+
+	switch resp := pdu.(type) {
+	case dlms.ActionResponseNormal:
+		if resp.Response.Result != dlms.TagActSuccess {
+			return nil, dlms.NewActionRejectedError(fmt.Sprintf("action %s rejected: %s", mth.String(), resp.Response.Result.String()), resp.Response.Result)
+		}
+
+		if resp.Response.ReturnParam != nil {
+			value, e := resp.Response.ReturnParam.ValueAsData()
+			if e != nil {
+				access, _ := resp.Response.ReturnParam.ValueAsAccess()
+				return nil, dlms.NewError(dlms.ErrorActionRejected, fmt.Sprintf("action %s return parameters rejected: %s", mth.String(), access.String()))
+			}
+
+			ret = &value
+		}
+	case dlms.ActionResponseWithPBlock:
+		ret, err = c.actionResponseBlocks(mth, req.InvokePriority, resp)
+	default:
+		err = dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("in %s unexpected PDU response type: %T", mth.String(), pdu))
+	}
+
+	return
+}
+
+// actionRequestNormal validates an action and creates its request with the given service class.
+func (c *client) actionRequestNormal(mth *dlms.MethodDescriptor, data interface{}, confirmed bool) (*dlms.ActionRequestNormal, error) {
 	if mth == nil {
-		return dlms.NewError(dlms.ErrorInvalidParameter, "method descriptor must be non-nil")
+		return nil, dlms.NewError(dlms.ErrorInvalidParameter, "method descriptor must be non-nil")
+	}
+
+	if c.settings.Referencing == dlms.ReferencingShortName {
+		return nil, dlms.NewError(dlms.ErrorInvalidState, fmt.Sprintf("action %s requires logical name referencing", mth.String()))
+	}
+
+	err := c.checkAccepted(dlms.ConformanceBlockAction, "action service")
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.validateAction(mth)
+	if err != nil {
+		return nil, err
 	}
 
 	dt, ok := data.(*axdr.DlmsData)
 	if !ok {
 		dt, err = axdr.MarshalData(data)
 		if err != nil {
-			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("error marshaling %s data: %v", mth.String(), err))
+			return nil, dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("error marshaling %s data: %v", mth.String(), err))
 		}
 	}
 
-	req := dlms.CreateActionRequestNormal(unicastInvokeID, *mth, dt)
+	return dlms.CreateActionRequestNormal(c.nextInvokeIDAndPriority(confirmed), *mth, dt), nil
+}
 
-	pdu, err := c.encodeSendReceiveAndDecode(req)
-	if err != nil {
-		return
-	}
+// actionResponseBlocks requests the remaining blocks of the return parameters of a method.
+func (c *client) actionResponseBlocks(mth *dlms.MethodDescriptor, invokeID uint8, resp dlms.ActionResponseWithPBlock) (*axdr.DlmsData, error) {
+	blockNumber := uint32(1)
+	out := make([]byte, 0)
 
-	resp, ok := pdu.(dlms.ActionResponseNormal)
-	if !ok {
-		return dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("in %s unexpected PDU response type: %T", mth.String(), pdu))
+	for {
+		if resp.PBlock.BlockNumber != blockNumber {
+			return nil, dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("block number mismatch in %s: expected %d, got %d", mth.String(), blockNumber, resp.PBlock.BlockNumber))
+		}
+
+		out = append(out, resp.PBlock.Raw...)
+		if resp.PBlock.LastBlock {
+			break
+		}
+
+		pdu, err := c.encodeSendReceiveAndDecode(dlms.CreateActionRequestNextPBlock(invokeID, blockNumber))
+		if err != nil {
+			return nil, err
+		}
+
+		switch next := pdu.(type) {
+		case dlms.ActionResponseWithPBlock:
+			resp = next
+			blockNumber++
+		case dlms.ActionResponseNormal:
+			return nil, dlms.NewActionRejectedError(fmt.Sprintf("action %s rejected: %s", mth.String(), next.Response.Result.String()), next.Response.Result)
+		default:
+			return nil, dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("in %s expected ActionResponseWithPBlock response, got %T", mth.String(), pdu))
+		}
 	}
 
-	if resp.Response.Result != dlms.TagActSuccess {
-		return dlms.NewError(dlms.ErrorActionRejected, fmt.Sprintf("action %s rejected: %s", mth.String(), resp.Response.Result.String()))
+	decoder := axdr.NewDataDecoder(&out)
+	data, err := decoder.Decode(&out)
+	if err != nil {
+		return nil, dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("error decoding %s return parameters: %v", mth.String(), err))
 	}
 
-	return
+	return &data, nil
 }
@@ -21,6 +21,36 @@ func TestClient_ActionRequest(t *testing.T) {
 	tm.AssertExpectations(t)
 }
 
+func TestClient_ActionRequestWithResponse(t *testing.T) {
+	c, tm, rdc := associate(t)
+
+	var response int8
+	mth := dlms.CreateMethodDescriptor(70, "0-0:96.3.10.255", 1)
+
+	sendReceive(tm, rdc, "C301C10046000060030AFF01010F00", "C701C10001000F05")
+	err := c.ActionRequestWithResponse(mth, int8(0), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, int8(5), response)
+
+	// Return parameters in blocks
+	sendReceive(tm, rdc, "C301C20046000060030AFF01010F00", "C702C200000000010109")
+	sendReceive(tm, rdc, "C302C200000001", "C702C20100000002020107")
+
+	var octetString string
+	err = c.ActionRequestWithResponse(mth, int8(0), &octetString)
+	assert.NoError(t, err)
+	assert.Equal(t, "07", octetString)
+
+	// No return parameters
+	sendReceive(tm, rdc, "C301C30046000060030AFF01010F00", "C701C30000")
+	err = c.ActionRequestWithResponse(mth, int8(0), &response)
+	var clientError *dlms.Error
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
+
+	tm.AssertExpectations(t)
+}
+
 func TestClient_ActionRequestFail(t *testing.T) {
 	c, tm, rdc := associate(t)
 
@@ -33,21 +63,24 @@ func TestClient_ActionRequestFail(t *testing.T) {
 	var clientError *dlms.Error
 	assert.ErrorAs(t, err, &clientError)
 	assert.Equal(t, dlms.ErrorActionRejected, clientError.Code())
+	result, ok := clientError.ActionResult()
+	assert.True(t, ok)
+	assert.Equal(t, dlms.TagActHardwareFault, result)
 
-	// Unexpected response
-	sendReceive(tm, rdc, "C301C10046000060030AFF01010F00", "0E010203")
+	// Confirmed service error
+	sendReceive(tm, rdc, "C301C20046000060030AFF01010F00", "0E010203")
 	err = c.ActionRequest(disconnectorMethodDescriptor, data)
 	assert.ErrorAs(t, err, &clientError)
-	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
+	assert.Equal(t, dlms.ErrorServiceError, clientError.Code())
 
 	// Invalid response
-	sendReceive(tm, rdc, "C301C10046000060030AFF01010F00", "AE12")
+	sendReceive(tm, rdc, "C301C30046000060030AFF01010F00", "AE12")
 	err = c.ActionRequest(disconnectorMethodDescriptor, data)
 	assert.ErrorAs(t, err, &clientError)
 	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
 
 	// Send failed
-	tm.On("Send", decodeHexString("C301C10046000060030AFF01010F00")).Return(fmt.Errorf("error")).Once()
+	tm.On("Send", decodeHexString("C301C40046000060030AFF01010F00")).Return(fmt.Errorf("error")).Once()
 	tm.On("IsConnected").Return(false).Once()
 
 	err = c.ActionRequest(disconnectorMethodDescriptor, data)
@@ -74,3 +107,18 @@ func TestClient_ActionRequestFail(t *testing.T) {
 
 	tm.AssertExpectations(t)
 }
+
+func TestClient_UnconfirmedActionRequest(t *testing.T) {
+	c, tm, _ := associate(t)
+
+	sendReceive(tm, nil, "C301810046000060030AFF01010F00", "")
+	err := c.UnconfirmedActionRequest(dlms.CreateMethodDescriptor(70, "0-0:96.3.10.255", 1), int8(0))
+	assert.NoError(t, err)
+
+	err = c.UnconfirmedActionRequest(nil, int8(0))
+	var clientError *dlms.Error
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidParameter, clientError.Code())
+
+	tm.AssertExpectations(t)
+}
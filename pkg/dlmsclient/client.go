@@ -1,6 +1,8 @@
 package dlmsclient
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -10,7 +12,12 @@ import (
 )
 
 const (
-	unicastInvokeID = 0xC1
+	// noInvokeID matches the requests and responses without invoke ID
+	noInvokeID = ^uint32(0)
+	// maxOutstandingRequests keeps an invoke ID free while pipelining
+	maxOutstandingRequests = int(dlms.InvokeIDMask)
+	// maxListLength is the maximum number of elements of a request with a list, encoded in a byte
+	maxListLength = 255
 )
 
 type client struct {
@@ -19,11 +26,21 @@ type client struct {
 	replyTimeout       time.Duration
 	associationTimeout time.Duration
 	isAssociated       bool
+	association        *dlms.AssociationInfo
+	serverSystemTitle  []byte
+	invokeID           uint8
+	highPriority       bool
+	pipelining         int
+	expired            map[uint32]bool
 	timeoutTimer       *time.Timer
 	tc                 dlms.DataChannel
 	dc                 dlms.DataChannel
 	notificationID     string
 	notificationChan   chan dlms.Notification
+	objectListCache    dlms.ObjectListCache
+	objectListKey      string
+	shortNames         dlms.ShortNameList
+	accessValidation   bool
 	mutex              sync.Mutex
 	subsMutex          sync.Mutex
 }
@@ -35,11 +52,21 @@ func New(settings dlms.Settings, transport dlms.Transport, replyTimeout time.Dur
 		replyTimeout:       replyTimeout,
 		associationTimeout: associationTimeout,
 		isAssociated:       false,
+		association:        nil,
+		serverSystemTitle:  nil,
+		invokeID:           0,
+		highPriority:       true,
+		pipelining:         0,
+		expired:            make(map[uint32]bool),
 		timeoutTimer:       nil,
 		tc:                 make(dlms.DataChannel, 10),
 		dc:                 nil,
 		notificationID:     "",
 		notificationChan:   nil,
+		objectListCache:    dlms.NewObjectListMemoryCache(),
+		objectListKey:      "",
+		shortNames:         nil,
+		accessValidation:   false,
 		mutex:              sync.Mutex{},
 		subsMutex:          sync.Mutex{},
 	}
@@ -106,8 +133,29 @@ func (c *client) GetSettings() dlms.Settings {
 	return c.settings
 }
 
+// SetSettings changes the settings of the next association. The cached object list is removed, as
+// it depends on the association.
 func (c *client) SetSettings(settings dlms.Settings) {
 	c.settings = settings
+	c.deleteObjectList()
+}
+
+// SetHighPriority sets the priority bit of the requests, which is set by default. The server only
+// honors it when it supports priority management.
+func (c *client) SetHighPriority(enabled bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.highPriority = enabled
+}
+
+// SetPipelining sets the number of requests GetRequests sends without waiting for the responses.
+// It's only used when priority management has been negotiated; 0 or 1 disables pipelining.
+func (c *client) SetPipelining(outstanding int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.pipelining = outstanding
 }
 
 func (c *client) SetLogger(logger *log.Logger) {
@@ -136,7 +184,11 @@ func (c *client) Associate() error {
 		return err
 	}
 
+	// Decoding the AARE stores its responding AP title in the settings to decipher it, but the
+	// settings keep the title configured by the user for the next associations
+	configuredTitle := c.settings.Ciphering.SourceSystemTitle
 	aare, err := dlms.DecodeAARE(&c.settings, &out)
+	c.settings.Ciphering.SourceSystemTitle = configuredTitle
 	if err != nil {
 		return dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("error decoding AARE: %v", err))
 	}
@@ -157,6 +209,27 @@ func (c *client) Associate() error {
 		if maxPduSendSize < c.settings.MaxPduSendSize {
 			c.settings.MaxPduSendSize = maxPduSendSize
 		}
+
+		c.association = dlms.CreateAssociationInfo(aare)
+	}
+
+	// The ciphered responses must come from the responding AP title of the AARE for the rest of the
+	// association, or from the title configured if the AARE has none
+	if c.settings.Ciphering.Level != dlms.SecurityLevelNone {
+		title := aare.SourceSystemTitle
+		if len(title) == 0 {
+			title = configuredTitle
+		}
+
+		if len(title) != 8 {
+			// The server accepted the association, so it's released before failing
+			_ = c.release()
+			c.closeAssociation()
+			return dlms.NewError(dlms.ErrorInvalidResponse, "server system title unknown: not in the AARE nor in the settings")
+		}
+
+		c.serverSystemTitle = make([]byte, len(title))
+		copy(c.serverSystemTitle, title)
 	}
 
 	c.isAssociated = true
@@ -172,9 +245,21 @@ func (c *client) CloseAssociation() error {
 	}
 
 	if !c.isAssociated {
-		return dlms.NewError(dlms.ErrorInvalidState, "not associated")
+		return dlms.NewErrorWithCause(dlms.ErrorInvalidState, "not associated", dlms.ErrAssociationNotEstablished)
 	}
 
+	err := c.release()
+	if err != nil {
+		return err
+	}
+
+	c.closeAssociation()
+
+	return nil
+}
+
+// release sends an RLRQ and waits for the RLRE.
+func (c *client) release() error {
 	src, err := dlms.EncodeRLRQ(&c.settings)
 	if err != nil {
 		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("error encoding RLRQ: %v", err))
@@ -190,8 +275,6 @@ func (c *client) CloseAssociation() error {
 		return dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("error decoding RLRE: %v", err))
 	}
 
-	c.closeAssociation()
-
 	return nil
 }
 
@@ -206,6 +289,18 @@ func (c *client) IsAssociated() bool {
 	return c.isAssociated
 }
 
+// AssociationInfo returns the parameters negotiated with the server in the current association.
+func (c *client) AssociationInfo() (info dlms.AssociationInfo, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.isAssociated || c.association == nil {
+		return info, dlms.NewErrorWithCause(dlms.ErrorInvalidState, "not associated", dlms.ErrAssociationNotEstablished)
+	}
+
+	return *c.association, nil
+}
+
 func (c *client) manager() {
 	for {
 		data := <-c.tc
@@ -261,7 +356,7 @@ func (c *client) subscribe() {
 	c.subsMutex.Lock()
 	defer c.subsMutex.Unlock()
 
-	c.dc = make(dlms.DataChannel)
+	c.dc = make(dlms.DataChannel, maxOutstandingRequests+1)
 }
 
 func (c *client) unsubscribe() {
@@ -272,54 +367,272 @@ func (c *client) unsubscribe() {
 }
 
 func (c *client) encodeSendReceiveAndDecode(req dlms.CosemPDU) (dlms.CosemPDU, error) {
-	if !c.isAssociated {
-		return nil, dlms.NewError(dlms.ErrorInvalidState, "client is not associated")
+	pdus, err := c.encodeSendReceiveAndDecodeList([]dlms.CosemPDU{req})
+	if err != nil {
+		return nil, err
 	}
 
-	src, err := req.Encode()
-	if err != nil {
-		return nil, dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("error encoding PDU: %v", err))
+	return pdus[0], nil
+}
+
+// encodeSendReceiveAndDecodeList sends the requests back to back, and returns their responses in
+// the same order. The responses are matched with the requests by invoke ID: late responses to
+// requests that timed out are discarded, and any other response is rejected. A response without
+// invoke ID is only accepted when a single request is outstanding.
+func (c *client) encodeSendReceiveAndDecodeList(reqs []dlms.CosemPDU) ([]dlms.CosemPDU, error) {
+	c.subscribe()
+	defer c.unsubscribe()
+
+	pending := make(map[uint32]int, len(reqs))
+	for i, req := range reqs {
+		src, err := c.encodePlain(req)
+		if err != nil {
+			c.expire(pending)
+			return nil, err
+		}
+
+		invokeID, ok := dlms.DecodeInvokeID(src)
+		if !ok {
+			invokeID = noInvokeID
+		}
+
+		src, err = c.cipher(src)
+		if err != nil {
+			c.expire(pending)
+			return nil, err
+		}
+
+		err = c.transport.Send(src)
+		if err != nil {
+			c.expire(pending)
+			if !c.transport.IsConnected() {
+				c.closeAssociation()
+			}
+
+			return nil, dlms.NewError(dlms.ErrorCommunicationFailed, fmt.Sprintf("error sending request: %v", err))
+		}
+
+		delete(c.expired, invokeID)
+		pending[invokeID] = i
 	}
 
-	if c.settings.Ciphering.Level != dlms.SecurityLevelNone {
-		src, err = c.cipherData(src)
+	// Wait for the device responses
+	timeout := time.NewTimer(c.replyTimeout)
+	defer timeout.Stop()
+
+	pdus := make([]dlms.CosemPDU, len(reqs))
+	for len(pending) > 0 {
+		var out []byte
+		select {
+		case out = <-c.dc:
+		case <-timeout.C:
+			c.expire(pending)
+			return nil, dlms.NewError(dlms.ErrorCommunicationFailed, "timeout reached")
+		}
+
+		out, pdu, err := c.decode(out)
+		if err != nil {
+			c.expire(pending)
+			return nil, err
+		}
+
+		// The exceptions have no invoke ID, so they fail all the outstanding requests
+		err = serviceError(pdu)
 		if err != nil {
+			c.expire(pending)
 			return nil, err
 		}
+
+		invokeID, ok := dlms.DecodeInvokeID(out)
+		if !ok {
+			invokeID = noInvokeID
+		}
+
+		i, ok := pending[invokeID]
+		if !ok {
+			if c.expired[invokeID] {
+				delete(c.expired, invokeID)
+				continue
+			}
+
+			if invokeID != noInvokeID || len(pending) != 1 {
+				c.expire(pending)
+				return nil, dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("unexpected response with invoke ID %d", invokeID))
+			}
+
+			// A response without invoke ID answers the only outstanding request
+			for id, idx := range pending {
+				invokeID, i = id, idx
+			}
+		}
+
+		delete(pending, invokeID)
+		pdus[i] = pdu
 	}
 
-	out, err := c.sendReceive(src)
+	if c.timeoutTimer != nil {
+		c.timeoutTimer.Reset(c.associationTimeout)
+	}
+
+	return pdus, nil
+}
+
+// encodeAndSend sends a request the server doesn't respond to.
+func (c *client) encodeAndSend(req dlms.CosemPDU) error {
+	src, err := c.encode(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.transport.Send(src)
 	if err != nil {
 		if !c.transport.IsConnected() {
 			c.closeAssociation()
 		}
 
+		return dlms.NewError(dlms.ErrorCommunicationFailed, fmt.Sprintf("error sending request: %v", err))
+	}
+
+	if c.timeoutTimer != nil {
+		c.timeoutTimer.Reset(c.associationTimeout)
+	}
+
+	return nil
+}
+
+// encode encodes a request, ciphering it if required.
+func (c *client) encode(req dlms.CosemPDU) ([]byte, error) {
+	src, err := c.encodePlain(req)
+	if err != nil {
 		return nil, err
 	}
 
-	if c.settings.Ciphering.Level != dlms.SecurityLevelNone {
-		out, err = c.decipherData(out)
+	return c.cipher(src)
+}
+
+func (c *client) encodePlain(req dlms.CosemPDU) ([]byte, error) {
+	if !c.isAssociated {
+		return nil, dlms.NewErrorWithCause(dlms.ErrorInvalidState, "client is not associated", dlms.ErrAssociationNotEstablished)
+	}
+
+	src, err := req.Encode()
+	if err != nil {
+		return nil, dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("error encoding PDU: %v", err))
+	}
+
+	return src, nil
+}
+
+func (c *client) cipher(src []byte) ([]byte, error) {
+	if c.settings.Ciphering.Level == dlms.SecurityLevelNone {
+		return src, nil
+	}
+
+	return c.cipherData(src)
+}
+
+// decode deciphers a response if required, and returns it with its decoded PDU.
+func (c *client) decode(src []byte) ([]byte, dlms.CosemPDU, error) {
+	var err error
+	if c.settings.Ciphering.Level != dlms.SecurityLevelNone && !isPlainError(src) {
+		src, err = c.decipherData(src)
 		if err != nil {
-			return nil, err
+			var dlmsError *dlms.Error
+			if errors.As(err, &dlmsError) {
+				return nil, nil, err
+			}
+			return nil, nil, dlms.NewErrorWithCause(dlms.ErrorInvalidResponse, fmt.Sprintf("error deciphering response: %v", err), dlms.ErrDecipherFailed)
 		}
 	}
 
+	out := src
 	pdu, err := dlms.DecodeCosem(&out)
 	if err != nil {
-		err = dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("error decoding PDU: %v", err))
-		return nil, err
+		return nil, nil, dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("error decoding PDU: %v", err))
 	}
 
-	if c.timeoutTimer != nil {
-		c.timeoutTimer.Reset(c.associationTimeout)
+	return src, pdu, nil
+}
+
+// isPlainError returns true for the responses that report a failure without ciphering, as the
+// server may not be able to cipher them.
+func isPlainError(src []byte) bool {
+	return len(src) > 0 && (src[0] == dlms.TagExceptionResponse.Value() || src[0] == dlms.TagConfirmedServiceError.Value())
+}
+
+// serviceError returns the error of a response that reports the failure of the requests instead
+// of answering them.
+func serviceError(pdu dlms.CosemPDU) error {
+	switch resp := pdu.(type) {
+	case dlms.ExceptionResponse:
+		return dlms.NewExceptionError(resp)
+	case dlms.ConfirmedServiceError:
+		return dlms.NewServiceError(resp)
+	default:
+		return nil
+	}
+}
+
+// nextInvokeID returns the invoke ID of a new request. The invoke IDs rotate from 1 on each
+// association.
+func (c *client) nextInvokeID() uint8 {
+	c.invokeID = (c.invokeID + 1) & dlms.InvokeIDMask
+	return c.invokeID
+}
+
+// nextInvokeIDAndPriority returns the invoke-id-and-priority of a new GET, SET or ACTION request.
+func (c *client) nextInvokeIDAndPriority(confirmed bool) uint8 {
+	return dlms.CreateInvokeIDAndPriority(c.nextInvokeID(), c.highPriority, confirmed)
+}
+
+// pipeliningWindow returns the number of requests that can be outstanding at the same time.
+func (c *client) pipeliningWindow() int {
+	if c.pipelining <= 1 || c.settings.Referencing == dlms.ReferencingShortName || !c.isAccepted(dlms.ConformanceBlockPriorityMgmtSupported) {
+		return 1
+	}
+
+	if c.pipelining > maxOutstandingRequests {
+		return maxOutstandingRequests
+	}
+
+	return c.pipelining
+}
+
+// isAccepted returns true if the server accepted the services of conformance in the association.
+func (c *client) isAccepted(conformance int) bool {
+	return c.association != nil && c.association.IsAccepted(conformance)
+}
+
+// checkAccepted fails if the server didn't accept the services of conformance in the association.
+// Without association it doesn't fail, as sending the request does.
+func (c *client) checkAccepted(conformance int, service string) error {
+	if c.association != nil && !c.association.IsAccepted(conformance) {
+		return dlms.NewError(dlms.ErrorInvalidState, fmt.Sprintf("%s not accepted by the server in the association", service))
 	}
 
-	return pdu, nil
+	return nil
+}
+
+// expire records the invoke IDs of requests whose responses won't be waited for, so the responses
+// are discarded if they arrive later.
+func (c *client) expire(pending map[uint32]int) {
+	for invokeID := range pending {
+		if invokeID != noInvokeID {
+			c.expired[invokeID] = true
+		}
+	}
 }
 
 func (c *client) cipherData(src []byte) ([]byte, error) {
 	tag := dlms.CosemTag(src[0])
-	if tag != dlms.TagGetRequest && tag != dlms.TagSetRequest && tag != dlms.TagActionRequest {
+	switch tag {
+	case dlms.TagGetRequest, dlms.TagSetRequest, dlms.TagActionRequest:
+	case dlms.TagReadRequest, dlms.TagWriteRequest, dlms.TagUnconfirmedWriteRequest:
+		// The short name services are only ciphered with the global key
+		if c.settings.Ciphering.Level != dlms.SecurityLevelGlobalKey {
+			return nil, dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("tag %d can only be ciphered with the global key", tag))
+		}
+	default:
 		return nil, fmt.Errorf("unexpected tag %d", tag)
 	}
 
@@ -337,6 +650,12 @@ func (c *client) cipherData(src []byte) ([]byte, error) {
 			cipher.Tag = dlms.TagGloSetRequest
 		case dlms.TagActionRequest:
 			cipher.Tag = dlms.TagGloActionRequest
+		case dlms.TagReadRequest:
+			cipher.Tag = dlms.TagGloReadRequest
+		case dlms.TagWriteRequest:
+			cipher.Tag = dlms.TagGloWriteRequest
+		case dlms.TagUnconfirmedWriteRequest:
+			cipher.Tag = dlms.TagGloUnconfirmedWriteRequest
 		}
 
 		if len(c.settings.Ciphering.UnicastKey) != 16 {
@@ -368,25 +687,52 @@ func (c *client) cipherData(src []byte) ([]byte, error) {
 	return dlms.CipherData(cipher, src)
 }
 
+// decipherData deciphers a response with the server system title of the association. The
+// service-specific ciphered responses are authenticated with it, and the general ciphered ones
+// are rejected if they carry a different one.
 func (c *client) decipherData(src []byte) ([]byte, error) {
 	cipher := dlms.Cipher{
 		Tag:         dlms.CosemTag(src[0]),
 		Security:    c.settings.Ciphering.Security,
-		SystemTitle: c.settings.Ciphering.SourceSystemTitle,
+		SystemTitle: c.serverSystemTitle,
 		AuthKey:     c.settings.Ciphering.AuthenticationKey,
 	}
 
-	if c.settings.Ciphering.Level == dlms.SecurityLevelGlobalKey {
+	switch cipher.Tag {
+	case dlms.TagGeneralGloCiphering:
 		cipher.Key = c.settings.Ciphering.UnicastKey
-	} else {
+	case dlms.TagGeneralDedCiphering:
 		cipher.Key = c.settings.Ciphering.DedicatedKey
+	default:
+		if c.settings.Ciphering.Level == dlms.SecurityLevelGlobalKey {
+			cipher.Key = c.settings.Ciphering.UnicastKey
+		} else {
+			cipher.Key = c.settings.Ciphering.DedicatedKey
+		}
+
+		return dlms.DecipherData(cipher, src)
+	}
+
+	systemTitle, out, err := dlms.DecipherGeneralData(cipher, src)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(systemTitle, c.serverSystemTitle) {
+		return nil, dlms.NewErrorWithCause(dlms.ErrorInvalidResponse, fmt.Sprintf("ciphered response from system title %X, expected %X", systemTitle, c.serverSystemTitle), dlms.ErrDecipherFailed)
 	}
 
-	return dlms.DecipherData(cipher, src)
+	return out, nil
 }
 
 func (c *client) closeAssociation() {
 	c.isAssociated = false
+	c.association = nil
+	c.serverSystemTitle = nil
+	c.invokeID = 0
+	c.expired = make(map[uint32]bool)
+	c.shortNames = nil
+	c.deleteObjectList()
 	if c.timeoutTimer != nil {
 		c.timeoutTimer.Stop()
 		c.timeoutTimer = nil
@@ -82,6 +82,69 @@ func TestClient_Associate(t *testing.T) {
 	tm.AssertExpectations(t)
 }
 
+func TestClient_AssociationInfo(t *testing.T) {
+	c, tm, rdc := associate(t)
+
+	info, err := c.AssociationInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, dlms.ApplicationContextLNNoCiphering, info.ApplicationContext)
+	assert.Equal(t, 0x00181D, info.Conformance)
+	assert.Equal(t, uint8(dlms.DlmsVersion), info.DlmsVersion)
+	assert.Equal(t, uint16(dlms.VAANameLN), info.VAAName)
+	assert.Equal(t, uint16(128), info.ServerMaxReceivePduSize)
+	assert.True(t, info.IsAccepted(dlms.ConformanceBlockGet|dlms.ConformanceBlockSelectiveAccess))
+	assert.False(t, info.IsAccepted(dlms.ConformanceBlockEventNotification))
+
+	tm.On("IsConnected").Return(true).Once()
+	sendReceive(tm, rdc, "6200", "6300")
+	err = c.CloseAssociation()
+	assert.NoError(t, err)
+
+	_, err = c.AssociationInfo()
+	var clientError *dlms.Error
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidState, clientError.Code())
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_ServicesNotAccepted(t *testing.T) {
+	// The server only accepts get and block transfer with get
+	settings, _ := dlms.NewSettingsWithoutAuthentication()
+	c, tm, rdc := associateWith(t, settings, 5*time.Second,
+		"601DA109060760857405080101BE10040E01000000065F1F040000181F0100",
+		"6129A109060760857405080101A203020100A305A103020100BE10040E0800065F1F040000101000800007")
+
+	var data uint32
+	sendReceive(tm, rdc, "C001C100030100010800FF0200", "C401C100060000000A")
+	err := c.GetRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), &data)
+	assert.NoError(t, err)
+
+	var clientError *dlms.Error
+	err = c.GetRequestWithSelectiveAccessByDate(dlms.CreateAttributeDescriptor(7, "1-0:99.1.0.255", 2), time.Now(), time.Now(), &data)
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidState, clientError.Code())
+
+	err = c.SetRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), data)
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidState, clientError.Code())
+
+	err = c.ActionRequest(dlms.CreateMethodDescriptor(70, "0-0:96.3.10.255", 1), int8(0))
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidState, clientError.Code())
+
+	// The server accepts set without block transfer
+	c, tm, _ = associateWith(t, settings, 5*time.Second,
+		"601DA109060760857405080101BE10040E01000000065F1F040000181F0100",
+		"6129A109060760857405080101A203020100A305A103020100BE10040E0800065F1F040000101D00800007")
+
+	err = c.SetRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), make([]uint32, 100))
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidState, clientError.Code())
+
+	tm.AssertExpectations(t)
+}
+
 func TestClient_InvalidPassword(t *testing.T) {
 	tm := mocks.NewTransportMock(t)
 
@@ -328,6 +391,217 @@ func TestClient_CompleteSecureCommunication(t *testing.T) {
 	tm.AssertExpectations(t)
 }
 
+func TestClient_ServerSystemTitle(t *testing.T) {
+	tm := mocks.NewTransportMock(t)
+
+	rdc := make(dlms.DataChannel, 10)
+	tm.On("SetReception", mock.Anything).Run(func(args mock.Arguments) {
+		rdc = args.Get(0).(dlms.DataChannel)
+	}).Once()
+
+	key := decodeHexString("00112233445566778899AABBCCDDEEFF")
+	serverTitle := decodeHexString("4C475A2022604828")
+	otherTitle := decodeHexString("4C475A2022604829")
+	security := dlms.SecurityEncryption | dlms.SecurityAuthentication
+
+	ciphering, _ := dlms.NewCiphering(dlms.SecurityLevelGlobalKey, security, decodeHexString("4349520000000001"), key, 1, key)
+	settings, _ := dlms.NewSettingsWithLowAuthenticationAndCiphering([]byte("JuS66BCZ"), ciphering)
+	c := dlmsclient.New(settings, tm, 5*time.Second, 0)
+
+	tm.On("Connect").Return(nil).Once()
+	assert.NoError(t, c.Connect())
+
+	// The AARE carries the server system title and a glo-initiate-response
+	serverSettings := &dlms.Settings{
+		Ciphering: dlms.Ciphering{
+			Security:          security,
+			SystemTitle:       serverTitle,
+			UnicastKey:        key,
+			AuthenticationKey: key,
+			UnicastKeyIC:      0x10,
+		},
+	}
+	aare, err := dlms.EncodeAARE(serverSettings, dlms.AARE{
+		ApplicationContext: dlms.ApplicationContextLNCiphering,
+		AssociationResult:  dlms.AssociationResultAccepted,
+		SourceDiagnostic:   dlms.SourceDiagnosticNone,
+		SourceSystemTitle:  serverTitle,
+		InitiateResponse:   dlms.CreateInitiateResponse(nil, 0x0000101D, 250),
+	})
+	assert.NoError(t, err)
+
+	tm.On("IsConnected").Return(true)
+	reply(tm, rdc, aare)
+	assert.NoError(t, c.Associate())
+	assert.Nil(t, c.GetSettings().Ciphering.SourceSystemTitle)
+
+	info, err := c.AssociationInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, serverTitle, info.RespondingSystemTitle)
+
+	cipher := dlms.Cipher{
+		Tag:          dlms.TagGeneralGloCiphering,
+		Security:     security,
+		SystemTitle:  serverTitle,
+		Key:          key,
+		AuthKey:      key,
+		FrameCounter: 0x11,
+	}
+
+	// Response with general ciphering from the server
+	out, _ := dlms.CipherGeneralData(cipher, decodeHexString("C401C10010003C"))
+	reply(tm, rdc, out)
+	var data int16
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(8, "0-0:1.0.0.255", 3), &data)
+	assert.NoError(t, err)
+	assert.Equal(t, int16(60), data)
+
+	// Response with general ciphering from another system
+	cipher.SystemTitle = otherTitle
+	out, _ = dlms.CipherGeneralData(cipher, decodeHexString("C401C20010003C"))
+	reply(tm, rdc, out)
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(8, "0-0:1.0.0.255", 3), &data)
+	var clientError *dlms.Error
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
+	assert.ErrorIs(t, err, dlms.ErrDecipherFailed)
+
+	// Response with service-specific ciphering from another system
+	cipher.Tag = dlms.TagGloGetResponse
+	out, _ = dlms.CipherData(cipher, decodeHexString("C401C30010003C"))
+	reply(tm, rdc, out)
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(8, "0-0:1.0.0.255", 3), &data)
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
+	assert.ErrorIs(t, err, dlms.ErrDecipherFailed)
+
+	// The server can't decipher the request and responds without ciphering
+	reply(tm, rdc, decodeHexString("D80105"))
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(8, "0-0:1.0.0.255", 3), &data)
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorServiceError, clientError.Code())
+	assert.ErrorIs(t, err, dlms.ErrDecipherFailed)
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_ServerSystemTitleUnknown(t *testing.T) {
+	tm := mocks.NewTransportMock(t)
+
+	rdc := make(dlms.DataChannel, 10)
+	tm.On("SetReception", mock.Anything).Run(func(args mock.Arguments) {
+		rdc = args.Get(0).(dlms.DataChannel)
+	}).Once()
+
+	key := decodeHexString("00112233445566778899AABBCCDDEEFF")
+	ciphering, _ := dlms.NewCiphering(dlms.SecurityLevelGlobalKey, dlms.SecurityEncryption|dlms.SecurityAuthentication, decodeHexString("4349520000000001"), key, 1, key)
+	settings, _ := dlms.NewSettingsWithLowAuthenticationAndCiphering([]byte("JuS66BCZ"), ciphering)
+	c := dlmsclient.New(settings, tm, 5*time.Second, 0)
+
+	tm.On("Connect").Return(nil).Once()
+	assert.NoError(t, c.Connect())
+
+	// The AARE has no responding AP title, and the association is released
+	tm.On("IsConnected").Return(true)
+	reply(tm, rdc, decodeHexString("6129A109060760857405080101A203020100A305A103020100BE10040E0800065F1F040000101D00800007"))
+	tm.On("Send", mock.MatchedBy(func(src []byte) bool { return src[0] == byte(dlms.TagRLRQ) })).Run(func(args mock.Arguments) {
+		rdc <- decodeHexString("6300")
+	}).Return(nil).Once()
+	err := c.Associate()
+	var clientError *dlms.Error
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
+	assert.False(t, c.IsAssociated())
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_ServerSystemTitleConfigured(t *testing.T) {
+	tm := mocks.NewTransportMock(t)
+
+	rdc := make(dlms.DataChannel, 10)
+	tm.On("SetReception", mock.Anything).Run(func(args mock.Arguments) {
+		rdc = args.Get(0).(dlms.DataChannel)
+	}).Once()
+
+	key := decodeHexString("00112233445566778899AABBCCDDEEFF")
+	serverTitle := decodeHexString("4C475A2022604828")
+	security := dlms.SecurityEncryption | dlms.SecurityAuthentication
+
+	ciphering, _ := dlms.NewCiphering(dlms.SecurityLevelGlobalKey, security, decodeHexString("4349520000000001"), key, 1, key)
+	ciphering.SourceSystemTitle = serverTitle
+	settings, _ := dlms.NewSettingsWithLowAuthenticationAndCiphering([]byte("JuS66BCZ"), ciphering)
+	c := dlmsclient.New(settings, tm, 5*time.Second, 0)
+
+	tm.On("Connect").Return(nil).Once()
+	assert.NoError(t, c.Connect())
+
+	// The AARE has no responding AP title, so the configured title is used
+	tm.On("IsConnected").Return(true)
+	reply(tm, rdc, decodeHexString("6129A109060760857405080101A203020100A305A103020100BE10040E0800065F1F040000101D00800007"))
+	assert.NoError(t, c.Associate())
+	assert.Equal(t, serverTitle, c.GetSettings().Ciphering.SourceSystemTitle)
+
+	out, _ := dlms.CipherGeneralData(dlms.Cipher{
+		Tag:          dlms.TagGeneralGloCiphering,
+		Security:     security,
+		SystemTitle:  serverTitle,
+		Key:          key,
+		AuthKey:      key,
+		FrameCounter: 0x11,
+	}, decodeHexString("C401C10010003C"))
+	reply(tm, rdc, out)
+	var data int16
+	err := c.GetRequest(dlms.CreateAttributeDescriptor(8, "0-0:1.0.0.255", 3), &data)
+	assert.NoError(t, err)
+	assert.Equal(t, int16(60), data)
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_ServiceErrors(t *testing.T) {
+	c, tm, rdc := associate(t)
+
+	var data int16
+	var clientError *dlms.Error
+
+	// Exception response
+	sendReceive(tm, rdc, "C001C100080000010000FF0300", "D80102")
+	err := c.GetRequest(dlms.CreateAttributeDescriptor(8, "0-0:1.0.0.255", 3), &data)
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorServiceError, clientError.Code())
+	assert.Equal(t, dlms.CreateExceptionResponse(dlms.TagExcServiceNotAllowed, dlms.TagExcServiceNotSupported), clientError.ExceptionResponse())
+	assert.ErrorIs(t, err, dlms.ErrAssociationNotEstablished)
+
+	// Confirmed service error
+	sendReceive(tm, rdc, "C101C2000300015E230BFF02000600002710", "0E060501")
+	err = c.SetRequest(dlms.CreateAttributeDescriptor(3, "0-1:94.35.11.255", 2), uint32(10000))
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorServiceError, clientError.Code())
+	assert.Equal(t, dlms.CreateConfirmedServiceError(dlms.TagErrWrite, dlms.TagErrAccess, 1), clientError.ConfirmedServiceError())
+	assert.NotErrorIs(t, err, dlms.ErrAssociationNotEstablished)
+	assert.NotErrorIs(t, err, dlms.ErrDecipherFailed)
+
+	// Requests without association
+	tm.On("IsConnected").Return(true).Once()
+	sendReceive(tm, rdc, "6200", "6300")
+	assert.NoError(t, c.CloseAssociation())
+
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(8, "0-0:1.0.0.255", 3), &data)
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidState, clientError.Code())
+	assert.ErrorIs(t, err, dlms.ErrAssociationNotEstablished)
+
+	tm.AssertExpectations(t)
+}
+
+// reply answers the next request, whose content isn't checked, with out.
+func reply(tm *mocks.TransportMock, rdc dlms.DataChannel, out []byte) {
+	tm.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+		rdc <- out
+	}).Return(nil).Once()
+}
+
 func sendReceive(tm *mocks.TransportMock, rdc dlms.DataChannel, in string, out string) {
 	tm.On("Send", decodeHexString(in)).Run(func(args mock.Arguments) {
 		if rdc != nil {
@@ -340,3 +614,70 @@ func decodeHexString(s string) []byte {
 	b, _ := hex.DecodeString(s)
 	return b
 }
+
+func TestClient_InvokeIDRotation(t *testing.T) {
+	c, tm, rdc := associate(t)
+
+	var data int16
+
+	sendReceive(tm, rdc, "C001C100080000010000FF0300", "C401C10010003C")
+	err := c.GetRequest(dlms.CreateAttributeDescriptor(8, "0-0:1.0.0.255", 3), &data)
+	assert.NoError(t, err)
+
+	sendReceive(tm, rdc, "C001C200080000010000FF0300", "C401C20010003C")
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(8, "0-0:1.0.0.255", 3), &data)
+	assert.NoError(t, err)
+
+	// Normal priority
+	c.SetHighPriority(false)
+	sendReceive(tm, rdc, "C0014300080000010000FF0300", "C401430010003C")
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(8, "0-0:1.0.0.255", 3), &data)
+	assert.NoError(t, err)
+
+	// Invoke IDs wrap around after 15
+	c.SetHighPriority(true)
+	for i := 4; i < 16; i++ {
+		sendReceive(tm, rdc, fmt.Sprintf("C001%02X00080000010000FF0300", 0xC0|i), fmt.Sprintf("C401%02X0010003C", 0xC0|i))
+		err = c.GetRequest(dlms.CreateAttributeDescriptor(8, "0-0:1.0.0.255", 3), &data)
+		assert.NoError(t, err)
+	}
+
+	sendReceive(tm, rdc, "C001C000080000010000FF0300", "C401C00010003C")
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(8, "0-0:1.0.0.255", 3), &data)
+	assert.NoError(t, err)
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_InvokeIDMismatch(t *testing.T) {
+	settings, _ := dlms.NewSettingsWithoutAuthentication()
+	c, tm, rdc := associateWith(t, settings, 100*time.Millisecond,
+		"601DA109060760857405080101BE10040E01000000065F1F040000181F0100",
+		"6129A109060760857405080101A203020100A305A103020100BE10040E0800065F1F040000101D00800007")
+
+	var data int16
+	var clientError *dlms.Error
+
+	// The request times out
+	sendReceive(tm, nil, "C001C100080000010000FF0300", "")
+	err := c.GetRequest(dlms.CreateAttributeDescriptor(8, "0-0:1.0.0.255", 3), &data)
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorCommunicationFailed, clientError.Code())
+
+	// Its late response is discarded
+	tm.On("Send", decodeHexString("C001C200080000010000FF0300")).Run(func(args mock.Arguments) {
+		rdc <- decodeHexString("C401C10010003C")
+		rdc <- decodeHexString("C401C2001000AA")
+	}).Return(nil).Once()
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(8, "0-0:1.0.0.255", 3), &data)
+	assert.NoError(t, err)
+	assert.Equal(t, int16(0xAA), data)
+
+	// A response to a request never sent is rejected
+	sendReceive(tm, rdc, "C001C300080000010000FF0300", "C401C70010003C")
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(8, "0-0:1.0.0.255", 3), &data)
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
+
+	tm.AssertExpectations(t)
+}
@@ -0,0 +1,146 @@
+package dlmsclient
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+// GetRequestWithStructOfElementsReport reads the fields of a struct of elements like
+// GetRequestWithStructOfElements, returning the result of each field. Pointer and optional
+// fields that are not read are set to zero. err is set if the fields could not be read: an
+// invalid struct, a communication failure or, in fail-fast mode, the first field that is not read.
+func (c *client) GetRequestWithStructOfElementsReport(data interface{}, mode dlms.ElementsMode) (report dlms.ElementsReport, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	rv := reflect.ValueOf(data)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		err = dlms.NewError(dlms.ErrorInvalidParameter, "data must be a non-nil pointer")
+		return
+	}
+
+	v := reflect.Indirect(rv)
+	if v.Kind() != reflect.Struct {
+		err = dlms.NewError(dlms.ErrorInvalidParameter, "data must be a pointer to a struct")
+		return
+	}
+
+	plan, err := structPlan(v.Type())
+	if err != nil {
+		return
+	}
+
+	getField := c.fieldGetter(plan)
+	return c.structElementsRequest(plan, mode, func(f structField) (bool, error) {
+		if !f.tag.isGettable() {
+			return false, nil
+		}
+
+		field := v.FieldByIndex(f.index)
+
+		err := getField(f, field)
+		if err != nil && (field.Kind() == reflect.Ptr || f.tag.OmitEmpty) {
+			field.Set(reflect.Zero(field.Type()))
+		}
+
+		return true, err
+	})
+}
+
+// SetRequestWithStructOfElementsReport writes the fields of a struct of elements like
+// SetRequestWithStructOfElements, returning the result of each field. The fields not written, like
+// nil pointer fields or the fields of nested structs, are skipped. err is set if the fields could
+// not be written: an invalid struct, a communication failure or, in fail-fast mode, the first field
+// that is not written. If some fields were written before, err has code ErrorSetPartial.
+func (c *client) SetRequestWithStructOfElementsReport(data interface{}, mode dlms.ElementsMode) (report dlms.ElementsReport, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	v := eindirect(reflect.ValueOf(data))
+	if v.Kind() != reflect.Struct {
+		err = dlms.NewError(dlms.ErrorInvalidParameter, "data must be a struct")
+		return
+	}
+
+	plan, err := structPlan(v.Type())
+	if err != nil {
+		return
+	}
+
+	report, err = c.structElementsRequest(plan, mode, func(f structField) (bool, error) {
+		// Only the fields of the struct itself are set, not the ones of nested structs
+		if len(f.index) > 1 {
+			return false, nil
+		}
+
+		field := v.FieldByIndex(f.index)
+		if !f.tag.isSettable(field) {
+			return false, nil
+		}
+
+		return true, c.setField(f.tag, field)
+	})
+
+	if err != nil && report.Count(dlms.ElementSucceeded) > 0 {
+		err = dlms.NewErrorWithCause(dlms.ErrorSetPartial, fmt.Sprintf("partial set: %v", err), err)
+	}
+
+	return
+}
+
+// structElementsRequest runs request on each field of the plan, which returns false if the field
+// is skipped. Once stopped, the remaining fields are reported as skipped.
+func (c *client) structElementsRequest(plan []structField, mode dlms.ElementsMode, request func(f structField) (bool, error)) (report dlms.ElementsReport, err error) {
+	report.Results = make([]dlms.ElementResult, len(plan))
+	for i, f := range plan {
+		report.Results[i] = dlms.ElementResult{
+			Field:   f.name,
+			Method:  f.tag.Method,
+			Outcome: dlms.ElementSkipped,
+		}
+		if f.tag.Attribute != nil {
+			report.Results[i].Attribute = *f.tag.Attribute
+		}
+	}
+
+	for i, f := range plan {
+		result := &report.Results[i]
+
+		start := time.Now()
+		requested, reqErr := request(f)
+		if !requested {
+			continue
+		}
+
+		result.Duration = time.Since(start)
+		result.Err = reqErr
+
+		if reqErr == nil {
+			result.Outcome = dlms.ElementSucceeded
+			continue
+		}
+
+		result.Outcome = dlms.ElementFailed
+		if isRejected(reqErr) {
+			result.Outcome = dlms.ElementRejected
+		}
+
+		var dlmsError *dlms.Error
+		if errors.As(reqErr, &dlmsError) {
+			if access, ok := dlmsError.AccessResult(); ok {
+				result.Result = access
+			}
+		}
+
+		if mode == dlms.ElementsFailFast || isCommunicationError(reqErr) {
+			err = reqErr
+			return
+		}
+	}
+
+	return
+}
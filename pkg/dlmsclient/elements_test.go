@@ -0,0 +1,170 @@
+package dlmsclient_test
+
+import (
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_GetRequestWithStructOfElementsReport(t *testing.T) {
+	type nested struct {
+		Value *uint `obis:"70,0-0:96.3.10.255,3"`
+	}
+
+	var data struct {
+		Value1 uint  `obis:"1,1-1:94.34.100.255,2"`
+		Value2 *uint `obis:"1,1-1:94.34.104.255,2"`
+		Nested nested
+		Value4 *uint `obis:"3,0.0.96.10.7.255,2"`
+	}
+
+	c, tm, rdc := associate(t)
+
+	sendReceive(tm, rdc, "C001C1000101015E2264FF0200", "C401C1001104")
+	sendReceive(tm, rdc, "C001C2000101015E2268FF0200", "C401C2001101")
+	sendReceive(tm, rdc, "C001C30046000060030AFF0300", "C401C30109")
+	sendReceive(tm, rdc, "C001C400030000600A07FF0200", "C401C40009062043594B3132")
+	report, err := c.GetRequestWithStructOfElementsReport(&data, dlms.ElementsBestEffort)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(4), data.Value1)
+	assert.Equal(t, uint(1), *data.Value2)
+	assert.Nil(t, data.Nested.Value)
+	assert.Nil(t, data.Value4)
+
+	assert.Len(t, report.Results, 4)
+	assert.Equal(t, "Value1", report.Results[0].Field)
+	assert.Equal(t, *dlms.CreateAttributeDescriptor(1, "1-1:94.34.100.255", 2), report.Results[0].Attribute)
+	assert.Equal(t, dlms.ElementSucceeded, report.Results[0].Outcome)
+	assert.Zero(t, report.Results[0].Result)
+	assert.NoError(t, report.Results[0].Err)
+	assert.Equal(t, dlms.ElementSucceeded, report.Results[1].Outcome)
+	assert.Equal(t, "Nested.Value", report.Results[2].Field)
+	assert.Equal(t, dlms.ElementRejected, report.Results[2].Outcome)
+	assert.Equal(t, dlms.TagAccObjectClassInconsistent, report.Results[2].Result)
+	assert.Error(t, report.Results[2].Err)
+	assert.Equal(t, dlms.ElementFailed, report.Results[3].Outcome)
+	assert.Zero(t, report.Results[3].Result)
+	assert.Error(t, report.Results[3].Err)
+	assert.Equal(t, 2, report.Count(dlms.ElementSucceeded))
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_GetRequestWithStructOfElementsReportFailFast(t *testing.T) {
+	var data struct {
+		Value1 *uint `obis:"1,1-1:94.34.100.255,2"`
+		Value2 uint  `obis:"1,1-1:94.34.104.255,2"`
+	}
+
+	c, tm, rdc := associate(t)
+
+	sendReceive(tm, rdc, "C001C1000101015E2264FF0200", "C401C10104")
+	report, err := c.GetRequestWithStructOfElementsReport(&data, dlms.ElementsFailFast)
+	var clientError *dlms.Error
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorGetRejected, clientError.Code())
+	assert.Nil(t, data.Value1)
+
+	assert.Len(t, report.Results, 2)
+	assert.Equal(t, dlms.ElementRejected, report.Results[0].Outcome)
+	assert.Equal(t, dlms.TagAccObjectUndefined, report.Results[0].Result)
+	assert.Equal(t, "Value2", report.Results[1].Field)
+	assert.Equal(t, dlms.ElementSkipped, report.Results[1].Outcome)
+	assert.Zero(t, report.Results[1].Duration)
+	assert.Zero(t, report.Results[1].Result)
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_GetRequestWithStructOfElementsReportInvalid(t *testing.T) {
+	var data struct {
+		Value uint `obis:"1,1-1:94.34.100.255"`
+	}
+
+	c, tm, _ := associate(t)
+
+	report, err := c.GetRequestWithStructOfElementsReport(&data, dlms.ElementsBestEffort)
+	var clientError *dlms.Error
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidParameter, clientError.Code())
+	assert.Empty(t, report.Results)
+
+	_, err = c.GetRequestWithStructOfElementsReport(data, dlms.ElementsBestEffort)
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidParameter, clientError.Code())
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_SetRequestWithStructOfElementsReport(t *testing.T) {
+	data := struct {
+		Value1 uint16  `obis:"3,0-1:94.35.11.255,2"`
+		Value2 *int32  `obis:"70,0-0:96.3.10.255,3"`
+		Value3 uint16  `obis:"1,1-1:94.34.104.255,2"`
+		Value4 *uint32 `obis:"3,0-1:94.35.12.255,2"`
+	}{
+		Value1: 6789,
+		Value2: nil, // nil fields are skipped
+		Value3: 12345,
+		Value4: new(uint32),
+	}
+
+	c, tm, rdc := associate(t)
+
+	sendReceive(tm, rdc, "C101C1000300015E230BFF0200121A85", "C501C103")
+	sendReceive(tm, rdc, "C101C2000101015E2268FF0200123039", "C501C200")
+	sendReceive(tm, rdc, "C101C3000300015E230CFF02000600000000", "C501C30D")
+	report, err := c.SetRequestWithStructOfElementsReport(data, dlms.ElementsBestEffort)
+	assert.NoError(t, err)
+
+	assert.Len(t, report.Results, 4)
+	assert.Equal(t, dlms.ElementRejected, report.Results[0].Outcome)
+	assert.Equal(t, dlms.TagAccReadWriteDenied, report.Results[0].Result)
+	assert.Equal(t, dlms.ElementSkipped, report.Results[1].Outcome)
+	assert.NoError(t, report.Results[1].Err)
+	assert.Equal(t, dlms.ElementSucceeded, report.Results[2].Outcome)
+	assert.Equal(t, *dlms.CreateAttributeDescriptor(1, "1-1:94.34.104.255", 2), report.Results[2].Attribute)
+	assert.Equal(t, dlms.ElementRejected, report.Results[3].Outcome)
+	assert.Equal(t, dlms.TagAccScopeAccessViolated, report.Results[3].Result)
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_SetRequestWithStructOfElementsReportFailFast(t *testing.T) {
+	data := struct {
+		Value1 uint16 `obis:"3,0-1:94.35.11.255,2"`
+		Value2 uint16 `obis:"1,1-1:94.34.104.255,2"`
+		Value3 uint16 `obis:"3,0-1:94.35.12.255,2"`
+	}{
+		Value1: 6789,
+		Value2: 12345,
+		Value3: 1,
+	}
+
+	c, tm, rdc := associate(t)
+
+	// If the second element fails after the first one is set, then we expect an ErrorSetPartial
+
+	sendReceive(tm, rdc, "C101C1000300015E230BFF0200121A85", "C501C100")
+	sendReceive(tm, rdc, "C101C2000101015E2268FF0200123039", "C501C203")
+	report, err := c.SetRequestWithStructOfElementsReport(&data, dlms.ElementsFailFast)
+	var clientError *dlms.Error
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorSetPartial, clientError.Code())
+
+	assert.Len(t, report.Results, 3)
+	assert.Equal(t, dlms.ElementSucceeded, report.Results[0].Outcome)
+	assert.Equal(t, dlms.ElementRejected, report.Results[1].Outcome)
+	assert.Equal(t, dlms.TagAccReadWriteDenied, report.Results[1].Result)
+	assert.Equal(t, dlms.ElementSkipped, report.Results[2].Outcome)
+
+	// If the first element fails, then we expect an ErrorSetRejected
+
+	sendReceive(tm, rdc, "C101C3000300015E230BFF0200121A85", "C501C303")
+	_, err = c.SetRequestWithStructOfElementsReport(&data, dlms.ElementsFailFast)
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorSetRejected, clientError.Code())
+
+	tm.AssertExpectations(t)
+}
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-	"strconv"
 	"strings"
 	"time"
 
@@ -49,46 +48,217 @@ func (c *client) CheckRequestWithStructOfElements(data interface{}) (err error)
 	return c.checkRequestWithStructOfElements(data)
 }
 
-func (c *client) getAttributeDescriptor(field reflect.StructField) (*dlms.AttributeDescriptor, error) {
-	tag := field.Tag.Get("obis")
-	if tag == "" {
-		return nil, nil
+// GetRequests reads several attributes, returning an error for each of them. With pipelining
+// enabled, the gets are sent without waiting for the previous responses. err is only set, and
+// errs left incomplete, when the communication fails.
+func (c *client) GetRequests(atts []*dlms.AttributeDescriptor, data []interface{}) (errs []error, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if len(atts) != len(data) {
+		return nil, dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("%d attribute descriptors for %d data", len(atts), len(data)))
 	}
 
-	values := strings.Split(tag, ",")
-	if len(values) != 3 {
-		return nil, dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("invalid obis tag: %s", tag))
+	return c.getRequests(atts, data)
+}
+
+// GetRequestWithList reads several attributes with get requests with a list, returning an error for
+// each of them. If the server didn't accept multiple references in the association, or with short
+// name referencing, it falls back to GetRequests. err is only set, and errs left incomplete, when the
+// communication fails.
+func (c *client) GetRequestWithList(atts []*dlms.AttributeDescriptor, data []interface{}) (errs []error, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if len(atts) != len(data) {
+		return nil, dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("%d attribute descriptors for %d data", len(atts), len(data)))
 	}
 
-	class, err := strconv.ParseUint(values[0], 0, 16)
-	if err != nil {
-		return nil, dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("invalid class: %s", tag))
+	return c.getRequestWithList(atts, data)
+}
+
+func (c *client) getRequestWithList(atts []*dlms.AttributeDescriptor, data []interface{}) (errs []error, err error) {
+	if c.settings.Referencing == dlms.ReferencingShortName || !c.isAccepted(dlms.ConformanceBlockMultipleReferences) {
+		return c.getRequests(atts, data)
 	}
-	obis := values[1]
-	att, err := strconv.ParseUint(values[2], 0, 8)
+
+	err = c.checkAccepted(dlms.ConformanceBlockGet, "get service")
 	if err != nil {
-		return nil, dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("invalid attribute: %s", tag))
+		return nil, err
+	}
+
+	errs = make([]error, len(atts))
+	pending := make([]int, 0, len(atts))
+	for i, att := range atts {
+		if att == nil {
+			errs[i] = dlms.NewError(dlms.ErrorInvalidParameter, "attribute descriptor cannot be nil")
+			continue
+		}
+
+		errs[i] = c.validateGet(att, nil)
+		if isCommunicationError(errs[i]) {
+			return errs, errs[i]
+		}
+
+		if errs[i] == nil {
+			pending = append(pending, i)
+		}
+	}
+
+	for len(pending) > 0 {
+		batch := pending
+		if len(batch) > maxListLength {
+			batch = batch[:maxListLength]
+		}
+		pending = pending[len(batch):]
+
+		list := make([]dlms.AttributeDescriptorWithSelection, len(batch))
+		for j, i := range batch {
+			list[j] = dlms.AttributeDescriptorWithSelection{ClassID: atts[i].ClassID, InstanceID: atts[i].InstanceID, AttributeID: atts[i].AttributeID}
+		}
+
+		invokeID := c.nextInvokeIDAndPriority(true)
+		pdu, err := c.encodeSendReceiveAndDecode(dlms.CreateGetRequestWithList(invokeID, list))
+		if err != nil {
+			return errs, err
+		}
+
+		results, e := c.getListResults(len(batch), invokeID, pdu)
+		if isCommunicationError(e) {
+			return errs, e
+		}
+
+		if e != nil {
+			for _, i := range batch {
+				errs[i] = e
+			}
+			continue
+		}
+
+		for j, i := range batch {
+			axdrData, e := results[j].ValueAsData()
+			if e != nil {
+				access, _ := results[j].ValueAsAccess()
+				errs[i] = dlms.NewRejectedError(dlms.ErrorGetRejected, fmt.Sprintf("get %s rejected: %s", atts[i].String(), access.String()), access)
+				continue
+			}
+
+			errs[i] = unmarshalData(atts[i], axdrData, data[i])
+		}
+	}
+
+	return errs, nil
+}
+
+// getRequests reads several attributes with a get each, pipelined if enabled.
+func (c *client) getRequests(atts []*dlms.AttributeDescriptor, data []interface{}) (errs []error, err error) {
+	errs = make([]error, len(atts))
+	window := c.pipeliningWindow()
+
+	if window > 1 {
+		err = c.checkAccepted(dlms.ConformanceBlockGet, "get service")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pending := make([]int, 0, len(atts))
+	for i, att := range atts {
+		if window == 1 {
+			errs[i] = c.getRequestWithUnmarshal(att, nil, data[i])
+			if isCommunicationError(errs[i]) {
+				return errs, errs[i]
+			}
+			continue
+		}
+
+		if att == nil {
+			errs[i] = dlms.NewError(dlms.ErrorInvalidParameter, "attribute descriptor cannot be nil")
+			continue
+		}
+
+		errs[i] = c.validateGet(att, nil)
+		if isCommunicationError(errs[i]) {
+			return errs, errs[i]
+		}
+
+		if errs[i] == nil {
+			pending = append(pending, i)
+		}
 	}
 
-	attribute := dlms.CreateAttributeDescriptor(uint16(class), obis, int8(att))
+	for len(pending) > 0 {
+		batch := pending
+		if len(batch) > window {
+			batch = batch[:window]
+		}
+		pending = pending[len(batch):]
+
+		invokeIDs := make([]uint8, len(batch))
+		reqs := make([]dlms.CosemPDU, len(batch))
+		for j, i := range batch {
+			invokeIDs[j] = c.nextInvokeIDAndPriority(true)
+			reqs[j] = dlms.CreateGetRequestNormal(invokeIDs[j], *atts[i], nil)
+		}
+
+		pdus, err := c.encodeSendReceiveAndDecodeList(reqs)
+		if err != nil {
+			return errs, err
+		}
+
+		for j, i := range batch {
+			axdrData, e := c.getResult(atts[i], invokeIDs[j], pdus[j])
+			if isCommunicationError(e) {
+				return errs, e
+			}
 
-	return attribute, nil
+			if e == nil {
+				e = unmarshalData(atts[i], axdrData, data[i])
+			}
+			errs[i] = e
+		}
+	}
+
+	return errs, nil
 }
 
 func (c *client) getRequestWithUnmarshal(att *dlms.AttributeDescriptor, acc *dlms.SelectiveAccessDescriptor, data interface{}) (err error) {
+	if att != nil {
+		err = c.validateGet(att, acc)
+		if err != nil {
+			return
+		}
+	}
+
 	axdrData, err := c.getRequest(att, acc)
 	if err != nil {
 		return
 	}
 
-	if data != nil {
-		err = axdr.UnmarshalData(axdrData, data)
-		if err != nil {
-			return dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("error unmarshaling %s data: %v", att.String(), err))
-		}
+	return unmarshalData(att, axdrData, data)
+}
+
+func unmarshalData(att *dlms.AttributeDescriptor, axdrData axdr.DlmsData, data interface{}) error {
+	if data == nil {
+		return nil
 	}
 
-	return
+	err := axdr.UnmarshalData(axdrData, data)
+	if err != nil {
+		return dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("error unmarshaling %s data: %v", att.String(), err))
+	}
+
+	return nil
+}
+
+// isCommunicationError returns true if the error prevents sending more requests.
+func isCommunicationError(err error) bool {
+	var dlmsError *dlms.Error
+	if !errors.As(err, &dlmsError) {
+		return false
+	}
+
+	return dlmsError.Code() == dlms.ErrorCommunicationFailed || dlmsError.Code() == dlms.ErrorInvalidState
 }
 
 func (c *client) getRequest(att *dlms.AttributeDescriptor, acc *dlms.SelectiveAccessDescriptor) (data axdr.DlmsData, err error) {
@@ -97,56 +267,48 @@ func (c *client) getRequest(att *dlms.AttributeDescriptor, acc *dlms.SelectiveAc
 		return
 	}
 
-	req := dlms.CreateGetRequestNormal(unicastInvokeID, *att, acc)
+	if c.settings.Referencing == dlms.ReferencingShortName {
+		return c.readRequest(att, acc)
+	}
+
+	err = c.checkAccepted(dlms.ConformanceBlockGet, "get service")
+	if err != nil {
+		return
+	}
+
+	if acc != nil {
+		err = c.checkAccepted(dlms.ConformanceBlockSelectiveAccess, "selective access")
+		if err != nil {
+			return
+		}
+	}
+
+	invokeID := c.nextInvokeIDAndPriority(true)
+	req := dlms.CreateGetRequestNormal(invokeID, *att, acc)
 
 	pdu, err := c.encodeSendReceiveAndDecode(req)
 	if err != nil {
 		return
 	}
 
+	return c.getResult(att, invokeID, pdu)
+}
+
+// getResult returns the data of the response to a get, requesting the remaining blocks with the
+// invoke ID of the request if the response is sent in blocks.
+func (c *client) getResult(att *dlms.AttributeDescriptor, invokeID uint8, pdu dlms.CosemPDU) (data axdr.DlmsData, err error) {
 	switch resp := pdu.(type) {
 	case dlms.GetResponseNormal:
 		data, err = resp.Result.ValueAsData()
 		if err != nil {
 			access, _ := resp.Result.ValueAsAccess()
-			err = dlms.NewError(dlms.ErrorGetRejected, fmt.Sprintf("get %s rejected: %s", att.String(), access.String()))
+			err = dlms.NewRejectedError(dlms.ErrorGetRejected, fmt.Sprintf("get %s rejected: %s", att.String(), access.String()), access)
 		}
 	case dlms.GetResponseWithDataBlock:
-		blockNumber := 1
-		out := make([]byte, 0)
-		for {
-			if resp.Result.IsResult {
-				access, _ := resp.Result.ResultAsAccess()
-				err = dlms.NewError(dlms.ErrorGetRejected, fmt.Sprintf("get %s rejected: %s", att.String(), access.String()))
-				return
-			}
-
-			if blockNumber != int(resp.Result.BlockNumber) {
-				err = dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("block number mismatch in %s: expected %d, got %d", att.String(), blockNumber, resp.Result.BlockNumber))
-				return
-			}
-
-			res, _ := resp.Result.ResultAsBytes()
-			out = append(out, res...)
-
-			if resp.Result.LastBlock {
-				break
-			}
-
-			req := dlms.CreateGetRequestNext(unicastInvokeID, uint32(blockNumber))
-			blockNumber++
-
-			pdu, err = c.encodeSendReceiveAndDecode(req)
-			if err != nil {
-				return
-			}
-
-			var ok bool
-			resp, ok = pdu.(dlms.GetResponseWithDataBlock)
-			if !ok {
-				err = dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("in %s expected GetResponseWithDataBlock response, got %T", att.String(), pdu))
-				return
-			}
+		var out []byte
+		out, err = c.getResponseBlocks(att.String(), invokeID, resp)
+		if err != nil {
+			return
 		}
 
 		decoder := axdr.NewDataDecoder(&out)
@@ -162,6 +324,88 @@ func (c *client) getRequest(att *dlms.AttributeDescriptor, acc *dlms.SelectiveAc
 	return
 }
 
+// getListResults returns the results of the response to a get with a list of n attributes,
+// requesting the remaining blocks if the response is sent in blocks.
+func (c *client) getListResults(n int, invokeID uint8, pdu dlms.CosemPDU) (results []dlms.GetDataResult, err error) {
+	switch resp := pdu.(type) {
+	case dlms.GetResponseWithList:
+		results = resp.ResultList
+	case dlms.GetResponseWithDataBlock:
+		var out []byte
+		out, err = c.getResponseBlocks("list", invokeID, resp)
+		if err != nil {
+			return
+		}
+
+		if len(out) == 0 {
+			err = dlms.NewError(dlms.ErrorInvalidResponse, "empty get with list response")
+			return
+		}
+
+		_, count, e := axdr.DecodeLength(&out)
+		if e != nil {
+			err = dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("error decoding get with list response: %v", e))
+			return
+		}
+
+		for i := 0; i < int(count); i++ {
+			result, e := dlms.DecodeGetDataResult(&out)
+			if e != nil {
+				err = dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("error decoding get with list response: %v", e))
+				return
+			}
+			results = append(results, result)
+		}
+	default:
+		err = dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("in list unexpected PDU response type: %T", pdu))
+		return
+	}
+
+	if len(results) != n {
+		err = dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("%d results in get with list response of %d attributes", len(results), n))
+	}
+
+	return
+}
+
+// getResponseBlocks returns the raw data of a get response sent in blocks, requesting the remaining
+// blocks with the invoke ID of the request.
+func (c *client) getResponseBlocks(name string, invokeID uint8, resp dlms.GetResponseWithDataBlock) ([]byte, error) {
+	blockNumber := 1
+	out := make([]byte, 0)
+	for {
+		if resp.Result.IsResult {
+			access, _ := resp.Result.ResultAsAccess()
+			return nil, dlms.NewRejectedError(dlms.ErrorGetRejected, fmt.Sprintf("get %s rejected: %s", name, access.String()), access)
+		}
+
+		if blockNumber != int(resp.Result.BlockNumber) {
+			return nil, dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("block number mismatch in %s: expected %d, got %d", name, blockNumber, resp.Result.BlockNumber))
+		}
+
+		res, _ := resp.Result.ResultAsBytes()
+		out = append(out, res...)
+
+		if resp.Result.LastBlock {
+			return out, nil
+		}
+
+		req := dlms.CreateGetRequestNext(invokeID, uint32(blockNumber))
+		blockNumber++
+
+		pdu, err := c.encodeSendReceiveAndDecode(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var ok bool
+		resp, ok = pdu.(dlms.GetResponseWithDataBlock)
+		if !ok {
+			return nil, dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("in %s expected GetResponseWithDataBlock response, got %T", name, pdu))
+		}
+	}
+}
+
 func (c *client) getRequestWithStructOfElements(data interface{}) (err error) {
 	rv := reflect.ValueOf(data)
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
@@ -173,28 +417,26 @@ func (c *client) getRequestWithStructOfElements(data interface{}) (err error) {
 		return dlms.NewError(dlms.ErrorInvalidParameter, "data must be a pointer to a struct")
 	}
 
-	for i := 0; i < v.NumField(); i++ {
-		ad, err := c.getAttributeDescriptor(v.Type().Field(i))
-		if err != nil {
-			return err
+	plan, err := structPlan(v.Type())
+	if err != nil {
+		return err
+	}
+
+	getField := c.fieldGetter(plan)
+	for _, f := range plan {
+		if !f.tag.isGettable() {
+			continue
 		}
 
-		field := v.Field(i)
+		field := v.FieldByIndex(f.index)
 
-		if ad != nil {
-			err = c.getRequestWithUnmarshal(ad, nil, field.Addr().Interface())
-			if err != nil {
-				// If a get is rejected in a field which is a pointer, then we will continue without any error
-				var dlmsError *dlms.Error
-				if errors.As(err, &dlmsError) && (dlmsError.Code() == dlms.ErrorGetRejected || dlmsError.Code() == dlms.ErrorInvalidResponse) && field.Kind() == reflect.Ptr {
-					field.Set(reflect.Zero(field.Type()))
-				} else {
-					return err
-				}
-			}
-		} else if field.Kind() == reflect.Struct {
-			err = c.getRequestWithStructOfElements(field.Addr().Interface())
-			if err != nil {
+		err = getField(f, field)
+		if err != nil {
+			// If a get is rejected in a field which is a pointer or optional, then we will continue without any error
+			var dlmsError *dlms.Error
+			if errors.As(err, &dlmsError) && (dlmsError.Code() == dlms.ErrorGetRejected || dlmsError.Code() == dlms.ErrorInvalidResponse) && (field.Kind() == reflect.Ptr || f.tag.OmitEmpty) {
+				field.Set(reflect.Zero(field.Type()))
+			} else {
 				return err
 			}
 		}
@@ -214,49 +456,45 @@ func (c *client) checkRequestWithStructOfElements(data interface{}) (err error)
 		return dlms.NewError(dlms.ErrorInvalidParameter, "data must be a pointer to a struct")
 	}
 
-	for i := 0; i < v.NumField(); i++ {
-		ad, err := c.getAttributeDescriptor(v.Type().Field(i))
-		if err != nil {
-			return err
+	plan, err := structPlan(v.Type())
+	if err != nil {
+		return err
+	}
+
+	getField := c.fieldGetter(plan)
+	for _, f := range plan {
+		if !f.tag.isGettable() {
+			continue
 		}
 
-		field := v.Field(i)
+		field := v.FieldByIndex(f.index)
 
-		if ad != nil {
-			if v.Field(i).Kind() == reflect.Pointer {
-				// All nil fields will be ignored
-				if v.Field(i).IsNil() {
-					continue
-				}
-			}
+		// All nil fields will be ignored, and zero fields if optional
+		if field.Kind() == reflect.Ptr && field.IsNil() || f.tag.OmitEmpty && field.IsZero() {
+			continue
+		}
 
-			// Get expected value
-			expected := reflect.Indirect(field).Interface()
+		// Get expected value
+		expected := reflect.Indirect(field).Interface()
 
-			// Copy the expected value
-			value := reflect.New(reflect.Indirect(field).Type())
+		// Copy the expected value
+		value := reflect.New(reflect.Indirect(field).Type())
 
-			err = c.getRequestWithUnmarshal(ad, nil, value.Interface())
-			if err != nil {
-				return err
-			}
+		err = getField(f, value.Elem())
+		if err != nil {
+			return err
+		}
 
-			if str, ok := expected.(string); ok {
-				expected = strings.ToLower(str)
-			}
+		if str, ok := expected.(string); ok {
+			expected = strings.ToLower(str)
+		}
 
-			// Get got value
-			got := reflect.Indirect(value).Interface()
+		// Get got value
+		got := reflect.Indirect(value).Interface()
 
-			// Compare values
-			if !reflect.DeepEqual(expected, got) {
-				return dlms.NewError(dlms.ErrorCheckDoesNotMatch, fmt.Sprintf("values are not equal. Expected %v, got %v", expected, got))
-			}
-		} else if field.Kind() == reflect.Struct {
-			err = c.checkRequestWithStructOfElements(field.Addr().Interface())
-			if err != nil {
-				return err
-			}
+		// Compare values
+		if !reflect.DeepEqual(expected, got) {
+			return dlms.NewError(dlms.ErrorCheckDoesNotMatch, fmt.Sprintf("values are not equal. Expected %v, got %v", expected, got))
 		}
 	}
 
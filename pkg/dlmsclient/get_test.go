@@ -39,29 +39,29 @@ func TestClient_GetRequestFail(t *testing.T) {
 	assert.ErrorAs(t, err, &clientError)
 	assert.Equal(t, dlms.ErrorGetRejected, clientError.Code())
 
-	// Unexpected response
-	sendReceive(tm, rdc, "C001C100080000010000FF0300", "0E010203")
+	// Confirmed service error
+	sendReceive(tm, rdc, "C001C200080000010000FF0300", "0E010203")
 
 	err = c.GetRequest(clockAttributeDescriptor, &data)
 	assert.ErrorAs(t, err, &clientError)
-	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
+	assert.Equal(t, dlms.ErrorServiceError, clientError.Code())
 
 	// Invalid response
-	sendReceive(tm, rdc, "C001C100080000010000FF0300", "AE12")
+	sendReceive(tm, rdc, "C001C300080000010000FF0300", "AE12")
 
 	err = c.GetRequest(clockAttributeDescriptor, &data)
 	assert.ErrorAs(t, err, &clientError)
 	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
 
 	// Response type doesn't match
-	sendReceive(tm, rdc, "C001C100080000010000FF0300", "C401C10010003C")
+	sendReceive(tm, rdc, "C001C400080000010000FF0300", "C401C40010003C")
 
 	err = c.GetRequest(clockAttributeDescriptor, &data)
 	assert.ErrorAs(t, err, &clientError)
 	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
 
 	// Send failed
-	tm.On("Send", decodeHexString("C001C100080000010000FF0300")).Return(fmt.Errorf("error")).Once()
+	tm.On("Send", decodeHexString("C001C500080000010000FF0300")).Return(fmt.Errorf("error")).Once()
 	tm.On("IsConnected").Return(false).Once()
 
 	err = c.GetRequest(clockAttributeDescriptor, &data)
@@ -112,27 +112,27 @@ func TestClient_GetRequestWithDataBlockFail(t *testing.T) {
 	assert.Equal(t, dlms.ErrorGetRejected, clientError.Code())
 
 	// Invalid block number
-	sendReceive(tm, rdc, "C001C100070100630100FF0200", "C402C10000000002000C010506000000010600000002")
+	sendReceive(tm, rdc, "C001C200070100630100FF0200", "C402C20000000002000C010506000000010600000002")
 	err = c.GetRequest(dlms.CreateAttributeDescriptor(7, "1-0:99.1.0.255", 2), &data)
 	assert.ErrorAs(t, err, &clientError)
 	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
 
 	// Invalid response
-	sendReceive(tm, rdc, "C001C100070100630100FF0200", "C402C10000000001000C010506000000010600000002")
-	sendReceive(tm, rdc, "C002C100000001", "AE12")
+	sendReceive(tm, rdc, "C001C300070100630100FF0200", "C402C30000000001000C010506000000010600000002")
+	sendReceive(tm, rdc, "C002C300000001", "AE12")
 	err = c.GetRequest(dlms.CreateAttributeDescriptor(7, "1-0:99.1.0.255", 2), &data)
 	assert.ErrorAs(t, err, &clientError)
 	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
 
-	// Unexpected response
-	sendReceive(tm, rdc, "C001C100070100630100FF0200", "C402C10000000001000C010506000000010600000002")
-	sendReceive(tm, rdc, "C002C100000001", "0E010203")
+	// Confirmed service error
+	sendReceive(tm, rdc, "C001C400070100630100FF0200", "C402C40000000001000C010506000000010600000002")
+	sendReceive(tm, rdc, "C002C400000001", "0E010203")
 	err = c.GetRequest(dlms.CreateAttributeDescriptor(7, "1-0:99.1.0.255", 2), &data)
 	assert.ErrorAs(t, err, &clientError)
-	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
+	assert.Equal(t, dlms.ErrorServiceError, clientError.Code())
 
 	// Invalid data
-	sendReceive(tm, rdc, "C001C100070100630100FF0200", "C402C10100000001000C010506000000010600000002")
+	sendReceive(tm, rdc, "C001C500070100630100FF0200", "C402C50100000001000C010506000000010600000002")
 	err = c.GetRequest(dlms.CreateAttributeDescriptor(7, "1-0:99.1.0.255", 2), &data)
 	assert.ErrorAs(t, err, &clientError)
 	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
@@ -186,9 +186,9 @@ func TestClient_GetRequestWithStructOfElements(t *testing.T) {
 	c, tm, rdc := associate(t)
 
 	sendReceive(tm, rdc, "C001C1000101015E2264FF0200", "C401C1001104")
-	sendReceive(tm, rdc, "C001C1000101015E2268FF0200", "C401C1001101")
-	sendReceive(tm, rdc, "C001C10046000060030AFF0300", "C401C10109")
-	sendReceive(tm, rdc, "C001C100030000600A07FF0200", "C401C10009062043594B3132")
+	sendReceive(tm, rdc, "C001C2000101015E2268FF0200", "C401C2001101")
+	sendReceive(tm, rdc, "C001C30046000060030AFF0300", "C401C30109")
+	sendReceive(tm, rdc, "C001C400030000600A07FF0200", "C401C40009062043594B3132")
 	err := c.GetRequestWithStructOfElements(&data)
 	assert.NoError(t, err)
 	assert.Equal(t, uint(4), data.Value1)
@@ -217,8 +217,8 @@ func TestClient_GetRequestWithNestedStructOfElements(t *testing.T) {
 	c, tm, rdc := associate(t)
 
 	sendReceive(tm, rdc, "C001C1000101015E2264FF0200", "C401C1001104")
-	sendReceive(tm, rdc, "C001C1000101015E2268FF0200", "C401C1001101")
-	sendReceive(tm, rdc, "C001C10046000060030AFF0300", "C401C10109")
+	sendReceive(tm, rdc, "C001C2000101015E2268FF0200", "C401C2001101")
+	sendReceive(tm, rdc, "C001C30046000060030AFF0300", "C401C30109")
 	err := c.GetRequestWithStructOfElements(&data)
 	assert.NoError(t, err)
 	assert.Equal(t, uint(4), data.Value1)
@@ -245,8 +245,8 @@ func TestClient_CheckRequestWithStructOfElements(t *testing.T) {
 	c, tm, rdc := associate(t)
 
 	sendReceive(tm, rdc, "C001C1000101015E2268FF0200", "C401C1001104")
-	sendReceive(tm, rdc, "C001C100010000600101FF0200", "C401C10009062043594B3132")
-	sendReceive(tm, rdc, "C001C1000300005E2204FF0200", "C401C10001020F030F04")
+	sendReceive(tm, rdc, "C001C200010000600101FF0200", "C401C20009062043594B3132")
+	sendReceive(tm, rdc, "C001C3000300005E2204FF0200", "C401C30001020F030F04")
 	err := c.CheckRequestWithStructOfElements(&data)
 	assert.NoError(t, err)
 
@@ -255,8 +255,8 @@ func TestClient_CheckRequestWithStructOfElements(t *testing.T) {
 	// If the first value is nil, just check the second value
 	data.Value1 = nil
 
-	sendReceive(tm, rdc, "C001C100010000600101FF0200", "C401C10009062043594B3132")
-	sendReceive(tm, rdc, "C001C1000300005E2204FF0200", "C401C10001020F030F04")
+	sendReceive(tm, rdc, "C001C400010000600101FF0200", "C401C40009062043594B3132")
+	sendReceive(tm, rdc, "C001C5000300005E2204FF0200", "C401C50001020F030F04")
 	err = c.CheckRequestWithStructOfElements(&data)
 	assert.NoError(t, err)
 
@@ -266,7 +266,7 @@ func TestClient_CheckRequestWithStructOfElements(t *testing.T) {
 	value1 = 8
 	data.Value1 = &value1
 
-	sendReceive(tm, rdc, "C001C1000101015E2268FF0200", "C401C1001104")
+	sendReceive(tm, rdc, "C001C6000101015E2268FF0200", "C401C6001104")
 	err = c.CheckRequestWithStructOfElements(&data)
 	var clientError *dlms.Error
 	assert.ErrorAs(t, err, &clientError)
@@ -277,8 +277,8 @@ func TestClient_CheckRequestWithStructOfElements(t *testing.T) {
 	// Values in a slice should also be checked
 	data.Value1 = nil
 
-	sendReceive(tm, rdc, "C001C100010000600101FF0200", "C401C10009062043594B3132")
-	sendReceive(tm, rdc, "C001C1000300005E2204FF0200", "C401C10001020F030F05")
+	sendReceive(tm, rdc, "C001C700010000600101FF0200", "C401C70009062043594B3132")
+	sendReceive(tm, rdc, "C001C8000300005E2204FF0200", "C401C80001020F030F05")
 	err = c.CheckRequestWithStructOfElements(&data)
 	assert.Error(t, err)
 
@@ -288,6 +288,15 @@ func TestClient_CheckRequestWithStructOfElements(t *testing.T) {
 func associate(t *testing.T) (dlms.Client, *mocks.TransportMock, dlms.DataChannel) {
 	t.Helper()
 
+	settings, _ := dlms.NewSettingsWithoutAuthentication()
+	return associateWith(t, settings, 5*time.Second,
+		"601DA109060760857405080101BE10040E01000000065F1F040000181F0100",
+		"6129A109060760857405080101A203020100A305A103020100BE10040E0800065F1F040000181D00800007")
+}
+
+func associateWith(t *testing.T, settings dlms.Settings, replyTimeout time.Duration, aarq string, aare string) (dlms.Client, *mocks.TransportMock, dlms.DataChannel) {
+	t.Helper()
+
 	tm := mocks.NewTransportMock(t)
 
 	rdc := make(dlms.DataChannel, 10)
@@ -295,17 +304,157 @@ func associate(t *testing.T) (dlms.Client, *mocks.TransportMock, dlms.DataChanne
 		rdc = args.Get(0).(dlms.DataChannel)
 	}).Once()
 
-	settings, _ := dlms.NewSettingsWithoutAuthentication()
-	c := dlmsclient.New(settings, tm, 5*time.Second, 0)
+	c := dlmsclient.New(settings, tm, replyTimeout, 0)
 
 	tm.On("Connect").Return(nil).Once()
 	c.Connect()
 
 	tm.On("IsConnected").Return(true).Once()
-	sendReceive(tm, rdc, "601DA109060760857405080101BE10040E01000000065F1F040000181F0100", "6129A109060760857405080101A203020100A305A103020100BE10040E0800065F1F040000101D00800007")
+	sendReceive(tm, rdc, aarq, aare)
 
 	err := c.Associate()
 	assert.NoError(t, err)
 
 	return c, tm, rdc
 }
+
+func TestClient_GetRequests(t *testing.T) {
+	c, tm, rdc := associate(t)
+
+	var data1, data2, data3 uint32
+	atts := []*dlms.AttributeDescriptor{
+		dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2),
+		dlms.CreateAttributeDescriptor(3, "1.0.2.8.0.255", 2),
+		dlms.CreateAttributeDescriptor(3, "1.0.3.8.0.255", 2),
+	}
+
+	// Without priority management, the gets aren't pipelined
+	c.SetPipelining(2)
+	sendReceive(tm, rdc, "C001C100030100010800FF0200", "C401C100060000000A")
+	sendReceive(tm, rdc, "C001C200030100020800FF0200", "C401C2000600000014")
+	sendReceive(tm, rdc, "C001C300030100030800FF0200", "C401C30104")
+	errs, err := c.GetRequests(atts, []interface{}{&data1, &data2, &data3})
+	assert.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	var clientError *dlms.Error
+	assert.ErrorAs(t, errs[2], &clientError)
+	assert.Equal(t, dlms.ErrorGetRejected, clientError.Code())
+	assert.Equal(t, uint32(10), data1)
+	assert.Equal(t, uint32(20), data2)
+
+	_, err = c.GetRequests(atts, []interface{}{&data1})
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidParameter, clientError.Code())
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_GetRequestsPipelined(t *testing.T) {
+	settings, _ := dlms.NewSettingsWithoutAuthentication()
+	settings.ConformanceBlock |= dlms.ConformanceBlockPriorityMgmtSupported
+	c, tm, rdc := associateWith(t, settings, 5*time.Second,
+		"601DA109060760857405080101BE10040E01000000065F1F040000581F0100",
+		"6129A109060760857405080101A203020100A305A103020100BE10040E0800065F1F040000501D00800007")
+
+	var data1, data2, data3 uint32
+	atts := []*dlms.AttributeDescriptor{
+		dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2),
+		dlms.CreateAttributeDescriptor(3, "1.0.2.8.0.255", 2),
+		dlms.CreateAttributeDescriptor(3, "1.0.3.8.0.255", 2),
+	}
+
+	// Two requests are sent before the responses, which arrive in any order
+	c.SetPipelining(2)
+	tm.On("Send", decodeHexString("C001C100030100010800FF0200")).Return(nil).Once()
+	tm.On("Send", decodeHexString("C001C200030100020800FF0200")).Run(func(args mock.Arguments) {
+		rdc <- decodeHexString("C401C2000600000014")
+		rdc <- decodeHexString("C401C100060000000A")
+	}).Return(nil).Once()
+	sendReceive(tm, rdc, "C001C300030100030800FF0200", "C401C30104")
+	errs, err := c.GetRequests(atts, []interface{}{&data1, &data2, &data3})
+	assert.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	var clientError *dlms.Error
+	assert.ErrorAs(t, errs[2], &clientError)
+	assert.Equal(t, dlms.ErrorGetRejected, clientError.Code())
+	assert.Equal(t, uint32(10), data1)
+	assert.Equal(t, uint32(20), data2)
+
+	// A confirmed service error fails all the outstanding requests
+	tm.On("Send", decodeHexString("C001C400030100010800FF0200")).Return(nil).Once()
+	sendReceive(tm, rdc, "C001C500030100020800FF0200", "0E010203")
+	_, err = c.GetRequests(atts[:2], []interface{}{&data1, &data2})
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorServiceError, clientError.Code())
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_GetRequestWithList(t *testing.T) {
+	settings, _ := dlms.NewSettingsWithoutAuthentication()
+	settings.ConformanceBlock |= dlms.ConformanceBlockMultipleReferences
+	c, tm, rdc := associateWith(t, settings, 5*time.Second,
+		"601DA109060760857405080101BE10040E01000000065F1F0400001A1F0100",
+		"6129A109060760857405080101A203020100A305A103020100BE10040E0800065F1F0400001A1D00800007")
+
+	var data1, data2, data3 uint32
+	atts := []*dlms.AttributeDescriptor{
+		dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2),
+		dlms.CreateAttributeDescriptor(3, "1.0.2.8.0.255", 2),
+		dlms.CreateAttributeDescriptor(3, "1.0.3.8.0.255", 2),
+	}
+
+	sendReceive(tm, rdc, "C003C10300030100010800FF020000030100020800FF020000030100030800FF0200", "C403C10300060000000A0006000000140104")
+	errs, err := c.GetRequestWithList(atts, []interface{}{&data1, &data2, &data3})
+	assert.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	var clientError *dlms.Error
+	assert.ErrorAs(t, errs[2], &clientError)
+	assert.Equal(t, dlms.ErrorGetRejected, clientError.Code())
+	result, ok := clientError.AccessResult()
+	assert.True(t, ok)
+	assert.Equal(t, dlms.TagAccObjectUndefined, result)
+	assert.Equal(t, uint32(10), data1)
+	assert.Equal(t, uint32(20), data2)
+
+	// Response in blocks
+	sendReceive(tm, rdc, "C003C20200030100010800FF020000030100020800FF0200", "C402C2000000000100050200060000")
+	sendReceive(tm, rdc, "C002C200000001", "C402C201000000020004000B0104")
+	errs, err = c.GetRequestWithList(atts[:2], []interface{}{&data1, &data2})
+	assert.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.ErrorAs(t, errs[1], &clientError)
+	assert.Equal(t, dlms.ErrorGetRejected, clientError.Code())
+	assert.Equal(t, uint32(11), data1)
+
+	_, err = c.GetRequestWithList(atts, []interface{}{&data1})
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidParameter, clientError.Code())
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_GetRequestWithListWithoutMultipleReferences(t *testing.T) {
+	c, tm, rdc := associate(t)
+
+	var data1, data2 uint32
+	atts := []*dlms.AttributeDescriptor{
+		dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2),
+		dlms.CreateAttributeDescriptor(3, "1.0.2.8.0.255", 2),
+	}
+
+	// Not accepted by the server, it falls back to a get each
+	sendReceive(tm, rdc, "C001C100030100010800FF0200", "C401C100060000000A")
+	sendReceive(tm, rdc, "C001C200030100020800FF0200", "C401C2000600000014")
+	errs, err := c.GetRequestWithList(atts, []interface{}{&data1, &data2})
+	assert.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.Equal(t, uint32(10), data1)
+	assert.Equal(t, uint32(20), data2)
+
+	tm.AssertExpectations(t)
+}
@@ -0,0 +1,184 @@
+package dlmsclient
+
+import (
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+// GetObjectList returns the object list of the current association. It's read from the device
+// only if it isn't already in the cache.
+func (c *client) GetObjectList() (ol dlms.ObjectList, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.getObjectList()
+}
+
+// SetObjectListCache sets the cache used to store the object list. Key identifies the device,
+// so a cache can be shared between clients connected to different devices. If cache is nil,
+// the object list is read every time. As the object list depends on the association, its entry is
+// removed when the association is closed or the settings change.
+func (c *client) SetObjectListCache(cache dlms.ObjectListCache, key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.objectListCache = cache
+	c.objectListKey = key
+}
+
+// SetAccessValidation enables the validation of requests against the access rights of the object
+// list before sending them, so requests that would be rejected by the device fail without a round trip.
+// Requests are not validated with short name referencing, as the Association SN has no access rights.
+func (c *client) SetAccessValidation(enabled bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.accessValidation = enabled
+}
+
+func (c *client) getObjectList() (ol dlms.ObjectList, err error) {
+	if c.objectListCache != nil {
+		if ol, ok := c.objectListCache.Get(c.objectListKey); ok {
+			return ol, nil
+		}
+	}
+
+	att := dlms.CreateAttributeDescriptor(dlms.AssociationLNClassID, dlms.AssociationLNCurrent, dlms.AssociationLNObjectList)
+
+	data, err := c.getRequest(att, nil)
+	if err != nil {
+		return
+	}
+
+	ol, err = dlms.DecodeObjectList(data)
+	if err != nil {
+		return nil, dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("error decoding object list: %v", err))
+	}
+
+	if c.objectListCache != nil {
+		c.objectListCache.Set(c.objectListKey, ol)
+	}
+
+	return
+}
+
+// deleteObjectList removes the object list of the association from the cache.
+func (c *client) deleteObjectList() {
+	if c.objectListCache != nil {
+		c.objectListCache.Delete(c.objectListKey)
+	}
+}
+
+// validateGet checks that the attribute can be read, if access validation is enabled.
+func (c *client) validateGet(att *dlms.AttributeDescriptor, acc *dlms.SelectiveAccessDescriptor) error {
+	if !c.accessValidation || c.settings.Referencing == dlms.ReferencingShortName {
+		return nil
+	}
+
+	element, err := c.findObjectListElement(att.ClassID, att.InstanceID)
+	if err != nil {
+		return err
+	}
+
+	if element == nil {
+		return dlms.NewRejectedError(dlms.ErrorGetRejected, fmt.Sprintf("get %s rejected: object not in the object list", att.String()), dlms.TagAccObjectUndefined)
+	}
+
+	mode := element.AttributeAccess(att.AttributeID)
+	if !mode.CanRead() {
+		return dlms.NewRejectedError(dlms.ErrorGetRejected, fmt.Sprintf("get %s rejected: no read access (%s)", att.String(), mode.String()), dlms.TagAccReadWriteDenied)
+	}
+
+	if !c.isAuthenticated() && (mode == dlms.AttributeAccessAuthenticatedReadOnly || mode == dlms.AttributeAccessAuthenticatedReadAndWrite) {
+		return dlms.NewRejectedError(dlms.ErrorGetRejected, fmt.Sprintf("get %s rejected: authenticated read access required (%s)", att.String(), mode.String()), dlms.TagAccReadWriteDenied)
+	}
+
+	if acc != nil {
+		for _, item := range element.AccessRights.Attributes {
+			if item.AttributeID == att.AttributeID && !containsSelector(item.AccessSelectors, int8(acc.AccessSelector.Value())) {
+				return dlms.NewRejectedError(dlms.ErrorGetRejected, fmt.Sprintf("get %s rejected: access selector %d not supported", att.String(), acc.AccessSelector.Value()), dlms.TagAccOtherReason)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateSet checks that the attribute can be written, if access validation is enabled.
+func (c *client) validateSet(att *dlms.AttributeDescriptor) error {
+	if !c.accessValidation || c.settings.Referencing == dlms.ReferencingShortName {
+		return nil
+	}
+
+	element, err := c.findObjectListElement(att.ClassID, att.InstanceID)
+	if err != nil {
+		return err
+	}
+
+	if element == nil {
+		return dlms.NewRejectedError(dlms.ErrorSetRejected, fmt.Sprintf("set %s rejected: object not in the object list", att.String()), dlms.TagAccObjectUndefined)
+	}
+
+	mode := element.AttributeAccess(att.AttributeID)
+	if !mode.CanWrite() {
+		return dlms.NewRejectedError(dlms.ErrorSetRejected, fmt.Sprintf("set %s rejected: no write access (%s)", att.String(), mode.String()), dlms.TagAccReadWriteDenied)
+	}
+
+	if !c.isAuthenticated() && (mode == dlms.AttributeAccessAuthenticatedWriteOnly || mode == dlms.AttributeAccessAuthenticatedReadAndWrite) {
+		return dlms.NewRejectedError(dlms.ErrorSetRejected, fmt.Sprintf("set %s rejected: authenticated write access required (%s)", att.String(), mode.String()), dlms.TagAccReadWriteDenied)
+	}
+
+	return nil
+}
+
+// validateAction checks that the method can be invoked, if access validation is enabled.
+func (c *client) validateAction(mth *dlms.MethodDescriptor) error {
+	if !c.accessValidation {
+		return nil
+	}
+
+	element, err := c.findObjectListElement(mth.ClassID, mth.InstanceID)
+	if err != nil {
+		return err
+	}
+
+	if element == nil {
+		return dlms.NewError(dlms.ErrorActionRejected, fmt.Sprintf("action %s rejected: object not in the object list", mth.String()))
+	}
+
+	mode := element.MethodAccess(mth.MethodID)
+	if !mode.CanExecute() {
+		return dlms.NewError(dlms.ErrorActionRejected, fmt.Sprintf("action %s rejected: no execute access (%s)", mth.String(), mode.String()))
+	}
+
+	if !c.isAuthenticated() && mode == dlms.MethodAccessAuthenticatedAccess {
+		return dlms.NewError(dlms.ErrorActionRejected, fmt.Sprintf("action %s rejected: authenticated access required (%s)", mth.String(), mode.String()))
+	}
+
+	return nil
+}
+
+func (c *client) findObjectListElement(classID uint16, logicalName dlms.Obis) (*dlms.ObjectListElement, error) {
+	ol, err := c.getObjectList()
+	if err != nil {
+		return nil, err
+	}
+
+	return ol.Find(classID, logicalName), nil
+}
+
+// isAuthenticated returns true if the requests sent by the client are authenticated.
+func (c *client) isAuthenticated() bool {
+	return c.settings.Ciphering.Level != dlms.SecurityLevelNone && c.settings.Ciphering.Security&dlms.SecurityAuthentication != 0
+}
+
+func containsSelector(selectors []int8, selector int8) bool {
+	for _, s := range selectors {
+		if s == selector {
+			return true
+		}
+	}
+
+	return false
+}
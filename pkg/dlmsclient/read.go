@@ -0,0 +1,208 @@
+package dlmsclient
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+// ReadRequest reads an attribute with short name referencing. The short name is resolved with the
+// object list of the Association SN, that is read once per association.
+func (c *client) ReadRequest(att *dlms.AttributeDescriptor, data interface{}) (err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.readRequestWithUnmarshal(att, nil, data)
+}
+
+// ReadRequestWithSelectiveAccess reads an attribute with short name referencing, using the
+// parameterized access with the selector and parameter of acc.
+func (c *client) ReadRequestWithSelectiveAccess(att *dlms.AttributeDescriptor, acc *dlms.SelectiveAccessDescriptor, data interface{}) (err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.readRequestWithUnmarshal(att, acc, data)
+}
+
+// GetShortNameList returns the object list of the current Association SN. It's read from the
+// device once per association.
+func (c *client) GetShortNameList() (sl dlms.ShortNameList, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.getShortNameList()
+}
+
+func (c *client) readRequestWithUnmarshal(att *dlms.AttributeDescriptor, acc *dlms.SelectiveAccessDescriptor, data interface{}) (err error) {
+	axdrData, err := c.readRequest(att, acc)
+	if err != nil {
+		return
+	}
+
+	if data != nil {
+		err = axdr.UnmarshalData(axdrData, data)
+		if err != nil {
+			return dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("error unmarshaling %s data: %v", att.String(), err))
+		}
+	}
+
+	return
+}
+
+func (c *client) readRequest(att *dlms.AttributeDescriptor, acc *dlms.SelectiveAccessDescriptor) (data axdr.DlmsData, err error) {
+	if att == nil {
+		err = dlms.NewError(dlms.ErrorInvalidParameter, "attribute descriptor cannot be nil")
+		return
+	}
+
+	err = c.checkAccepted(dlms.ConformanceBlockRead, "read service")
+	if err != nil {
+		return
+	}
+
+	if acc != nil {
+		err = c.checkAccepted(dlms.ConformanceBlockParametrizedAccess, "parameterized access")
+		if err != nil {
+			return
+		}
+	}
+
+	name, ok, err := c.shortName(att)
+	if err != nil {
+		return
+	}
+
+	if !ok {
+		err = dlms.NewRejectedError(dlms.ErrorGetRejected, fmt.Sprintf("read %s rejected: object not in the short name list", att.String()), dlms.TagAccObjectUndefined)
+		return
+	}
+
+	variable := dlms.CreateVariableName(name)
+	if acc != nil {
+		variable = dlms.CreateParameterizedAccess(name, acc.AccessSelector.Value(), acc.AccessParameter)
+	}
+
+	result, err := c.readResult(att, *variable)
+	if err != nil {
+		return
+	}
+
+	if result.Tag == dlms.TagReadResultDataBlockResult {
+		result, err = c.readResponseBlocks(att, result)
+		if err != nil {
+			return
+		}
+	}
+
+	switch result.Tag {
+	case dlms.TagReadResultData:
+		data = result.Data
+	case dlms.TagReadResultDataAccessError:
+		err = dlms.NewRejectedError(dlms.ErrorGetRejected, fmt.Sprintf("read %s rejected: %s", att.String(), result.Access.String()), result.Access)
+	default:
+		err = dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("in %s unexpected read result %d", att.String(), result.Tag))
+	}
+
+	return
+}
+
+// readResult sends a ReadRequest with a single variable and returns its result.
+func (c *client) readResult(att *dlms.AttributeDescriptor, variable dlms.VariableAccessSpecification) (result dlms.ReadResult, err error) {
+	pdu, err := c.encodeSendReceiveAndDecode(dlms.CreateReadRequest([]dlms.VariableAccessSpecification{variable}))
+	if err != nil {
+		return
+	}
+
+	resp, ok := pdu.(dlms.ReadResponse)
+	if !ok {
+		err = dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("in %s unexpected PDU response type: %T", att.String(), pdu))
+		return
+	}
+
+	if len(resp.Results) != 1 {
+		err = dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("in %s expected 1 read result, got %d", att.String(), len(resp.Results)))
+		return
+	}
+
+	return resp.Results[0], nil
+}
+
+// readResponseBlocks requests the remaining blocks of a response. The blocks hold the encoded
+// ReadResponse without its tag, whose result is returned.
+func (c *client) readResponseBlocks(att *dlms.AttributeDescriptor, result dlms.ReadResult) (dlms.ReadResult, error) {
+	blockNumber := uint16(1)
+	out := []byte{dlms.TagReadResponse.Value()}
+
+	for {
+		switch result.Tag {
+		case dlms.TagReadResultDataBlockResult:
+		case dlms.TagReadResultDataAccessError:
+			return result, nil
+		default:
+			return result, dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("in %s expected a data block, got read result %d", att.String(), result.Tag))
+		}
+
+		if result.BlockNumber != blockNumber {
+			return result, dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("block number mismatch in %s: expected %d, got %d", att.String(), blockNumber, result.BlockNumber))
+		}
+
+		out = append(out, result.RawData...)
+		if result.LastBlock {
+			break
+		}
+
+		var err error
+		result, err = c.readResult(att, *dlms.CreateBlockNumberAccess(blockNumber))
+		if err != nil {
+			return result, err
+		}
+		blockNumber++
+	}
+
+	resp, err := dlms.DecodeReadResponse(&out)
+	if err != nil || len(resp.Results) != 1 {
+		return dlms.ReadResult{}, dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("error decoding %s data blocks: %v", att.String(), err))
+	}
+
+	return resp.Results[0], nil
+}
+
+// shortName returns the short name of an attribute, or false if its object is not in the object
+// list of the Association SN.
+func (c *client) shortName(att *dlms.AttributeDescriptor) (uint16, bool, error) {
+	if att.ClassID == dlms.AssociationSNClassID && bytes.Equal(att.InstanceID.Bytes(), dlms.CreateObis(dlms.AssociationSNCurrent).Bytes()) {
+		association := dlms.ShortNameListElement{BaseName: dlms.AssociationSNBaseName}
+		return association.AttributeName(att.AttributeID), att.AttributeID > 0, nil
+	}
+
+	sl, err := c.getShortNameList()
+	if err != nil {
+		return 0, false, err
+	}
+
+	name, ok := sl.AttributeName(*att)
+	return name, ok, nil
+}
+
+func (c *client) getShortNameList() (sl dlms.ShortNameList, err error) {
+	if c.shortNames != nil {
+		return c.shortNames, nil
+	}
+
+	att := dlms.CreateAttributeDescriptor(dlms.AssociationSNClassID, dlms.AssociationSNCurrent, dlms.AssociationSNObjectList)
+
+	data, err := c.readRequest(att, nil)
+	if err != nil {
+		return
+	}
+
+	sl, err = dlms.DecodeShortNameList(data)
+	if err != nil {
+		return nil, dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("error decoding short name list: %v", err))
+	}
+
+	c.shortNames = sl
+	return
+}
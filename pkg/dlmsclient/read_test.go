@@ -0,0 +1,115 @@
+package dlmsclient_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlms/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	// Short name list with the association at 0xFA00 and the register 1.0.1.8.0.255 at 0x0110
+	readShortNameList     = "050102FA08"
+	shortNameListResponse = "0C01000102020410FA0012000C110209060000280000FF0204100110120003110009060100010800FF"
+)
+
+func TestClient_ReadRequest(t *testing.T) {
+	c, tm, rdc := associateWithShortNames(t)
+
+	var data uint32
+
+	sendReceive(tm, rdc, readShortNameList, shortNameListResponse)
+	sendReceive(tm, rdc, "0501020118", "0C01000600003039")
+	err := c.ReadRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), &data)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(12345), data)
+
+	// The short name list is read once, and get requests are sent as read requests
+	sendReceive(tm, rdc, "0501020118", "0C01000600003040")
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), &data)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(12352), data)
+
+	sendReceive(tm, rdc, "050104011802020406000000010600000002120000120000", "0C01000600003039")
+	err = c.ReadRequestWithSelectiveAccess(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), dlms.CreateSelectiveAccessByEntryDescriptor(1, 2), &data)
+	assert.NoError(t, err)
+
+	sl, err := c.GetShortNameList()
+	assert.NoError(t, err)
+	assert.Len(t, sl, 2)
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_ReadRequestWithDataBlock(t *testing.T) {
+	c, tm, rdc := associateWithShortNames(t)
+
+	var data uint32
+
+	sendReceive(tm, rdc, readShortNameList, shortNameListResponse)
+	sendReceive(tm, rdc, "0501020118", "0C0102000001020100")
+	sendReceive(tm, rdc, "0501050001", "0C0102FF0002050600003039")
+	err := c.ReadRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), &data)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(12345), data)
+
+	// Invalid block number
+	sendReceive(tm, rdc, "0501020118", "0C0102000002020100")
+	err = c.ReadRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), &data)
+	var clientError *dlms.Error
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
+
+	// Read failed in a block
+	sendReceive(tm, rdc, "0501020118", "0C0102000001020100")
+	sendReceive(tm, rdc, "0501050001", "0C010102")
+	err = c.ReadRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), &data)
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorGetRejected, clientError.Code())
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_ReadRequestFail(t *testing.T) {
+	c, tm, rdc := associateWithShortNames(t)
+
+	var data uint32
+
+	// Read failed
+	sendReceive(tm, rdc, readShortNameList, shortNameListResponse)
+	sendReceive(tm, rdc, "0501020118", "0C010104")
+	err := c.ReadRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), &data)
+	var clientError *dlms.Error
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorGetRejected, clientError.Code())
+
+	// Object not in the short name list
+	err = c.ReadRequest(dlms.CreateAttributeDescriptor(3, "1.0.2.8.0.255", 2), &data)
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorGetRejected, clientError.Code())
+
+	// Unexpected response
+	sendReceive(tm, rdc, "0501020118", "C401C10010003C")
+	err = c.ReadRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), &data)
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
+
+	// Actions can't be invoked with short names
+	err = c.ActionRequest(dlms.CreateMethodDescriptor(3, "1.0.1.8.0.255", 1), int8(0))
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidState, clientError.Code())
+
+	tm.AssertExpectations(t)
+}
+
+func associateWithShortNames(t *testing.T) (dlms.Client, *mocks.TransportMock, dlms.DataChannel) {
+	t.Helper()
+
+	settings, _ := dlms.NewSettingsWithoutAuthentication()
+	settings.UseShortNameReferencing()
+	return associateWith(t, settings, 5*time.Second,
+		"601DA109060760857405080102BE10040E01000000065F1F04001C1B200100",
+		"6129A109060760857405080102A203020100A305A103020100BE10040E0800065F1F04001C1B200080FA00")
+}
@@ -16,6 +16,55 @@ func (c *client) SetRequest(att *dlms.AttributeDescriptor, data interface{}) (er
 	return c.setRequest(att, data)
 }
 
+// UnconfirmedSetRequest sets an attribute without waiting for the result, with the unconfirmed
+// service class. The request must fit in a single PDU.
+func (c *client) UnconfirmedSetRequest(att *dlms.AttributeDescriptor, data interface{}) (err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if att == nil {
+		return dlms.NewError(dlms.ErrorInvalidParameter, "attribute descriptor must be non-nil")
+	}
+
+	if c.settings.Referencing == dlms.ReferencingShortName {
+		return c.unconfirmedWriteRequest(att, data)
+	}
+
+	err = c.checkAccepted(dlms.ConformanceBlockSet, "set service")
+	if err != nil {
+		return
+	}
+
+	err = c.validateSet(att)
+	if err != nil {
+		return
+	}
+
+	dt, ok := data.(*axdr.DlmsData)
+	if !ok {
+		dt, err = axdr.MarshalData(data)
+		if err != nil {
+			return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("error marshaling %s data: %v", att.String(), err))
+		}
+	}
+
+	req := dlms.CreateSetRequestNormal(c.nextInvokeIDAndPriority(false), *att, nil, *dt)
+
+	out, err := req.Encode()
+	if err != nil {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("error encoding %s data: %v", att.String(), err))
+	}
+
+	if len(out) > c.maxPlainPduSize() {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("%s data is too long for an unconfirmed set (%d bytes)", att.String(), len(out)))
+	}
+
+	return c.encodeAndSend(req)
+}
+
+// SetRequestWithStructOfElements sets the attributes, or invokes the methods, of the tagged fields
+// of a struct. The fields of nested structs are not set. With continueOnSetRejected, the fields
+// after a rejected one are set too, and the error has code ErrorSetPartial if some were set.
 func (c *client) SetRequestWithStructOfElements(data interface{}, continueOnSetRejected bool) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
@@ -26,32 +75,31 @@ func (c *client) SetRequestWithStructOfElements(data interface{}, continueOnSetR
 		return dlms.NewError(dlms.ErrorInvalidParameter, "data must be a struct")
 	}
 
+	plan, err := structPlan(v.Type())
+	if err != nil {
+		return err
+	}
+
 	var errSet error
 	isSomethingDone := false
 	isSomethingFailed := false
 
-	for i := 0; i < v.NumField(); i++ {
-		ad, err := c.getAttributeDescriptor(v.Type().Field(i))
-		if err != nil {
-			return err
-		}
-
-		if ad == nil {
+	for _, f := range plan {
+		// Only the fields of the struct itself are set, not the ones of nested structs
+		if len(f.index) > 1 {
 			continue
 		}
 
-		if v.Field(i).Kind() == reflect.Pointer {
-			// All fields need to have been set beforehand: nil fields will be ignored
-			if v.Field(i).IsNil() {
-				continue
-			}
+		field := v.FieldByIndex(f.index)
+		if !f.tag.isSettable(field) {
+			continue
 		}
 
-		err = c.setRequest(ad, v.Field(i).Interface())
+		err = c.setField(f.tag, field)
 		if err != nil {
 			// If a set is rejected, we will continue anyway
 			var dlmsError *dlms.Error
-			if errors.As(err, &dlmsError) && dlmsError.Code() == dlms.ErrorSetRejected && continueOnSetRejected {
+			if errors.As(err, &dlmsError) && (dlmsError.Code() == dlms.ErrorSetRejected || dlmsError.Code() == dlms.ErrorActionRejected) && continueOnSetRejected {
 				isSomethingFailed = true
 			} else {
 				if isSomethingDone {
@@ -79,6 +127,20 @@ func (c *client) setRequest(att *dlms.AttributeDescriptor, data interface{}) (er
 		return dlms.NewError(dlms.ErrorInvalidParameter, "attribute descriptor must be non-nil")
 	}
 
+	err = c.validateSet(att)
+	if err != nil {
+		return
+	}
+
+	if c.settings.Referencing == dlms.ReferencingShortName {
+		return c.writeRequest(att, data)
+	}
+
+	err = c.checkAccepted(dlms.ConformanceBlockSet, "set service")
+	if err != nil {
+		return
+	}
+
 	dt, ok := data.(*axdr.DlmsData)
 	if !ok {
 		dt, err = axdr.MarshalData(data)
@@ -98,7 +160,7 @@ func (c *client) setRequest(att *dlms.AttributeDescriptor, data interface{}) (er
 	}
 
 	if len(out) < (c.settings.MaxPduSendSize - lenHeader) {
-		req := dlms.CreateSetRequestNormal(unicastInvokeID, *att, nil, *dt)
+		req := dlms.CreateSetRequestNormal(c.nextInvokeIDAndPriority(true), *att, nil, *dt)
 
 		pdu, err := c.encodeSendReceiveAndDecode(req)
 		if err != nil {
@@ -111,9 +173,14 @@ func (c *client) setRequest(att *dlms.AttributeDescriptor, data interface{}) (er
 		}
 
 		if resp.Result != dlms.TagAccSuccess {
-			return dlms.NewError(dlms.ErrorSetRejected, fmt.Sprintf("set %s rejected: %s", att.String(), resp.Result.String()))
+			return dlms.NewRejectedError(dlms.ErrorSetRejected, fmt.Sprintf("set %s rejected: %s", att.String(), resp.Result.String()), resp.Result)
 		}
 	} else {
+		err = c.checkAccepted(dlms.ConformanceBlockBlockTransferWithSetOrWrite, fmt.Sprintf("block transfer with set of %s", att.String()))
+		if err != nil {
+			return
+		}
+
 		return c.setRequestWithDataBlock(att, out)
 	}
 
@@ -124,6 +191,7 @@ func (c *client) setRequestWithDataBlock(att *dlms.AttributeDescriptor, out []by
 	isLastBlock := false
 	isFirstBlock := true
 	blockNumber := uint32(1)
+	invokeID := c.nextInvokeIDAndPriority(true)
 
 	for {
 		lenHeader := 11
@@ -145,9 +213,9 @@ func (c *client) setRequestWithDataBlock(att *dlms.AttributeDescriptor, out []by
 		var req dlms.CosemPDU
 
 		if isFirstBlock {
-			req = dlms.CreateSetRequestWithFirstDataBlock(unicastInvokeID, *att, nil, *db)
+			req = dlms.CreateSetRequestWithFirstDataBlock(invokeID, *att, nil, *db)
 		} else {
-			req = dlms.CreateSetRequestWithDataBlock(unicastInvokeID, *db)
+			req = dlms.CreateSetRequestWithDataBlock(invokeID, *db)
 		}
 
 		pdu, err := c.encodeSendReceiveAndDecode(req)
@@ -166,7 +234,7 @@ func (c *client) setRequestWithDataBlock(att *dlms.AttributeDescriptor, out []by
 			}
 
 			if resp.Result != dlms.TagAccSuccess {
-				return dlms.NewError(dlms.ErrorSetRejected, fmt.Sprintf("set %s rejected: %s", att.String(), resp.Result.String()))
+				return dlms.NewRejectedError(dlms.ErrorSetRejected, fmt.Sprintf("set %s rejected: %s", att.String(), resp.Result.String()), resp.Result)
 			}
 
 			return nil
@@ -0,0 +1,177 @@
+package dlmsclient
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+// obisTag is the parsed obis tag of a field. See dlms.ObisTag.
+type obisTag dlms.ObisTag
+
+// structField is a field of a struct of elements with an obis tag. Name includes the names of the
+// enclosing fields for nested structs, and index is the index sequence of reflect.Value.FieldByIndex.
+type structField struct {
+	name  string
+	index []int
+	tag   obisTag
+}
+
+// structPlan returns the fields with an obis tag of a struct type, including the ones of nested
+// structs without tag. See dlms.ObisFields.
+func structPlan(t reflect.Type) ([]structField, error) {
+	fields, err := dlms.ObisFields(t)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := make([]structField, len(fields))
+	for i, f := range fields {
+		plan[i] = structField{name: f.Name, index: f.Index, tag: obisTag(f.Tag)}
+	}
+
+	return plan, nil
+}
+
+// isGettable returns false if the field is not read.
+func (ot obisTag) isGettable() bool {
+	return !ot.WriteOnly && ot.Method == nil
+}
+
+// isSettable returns false if the field is not written with the given value.
+func (ot obisTag) isSettable(value reflect.Value) bool {
+	if ot.ReadOnly || ot.Scaled {
+		return false
+	}
+
+	// All fields need to have been set beforehand: nil fields will be ignored
+	if value.Kind() == reflect.Ptr && value.IsNil() {
+		return false
+	}
+
+	return !ot.OmitEmpty || !value.IsZero()
+}
+
+// selectiveAccess returns the selective access of the range option, or nil.
+func (ot obisTag) selectiveAccess() *dlms.SelectiveAccessDescriptor {
+	if ot.Last == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	return dlms.CreateSelectiveAccessByRangeDescriptor(now.Add(-ot.Last), now, nil)
+}
+
+// getField reads a field with the options of its tag.
+func (c *client) getField(ot obisTag, field reflect.Value) error {
+	if !ot.Scaled {
+		return c.getRequestWithUnmarshal(ot.Attribute, ot.selectiveAccess(), field.Addr().Interface())
+	}
+
+	var data axdr.DlmsData
+	err := c.getRequestWithUnmarshal(ot.Attribute, ot.selectiveAccess(), &data)
+	if err != nil {
+		return err
+	}
+
+	return storeField(ot, field, data)
+}
+
+// fieldGetter returns a function reading a field like getField, except for the fields of a list,
+// which are read together with a get request with a list when the first of them is read.
+func (c *client) fieldGetter(plan []structField) func(f structField, field reflect.Value) error {
+	type listResult struct {
+		data axdr.DlmsData
+		err  error
+	}
+	results := make(map[string]listResult)
+
+	return func(f structField, field reflect.Value) error {
+		if f.tag.List == "" {
+			return c.getField(f.tag, field)
+		}
+
+		result, ok := results[f.name]
+		if !ok {
+			var list []structField
+			for _, lf := range plan {
+				if lf.tag.List == f.tag.List && lf.tag.isGettable() {
+					list = append(list, lf)
+				}
+			}
+
+			atts := make([]*dlms.AttributeDescriptor, len(list))
+			values := make([]axdr.DlmsData, len(list))
+			data := make([]interface{}, len(list))
+			for i, lf := range list {
+				atts[i] = lf.tag.Attribute
+				data[i] = &values[i]
+			}
+
+			errs, err := c.getRequestWithList(atts, data)
+			if err != nil {
+				return err
+			}
+
+			for i, lf := range list {
+				results[lf.name] = listResult{data: values[i], err: errs[i]}
+			}
+			result = results[f.name]
+		}
+
+		if result.err != nil {
+			return result.err
+		}
+
+		return storeField(f.tag, field, result.data)
+	}
+}
+
+// storeField stores the data got in a field, with the options of its tag.
+func storeField(ot obisTag, field reflect.Value, data axdr.DlmsData) error {
+	if !ot.Scaled {
+		return unmarshalData(ot.Attribute, data, field.Addr().Interface())
+	}
+
+	value, ok := numberValue(data.Value)
+	if !ok {
+		return dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("%s data is not a number: %v", ot.Attribute.String(), data.Value))
+	}
+
+	if field.Kind() == reflect.Ptr {
+		field.Set(reflect.New(field.Type().Elem()))
+		field = field.Elem()
+	}
+
+	field.SetFloat(value * math.Pow10(ot.Scale))
+	return nil
+}
+
+// setField sets the attribute of a field, or invokes its method, with the value of the field.
+func (c *client) setField(ot obisTag, field reflect.Value) error {
+	if ot.Method != nil {
+		_, err := c.actionRequest(ot.Method, field.Interface())
+		return err
+	}
+
+	return c.setRequest(ot.Attribute, field.Interface())
+}
+
+func numberValue(value interface{}) (float64, bool) {
+	rv := reflect.ValueOf(value)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,284 @@
+package dlmsclient_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestClient_GetRequestWithStructOfElementsOptions(t *testing.T) {
+	var data struct {
+		Value1 uint    `obis:"1,1-1:94.34.100.255,2,omitempty"`
+		Value2 uint    `obis:"1,1-1:94.34.104.255,2,writeonly"`
+		Value3 float64 `obis:"3,1-0:1.8.0.255,2,scale=-2"`
+		Value4 uint    `obis:"1,0-0:96.3.10.255,2,readonly"`
+	}
+
+	data.Value1 = 7
+	data.Value2 = 8
+
+	c, tm, rdc := associate(t)
+
+	sendReceive(tm, rdc, "C001C1000101015E2264FF0200", "C401C10104")
+	sendReceive(tm, rdc, "C001C200030100010800FF0200", "C401C200121CE2")
+	sendReceive(tm, rdc, "C001C30001000060030AFF0200", "C401C3001101")
+	err := c.GetRequestWithStructOfElements(&data)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(0), data.Value1)
+	assert.Equal(t, uint(8), data.Value2)
+	assert.InDelta(t, 73.94, data.Value3, 1e-9)
+	assert.Equal(t, uint(1), data.Value4)
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_GetRequestWithStructOfElementsRange(t *testing.T) {
+	var data struct {
+		Buffer []uint `obis:"7,1-0:99.1.0.255,2,range=last1d"`
+	}
+
+	c, tm, rdc := associate(t)
+
+	var sent []byte
+	tm.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+		sent = args.Get(0).([]byte)
+		rdc <- decodeHexString("C401C100010211011102")
+	}).Return(nil).Once()
+
+	err := c.GetRequestWithStructOfElements(&data)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{1, 2}, data.Buffer)
+
+	pdu, err := dlms.DecodeCosem(&sent)
+	assert.NoError(t, err)
+	req, ok := pdu.(dlms.GetRequestNormal)
+	assert.True(t, ok)
+	assert.Equal(t, *dlms.CreateAttributeDescriptor(7, "1-0:99.1.0.255", 2), req.AttributeInfo)
+	assert.NotNil(t, req.SelectiveAccessInfo)
+	assert.Equal(t, dlms.AccessSelectorRange, req.SelectiveAccessInfo.AccessSelector)
+
+	parameters := req.SelectiveAccessInfo.AccessParameter.Value.([]*axdr.DlmsData)
+	var from, to time.Time
+	assert.NoError(t, axdr.UnmarshalData(*parameters[1], &from))
+	assert.NoError(t, axdr.UnmarshalData(*parameters[2], &to))
+	assert.Equal(t, 24*time.Hour, to.Sub(from).Round(time.Second))
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_SetRequestWithStructOfElementsOptions(t *testing.T) {
+	data := struct {
+		Value1 uint16  `obis:"3,0-1:94.35.11.255,2,omitempty"`
+		Value2 uint16  `obis:"1,1-1:94.34.104.255,2,readonly"`
+		Value3 float64 `obis:"3,1-0:1.8.0.255,2,scale=-2"`
+		Value4 uint16  `obis:"3,0-1:94.35.12.255,2,omitempty,writeonly"`
+	}{
+		Value1: 0,
+		Value2: 12345,
+		Value3: 73.94,
+		Value4: 6789,
+	}
+
+	c, tm, rdc := associate(t)
+
+	sendReceive(tm, rdc, "C101C1000300015E230CFF0200121A85", "C501C100")
+	err := c.SetRequestWithStructOfElements(data, false)
+	assert.NoError(t, err)
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_CheckRequestWithStructOfElementsOptions(t *testing.T) {
+	data := struct {
+		Value1 uint16  `obis:"3,0-1:94.35.11.255,2,omitempty"`
+		Value2 uint16  `obis:"1,1-1:94.34.104.255,2,writeonly"`
+		Value3 float64 `obis:"3,1-0:1.8.0.255,2,scale=-2"`
+	}{
+		Value1: 0,
+		Value2: 12345,
+		Value3: 73.94,
+	}
+
+	c, tm, rdc := associate(t)
+
+	sendReceive(tm, rdc, "C001C100030100010800FF0200", "C401C100121CE2")
+	err := c.CheckRequestWithStructOfElements(&data)
+	assert.NoError(t, err)
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_StructOfElementsInvalidTags(t *testing.T) {
+	c, tm, _ := associate(t)
+
+	var clientError *dlms.Error
+
+	var unknownOption struct {
+		Value uint `obis:"1,1-1:94.34.100.255,2,nocache"`
+	}
+	err := c.GetRequestWithStructOfElements(&unknownOption)
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidParameter, clientError.Code())
+
+	var invalidRange struct {
+		Value []uint `obis:"7,1-0:99.1.0.255,2,range=yesterday"`
+	}
+	err = c.GetRequestWithStructOfElements(&invalidRange)
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidParameter, clientError.Code())
+
+	var scaledInteger struct {
+		Value int `obis:"3,1-0:1.8.0.255,2,scale=3"`
+	}
+	err = c.GetRequestWithStructOfElements(&scaledInteger)
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidParameter, clientError.Code())
+
+	var readAndWriteOnly struct {
+		Value uint `obis:"1,1-1:94.34.100.255,2,readonly,writeonly"`
+	}
+	err = c.SetRequestWithStructOfElements(readAndWriteOnly, false)
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidParameter, clientError.Code())
+
+	var unexported struct {
+		value uint `obis:"1,1-1:94.34.100.255,2"`
+	}
+	_, err = c.GetRequestWithStructOfElementsReport(&unexported, dlms.ElementsBestEffort)
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidParameter, clientError.Code())
+	_ = unexported.value
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_SetRequestWithStructOfElementsNested(t *testing.T) {
+	type nested struct {
+		Value2 uint16 `obis:"3,0-1:94.35.12.255,2"`
+	}
+
+	data := struct {
+		Value1 uint16 `obis:"3,0-1:94.35.11.255,2"`
+		Nested nested
+	}{
+		Value1: 6789,
+		Nested: nested{Value2: 12345},
+	}
+
+	c, tm, rdc := associate(t)
+
+	// The fields of nested structs are not set, with or without the report
+	sendReceive(tm, rdc, "C101C1000300015E230BFF0200121A85", "C501C100")
+	err := c.SetRequestWithStructOfElements(data, false)
+	assert.NoError(t, err)
+
+	sendReceive(tm, rdc, "C101C2000300015E230BFF0200121A85", "C501C200")
+	report, err := c.SetRequestWithStructOfElementsReport(data, dlms.ElementsFailFast)
+	assert.NoError(t, err)
+	assert.Len(t, report.Results, 2)
+	assert.Equal(t, dlms.ElementSucceeded, report.Results[0].Outcome)
+	assert.Equal(t, "Nested.Value2", report.Results[1].Field)
+	assert.Equal(t, dlms.ElementSkipped, report.Results[1].Outcome)
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_StructOfElementsMethods(t *testing.T) {
+	data := struct {
+		Value  uint16 `obis:"3,0-1:94.35.11.255,2"`
+		Reset  int8   `obis:"70,0-0:96.3.10.255,m1"`
+		Remote *int8  `obis:"70,0-0:96.3.10.255,m2"`
+	}{
+		Value: 6789,
+	}
+
+	c, tm, rdc := associate(t)
+
+	// The method is invoked with the value of the field, and nil fields are skipped
+	sendReceive(tm, rdc, "C101C1000300015E230BFF0200121A85", "C501C100")
+	sendReceive(tm, rdc, "C301C20046000060030AFF01010F00", "C701C20000")
+	err := c.SetRequestWithStructOfElements(data, false)
+	assert.NoError(t, err)
+
+	// Methods are not got
+	sendReceive(tm, rdc, "C001C3000300015E230BFF0200", "C401C300121A85")
+	err = c.GetRequestWithStructOfElements(&data)
+	assert.NoError(t, err)
+
+	// A rejected method is reported with its descriptor
+	sendReceive(tm, rdc, "C101C4000300015E230BFF0200121A85", "C501C400")
+	sendReceive(tm, rdc, "C301C50046000060030AFF01010F00", "C701C50300")
+	report, err := c.SetRequestWithStructOfElementsReport(data, dlms.ElementsBestEffort)
+	assert.NoError(t, err)
+	assert.Len(t, report.Results, 3)
+	assert.Equal(t, dlms.ElementSucceeded, report.Results[0].Outcome)
+	assert.Nil(t, report.Results[0].Method)
+	assert.Equal(t, "Reset", report.Results[1].Field)
+	assert.Equal(t, dlms.CreateMethodDescriptor(70, "0-0:96.3.10.255", 1), report.Results[1].Method)
+	assert.Zero(t, report.Results[1].Attribute)
+	assert.Equal(t, dlms.ElementRejected, report.Results[1].Outcome)
+	assert.Zero(t, report.Results[1].Result)
+	assert.Equal(t, dlms.ElementSkipped, report.Results[2].Outcome)
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_GetRequestWithStructOfElementsList(t *testing.T) {
+	var data struct {
+		Value1 uint32  `obis:"3,1-0:1.8.0.255,2,list=energy"`
+		Value2 uint16  `obis:"3,0-1:94.35.11.255,2"`
+		Value3 float64 `obis:"3,1-0:2.8.0.255,2,list=energy,scale=-1"`
+	}
+
+	settings, _ := dlms.NewSettingsWithoutAuthentication()
+	settings.ConformanceBlock |= dlms.ConformanceBlockMultipleReferences
+	c, tm, rdc := associateWith(t, settings, 5*time.Second,
+		"601DA109060760857405080101BE10040E01000000065F1F0400001A1F0100",
+		"6129A109060760857405080101A203020100A305A103020100BE10040E0800065F1F0400001A1D00800007")
+
+	// The fields of the list are got together when the first one is got
+	sendReceive(tm, rdc, "C003C10200030100010800FF020000030100020800FF0200", "C403C10200060000000A000600000014")
+	sendReceive(tm, rdc, "C001C2000300015E230BFF0200", "C401C200121A85")
+	err := c.GetRequestWithStructOfElements(&data)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(10), data.Value1)
+	assert.Equal(t, uint16(6789), data.Value2)
+	assert.InDelta(t, 2.0, data.Value3, 1e-9)
+
+	// A field rejected in the list
+	sendReceive(tm, rdc, "C003C30200030100010800FF020000030100020800FF0200", "C403C30200060000000B0104")
+	sendReceive(tm, rdc, "C001C4000300015E230BFF0200", "C401C400121A85")
+	report, err := c.GetRequestWithStructOfElementsReport(&data, dlms.ElementsBestEffort)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(11), data.Value1)
+	assert.Equal(t, dlms.ElementRejected, report.Results[2].Outcome)
+	assert.Equal(t, dlms.TagAccObjectUndefined, report.Results[2].Result)
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_GetRequestWithStructOfElementsListWithoutMultipleReferences(t *testing.T) {
+	var data struct {
+		Value1 uint32 `obis:"3,1-0:1.8.0.255,2,list=energy"`
+		Value2 uint16 `obis:"3,0-1:94.35.11.255,2"`
+		Value3 uint32 `obis:"3,1-0:2.8.0.255,2,list=energy"`
+	}
+
+	c, tm, rdc := associate(t)
+
+	// Not accepted by the server, the fields of the list are got with a get each
+	sendReceive(tm, rdc, "C001C100030100010800FF0200", "C401C100060000000A")
+	sendReceive(tm, rdc, "C001C200030100020800FF0200", "C401C2000600000014")
+	sendReceive(tm, rdc, "C001C3000300015E230BFF0200", "C401C300121A85")
+	err := c.GetRequestWithStructOfElements(&data)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(10), data.Value1)
+	assert.Equal(t, uint16(6789), data.Value2)
+	assert.Equal(t, uint32(20), data.Value3)
+
+	tm.AssertExpectations(t)
+}
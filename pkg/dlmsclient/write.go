@@ -0,0 +1,203 @@
+package dlmsclient
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+// WriteRequest writes an attribute with short name referencing. The short name is resolved with
+// the object list of the Association SN, that is read once per association.
+func (c *client) WriteRequest(att *dlms.AttributeDescriptor, data interface{}) (err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.writeRequest(att, data)
+}
+
+// UnconfirmedWriteRequest writes an attribute with short name referencing, without waiting for
+// the result. The request must fit in a single PDU.
+func (c *client) UnconfirmedWriteRequest(att *dlms.AttributeDescriptor, data interface{}) (err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.unconfirmedWriteRequest(att, data)
+}
+
+func (c *client) unconfirmedWriteRequest(att *dlms.AttributeDescriptor, data interface{}) (err error) {
+	err = c.checkAccepted(dlms.ConformanceBlockUnconfirmedWrite, "unconfirmed write service")
+	if err != nil {
+		return
+	}
+
+	variable, dt, err := c.writeVariable(att, data)
+	if err != nil {
+		return
+	}
+
+	req := dlms.CreateUnconfirmedWriteRequest([]dlms.VariableAccessSpecification{*variable}, []axdr.DlmsData{*dt})
+
+	out, err := req.Encode()
+	if err != nil {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("error encoding %s data: %v", att.String(), err))
+	}
+
+	if len(out) > c.maxPlainPduSize() {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("%s data is too long for an unconfirmed write (%d bytes)", att.String(), len(out)))
+	}
+
+	return c.encodeAndSend(req)
+}
+
+func (c *client) writeRequest(att *dlms.AttributeDescriptor, data interface{}) (err error) {
+	err = c.checkAccepted(dlms.ConformanceBlockWrite, "write service")
+	if err != nil {
+		return
+	}
+
+	variable, dt, err := c.writeVariable(att, data)
+	if err != nil {
+		return
+	}
+
+	req := dlms.CreateWriteRequest([]dlms.VariableAccessSpecification{*variable}, []axdr.DlmsData{*dt})
+
+	out, err := req.Encode()
+	if err != nil {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("error encoding %s data: %v", att.String(), err))
+	}
+
+	if len(out) > c.maxPlainPduSize() {
+		err = c.checkAccepted(dlms.ConformanceBlockBlockTransferWithSetOrWrite, fmt.Sprintf("block transfer with write of %s", att.String()))
+		if err != nil {
+			return
+		}
+
+		// The blocks hold the encoded WriteRequest without its tag
+		return c.writeRequestWithDataBlock(att, out[1:])
+	}
+
+	result, err := c.writeResult(att, req)
+	if err != nil {
+		return
+	}
+
+	return checkWriteResult(att, result)
+}
+
+// writeVariable resolves the short name of an attribute and marshals the data to write.
+func (c *client) writeVariable(att *dlms.AttributeDescriptor, data interface{}) (*dlms.VariableAccessSpecification, *axdr.DlmsData, error) {
+	if att == nil {
+		return nil, nil, dlms.NewError(dlms.ErrorInvalidParameter, "attribute descriptor must be non-nil")
+	}
+
+	dt, ok := data.(*axdr.DlmsData)
+	if !ok {
+		var err error
+		dt, err = axdr.MarshalData(data)
+		if err != nil {
+			return nil, nil, dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("error marshaling %s data: %v", att.String(), err))
+		}
+	}
+
+	name, ok, err := c.shortName(att)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !ok {
+		return nil, nil, dlms.NewRejectedError(dlms.ErrorSetRejected, fmt.Sprintf("write %s rejected: object not in the short name list", att.String()), dlms.TagAccObjectUndefined)
+	}
+
+	return dlms.CreateVariableName(name), dt, nil
+}
+
+func (c *client) writeRequestWithDataBlock(att *dlms.AttributeDescriptor, out []byte) error {
+	isLastBlock := false
+	blockNumber := uint16(1)
+
+	for {
+		// Tag, variable with the block access, and the octet string with its length
+		lenHeader := 12
+		if c.settings.Ciphering.Level != dlms.SecurityLevelNone {
+			lenHeader += 21
+		}
+
+		blockSize := c.settings.MaxPduSendSize - lenHeader
+		if blockSize >= len(out) {
+			blockSize = len(out)
+			isLastBlock = true
+		}
+
+		req := dlms.CreateWriteRequest(
+			[]dlms.VariableAccessSpecification{*dlms.CreateWriteDataBlockAccess(isLastBlock, blockNumber)},
+			[]axdr.DlmsData{*axdr.CreateAxdrOctetString(hex.EncodeToString(out[:blockSize]))},
+		)
+
+		result, err := c.writeResult(att, req)
+		if err != nil {
+			return err
+		}
+
+		if isLastBlock {
+			return checkWriteResult(att, result)
+		}
+
+		switch result.Tag {
+		case dlms.TagWriteResultBlockNumber:
+			if result.BlockNumber != blockNumber {
+				return dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("in %s unexpected block number %d (expected %d)", att.String(), result.BlockNumber, blockNumber))
+			}
+		case dlms.TagWriteResultDataAccessError:
+			return checkWriteResult(att, result)
+		default:
+			return dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("in %s expected block number %d, got write result %d", att.String(), blockNumber, result.Tag))
+		}
+
+		out = out[blockSize:]
+		blockNumber++
+	}
+}
+
+// writeResult sends a WriteRequest with a single variable and returns its result.
+func (c *client) writeResult(att *dlms.AttributeDescriptor, req *dlms.WriteRequest) (result dlms.WriteResult, err error) {
+	pdu, err := c.encodeSendReceiveAndDecode(req)
+	if err != nil {
+		return
+	}
+
+	resp, ok := pdu.(dlms.WriteResponse)
+	if !ok {
+		err = dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("in %s unexpected PDU response type: %T", att.String(), pdu))
+		return
+	}
+
+	if len(resp.Results) != 1 {
+		err = dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("in %s expected 1 write result, got %d", att.String(), len(resp.Results)))
+		return
+	}
+
+	return resp.Results[0], nil
+}
+
+func checkWriteResult(att *dlms.AttributeDescriptor, result dlms.WriteResult) error {
+	switch result.Tag {
+	case dlms.TagWriteResultSuccess:
+		return nil
+	case dlms.TagWriteResultDataAccessError:
+		return dlms.NewRejectedError(dlms.ErrorSetRejected, fmt.Sprintf("write %s rejected: %s", att.String(), result.Access.String()), result.Access)
+	default:
+		return dlms.NewError(dlms.ErrorInvalidResponse, fmt.Sprintf("in %s unexpected write result %d", att.String(), result.Tag))
+	}
+}
+
+// maxPlainPduSize returns the maximum size of a request before ciphering.
+func (c *client) maxPlainPduSize() int {
+	if c.settings.Ciphering.Level != dlms.SecurityLevelNone {
+		return c.settings.MaxPduSendSize - 21
+	}
+
+	return c.settings.MaxPduSendSize
+}
@@ -0,0 +1,84 @@
+package dlmsclient_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_WriteRequest(t *testing.T) {
+	c, tm, rdc := associateWithShortNames(t)
+
+	sendReceive(tm, rdc, readShortNameList, shortNameListResponse)
+	sendReceive(tm, rdc, "060102011801060000000A", "0D0100")
+	err := c.WriteRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), uint32(10))
+	assert.NoError(t, err)
+
+	// Set requests are sent as write requests
+	sendReceive(tm, rdc, "060102011801060000000A", "0D010103")
+	err = c.SetRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), uint32(10))
+	var clientError *dlms.Error
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorSetRejected, clientError.Code())
+
+	// Object not in the short name list
+	err = c.WriteRequest(dlms.CreateAttributeDescriptor(3, "1.0.2.8.0.255", 2), uint32(10))
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorSetRejected, clientError.Code())
+
+	// Unexpected response
+	sendReceive(tm, rdc, "060102011801060000000A", "0C01000600003039")
+	err = c.WriteRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), uint32(10))
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_UnconfirmedWriteRequest(t *testing.T) {
+	c, tm, rdc := associateWithShortNames(t)
+
+	sendReceive(tm, rdc, readShortNameList, shortNameListResponse)
+	sendReceive(tm, nil, "160102011801060000000A", "")
+	err := c.UnconfirmedWriteRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), uint32(10))
+	assert.NoError(t, err)
+
+	err = c.UnconfirmedWriteRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), strings.Repeat("AB", 150))
+	var clientError *dlms.Error
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidParameter, clientError.Code())
+
+	tm.AssertExpectations(t)
+}
+
+func TestClient_WriteRequestWithDataBlock(t *testing.T) {
+	c, tm, rdc := associateWithShortNames(t)
+
+	// The blocks hold the WriteRequest without its tag: 158 bytes, split in 116 and 42 bytes
+	value := strings.Repeat("AB", 150)
+	body := "0102011801098196" + value
+
+	sendReceive(tm, rdc, readShortNameList, shortNameListResponse)
+	sendReceive(tm, rdc, "060107000001010974"+body[:232], "0D01020001")
+	sendReceive(tm, rdc, "060107FF000201092A"+body[232:], "0D0100")
+	err := c.WriteRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), value)
+	assert.NoError(t, err)
+
+	// Invalid block number
+	sendReceive(tm, rdc, "060107000001010974"+body[:232], "0D01020002")
+	err = c.WriteRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), value)
+	var clientError *dlms.Error
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorInvalidResponse, clientError.Code())
+
+	// Write failed in the last block
+	sendReceive(tm, rdc, "060107000001010974"+body[:232], "0D01020001")
+	sendReceive(tm, rdc, "060107FF000201092A"+body[232:], "0D010103")
+	err = c.WriteRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), value)
+	assert.ErrorAs(t, err, &clientError)
+	assert.Equal(t, dlms.ErrorSetRejected, clientError.Code())
+
+	tm.AssertExpectations(t)
+}
@@ -0,0 +1,193 @@
+package dlmsserver
+
+import (
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const (
+	// Header of ActionResponseNormal: tag, choice, invoke id, result, return parameters flag and choice
+	actionResponseNormalHeader = 6
+	// Header of ActionResponseWithPBlock: tag, choice, invoke id, last block, block number and length
+	actionResponseBlockHeader = 12
+)
+
+func (s *server) action(pdu dlms.CosemPDU) dlms.CosemPDU {
+	switch req := pdu.(type) {
+	case dlms.ActionRequestNormal:
+		s.actionTransfer = nil
+
+		data, result := s.registry.action(req.MethodInfo, req.MethodParam)
+		return s.actionResponse(req.InvokePriority, result, data)
+
+	case dlms.ActionRequestWithFirstPBlock:
+		s.actionTransfer = nil
+
+		if !s.isAccepted(dlms.ConformanceBlockBlockTransferWithAction) {
+			return nil
+		}
+
+		if req.PBlock.BlockNumber != 1 {
+			return dlms.CreateActionResponseNormal(req.InvokePriority, *dlms.CreateActResponse(dlms.TagActOtherReason, nil))
+		}
+
+		s.actionTransfer = &blockTransfer{methods: []dlms.MethodDescriptor{req.MethodInfo}}
+
+		return s.actionBlock(req.InvokePriority, req.PBlock)
+
+	case dlms.ActionRequestWithListAndFirstPBlock:
+		s.actionTransfer = nil
+
+		if !s.isAccepted(dlms.ConformanceBlockBlockTransferWithAction) || !s.isAccepted(dlms.ConformanceBlockMultipleReferences) {
+			return nil
+		}
+
+		if req.PBlock.BlockNumber != 1 {
+			return dlms.CreateActionResponseNormal(req.InvokePriority, *dlms.CreateActResponse(dlms.TagActOtherReason, nil))
+		}
+
+		s.actionTransfer = &blockTransfer{isList: true, methods: req.MethodInfoList}
+
+		return s.actionBlock(req.InvokePriority, req.PBlock)
+
+	case dlms.ActionRequestWithPBlock:
+		if s.actionTransfer == nil || len(s.actionTransfer.methods) == 0 {
+			s.actionTransfer = nil
+			return dlms.CreateActionResponseNormal(req.InvokePriority, *dlms.CreateActResponse(dlms.TagActNoLongActionInProgress, nil))
+		}
+
+		if req.PBlock.BlockNumber != s.actionTransfer.blockNumber+1 {
+			s.actionTransfer = nil
+			return dlms.CreateActionResponseNormal(req.InvokePriority, *dlms.CreateActResponse(dlms.TagActLongActionAborted, nil))
+		}
+
+		return s.actionBlock(req.InvokePriority, req.PBlock)
+
+	case dlms.ActionRequestNextPBlock:
+		if s.actionTransfer == nil || len(s.actionTransfer.methods) != 0 {
+			s.actionTransfer = nil
+			return dlms.CreateActionResponseNormal(req.InvokePriority, *dlms.CreateActResponse(dlms.TagActNoLongActionInProgress, nil))
+		}
+
+		if req.BlockNum != s.actionTransfer.blockNumber {
+			s.actionTransfer = nil
+			return dlms.CreateActionResponseNormal(req.InvokePriority, *dlms.CreateActResponse(dlms.TagActLongActionAborted, nil))
+		}
+
+		return s.actionNextBlock(req.InvokePriority)
+
+	case dlms.ActionRequestWithList:
+		s.actionTransfer = nil
+
+		if !s.isAccepted(dlms.ConformanceBlockMultipleReferences) {
+			return nil
+		}
+
+		return dlms.CreateActionResponseWithList(req.InvokePriority, s.actionList(req.MethodInfoList, req.MethodParamList))
+	}
+
+	return nil
+}
+
+// actionBlock accumulates the parameters received in blocks. When the last one is received the method is invoked.
+func (s *server) actionBlock(invokePriority uint8, block dlms.DataBlockSA) dlms.CosemPDU {
+	t := s.actionTransfer
+	t.blockNumber = block.BlockNumber
+	t.raw = append(t.raw, block.Raw...)
+
+	if !block.LastBlock {
+		return dlms.CreateActionResponseNextPBlock(invokePriority, t.blockNumber)
+	}
+
+	s.actionTransfer = nil
+
+	params, err := decodeDataList(t.raw, t.isList)
+	if err != nil || len(params) != len(t.methods) {
+		s.logf("error decoding action parameters: %v", err)
+		params = nil
+	}
+
+	if !t.isList {
+		if params == nil {
+			return dlms.CreateActionResponseNormal(invokePriority, *dlms.CreateActResponse(dlms.TagActTypeUnmatched, nil))
+		}
+
+		data, result := s.registry.action(t.methods[0], &params[0])
+		return s.actionResponse(invokePriority, result, data)
+	}
+
+	if params == nil {
+		results := make([]dlms.ActResponse, len(t.methods))
+		for i := range results {
+			results[i] = *dlms.CreateActResponse(dlms.TagActTypeUnmatched, nil)
+		}
+
+		return dlms.CreateActionResponseWithList(invokePriority, results)
+	}
+
+	return dlms.CreateActionResponseWithList(invokePriority, s.actionList(t.methods, params))
+}
+
+// actionResponse builds the response of a single method. If the returned data doesn't fit in a PDU, it's sent in blocks.
+func (s *server) actionResponse(invokePriority uint8, result dlms.ActionResultTag, data *axdr.DlmsData) dlms.CosemPDU {
+	if data == nil {
+		return dlms.CreateActionResponseNormal(invokePriority, *dlms.CreateActResponse(result, nil))
+	}
+
+	raw, err := data.Encode()
+	if err != nil {
+		s.logf("error encoding action return parameters: %v", err)
+		return dlms.CreateActionResponseNormal(invokePriority, *dlms.CreateActResponse(dlms.TagActOtherReason, nil))
+	}
+
+	if len(raw) <= s.maxBlockSize(actionResponseNormalHeader) {
+		return dlms.CreateActionResponseNormal(invokePriority, *dlms.CreateActResponse(result, dlms.CreateGetDataResultAsData(*data)))
+	}
+
+	if !s.isAccepted(dlms.ConformanceBlockBlockTransferWithAction) {
+		return dlms.CreateActionResponseNormal(invokePriority, *dlms.CreateActResponse(dlms.TagActOtherReason, nil))
+	}
+
+	s.actionTransfer = &blockTransfer{raw: raw}
+	return s.actionNextBlock(invokePriority)
+}
+
+func (s *server) actionNextBlock(invokePriority uint8) dlms.CosemPDU {
+	t := s.actionTransfer
+
+	size := s.maxBlockSize(actionResponseBlockHeader)
+	lastBlock := len(t.raw) <= size
+	if lastBlock {
+		size = len(t.raw)
+	}
+
+	t.blockNumber++
+	block := dlms.CreateDataBlockSA(lastBlock, t.blockNumber, t.raw[:size])
+	t.raw = t.raw[size:]
+
+	if lastBlock {
+		s.actionTransfer = nil
+	}
+
+	return dlms.CreateActionResponseWithPBlock(invokePriority, *block)
+}
+
+func (s *server) actionList(methods []dlms.MethodDescriptor, params []axdr.DlmsData) []dlms.ActResponse {
+	results := make([]dlms.ActResponse, len(methods))
+
+	for i, mth := range methods {
+		var param *axdr.DlmsData
+		if i < len(params) {
+			param = &params[i]
+		}
+
+		data, result := s.registry.action(mth, param)
+		if data != nil {
+			results[i] = *dlms.CreateActResponse(result, dlms.CreateGetDataResultAsData(*data))
+		} else {
+			results[i] = *dlms.CreateActResponse(result, nil)
+		}
+	}
+
+	return results
+}
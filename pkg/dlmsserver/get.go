@@ -0,0 +1,119 @@
+package dlmsserver
+
+import (
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const (
+	// Header of GetResponseNormal: tag, choice, invoke id and result choice
+	getResponseNormalHeader = 4
+	// Header of GetResponseWithDataBlock: tag, choice, invoke id, last block, block number, result choice and length
+	getResponseBlockHeader = 13
+)
+
+// blockTransfer holds the state of a transfer split in several blocks.
+type blockTransfer struct {
+	blockNumber uint32
+	raw         []byte
+	isList      bool
+	attributes  []dlms.AttributeDescriptorWithSelection
+	methods     []dlms.MethodDescriptor
+}
+
+func (s *server) get(pdu dlms.CosemPDU) dlms.CosemPDU {
+	switch req := pdu.(type) {
+	case dlms.GetRequestNormal:
+		s.getTransfer = nil
+
+		data, result := s.registry.get(req.AttributeInfo, req.SelectiveAccessInfo)
+		if result != dlms.TagAccSuccess {
+			return dlms.CreateGetResponseNormal(req.InvokePriority, *dlms.CreateGetDataResultAsResult(result))
+		}
+
+		raw, err := data.Encode()
+		if err != nil {
+			s.logf("error encoding %s data: %v", req.AttributeInfo.String(), err)
+			return dlms.CreateGetResponseNormal(req.InvokePriority, *dlms.CreateGetDataResultAsResult(dlms.TagAccOtherReason))
+		}
+
+		if len(raw) <= s.maxBlockSize(getResponseNormalHeader) {
+			return dlms.CreateGetResponseNormal(req.InvokePriority, *dlms.CreateGetDataResultAsData(data))
+		}
+
+		if !s.isAccepted(dlms.ConformanceBlockBlockTransferWithGetOrRead) {
+			return dlms.CreateGetResponseNormal(req.InvokePriority, *dlms.CreateGetDataResultAsResult(dlms.TagAccOtherReason))
+		}
+
+		s.getTransfer = &blockTransfer{raw: raw}
+		return s.getNextBlock(req.InvokePriority)
+
+	case dlms.GetRequestNext:
+		if s.getTransfer == nil {
+			return dlms.CreateGetResponseWithDataBlock(req.InvokePriority, *dlms.CreateDataBlockGAsResult(true, req.BlockNum, dlms.TagAccNoLongGetInProgress))
+		}
+
+		if req.BlockNum != s.getTransfer.blockNumber {
+			s.getTransfer = nil
+			return dlms.CreateGetResponseWithDataBlock(req.InvokePriority, *dlms.CreateDataBlockGAsResult(true, req.BlockNum, dlms.TagAccDataBlockNumberInvalid))
+		}
+
+		return s.getNextBlock(req.InvokePriority)
+
+	case dlms.GetRequestWithList:
+		s.getTransfer = nil
+
+		if !s.isAccepted(dlms.ConformanceBlockMultipleReferences) {
+			return nil
+		}
+
+		results := make([]dlms.GetDataResult, len(req.AttributeInfoList))
+		for i, att := range req.AttributeInfoList {
+			ad := dlms.AttributeDescriptor{ClassID: att.ClassID, InstanceID: att.InstanceID, AttributeID: att.AttributeID}
+
+			data, result := s.registry.get(ad, att.AccessDescriptor)
+			if result == dlms.TagAccSuccess {
+				results[i] = *dlms.CreateGetDataResultAsData(data)
+			} else {
+				results[i] = *dlms.CreateGetDataResultAsResult(result)
+			}
+		}
+
+		resp := dlms.CreateGetResponseWithList(req.InvokePriority, results)
+		out, err := resp.Encode()
+		if err != nil {
+			s.logf("error encoding get with list response: %v", err)
+			return nil
+		}
+
+		// Response without tag, choice and invoke id
+		raw := out[3:]
+		if len(raw) <= s.maxBlockSize(3) || !s.isAccepted(dlms.ConformanceBlockBlockTransferWithGetOrRead) {
+			return resp
+		}
+
+		s.getTransfer = &blockTransfer{raw: raw}
+		return s.getNextBlock(req.InvokePriority)
+	}
+
+	return nil
+}
+
+func (s *server) getNextBlock(invokePriority uint8) dlms.CosemPDU {
+	t := s.getTransfer
+
+	size := s.maxBlockSize(getResponseBlockHeader)
+	lastBlock := len(t.raw) <= size
+	if lastBlock {
+		size = len(t.raw)
+	}
+
+	t.blockNumber++
+	block := dlms.CreateDataBlockGAsData(lastBlock, t.blockNumber, t.raw[:size])
+	t.raw = t.raw[size:]
+
+	if lastBlock {
+		s.getTransfer = nil
+	}
+
+	return dlms.CreateGetResponseWithDataBlock(invokePriority, *block)
+}
@@ -0,0 +1,126 @@
+package dlmsserver
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+// pipe is one end of an in-memory connection. Connection state is shared by both ends.
+type pipe struct {
+	name   string
+	state  *pipeState
+	peer   *pipe
+	dc     dlms.DataChannel
+	logger *log.Logger
+	mutex  sync.Mutex
+}
+
+type pipeState struct {
+	isConnected bool
+	mutex       sync.Mutex
+}
+
+// NewPipe creates two connected in-memory transports. The first one is meant to be used by a client and
+// the second one by a server. Data sent through one end is received by the other end.
+func NewPipe() (dlms.Transport, dlms.Transport) {
+	state := &pipeState{}
+
+	client := &pipe{name: "client", state: state}
+	server := &pipe{name: "server", state: state}
+	client.peer = server
+	server.peer = client
+
+	return client, server
+}
+
+func (p *pipe) Close() {
+	p.Disconnect()
+}
+
+func (p *pipe) Connect() error {
+	p.state.mutex.Lock()
+	defer p.state.mutex.Unlock()
+
+	if !p.state.isConnected {
+		p.state.isConnected = true
+		p.log("Connected")
+	}
+
+	return nil
+}
+
+func (p *pipe) Disconnect() error {
+	p.state.mutex.Lock()
+	defer p.state.mutex.Unlock()
+
+	if p.state.isConnected {
+		p.state.isConnected = false
+		p.log("Disconnected")
+	}
+
+	return nil
+}
+
+func (p *pipe) IsConnected() bool {
+	p.state.mutex.Lock()
+	defer p.state.mutex.Unlock()
+
+	return p.state.isConnected
+}
+
+func (p *pipe) SetAddress(client int, server int) {
+}
+
+func (p *pipe) SetReception(dc dlms.DataChannel) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.dc = dc
+}
+
+func (p *pipe) Send(src []byte) error {
+	if !p.IsConnected() {
+		return fmt.Errorf("not connected")
+	}
+
+	p.log(fmt.Sprintf("TX: %s", encodeHexString(src)))
+
+	data := make([]byte, len(src))
+	copy(data, src)
+
+	p.peer.mutex.Lock()
+	dc := p.peer.dc
+	p.peer.mutex.Unlock()
+
+	if dc != nil {
+		dc <- data
+	}
+
+	return nil
+}
+
+func (p *pipe) SetLogger(logger *log.Logger) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.logger = logger
+}
+
+func (p *pipe) log(msg string) {
+	p.mutex.Lock()
+	logger := p.logger
+	p.mutex.Unlock()
+
+	if logger != nil {
+		logger.Printf("%s (pipe %s)", msg, p.name)
+	}
+}
+
+func encodeHexString(b []byte) string {
+	return strings.ToUpper(hex.EncodeToString(b))
+}
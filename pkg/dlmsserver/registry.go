@@ -0,0 +1,306 @@
+package dlmsserver
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+// GetHandler returns the value of an attribute. Selective access is only present if requested by the client.
+type GetHandler func(att dlms.AttributeDescriptor, acc *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag)
+
+// SetHandler writes the value of an attribute.
+type SetHandler func(att dlms.AttributeDescriptor, acc *dlms.SelectiveAccessDescriptor, data axdr.DlmsData) dlms.AccessResultTag
+
+// ActionHandler invokes a method. Both the parameters and the returned data are optional.
+type ActionHandler func(mth dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag)
+
+// Attribute defines how an attribute is accessed. A nil handler means that access is denied.
+type Attribute struct {
+	Get GetHandler
+	Set SetHandler
+}
+
+// Object is a COSEM object exposed by the server. Its attributes and methods can be defined while
+// it's being served, but only through its methods, not by changing the maps directly.
+type Object struct {
+	ClassID       uint16
+	Version       uint8
+	LogicalName   dlms.Obis
+	Attributes    map[int8]Attribute
+	Methods       map[int8]ActionHandler
+	mutex         sync.Mutex
+	handlersMutex sync.RWMutex
+}
+
+// NewObject creates an object without attributes nor methods, except the logical name, which is
+// always readable.
+func NewObject(classID uint16, version uint8, logicalName string) *Object {
+	o := &Object{
+		ClassID:       classID,
+		Version:       version,
+		LogicalName:   *dlms.CreateObis(logicalName),
+		Attributes:    make(map[int8]Attribute),
+		Methods:       make(map[int8]ActionHandler),
+		mutex:         sync.Mutex{},
+		handlersMutex: sync.RWMutex{},
+	}
+
+	// Logical name is always readable
+	ln := *axdr.CreateAxdrOctetString(o.LogicalName.String())
+	o.Attributes[1] = Attribute{
+		Get: func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			return ln, dlms.TagAccSuccess
+		},
+	}
+
+	return o
+}
+
+// SetAttribute defines the handlers of an attribute.
+func (o *Object) SetAttribute(id int8, get GetHandler, set SetHandler) *Object {
+	o.handlersMutex.Lock()
+	defer o.handlersMutex.Unlock()
+
+	o.Attributes[id] = Attribute{Get: get, Set: set}
+	return o
+}
+
+// SetValue defines an attribute that holds a static value. If writable, a set request updates it.
+func (o *Object) SetValue(id int8, value axdr.DlmsData, writable bool) *Object {
+	attribute := Attribute{
+		Get: func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+			o.mutex.Lock()
+			defer o.mutex.Unlock()
+
+			return value, dlms.TagAccSuccess
+		},
+	}
+
+	if writable {
+		attribute.Set = func(_ dlms.AttributeDescriptor, _ *dlms.SelectiveAccessDescriptor, data axdr.DlmsData) dlms.AccessResultTag {
+			o.mutex.Lock()
+			defer o.mutex.Unlock()
+
+			if data.Tag != value.Tag {
+				return dlms.TagAccTypeUnmatched
+			}
+
+			value = data
+			return dlms.TagAccSuccess
+		}
+	}
+
+	o.handlersMutex.Lock()
+	defer o.handlersMutex.Unlock()
+
+	o.Attributes[id] = attribute
+	return o
+}
+
+// SetMethod defines the handler of a method.
+func (o *Object) SetMethod(id int8, handler ActionHandler) *Object {
+	o.handlersMutex.Lock()
+	defer o.handlersMutex.Unlock()
+
+	o.Methods[id] = handler
+	return o
+}
+
+// attribute returns the handlers of an attribute, if defined.
+func (o *Object) attribute(id int8) (Attribute, bool) {
+	o.handlersMutex.RLock()
+	defer o.handlersMutex.RUnlock()
+
+	attribute, ok := o.Attributes[id]
+	return attribute, ok
+}
+
+// method returns the handler of a method, if defined.
+func (o *Object) method(id int8) (ActionHandler, bool) {
+	o.handlersMutex.RLock()
+	defer o.handlersMutex.RUnlock()
+
+	handler, ok := o.Methods[id]
+	return handler, ok
+}
+
+// AccessRights returns the access rights of the object, derived from the defined handlers.
+func (o *Object) AccessRights() dlms.AccessRights {
+	o.handlersMutex.RLock()
+	defer o.handlersMutex.RUnlock()
+
+	rights := dlms.AccessRights{
+		Attributes: make([]dlms.AttributeAccessItem, 0, len(o.Attributes)),
+		Methods:    make([]dlms.MethodAccessItem, 0, len(o.Methods)),
+	}
+
+	for id, a := range o.Attributes {
+		mode := dlms.AttributeAccessNoAccess
+		switch {
+		case a.Get != nil && a.Set != nil:
+			mode = dlms.AttributeAccessReadAndWrite
+		case a.Get != nil:
+			mode = dlms.AttributeAccessReadOnly
+		case a.Set != nil:
+			mode = dlms.AttributeAccessWriteOnly
+		}
+
+		rights.Attributes = append(rights.Attributes, dlms.AttributeAccessItem{AttributeID: id, AccessMode: mode})
+	}
+
+	for id, handler := range o.Methods {
+		mode := dlms.MethodAccessNoAccess
+		if handler != nil {
+			mode = dlms.MethodAccessAccess
+		}
+
+		rights.Methods = append(rights.Methods, dlms.MethodAccessItem{MethodID: id, AccessMode: mode})
+	}
+
+	sort.Slice(rights.Attributes, func(i, j int) bool { return rights.Attributes[i].AttributeID < rights.Attributes[j].AttributeID })
+	sort.Slice(rights.Methods, func(i, j int) bool { return rights.Methods[i].MethodID < rights.Methods[j].MethodID })
+
+	return rights
+}
+
+// NewAssociationLN creates an Association LN object whose object list contains all the objects of
+// the registry, including itself once registered.
+func NewAssociationLN(logicalName string, registry *Registry) *Object {
+	o := NewObject(dlms.AssociationLNClassID, 1, logicalName)
+	o.SetAttribute(dlms.AssociationLNObjectList, func(dlms.AttributeDescriptor, *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+		return *registry.ObjectList().Data(), dlms.TagAccSuccess
+	}, nil)
+
+	return o
+}
+
+// Registry holds the COSEM objects served by a server. It can be shared between servers.
+type Registry struct {
+	objects []*Object
+	mutex   sync.RWMutex
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		objects: make([]*Object, 0),
+		mutex:   sync.RWMutex{},
+	}
+}
+
+// Register adds an object to the registry. Objects are identified by class and logical name.
+func (r *Registry) Register(objects ...*Object) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, o := range objects {
+		if r.find(o.ClassID, o.LogicalName) != nil {
+			return fmt.Errorf("object { %d, %s } already registered", o.ClassID, o.LogicalName.String())
+		}
+
+		r.objects = append(r.objects, o)
+	}
+
+	return nil
+}
+
+// Find returns the object with the given class and logical name, or nil if not registered.
+func (r *Registry) Find(classID uint16, logicalName dlms.Obis) *Object {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.find(classID, logicalName)
+}
+
+// Objects returns the registered objects in registration order.
+func (r *Registry) Objects() []*Object {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	out := make([]*Object, len(r.objects))
+	copy(out, r.objects)
+
+	return out
+}
+
+// ObjectList returns the object list of the registered objects.
+func (r *Registry) ObjectList() dlms.ObjectList {
+	objects := r.Objects()
+
+	ol := make(dlms.ObjectList, 0, len(objects))
+	for _, o := range objects {
+		ol = append(ol, dlms.ObjectListElement{
+			ClassID:      o.ClassID,
+			Version:      o.Version,
+			LogicalName:  o.LogicalName,
+			AccessRights: o.AccessRights(),
+		})
+	}
+
+	return ol
+}
+
+func (r *Registry) find(classID uint16, logicalName dlms.Obis) *Object {
+	for _, o := range r.objects {
+		if o.ClassID == classID && bytes.Equal(o.LogicalName.Bytes(), logicalName.Bytes()) {
+			return o
+		}
+	}
+
+	return nil
+}
+
+func (r *Registry) get(att dlms.AttributeDescriptor, acc *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+	o := r.Find(att.ClassID, att.InstanceID)
+	if o == nil {
+		return axdr.DlmsData{}, dlms.TagAccObjectUndefined
+	}
+
+	attribute, ok := o.attribute(att.AttributeID)
+	if !ok {
+		return axdr.DlmsData{}, dlms.TagAccObjectUndefined
+	}
+
+	if attribute.Get == nil {
+		return axdr.DlmsData{}, dlms.TagAccReadWriteDenied
+	}
+
+	return attribute.Get(att, acc)
+}
+
+func (r *Registry) set(att dlms.AttributeDescriptor, acc *dlms.SelectiveAccessDescriptor, data axdr.DlmsData) dlms.AccessResultTag {
+	o := r.Find(att.ClassID, att.InstanceID)
+	if o == nil {
+		return dlms.TagAccObjectUndefined
+	}
+
+	attribute, ok := o.attribute(att.AttributeID)
+	if !ok {
+		return dlms.TagAccObjectUndefined
+	}
+
+	if attribute.Set == nil {
+		return dlms.TagAccReadWriteDenied
+	}
+
+	return attribute.Set(att, acc, data)
+}
+
+func (r *Registry) action(mth dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+	o := r.Find(mth.ClassID, mth.InstanceID)
+	if o == nil {
+		return nil, dlms.TagActObjectUndefined
+	}
+
+	handler, ok := o.method(mth.MethodID)
+	if !ok || handler == nil {
+		return nil, dlms.TagActObjectUndefined
+	}
+
+	return handler(mth, data)
+}
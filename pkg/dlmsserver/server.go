@@ -0,0 +1,405 @@
+package dlmsserver
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+const (
+	// Length of the security header and authentication tag added by ciphering
+	cipherOverhead = 21
+
+	// Initiate error values of the confirmed service error
+	initiateErrorIncompatibleConformance = 2
+)
+
+// Server specifies a COSEM server (meter simulator) attached to a transport.
+type Server interface {
+	Close()
+	IsAssociated() bool
+	GetSettings() dlms.Settings
+	SetLogger(logger *log.Logger)
+	SendDataNotification(dn dlms.DataNotification) error
+}
+
+type server struct {
+	settings       dlms.Settings
+	registry       *Registry
+	transport      dlms.Transport
+	dc             dlms.DataChannel
+	done           chan struct{}
+	isAssociated   bool
+	conformance    uint32
+	maxPduSendSize int
+	getTransfer    *blockTransfer
+	setTransfer    *blockTransfer
+	actionTransfer *blockTransfer
+	logger         *log.Logger
+	mutex          sync.Mutex
+}
+
+// New creates a server that answers the requests received through the transport using the
+// objects of the registry. Settings define the server side of the association: the required
+// authentication and password, the conformance block offered, the maximum PDU sizes and, if
+// ciphering is used, the own system title and the keys.
+func New(settings dlms.Settings, registry *Registry, transport dlms.Transport) Server {
+	s := &server{
+		settings:       settings,
+		registry:       registry,
+		transport:      transport,
+		dc:             make(dlms.DataChannel, 10),
+		done:           make(chan struct{}),
+		isAssociated:   false,
+		conformance:    0,
+		maxPduSendSize: 0,
+		getTransfer:    nil,
+		setTransfer:    nil,
+		actionTransfer: nil,
+		logger:         nil,
+		mutex:          sync.Mutex{},
+	}
+
+	transport.SetReception(s.dc)
+
+	go s.manager()
+
+	return s
+}
+
+func (s *server) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+		s.isAssociated = false
+	}
+}
+
+func (s *server) IsAssociated() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.isAssociated
+}
+
+func (s *server) GetSettings() dlms.Settings {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.settings
+}
+
+func (s *server) SetLogger(logger *log.Logger) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.logger = logger
+}
+
+// SendDataNotification pushes a data notification to the client.
+func (s *server) SendDataNotification(dn dlms.DataNotification) error {
+	out, err := dn.Encode()
+	if err != nil {
+		return dlms.NewError(dlms.ErrorInvalidParameter, fmt.Sprintf("error encoding data notification: %v", err))
+	}
+
+	err = s.transport.Send(out)
+	if err != nil {
+		return dlms.NewError(dlms.ErrorCommunicationFailed, fmt.Sprintf("error sending data notification: %v", err))
+	}
+
+	return nil
+}
+
+func (s *server) manager() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case data := <-s.dc:
+			out := s.process(data)
+			if len(out) == 0 {
+				continue
+			}
+
+			err := s.transport.Send(out)
+			if err != nil {
+				s.logf("error sending response: %v", err)
+			}
+		}
+	}
+}
+
+func (s *server) process(src []byte) []byte {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(src) == 0 {
+		return nil
+	}
+
+	switch dlms.CosemTag(src[0]) {
+	case dlms.TagAARQ:
+		return s.associate(src)
+	case dlms.TagRLRQ:
+		return s.release(src)
+	}
+
+	if !s.isAssociated {
+		return exception(dlms.CreateExceptionResponse(dlms.TagExcServiceNotAllowed, dlms.TagExcOperationNotPossible))
+	}
+
+	tag := dlms.CosemTag(src[0])
+	ciphered := isCipheredRequest(tag)
+
+	if ciphered {
+		var err error
+		src, err = s.decipherData(src)
+		if err != nil {
+			s.logf("error deciphering request: %v", err)
+			return exception(dlms.CreateExceptionResponse(dlms.TagExcServiceNotAllowed, dlms.TagExcOtherReason))
+		}
+	} else if s.settings.Ciphering.Security != dlms.SecurityNone {
+		return exception(dlms.CreateExceptionResponse(dlms.TagExcServiceNotAllowed, dlms.TagExcOperationNotPossible))
+	}
+
+	pdu, err := dlms.DecodeCosem(&src)
+	if err != nil {
+		s.logf("error decoding request: %v", err)
+		return exception(dlms.CreateExceptionResponse(dlms.TagExcServiceUnknown, dlms.TagExcServiceNotSupported))
+	}
+
+	resp := s.dispatch(pdu)
+	if resp == nil {
+		return exception(dlms.CreateExceptionResponse(dlms.TagExcServiceUnknown, dlms.TagExcServiceNotSupported))
+	}
+
+	out, err := resp.Encode()
+	if err != nil {
+		s.logf("error encoding response: %v", err)
+		return exception(dlms.CreateExceptionResponse(dlms.TagExcServiceNotAllowed, dlms.TagExcOtherReason))
+	}
+
+	if ciphered {
+		out, err = s.cipherData(tag, out)
+		if err != nil {
+			s.logf("error ciphering response: %v", err)
+			return exception(dlms.CreateExceptionResponse(dlms.TagExcServiceNotAllowed, dlms.TagExcOtherReason))
+		}
+	}
+
+	return out
+}
+
+func (s *server) dispatch(pdu dlms.CosemPDU) dlms.CosemPDU {
+	switch req := pdu.(type) {
+	case dlms.GetRequestNormal, dlms.GetRequestNext, dlms.GetRequestWithList:
+		if !s.isAccepted(dlms.ConformanceBlockGet) {
+			return nil
+		}
+		return s.get(req)
+	case dlms.SetRequestNormal, dlms.SetRequestWithFirstDataBlock, dlms.SetRequestWithDataBlock,
+		dlms.SetRequestWithList, dlms.SetRequestWithListAndFirstDataBlock:
+		if !s.isAccepted(dlms.ConformanceBlockSet) {
+			return nil
+		}
+		return s.set(req)
+	case dlms.ActionRequestNormal, dlms.ActionRequestNextPBlock, dlms.ActionRequestWithFirstPBlock,
+		dlms.ActionRequestWithPBlock, dlms.ActionRequestWithList, dlms.ActionRequestWithListAndFirstPBlock:
+		if !s.isAccepted(dlms.ConformanceBlockAction) {
+			return nil
+		}
+		return s.action(req)
+	}
+
+	return nil
+}
+
+func (s *server) associate(src []byte) []byte {
+	s.isAssociated = false
+	s.resetTransfers()
+
+	aare := dlms.AARE{
+		ApplicationContext: s.applicationContext(),
+		AssociationResult:  dlms.AssociationResultAccepted,
+		SourceDiagnostic:   dlms.SourceDiagnosticNone,
+	}
+
+	aarq, err := dlms.DecodeAARQ(&s.settings, &src)
+
+	switch {
+	case err != nil:
+		s.logf("error decoding AARQ: %v", err)
+		aare.AssociationResult = dlms.AssociationResultPermanentRejected
+		aare.SourceDiagnostic = dlms.SourceDiagnosticNoReasonGiven
+	case aarq.ApplicationContext != s.applicationContext():
+		aare.AssociationResult = dlms.AssociationResultPermanentRejected
+		aare.SourceDiagnostic = dlms.SourceDiagnosticApplicationContextNameNotSupported
+	case aarq.Authentication != s.settings.Authentication:
+		aare.AssociationResult = dlms.AssociationResultPermanentRejected
+		if aarq.Authentication == dlms.AuthenticationNone {
+			aare.SourceDiagnostic = dlms.SourceDiagnosticAuthenticationRequired
+		} else {
+			aare.SourceDiagnostic = dlms.SourceDiagnosticAuthenticationMechanismNameNotRecognized
+		}
+	case aarq.Authentication == dlms.AuthenticationLow && !bytes.Equal(aarq.Password, s.settings.Password):
+		aare.AssociationResult = dlms.AssociationResultPermanentRejected
+		aare.SourceDiagnostic = dlms.SourceDiagnosticAuthenticationFailure
+	case aarq.Authentication > dlms.AuthenticationLow:
+		// High level authentication is not implemented
+		aare.AssociationResult = dlms.AssociationResultPermanentRejected
+		aare.SourceDiagnostic = dlms.SourceDiagnosticAuthenticationMechanismNameNotRecognized
+	case aarq.InitiateRequest == nil:
+		aare.AssociationResult = dlms.AssociationResultPermanentRejected
+		aare.SourceDiagnostic = dlms.SourceDiagnosticNoReasonGiven
+	default:
+		ir := aarq.InitiateRequest
+		conformance := ir.ProposedConformance & uint32(s.settings.ConformanceBlock)
+		if conformance == 0 {
+			aare.AssociationResult = dlms.AssociationResultPermanentRejected
+			aare.SourceDiagnostic = dlms.SourceDiagnosticNoReasonGiven
+			aare.ConfirmedServiceError = dlms.CreateConfirmedServiceError(dlms.TagErrInitiateError, dlms.TagErrInitiate, initiateErrorIncompatibleConformance)
+			break
+		}
+
+		s.conformance = conformance
+		s.maxPduSendSize = int(ir.ClientMaxReceivePduSize)
+		if s.maxPduSendSize == 0 || (s.settings.MaxPduSendSize != 0 && s.settings.MaxPduSendSize < s.maxPduSendSize) {
+			s.maxPduSendSize = s.settings.MaxPduSendSize
+		}
+		s.settings.Ciphering.DedicatedKey = ir.DedicatedKey
+
+		if aare.ApplicationContext == dlms.ApplicationContextLNCiphering {
+			aare.SourceSystemTitle = s.settings.Ciphering.SystemTitle
+		}
+		aare.InitiateResponse = dlms.CreateInitiateResponse(nil, conformance, uint16(s.settings.MaxPduRecvSize))
+	}
+
+	out, err := dlms.EncodeAARE(&s.settings, aare)
+	if err != nil {
+		s.logf("error encoding AARE: %v", err)
+		return nil
+	}
+
+	s.isAssociated = aare.InitiateResponse != nil
+
+	return out
+}
+
+func (s *server) release(src []byte) []byte {
+	_, err := dlms.DecodeRLRQ(&src)
+	if err != nil {
+		s.logf("error decoding RLRQ: %v", err)
+	}
+
+	s.isAssociated = false
+	s.resetTransfers()
+
+	reason := dlms.ReleaseResponseReasonNormal
+	out, _ := dlms.EncodeRLRE(&reason)
+
+	return out
+}
+
+func exception(er *dlms.ExceptionResponse) []byte {
+	out, _ := er.Encode()
+	return out
+}
+
+func (s *server) applicationContext() dlms.ApplicationContext {
+	if s.settings.Ciphering.Security == dlms.SecurityNone && len(s.settings.Ciphering.SystemTitle) == 0 {
+		return dlms.ApplicationContextLNNoCiphering
+	}
+
+	return dlms.ApplicationContextLNCiphering
+}
+
+func (s *server) isAccepted(conformance int) bool {
+	return s.conformance&uint32(conformance) != 0
+}
+
+func (s *server) resetTransfers() {
+	s.getTransfer = nil
+	s.setTransfer = nil
+	s.actionTransfer = nil
+}
+
+// maxBlockSize returns the maximum size of the raw data sent in a block, given the header length.
+func (s *server) maxBlockSize(header int) int {
+	size := s.maxPduSendSize - header
+	if s.settings.Ciphering.Security != dlms.SecurityNone {
+		size -= cipherOverhead
+	}
+
+	return size
+}
+
+func (s *server) decipherData(src []byte) ([]byte, error) {
+	cipher := dlms.Cipher{
+		Tag:         dlms.CosemTag(src[0]),
+		Security:    s.settings.Ciphering.Security,
+		SystemTitle: s.settings.Ciphering.SourceSystemTitle,
+		AuthKey:     s.settings.Ciphering.AuthenticationKey,
+	}
+
+	if isDedicatedRequest(cipher.Tag) {
+		cipher.Key = s.settings.Ciphering.DedicatedKey
+	} else {
+		cipher.Key = s.settings.Ciphering.UnicastKey
+	}
+
+	if len(cipher.Key) != 16 {
+		return nil, fmt.Errorf("invalid key")
+	}
+
+	return dlms.DecipherData(cipher, src)
+}
+
+func (s *server) cipherData(requestTag dlms.CosemTag, src []byte) ([]byte, error) {
+	cipher := dlms.Cipher{
+		// Ciphered responses tags are always 4 positions after the request ones
+		Tag:         requestTag + 4,
+		Security:    s.settings.Ciphering.Security,
+		SystemTitle: s.settings.Ciphering.SystemTitle,
+		AuthKey:     s.settings.Ciphering.AuthenticationKey,
+	}
+
+	if isDedicatedRequest(requestTag) {
+		cipher.Key = s.settings.Ciphering.DedicatedKey
+		cipher.FrameCounter = s.settings.Ciphering.DedicatedKeyIC
+		s.settings.Ciphering.DedicatedKeyIC++
+	} else {
+		cipher.Key = s.settings.Ciphering.UnicastKey
+		cipher.FrameCounter = s.settings.Ciphering.UnicastKeyIC
+		s.settings.Ciphering.UnicastKeyIC++
+	}
+
+	return dlms.CipherData(cipher, src)
+}
+
+func (s *server) logf(format string, v ...interface{}) {
+	if s.logger != nil {
+		s.logger.Printf(format, v...)
+	}
+}
+
+func isCipheredRequest(tag dlms.CosemTag) bool {
+	switch tag {
+	case dlms.TagGloGetRequest, dlms.TagGloSetRequest, dlms.TagGloActionRequest,
+		dlms.TagDedGetRequest, dlms.TagDedSetRequest, dlms.TagDedActionRequest:
+		return true
+	}
+
+	return false
+}
+
+func isDedicatedRequest(tag dlms.CosemTag) bool {
+	return tag == dlms.TagDedGetRequest || tag == dlms.TagDedSetRequest || tag == dlms.TagDedActionRequest
+}
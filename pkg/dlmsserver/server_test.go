@@ -0,0 +1,460 @@
+package dlmsserver_test
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/dlmsclient"
+	"github.com/Circutor/gosem/pkg/dlmsserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Associate(t *testing.T) {
+	settings, _ := dlms.NewSettingsWithLowAuthentication([]byte("12345678"))
+	c, s := connect(t, settings, settings, newRegistry(t))
+
+	require.NoError(t, c.Associate())
+	assert.True(t, c.IsAssociated())
+	assert.True(t, s.IsAssociated())
+
+	assert.NoError(t, c.CloseAssociation())
+	assert.False(t, c.IsAssociated())
+	assert.Eventually(t, func() bool { return !s.IsAssociated() }, time.Second, 10*time.Millisecond)
+}
+
+func TestServer_AssociateFail(t *testing.T) {
+	serverSettings, _ := dlms.NewSettingsWithLowAuthentication([]byte("12345678"))
+
+	// Invalid password
+	clientSettings, _ := dlms.NewSettingsWithLowAuthentication([]byte("00000000"))
+	c, s := connect(t, serverSettings, clientSettings, newRegistry(t))
+	assertErrorCode(t, c.Associate(), dlms.ErrorInvalidPassword)
+	assert.False(t, s.IsAssociated())
+
+	// Authentication required
+	clientSettings, _ = dlms.NewSettingsWithoutAuthentication()
+	c, s = connect(t, serverSettings, clientSettings, newRegistry(t))
+	assertErrorCode(t, c.Associate(), dlms.ErrorAuthenticationFailed)
+	assert.False(t, s.IsAssociated())
+
+	// No common services
+	clientSettings, _ = dlms.NewSettingsWithLowAuthentication([]byte("12345678"))
+	clientSettings.ConformanceBlock = dlms.ConformanceBlockAccess
+	c, s = connect(t, serverSettings, clientSettings, newRegistry(t))
+	assertErrorCode(t, c.Associate(), dlms.ErrorAuthenticationFailed)
+	assert.False(t, s.IsAssociated())
+}
+
+func TestServer_NotAssociated(t *testing.T) {
+	settings, _ := dlms.NewSettingsWithoutAuthentication()
+
+	ct, st := dlmsserver.NewPipe()
+	s := dlmsserver.New(settings, newRegistry(t), st)
+	defer s.Close()
+
+	dc := make(dlms.DataChannel, 1)
+	ct.SetReception(dc)
+	require.NoError(t, ct.Connect())
+
+	req, _ := dlms.CreateGetRequestNormal(0xC1, *dlms.CreateAttributeDescriptor(1, "0.0.96.1.0.255", 2), nil).Encode()
+	require.NoError(t, ct.Send(req))
+
+	select {
+	case data := <-dc:
+		assert.Equal(t, decodeHexString("D80101"), data)
+	case <-time.After(time.Second):
+		t.Fatal("no response received")
+	}
+}
+
+func TestServer_GetRequest(t *testing.T) {
+	c := associate(t, newRegistry(t))
+
+	var serial string
+	err := c.GetRequest(dlms.CreateAttributeDescriptor(1, "0.0.96.1.0.255", 2), &serial)
+	assert.NoError(t, err)
+	assert.Equal(t, "SERIAL0001", serial)
+
+	var ln string
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(1, "0.0.96.1.0.255", 1), &ln)
+	assert.NoError(t, err)
+	assert.Equal(t, "0000600100ff", ln)
+
+	// Undefined object
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(1, "0.0.96.1.1.255", 2), &serial)
+	assertErrorCode(t, err, dlms.ErrorGetRejected)
+
+	// Undefined attribute
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(1, "0.0.96.1.0.255", 3), &serial)
+	assertErrorCode(t, err, dlms.ErrorGetRejected)
+}
+
+func TestServer_GetRequestWithDataBlock(t *testing.T) {
+	value := strings.Repeat("0123456789", 100)
+
+	registry := newRegistry(t)
+	err := registry.Register(dlmsserver.NewObject(1, 0, "0.0.96.1.10.255").SetValue(2, *axdr.CreateAxdrVisibleString(value), false))
+	require.NoError(t, err)
+
+	c := associate(t, registry)
+
+	var got string
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(1, "0.0.96.1.10.255", 2), &got)
+	assert.NoError(t, err)
+	assert.Equal(t, value, got)
+}
+
+func TestServer_GetRequestWithSelectiveAccess(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	registry := newRegistry(t)
+	profile := dlmsserver.NewObject(7, 1, "1.0.99.1.0.255")
+	profile.SetAttribute(2, func(_ dlms.AttributeDescriptor, acc *dlms.SelectiveAccessDescriptor) (axdr.DlmsData, dlms.AccessResultTag) {
+		if acc == nil || acc.AccessSelector != dlms.AccessSelectorRange {
+			return axdr.DlmsData{}, dlms.TagAccOtherReason
+		}
+
+		rows := []*axdr.DlmsData{axdr.CreateAxdrDoubleLongUnsigned(1), axdr.CreateAxdrDoubleLongUnsigned(2)}
+		return *axdr.CreateAxdrArray(rows), dlms.TagAccSuccess
+	}, nil)
+	require.NoError(t, registry.Register(profile))
+
+	c := associate(t, registry)
+
+	var rows []uint32
+	err := c.GetRequestWithSelectiveAccessByDate(dlms.CreateAttributeDescriptor(7, "1.0.99.1.0.255", 2), start, end, &rows)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{1, 2}, rows)
+
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(7, "1.0.99.1.0.255", 2), &rows)
+	assertErrorCode(t, err, dlms.ErrorGetRejected)
+}
+
+func TestServer_SetRequest(t *testing.T) {
+	c := associate(t, newRegistry(t))
+
+	err := c.SetRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), uint32(1234))
+	assert.NoError(t, err)
+
+	var energy uint32
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), &energy)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1234), energy)
+
+	// Type mismatch
+	err = c.SetRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), uint16(1))
+	assertErrorCode(t, err, dlms.ErrorSetRejected)
+
+	// Read only
+	err = c.SetRequest(dlms.CreateAttributeDescriptor(1, "0.0.96.1.0.255", 2), axdr.CreateAxdrVisibleString("SERIAL0002"))
+	assertErrorCode(t, err, dlms.ErrorSetRejected)
+}
+
+func TestServer_SetRequestWithDataBlock(t *testing.T) {
+	value := strings.Repeat("0123456789", 100)
+
+	registry := newRegistry(t)
+	err := registry.Register(dlmsserver.NewObject(1, 0, "0.0.96.1.10.255").SetValue(2, *axdr.CreateAxdrVisibleString(""), true))
+	require.NoError(t, err)
+
+	c := associate(t, registry)
+
+	err = c.SetRequest(dlms.CreateAttributeDescriptor(1, "0.0.96.1.10.255", 2), axdr.CreateAxdrVisibleString(value))
+	assert.NoError(t, err)
+
+	var got string
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(1, "0.0.96.1.10.255", 2), &got)
+	assert.NoError(t, err)
+	assert.Equal(t, value, got)
+}
+
+func TestServer_ActionRequest(t *testing.T) {
+	var param int8
+
+	registry := newRegistry(t)
+	register := dlmsserver.NewObject(3, 0, "1.0.2.8.0.255")
+	register.SetMethod(1, func(_ dlms.MethodDescriptor, data *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+		if data == nil || data.Tag != axdr.TagInteger {
+			return nil, dlms.TagActTypeUnmatched
+		}
+
+		param = data.Value.(int8)
+		return nil, dlms.TagActSuccess
+	})
+	require.NoError(t, registry.Register(register))
+
+	c := associate(t, registry)
+
+	err := c.ActionRequest(dlms.CreateMethodDescriptor(3, "1.0.2.8.0.255", 1), int8(5))
+	assert.NoError(t, err)
+	assert.Equal(t, int8(5), param)
+
+	err = c.ActionRequest(dlms.CreateMethodDescriptor(3, "1.0.2.8.0.255", 1), uint8(5))
+	assertErrorCode(t, err, dlms.ErrorActionRejected)
+
+	err = c.ActionRequest(dlms.CreateMethodDescriptor(3, "1.0.2.8.0.255", 2), int8(0))
+	assertErrorCode(t, err, dlms.ErrorActionRejected)
+}
+
+func TestServer_Ciphering(t *testing.T) {
+	key := decodeHexString("00112233445566778899AABBCCDDEEFF")
+	security := dlms.SecurityEncryption | dlms.SecurityAuthentication
+
+	for _, level := range []dlms.SecurityLevel{dlms.SecurityLevelGlobalKey, dlms.SecurityLevelDedicatedKey} {
+		clientCiphering, _ := dlms.NewCiphering(level, security, decodeHexString("4349520000000001"), key, 1, key)
+		clientSettings, _ := dlms.NewSettingsWithLowAuthenticationAndCiphering([]byte("12345678"), clientCiphering)
+
+		serverSettings, _ := dlms.NewSettingsWithLowAuthenticationAndCiphering([]byte("12345678"), dlms.Ciphering{
+			Security:          security,
+			SystemTitle:       decodeHexString("4349520000000002"),
+			UnicastKey:        key,
+			AuthenticationKey: key,
+			UnicastKeyIC:      1,
+			DedicatedKeyIC:    1,
+		})
+
+		c, s := connect(t, serverSettings, clientSettings, newRegistry(t))
+		require.NoError(t, c.Associate())
+
+		assert.Equal(t, decodeHexString("4349520000000001"), s.GetSettings().Ciphering.SourceSystemTitle)
+		info, err := c.AssociationInfo()
+		require.NoError(t, err)
+		assert.Equal(t, decodeHexString("4349520000000002"), info.RespondingSystemTitle)
+
+		var serial string
+		err = c.GetRequest(dlms.CreateAttributeDescriptor(1, "0.0.96.1.0.255", 2), &serial)
+		assert.NoError(t, err)
+		assert.Equal(t, "SERIAL0001", serial)
+
+		err = c.SetRequest(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), uint32(1))
+		assert.NoError(t, err)
+	}
+}
+
+func TestServer_DataNotification(t *testing.T) {
+	settings, _ := dlms.NewSettingsWithoutAuthentication()
+	c, s := connect(t, settings, settings, newRegistry(t))
+
+	nc := make(chan dlms.Notification, 1)
+	c.SetNotificationChannel("meter", nc)
+
+	tm := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	err := s.SendDataNotification(*dlms.CreateDataNotification(1, &tm, *axdr.CreateAxdrDoubleLongUnsigned(10)))
+	require.NoError(t, err)
+
+	select {
+	case n := <-nc:
+		assert.Equal(t, "meter", n.ID)
+		assert.Equal(t, uint32(10), n.DataNotification.DataValue.Value)
+	case <-time.After(time.Second):
+		t.Fatal("no notification received")
+	}
+}
+
+func TestServer_ObjectList(t *testing.T) {
+	registry := newRegistry(t)
+	require.NoError(t, registry.Register(dlmsserver.NewAssociationLN(dlms.AssociationLNCurrent, registry)))
+
+	c := associate(t, registry)
+
+	ol, err := c.GetObjectList()
+	require.NoError(t, err)
+	require.Len(t, ol, 3)
+
+	serial := ol.Find(1, *dlms.CreateObis("0.0.96.1.0.255"))
+	require.NotNil(t, serial)
+	assert.Equal(t, dlms.AttributeAccessReadOnly, serial.AttributeAccess(2))
+
+	energy := ol.Find(3, *dlms.CreateObis("1.0.1.8.0.255"))
+	require.NotNil(t, energy)
+	assert.Equal(t, dlms.AttributeAccessReadAndWrite, energy.AttributeAccess(2))
+
+	// Following reads are served from the cache
+	require.NoError(t, registry.Register(dlmsserver.NewObject(1, 0, "0.0.96.1.1.255")))
+	ol, err = c.GetObjectList()
+	require.NoError(t, err)
+	assert.Len(t, ol, 3)
+
+	// A cache shared between clients is used if the device key matches
+	cache := dlms.NewObjectListMemoryCache()
+	c.SetObjectListCache(cache, "meter")
+	ol, err = c.GetObjectList()
+	require.NoError(t, err)
+	assert.Len(t, ol, 4)
+
+	cached, ok := cache.Get("meter")
+	assert.True(t, ok)
+	assert.Equal(t, ol, cached)
+
+	// A new association reads it again
+	require.NoError(t, c.CloseAssociation())
+	_, ok = cache.Get("meter")
+	assert.False(t, ok)
+
+	require.NoError(t, registry.Register(dlmsserver.NewObject(1, 0, "0.0.96.1.2.255")))
+	require.NoError(t, c.Associate())
+	ol, err = c.GetObjectList()
+	require.NoError(t, err)
+	assert.Len(t, ol, 5)
+}
+
+func TestServer_DefineWhileServing(t *testing.T) {
+	registry := newRegistry(t)
+	require.NoError(t, registry.Register(dlmsserver.NewAssociationLN(dlms.AssociationLNCurrent, registry)))
+	serial := registry.Find(1, *dlms.CreateObis("0.0.96.1.0.255"))
+	require.NotNil(t, serial)
+
+	c := associate(t, registry)
+	c.SetObjectListCache(nil, "")
+
+	// Attributes and methods are defined while the server reads them (run with -race)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 50; i++ {
+			serial.SetValue(3, *axdr.CreateAxdrDoubleLongUnsigned(uint32(i)), false)
+			serial.SetMethod(1, func(dlms.MethodDescriptor, *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+				return nil, dlms.TagActSuccess
+			})
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		var value string
+		require.NoError(t, c.GetRequest(dlms.CreateAttributeDescriptor(1, "0.0.96.1.0.255", 2), &value))
+
+		_, err := c.GetObjectList()
+		require.NoError(t, err)
+	}
+
+	<-done
+}
+
+func TestServer_AccessValidation(t *testing.T) {
+	registry := newRegistry(t)
+	require.NoError(t, registry.Register(
+		dlmsserver.NewAssociationLN(dlms.AssociationLNCurrent, registry),
+		dlmsserver.NewObject(70, 0, "0.0.96.3.10.255").SetMethod(1, func(dlms.MethodDescriptor, *axdr.DlmsData) (*axdr.DlmsData, dlms.ActionResultTag) {
+			return nil, dlms.TagActSuccess
+		}),
+	))
+
+	c := associate(t, registry)
+	c.SetAccessValidation(true)
+
+	var serial string
+	err := c.GetRequest(dlms.CreateAttributeDescriptor(1, "0.0.96.1.0.255", 2), &serial)
+	assert.NoError(t, err)
+	assert.Equal(t, "SERIAL0001", serial)
+
+	err = c.SetRequest(dlms.CreateAttributeDescriptor(1, "0.0.96.1.0.255", 2), axdr.CreateAxdrVisibleString("SERIAL0002"))
+	assertErrorCode(t, err, dlms.ErrorSetRejected)
+	assert.Contains(t, err.Error(), "no write access (read-only)")
+
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(1, "0.0.96.1.0.255", 3), &serial)
+	assertErrorCode(t, err, dlms.ErrorGetRejected)
+	assert.Contains(t, err.Error(), "no read access (no-access)")
+
+	err = c.GetRequestWithSelectiveAccessByDate(dlms.CreateAttributeDescriptor(3, "1.0.1.8.0.255", 2), time.Now(), time.Now(), nil)
+	assertErrorCode(t, err, dlms.ErrorGetRejected)
+	assert.Contains(t, err.Error(), "access selector 1 not supported")
+
+	err = c.ActionRequest(dlms.CreateMethodDescriptor(70, "0.0.96.3.10.255", 1), int8(0))
+	assert.NoError(t, err)
+
+	err = c.ActionRequest(dlms.CreateMethodDescriptor(70, "0.0.96.3.10.255", 2), int8(0))
+	assertErrorCode(t, err, dlms.ErrorActionRejected)
+	assert.Contains(t, err.Error(), "no execute access")
+
+	// Objects not in the cached object list are rejected without being requested
+	require.NoError(t, registry.Register(dlmsserver.NewObject(1, 0, "0.0.96.1.1.255").SetValue(2, *axdr.CreateAxdrVisibleString("SERIAL0002"), false)))
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(1, "0.0.96.1.1.255", 2), &serial)
+	assertErrorCode(t, err, dlms.ErrorGetRejected)
+	assert.Contains(t, err.Error(), "object not in the object list")
+
+	// Struct of elements skips the rejected pointer fields
+	type meter struct {
+		Serial  string  `obis:"1,0.0.96.1.0.255,2"`
+		Serial2 *string `obis:"1,0.0.96.1.1.255,2"`
+	}
+	var m meter
+	err = c.GetRequestWithStructOfElements(&m)
+	assert.NoError(t, err)
+	assert.Equal(t, "SERIAL0001", m.Serial)
+	assert.Nil(t, m.Serial2)
+
+	c.SetAccessValidation(false)
+	err = c.GetRequest(dlms.CreateAttributeDescriptor(1, "0.0.96.1.1.255", 2), &serial)
+	assert.NoError(t, err)
+	assert.Equal(t, "SERIAL0002", serial)
+}
+
+func TestRegistry_Register(t *testing.T) {
+	registry := dlmsserver.NewRegistry()
+
+	assert.NoError(t, registry.Register(dlmsserver.NewObject(1, 0, "0.0.96.1.0.255")))
+	assert.Error(t, registry.Register(dlmsserver.NewObject(1, 0, "0.0.96.1.0.255")))
+	assert.NoError(t, registry.Register(dlmsserver.NewObject(3, 0, "0.0.96.1.0.255")))
+
+	assert.NotNil(t, registry.Find(1, *dlms.CreateObis("0.0.96.1.0.255")))
+	assert.Nil(t, registry.Find(1, *dlms.CreateObis("0.0.96.1.1.255")))
+	assert.Len(t, registry.Objects(), 2)
+}
+
+func newRegistry(t *testing.T) *dlmsserver.Registry {
+	t.Helper()
+
+	registry := dlmsserver.NewRegistry()
+	err := registry.Register(
+		dlmsserver.NewObject(1, 0, "0.0.96.1.0.255").SetValue(2, *axdr.CreateAxdrVisibleString("SERIAL0001"), false),
+		dlmsserver.NewObject(3, 0, "1.0.1.8.0.255").SetValue(2, *axdr.CreateAxdrDoubleLongUnsigned(0), true),
+	)
+	require.NoError(t, err)
+
+	return registry
+}
+
+func connect(t *testing.T, serverSettings dlms.Settings, clientSettings dlms.Settings, registry *dlmsserver.Registry) (dlms.Client, dlmsserver.Server) {
+	t.Helper()
+
+	ct, st := dlmsserver.NewPipe()
+
+	s := dlmsserver.New(serverSettings, registry, st)
+	t.Cleanup(s.Close)
+
+	c := dlmsclient.New(clientSettings, ct, time.Second, 0)
+	require.NoError(t, c.Connect())
+
+	return c, s
+}
+
+func associate(t *testing.T, registry *dlmsserver.Registry) dlms.Client {
+	t.Helper()
+
+	settings, _ := dlms.NewSettingsWithoutAuthentication()
+	c, _ := connect(t, settings, settings, registry)
+	require.NoError(t, c.Associate())
+
+	return c
+}
+
+func assertErrorCode(t *testing.T, err error, code dlms.ErrorCode) {
+	t.Helper()
+
+	var dlmsError *dlms.Error
+	if assert.ErrorAs(t, err, &dlmsError) {
+		assert.Equal(t, code, dlmsError.Code())
+	}
+}
+
+func decodeHexString(s string) []byte {
+	b, _ := hex.DecodeString(s)
+	return b
+}
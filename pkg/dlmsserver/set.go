@@ -0,0 +1,164 @@
+package dlmsserver
+
+import (
+	"fmt"
+
+	"github.com/Circutor/gosem/pkg/axdr"
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+func (s *server) set(pdu dlms.CosemPDU) dlms.CosemPDU {
+	switch req := pdu.(type) {
+	case dlms.SetRequestNormal:
+		s.setTransfer = nil
+
+		result := s.registry.set(req.AttributeInfo, req.SelectiveAccessInfo, req.Value)
+		return dlms.CreateSetResponseNormal(req.InvokePriority, result)
+
+	case dlms.SetRequestWithFirstDataBlock:
+		s.setTransfer = nil
+
+		if !s.isAccepted(dlms.ConformanceBlockBlockTransferWithSetOrWrite) {
+			return nil
+		}
+
+		if req.DataBlock.BlockNumber != 1 {
+			return dlms.CreateSetResponseLastDataBlock(req.InvokePriority, dlms.TagAccDataBlockNumberInvalid, req.DataBlock.BlockNumber)
+		}
+
+		s.setTransfer = &blockTransfer{
+			attributes: []dlms.AttributeDescriptorWithSelection{{
+				ClassID:          req.AttributeInfo.ClassID,
+				InstanceID:       req.AttributeInfo.InstanceID,
+				AttributeID:      req.AttributeInfo.AttributeID,
+				AccessDescriptor: req.SelectiveAccessInfo,
+			}},
+		}
+
+		return s.setBlock(req.InvokePriority, req.DataBlock)
+
+	case dlms.SetRequestWithListAndFirstDataBlock:
+		s.setTransfer = nil
+
+		if !s.isAccepted(dlms.ConformanceBlockBlockTransferWithSetOrWrite) || !s.isAccepted(dlms.ConformanceBlockMultipleReferences) {
+			return nil
+		}
+
+		if req.DataBlock.BlockNumber != 1 {
+			return dlms.CreateSetResponseLastDataBlock(req.InvokePriority, dlms.TagAccDataBlockNumberInvalid, req.DataBlock.BlockNumber)
+		}
+
+		s.setTransfer = &blockTransfer{isList: true, attributes: req.AttributeInfoList}
+
+		return s.setBlock(req.InvokePriority, req.DataBlock)
+
+	case dlms.SetRequestWithDataBlock:
+		if s.setTransfer == nil {
+			return dlms.CreateSetResponseLastDataBlock(req.InvokePriority, dlms.TagAccNoLongSetInProgress, req.DataBlock.BlockNumber)
+		}
+
+		if req.DataBlock.BlockNumber != s.setTransfer.blockNumber+1 {
+			s.setTransfer = nil
+			return dlms.CreateSetResponseLastDataBlock(req.InvokePriority, dlms.TagAccDataBlockNumberInvalid, req.DataBlock.BlockNumber)
+		}
+
+		return s.setBlock(req.InvokePriority, req.DataBlock)
+
+	case dlms.SetRequestWithList:
+		s.setTransfer = nil
+
+		if !s.isAccepted(dlms.ConformanceBlockMultipleReferences) {
+			return nil
+		}
+
+		results := s.setList(req.AttributeInfoList, req.ValueList)
+		return dlms.CreateSetResponseWithList(req.InvokePriority, results)
+	}
+
+	return nil
+}
+
+func (s *server) setBlock(invokePriority uint8, block dlms.DataBlockSA) dlms.CosemPDU {
+	t := s.setTransfer
+	t.blockNumber = block.BlockNumber
+	t.raw = append(t.raw, block.Raw...)
+
+	if !block.LastBlock {
+		return dlms.CreateSetResponseDataBlock(invokePriority, t.blockNumber)
+	}
+
+	s.setTransfer = nil
+
+	values, err := decodeDataList(t.raw, t.isList)
+	if err != nil || len(values) != len(t.attributes) {
+		s.logf("error decoding set data: %v", err)
+		values = nil
+	}
+
+	if !t.isList {
+		result := dlms.TagAccTypeUnmatched
+		if values != nil {
+			result = s.setList(t.attributes, values)[0]
+		}
+
+		return dlms.CreateSetResponseLastDataBlock(invokePriority, result, t.blockNumber)
+	}
+
+	var results []dlms.AccessResultTag
+	if values != nil {
+		results = s.setList(t.attributes, values)
+	} else {
+		results = make([]dlms.AccessResultTag, len(t.attributes))
+		for i := range results {
+			results[i] = dlms.TagAccTypeUnmatched
+		}
+	}
+
+	return dlms.CreateSetResponseLastDataBlockWithList(invokePriority, results, t.blockNumber)
+}
+
+func (s *server) setList(attributes []dlms.AttributeDescriptorWithSelection, values []axdr.DlmsData) []dlms.AccessResultTag {
+	results := make([]dlms.AccessResultTag, len(attributes))
+
+	for i, att := range attributes {
+		if i >= len(values) {
+			results[i] = dlms.TagAccOtherReason
+			continue
+		}
+
+		ad := dlms.AttributeDescriptor{ClassID: att.ClassID, InstanceID: att.InstanceID, AttributeID: att.AttributeID}
+		results[i] = s.registry.set(ad, att.AccessDescriptor, values[i])
+	}
+
+	return results
+}
+
+// decodeDataList decodes the raw data of a block transfer. If it's a list, raw data is
+// a sequence of data prefixed by its length.
+func decodeDataList(raw []byte, isList bool) ([]axdr.DlmsData, error) {
+	count := uint64(1)
+	if isList {
+		var err error
+		_, count, err = axdr.DecodeLength(&raw)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	values := make([]axdr.DlmsData, 0, count)
+	for i := uint64(0); i < count; i++ {
+		decoder := axdr.NewDataDecoder(&raw)
+		data, err := decoder.Decode(&raw)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, data)
+	}
+
+	if len(raw) != 0 {
+		return nil, fmt.Errorf("%d unexpected bytes after data", len(raw))
+	}
+
+	return values, nil
+}
@@ -8,6 +8,7 @@ import (
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Circutor/gosem/pkg/dlms"
@@ -24,24 +25,36 @@ type tcp struct {
 	dc          dlms.DataChannel
 	conn        net.Conn
 	isConnected bool
+	stop        chan struct{}
+	done        chan struct{}
 	logger      *log.Logger
+	dial        func(address string, timeout time.Duration) (net.Conn, error)
+	mutex       sync.Mutex
 }
 
 func New(port int, host string, timeout time.Duration) dlms.Transport {
-	t := &tcp{
+	return newTCP(port, host, timeout, dialTCP)
+}
+
+func newTCP(port int, host string, timeout time.Duration, dial func(address string, timeout time.Duration) (net.Conn, error)) *tcp {
+	return &tcp{
 		port:        port,
 		host:        host,
 		timeout:     timeout,
 		dc:          nil,
 		isConnected: false,
 		logger:      nil,
+		dial:        dial,
+		mutex:       sync.Mutex{},
 	}
-
-	return t
 }
 
 func (t *tcp) Close() {
 	t.Disconnect()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
 	if t.dc != nil {
 		close(t.dc)
 		t.dc = nil
@@ -49,10 +62,13 @@ func (t *tcp) Close() {
 }
 
 func (t *tcp) Connect() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
 	if !t.isConnected {
 		address := net.JoinHostPort(t.host, strconv.Itoa(t.port))
 
-		conn, err := net.DialTimeout("tcp", address, t.timeout)
+		conn, err := t.dial(address, t.timeout)
 		if err != nil {
 			if t.logger != nil {
 				t.logger.Printf("Connect to %s failed: %v", address, err)
@@ -65,16 +81,33 @@ func (t *tcp) Connect() error {
 			t.logger.Printf("Connected to %s", address)
 		}
 
-		go t.manager()
-
 		t.conn = conn
 		t.isConnected = true
+		t.stop = make(chan struct{})
+		t.done = make(chan struct{})
+
+		go t.manager(conn, t.stop, t.done)
 	}
 
 	return nil
 }
 
+// Disconnect closes the connection and waits for the reception to end.
 func (t *tcp) Disconnect() error {
+	t.mutex.Lock()
+	done := t.done
+	t.disconnect()
+	t.mutex.Unlock()
+
+	if done != nil {
+		<-done
+	}
+
+	return nil
+}
+
+// disconnect closes the connection, with the mutex locked.
+func (t *tcp) disconnect() {
 	if t.isConnected {
 		t.isConnected = false
 
@@ -83,15 +116,18 @@ func (t *tcp) Disconnect() error {
 			t.conn = nil
 		}
 
+		close(t.stop)
+
 		if t.logger != nil {
 			t.logger.Printf("Disconnected from %s", t.host)
 		}
 	}
-
-	return nil
 }
 
 func (t *tcp) IsConnected() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
 	return t.isConnected
 }
 
@@ -99,17 +135,24 @@ func (t *tcp) SetAddress(client int, server int) {
 }
 
 func (t *tcp) SetReception(dc dlms.DataChannel) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
 	t.dc = dc
 }
 
 func (t *tcp) Send(src []byte) error {
-	if !t.isConnected {
+	t.mutex.Lock()
+	conn := t.conn
+	t.mutex.Unlock()
+
+	if conn == nil {
 		return fmt.Errorf("not connected")
 	}
 
-	t.conn.SetWriteDeadline(time.Now().Add(t.timeout))
+	conn.SetWriteDeadline(time.Now().Add(t.timeout))
 
-	_, err := t.conn.Write(src)
+	_, err := conn.Write(src)
 	if err != nil {
 		t.Disconnect()
 		return fmt.Errorf("write failed: %w", err)
@@ -126,29 +169,40 @@ func (t *tcp) SetLogger(logger *log.Logger) {
 	t.logger = logger
 }
 
-func (t *tcp) manager() {
-	for {
-		if !t.isConnected {
-			return
-		}
+// manager receives the data of a connection until it's closed, and then closes done.
+func (t *tcp) manager(conn net.Conn, stop chan struct{}, done chan struct{}) {
+	defer close(done)
 
-		data, err := t.read()
+	for {
+		data, err := t.read(conn)
 		if err != nil {
-			t.Disconnect()
+			t.mutex.Lock()
+			if t.conn == conn {
+				t.disconnect()
+			}
+			t.mutex.Unlock()
 
 			return
 		}
 
-		if len(data) > 0 && t.dc != nil {
-			t.dc <- data
+		t.mutex.Lock()
+		dc := t.dc
+		t.mutex.Unlock()
+
+		if len(data) > 0 && dc != nil {
+			select {
+			case dc <- data:
+			case <-stop:
+				return
+			}
 		}
 	}
 }
 
-func (t *tcp) read() ([]byte, error) {
+func (t *tcp) read(conn net.Conn) ([]byte, error) {
 	rxBuffer := make([]byte, maxLength)
 
-	rxLen, err := t.conn.Read(rxBuffer)
+	rxLen, err := conn.Read(rxBuffer)
 	if err != nil {
 		var netErr net.Error
 		if !errors.As(err, &netErr) || !netErr.Timeout() {
@@ -163,6 +217,10 @@ func (t *tcp) read() ([]byte, error) {
 	return rxBuffer[:rxLen], nil
 }
 
+func dialTCP(address string, timeout time.Duration) (net.Conn, error) {
+	return net.DialTimeout("tcp", address, timeout)
+}
+
 func encodeHexString(b []byte) string {
 	return strings.ToUpper(hex.EncodeToString(b))
 }
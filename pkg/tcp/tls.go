@@ -0,0 +1,114 @@
+package tcp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/dlms"
+)
+
+var ErrPinMismatch = errors.New("server certificate does not match any pinned key")
+
+// TLSConfig defines the security parameters of a TLS connection.
+type TLSConfig struct {
+	// Certificates presented to the server for client authentication.
+	Certificates []tls.Certificate
+	// RootCAs used to verify the server certificate. If nil, the system pool is used.
+	RootCAs *x509.CertPool
+	// ServerName used for SNI and certificate verification. If empty, host is used.
+	ServerName string
+	// PinnedKeys holds SHA-256 hashes of the accepted server SubjectPublicKeyInfo.
+	// If not empty, the leaf certificate must match one of them.
+	PinnedKeys [][]byte
+	// InsecureSkipVerify disables the chain verification. Pinning is still applied.
+	InsecureSkipVerify bool
+	// MinVersion is the minimum TLS version accepted. Defaults to TLS 1.2.
+	MinVersion uint16
+}
+
+// NewTLS creates a TCP transport whose connection is protected with TLS.
+func NewTLS(port int, host string, timeout time.Duration, config TLSConfig) dlms.Transport {
+	tlsConfig := config.build(host)
+
+	return newTCP(port, host, timeout, func(address string, timeout time.Duration) (net.Conn, error) {
+		dialer := &net.Dialer{Timeout: timeout}
+		return tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	})
+}
+
+// LoadCertPool creates a certificate pool from PEM encoded files.
+func LoadCertPool(files ...string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+
+	for _, file := range files {
+		pem, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file failed: %w", err)
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in %s", file)
+		}
+	}
+
+	return pool, nil
+}
+
+// PublicKeyPin returns the SHA-256 hash of the certificate SubjectPublicKeyInfo,
+// suitable to be used in TLSConfig.PinnedKeys.
+func PublicKeyPin(cert *x509.Certificate) []byte {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return sum[:]
+}
+
+func (c TLSConfig) build(host string) *tls.Config {
+	serverName := c.ServerName
+	if serverName == "" {
+		serverName = host
+	}
+
+	minVersion := c.MinVersion
+	if minVersion == 0 {
+		minVersion = tls.VersionTLS12
+	}
+
+	//nolint:gosec // InsecureSkipVerify is an explicit user choice, usually combined with pinning.
+	tlsConfig := &tls.Config{
+		Certificates:       c.Certificates,
+		RootCAs:            c.RootCAs,
+		ServerName:         serverName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		MinVersion:         minVersion,
+	}
+
+	if len(c.PinnedKeys) > 0 {
+		pins := c.PinnedKeys
+		tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyPin(cs, pins)
+		}
+	}
+
+	return tlsConfig
+}
+
+func verifyPin(cs tls.ConnectionState, pins [][]byte) error {
+	if len(cs.PeerCertificates) == 0 {
+		return ErrPinMismatch
+	}
+
+	pin := PublicKeyPin(cs.PeerCertificates[0])
+	for _, p := range pins {
+		if bytes.Equal(p, pin) {
+			return nil
+		}
+	}
+
+	return ErrPinMismatch
+}
@@ -0,0 +1,139 @@
+package tcp_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Circutor/gosem/pkg/dlms"
+	"github.com/Circutor/gosem/pkg/tcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLS_SendReceive(t *testing.T) {
+	cert, leaf := generateCertificate(t)
+	port := startEchoServer(t, cert)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+
+	tr := tcp.NewTLS(port, "127.0.0.1", time.Second, tcp.TLSConfig{
+		RootCAs:    pool,
+		ServerName: "localhost",
+		PinnedKeys: [][]byte{tcp.PublicKeyPin(leaf)},
+	})
+
+	dc := make(dlms.DataChannel, 1)
+	tr.SetReception(dc)
+
+	require.NoError(t, tr.Connect())
+	assert.True(t, tr.IsConnected())
+
+	assert.NoError(t, tr.Send([]byte{0x01, 0x02, 0x03}))
+
+	select {
+	case data := <-dc:
+		assert.Equal(t, []byte{0x01, 0x02, 0x03}, data)
+	case <-time.After(time.Second):
+		t.Fatal("no data received")
+	}
+
+	assert.NoError(t, tr.Disconnect())
+	assert.False(t, tr.IsConnected())
+}
+
+func TestTLS_ConnectFail(t *testing.T) {
+	cert, leaf := generateCertificate(t)
+	port := startEchoServer(t, cert)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+
+	// Unknown CA
+	tr := tcp.NewTLS(port, "127.0.0.1", time.Second, tcp.TLSConfig{ServerName: "localhost"})
+	assert.Error(t, tr.Connect())
+	assert.False(t, tr.IsConnected())
+
+	// Invalid SNI
+	tr = tcp.NewTLS(port, "127.0.0.1", time.Second, tcp.TLSConfig{RootCAs: pool, ServerName: "meter.invalid"})
+	assert.Error(t, tr.Connect())
+
+	// Pin mismatch, even skipping chain verification
+	tr = tcp.NewTLS(port, "127.0.0.1", time.Second, tcp.TLSConfig{
+		InsecureSkipVerify: true,
+		PinnedKeys:         [][]byte{make([]byte, 32)},
+	})
+	err := tr.Connect()
+	assert.ErrorIs(t, err, tcp.ErrPinMismatch)
+}
+
+func generateCertificate(t *testing.T) (tls.Certificate, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, leaf
+}
+
+func startEchoServer(t *testing.T, cert tls.Certificate) int {
+	t.Helper()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+
+				buf := make([]byte, 2048)
+				for {
+					n, err := conn.Read(buf)
+					if err != nil {
+						return
+					}
+
+					conn.Write(buf[:n])
+				}
+			}(conn)
+		}
+	}()
+
+	return ln.Addr().(*net.TCPAddr).Port
+}